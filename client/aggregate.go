@@ -0,0 +1,527 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// aggregateSeparator joins a child's namespace and the original name of a
+// colliding tool, prompt or resource URI.
+const aggregateSeparator = "__"
+
+// aggregateRoute maps a name exposed by an AggregateClient back to the child
+// that owns it and the name the child knows it by.
+type aggregateRoute struct {
+	child *Client
+	name  string
+}
+
+// AggregateClient presents several MCP clients as a single server. Tools,
+// prompts and resources of all children are merged into one list and calls are
+// routed to the child that provides them.
+//
+// Names that are unique across children are exposed unchanged. When several
+// children provide a tool or prompt with the same name, or a resource with the
+// same URI, every colliding entry is exposed as "<namespace>__<name>", where the
+// namespace is the child's server name as reported during initialization, or
+// "server<N>" (1-based) if the name is empty or shared with another child.
+//
+// The ByPage list methods page through all children at once: each page holds
+// the next page of every child that has entries left, and its cursor records
+// where each child's listing stands. Collisions are then detected among the
+// entries of the same page.
+//
+// Each child keeps its own connection and request IDs, so responses are always
+// correlated by the child that sent the request. The children must be started
+// and initialized by the caller; Close closes all of them.
+type AggregateClient struct {
+	children []*Client
+
+	mu             sync.RWMutex
+	toolRoutes     map[string]aggregateRoute
+	promptRoutes   map[string]aggregateRoute
+	resourceRoutes map[string]aggregateRoute
+
+	notifyMu      sync.RWMutex
+	notifications []func(mcp.JSONRPCNotification)
+}
+
+// NewAggregateClient creates an AggregateClient over the given clients.
+// Notifications received by any child are forwarded to the handlers registered
+// with AggregateClient.OnNotification.
+func NewAggregateClient(clients ...*Client) *AggregateClient {
+	a := &AggregateClient{
+		children:       clients,
+		toolRoutes:     make(map[string]aggregateRoute),
+		promptRoutes:   make(map[string]aggregateRoute),
+		resourceRoutes: make(map[string]aggregateRoute),
+	}
+	for _, child := range clients {
+		child.OnNotification(a.dispatchNotification)
+	}
+	return a
+}
+
+// Clients returns the child clients in the order they were given.
+func (a *AggregateClient) Clients() []*Client {
+	return append([]*Client(nil), a.children...)
+}
+
+// OnNotification registers a handler function to be called when any child
+// receives a notification. Multiple handlers can be registered and will be
+// called in the order they were added.
+func (a *AggregateClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	a.notifyMu.Lock()
+	defer a.notifyMu.Unlock()
+	a.notifications = append(a.notifications, handler)
+}
+
+func (a *AggregateClient) dispatchNotification(notification mcp.JSONRPCNotification) {
+	a.notifyMu.RLock()
+	handlers := make([]func(mcp.JSONRPCNotification), len(a.notifications))
+	copy(handlers, a.notifications)
+	a.notifyMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(notification)
+	}
+}
+
+// Close closes all children and returns the joined errors, if any.
+func (a *AggregateClient) Close() error {
+	var errs []error
+	for _, child := range a.children {
+		if err := child.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListTools lists the tools of all children, following every child's
+// cursors to the end. Colliding names are namespaced.
+func (a *AggregateClient) ListTools(
+	ctx context.Context,
+	request mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, error) {
+	return a.listTools(ctx, request, (*Client).ListTools)
+}
+
+// ListToolsByPage lists the next page of every child that has tools left.
+// Pass the returned NextCursor to get the following page; it is empty once
+// all children have been listed. Colliding names are namespaced.
+func (a *AggregateClient) ListToolsByPage(
+	ctx context.Context,
+	request mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, error) {
+	return a.listTools(ctx, request, (*Client).ListToolsByPage)
+}
+
+func (a *AggregateClient) listTools(
+	ctx context.Context,
+	request mcp.ListToolsRequest,
+	list func(*Client, context.Context, mcp.ListToolsRequest) (*mcp.ListToolsResult, error),
+) (*mcp.ListToolsResult, error) {
+	cursors, err := a.decodeCursor(request.Params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	perChild := make([][]mcp.Tool, len(a.children))
+	names := make([][]string, len(a.children))
+	next := make([]*mcp.Cursor, len(a.children))
+	for i, child := range a.children {
+		if cursors[i] == nil {
+			continue
+		}
+		request.Params.Cursor = *cursors[i]
+		result, err := list(child, ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools of %s: %w", a.namespace(i), err)
+		}
+		perChild[i] = result.Tools
+		for _, tool := range result.Tools {
+			names[i] = append(names[i], tool.Name)
+		}
+		next[i] = nextChildCursor(result.NextCursor)
+	}
+
+	routes, exposed := a.buildRoutes(names)
+	result := &mcp.ListToolsResult{}
+	result.NextCursor = encodeAggregateCursor(next)
+	for i, tools := range perChild {
+		for j, tool := range tools {
+			tool.Name = exposed[i][j]
+			result.Tools = append(result.Tools, tool)
+		}
+	}
+
+	a.storeRoutes(&a.toolRoutes, routes, cursors)
+	return result, nil
+}
+
+// CallTool calls the tool on the child that provides it.
+func (a *AggregateClient) CallTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	route, err := a.route(ctx, request.Params.Name, a.lookupTool, func(ctx context.Context) error {
+		_, err := a.ListTools(ctx, mcp.ListToolsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !route.ok() {
+		return nil, fmt.Errorf("tool %q not found", request.Params.Name)
+	}
+	request.Params.Name = route.name
+	return route.child.CallTool(ctx, request)
+}
+
+// ListPrompts lists the prompts of all children, following every child's
+// cursors to the end. Colliding names are namespaced.
+func (a *AggregateClient) ListPrompts(
+	ctx context.Context,
+	request mcp.ListPromptsRequest,
+) (*mcp.ListPromptsResult, error) {
+	return a.listPrompts(ctx, request, (*Client).ListPrompts)
+}
+
+// ListPromptsByPage lists the next page of every child that has prompts left.
+// Pass the returned NextCursor to get the following page; it is empty once
+// all children have been listed. Colliding names are namespaced.
+func (a *AggregateClient) ListPromptsByPage(
+	ctx context.Context,
+	request mcp.ListPromptsRequest,
+) (*mcp.ListPromptsResult, error) {
+	return a.listPrompts(ctx, request, (*Client).ListPromptsByPage)
+}
+
+func (a *AggregateClient) listPrompts(
+	ctx context.Context,
+	request mcp.ListPromptsRequest,
+	list func(*Client, context.Context, mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error),
+) (*mcp.ListPromptsResult, error) {
+	cursors, err := a.decodeCursor(request.Params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	perChild := make([][]mcp.Prompt, len(a.children))
+	names := make([][]string, len(a.children))
+	next := make([]*mcp.Cursor, len(a.children))
+	for i, child := range a.children {
+		if cursors[i] == nil {
+			continue
+		}
+		request.Params.Cursor = *cursors[i]
+		result, err := list(child, ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prompts of %s: %w", a.namespace(i), err)
+		}
+		perChild[i] = result.Prompts
+		for _, prompt := range result.Prompts {
+			names[i] = append(names[i], prompt.Name)
+		}
+		next[i] = nextChildCursor(result.NextCursor)
+	}
+
+	routes, exposed := a.buildRoutes(names)
+	result := &mcp.ListPromptsResult{}
+	result.NextCursor = encodeAggregateCursor(next)
+	for i, prompts := range perChild {
+		for j, prompt := range prompts {
+			prompt.Name = exposed[i][j]
+			result.Prompts = append(result.Prompts, prompt)
+		}
+	}
+
+	a.storeRoutes(&a.promptRoutes, routes, cursors)
+	return result, nil
+}
+
+// GetPrompt gets the prompt from the child that provides it.
+func (a *AggregateClient) GetPrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	route, err := a.route(ctx, request.Params.Name, a.lookupPrompt, func(ctx context.Context) error {
+		_, err := a.ListPrompts(ctx, mcp.ListPromptsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !route.ok() {
+		return nil, fmt.Errorf("prompt %q not found", request.Params.Name)
+	}
+	request.Params.Name = route.name
+	return route.child.GetPrompt(ctx, request)
+}
+
+// ListResources lists the resources of all children, following every child's
+// cursors to the end. Colliding URIs are namespaced.
+func (a *AggregateClient) ListResources(
+	ctx context.Context,
+	request mcp.ListResourcesRequest,
+) (*mcp.ListResourcesResult, error) {
+	return a.listResources(ctx, request, (*Client).ListResources)
+}
+
+// ListResourcesByPage lists the next page of every child that has resources left.
+// Pass the returned NextCursor to get the following page; it is empty once
+// all children have been listed. Colliding URIs are namespaced.
+func (a *AggregateClient) ListResourcesByPage(
+	ctx context.Context,
+	request mcp.ListResourcesRequest,
+) (*mcp.ListResourcesResult, error) {
+	return a.listResources(ctx, request, (*Client).ListResourcesByPage)
+}
+
+func (a *AggregateClient) listResources(
+	ctx context.Context,
+	request mcp.ListResourcesRequest,
+	list func(*Client, context.Context, mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error),
+) (*mcp.ListResourcesResult, error) {
+	cursors, err := a.decodeCursor(request.Params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	perChild := make([][]mcp.Resource, len(a.children))
+	uris := make([][]string, len(a.children))
+	next := make([]*mcp.Cursor, len(a.children))
+	for i, child := range a.children {
+		if cursors[i] == nil {
+			continue
+		}
+		request.Params.Cursor = *cursors[i]
+		result, err := list(child, ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources of %s: %w", a.namespace(i), err)
+		}
+		perChild[i] = result.Resources
+		for _, resource := range result.Resources {
+			uris[i] = append(uris[i], resource.URI)
+		}
+		next[i] = nextChildCursor(result.NextCursor)
+	}
+
+	routes, exposed := a.buildRoutes(uris)
+	result := &mcp.ListResourcesResult{}
+	result.NextCursor = encodeAggregateCursor(next)
+	for i, resources := range perChild {
+		for j, resource := range resources {
+			resource.URI = exposed[i][j]
+			result.Resources = append(result.Resources, resource)
+		}
+	}
+
+	a.storeRoutes(&a.resourceRoutes, routes, cursors)
+	return result, nil
+}
+
+// ReadResource reads the resource from the child that provides it. Contents
+// returned for a namespaced URI are reported under that namespaced URI.
+func (a *AggregateClient) ReadResource(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	exposedURI := request.Params.URI
+	route, err := a.route(ctx, exposedURI, a.lookupResource, func(ctx context.Context) error {
+		_, err := a.ListResources(ctx, mcp.ListResourcesRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !route.ok() {
+		return nil, fmt.Errorf("resource %q not found", exposedURI)
+	}
+	request.Params.URI = route.name
+	result, err := route.child.ReadResource(ctx, request)
+	if err != nil || route.name == exposedURI {
+		return result, err
+	}
+
+	for i, contents := range result.Contents {
+		switch c := contents.(type) {
+		case mcp.TextResourceContents:
+			if c.URI == route.name {
+				c.URI = exposedURI
+			}
+			result.Contents[i] = c
+		case mcp.BlobResourceContents:
+			if c.URI == route.name {
+				c.URI = exposedURI
+			}
+			result.Contents[i] = c
+		}
+	}
+	return result, nil
+}
+
+func (r aggregateRoute) ok() bool {
+	return r.child != nil
+}
+
+func (a *AggregateClient) lookupTool(name string) aggregateRoute {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.toolRoutes[name]
+}
+
+func (a *AggregateClient) lookupPrompt(name string) aggregateRoute {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.promptRoutes[name]
+}
+
+func (a *AggregateClient) lookupResource(uri string) aggregateRoute {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.resourceRoutes[uri]
+}
+
+// route looks up name and, if it is unknown, refreshes the routes once in
+// case the caller did not list first or a child's list changed since.
+func (a *AggregateClient) route(
+	ctx context.Context,
+	name string,
+	lookup func(string) aggregateRoute,
+	refresh func(context.Context) error,
+) (aggregateRoute, error) {
+	if route := lookup(name); route.ok() {
+		return route, nil
+	}
+	if err := refresh(ctx); err != nil {
+		return aggregateRoute{}, err
+	}
+	return lookup(name), nil
+}
+
+// storeRoutes replaces the routes in target with those of a first page, and
+// adds those of later pages.
+func (a *AggregateClient) storeRoutes(target *map[string]aggregateRoute, routes map[string]aggregateRoute, cursors []*mcp.Cursor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if isFirstPage(cursors) {
+		*target = routes
+		return
+	}
+	maps.Copy(*target, routes)
+}
+
+// decodeCursor returns the cursor of each child for the page after cursor, or
+// nil for children that have no entries left. The empty cursor starts all
+// children from their first page.
+func (a *AggregateClient) decodeCursor(cursor mcp.Cursor) ([]*mcp.Cursor, error) {
+	cursors := make([]*mcp.Cursor, len(a.children))
+	if cursor == "" {
+		for i := range cursors {
+			cursors[i] = new(mcp.Cursor)
+		}
+		return cursors, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err == nil {
+		err = json.Unmarshal(data, &cursors)
+	}
+	if err != nil || len(cursors) != len(a.children) {
+		return nil, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return cursors, nil
+}
+
+// encodeAggregateCursor returns the cursor recording the per-child cursors,
+// or the empty cursor once every child has been listed completely.
+func encodeAggregateCursor(cursors []*mcp.Cursor) mcp.Cursor {
+	for _, cursor := range cursors {
+		if cursor != nil {
+			data, _ := json.Marshal(cursors)
+			return mcp.Cursor(base64.RawURLEncoding.EncodeToString(data))
+		}
+	}
+	return ""
+}
+
+// nextChildCursor returns the cursor to continue a child's listing with, or
+// nil if the child returned its last page.
+func nextChildCursor(cursor mcp.Cursor) *mcp.Cursor {
+	if cursor == "" {
+		return nil
+	}
+	return &cursor
+}
+
+func isFirstPage(cursors []*mcp.Cursor) bool {
+	for _, cursor := range cursors {
+		if cursor == nil || *cursor != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// buildRoutes assigns exposed names to the names listed by each child,
+// namespacing every name that more than one child provides. It returns the
+// routes by exposed name and the exposed names in the order of names.
+func (a *AggregateClient) buildRoutes(names [][]string) (map[string]aggregateRoute, [][]string) {
+	owners := make(map[string]int)
+	for _, childNames := range names {
+		seen := make(map[string]bool, len(childNames))
+		for _, name := range childNames {
+			if !seen[name] {
+				seen[name] = true
+				owners[name]++
+			}
+		}
+	}
+
+	routes := make(map[string]aggregateRoute)
+	exposed := make([][]string, len(names))
+	for i, childNames := range names {
+		exposed[i] = make([]string, len(childNames))
+		for j, name := range childNames {
+			exposedName := name
+			if owners[name] > 1 {
+				exposedName = a.namespace(i) + aggregateSeparator + name
+			}
+			exposed[i][j] = exposedName
+			routes[exposedName] = aggregateRoute{child: a.children[i], name: name}
+		}
+	}
+	return routes, exposed
+}
+
+// namespace returns the prefix used for colliding entries of the i-th child.
+func (a *AggregateClient) namespace(i int) string {
+	name := sanitizeNamespace(a.children[i].GetServerInfo().Name)
+	if name == "" {
+		return fmt.Sprintf("server%d", i+1)
+	}
+	for j, other := range a.children {
+		if j != i && sanitizeNamespace(other.GetServerInfo().Name) == name {
+			return fmt.Sprintf("server%d", i+1)
+		}
+	}
+	return name
+}
+
+// sanitizeNamespace replaces characters that are not allowed in tool names.
+func sanitizeNamespace(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(name))
+}
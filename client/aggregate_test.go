@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newAggregateChild(t *testing.T, name string, tools ...string) *Client {
+	t.Helper()
+
+	mcpServer := server.NewMCPServer(name, "1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(true),
+		server.WithResourceCapabilities(false, false),
+	)
+	for _, tool := range tools {
+		mcpServer.AddTool(mcp.NewTool(tool), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name + ":" + request.Params.Name), nil
+		})
+	}
+	mcpServer.AddPrompt(mcp.NewPrompt("greet"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult(name, nil), nil
+	})
+	mcpServer.AddResource(mcp.NewResource("config://"+name, name), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, Text: name},
+		}, nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("NewInProcessClient failed: %v", err)
+	}
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return client
+}
+
+func TestAggregateClient(t *testing.T) {
+	ctx := context.Background()
+	weather := newAggregateChild(t, "weather", "forecast", "search")
+	docs := newAggregateChild(t, "docs", "lookup", "search")
+
+	aggregate := NewAggregateClient(weather, docs)
+	defer aggregate.Close()
+
+	t.Run("CallTool before ListTools resolves routes", func(t *testing.T) {
+		result, err := aggregate.CallTool(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "lookup"},
+		})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if got := result.Content[0].(mcp.TextContent).Text; got != "docs:lookup" {
+			t.Errorf("expected docs:lookup, got %s", got)
+		}
+	})
+
+	t.Run("ListTools namespaces collisions only", func(t *testing.T) {
+		result, err := aggregate.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		sort.Strings(names)
+		expected := []string{"docs__search", "forecast", "lookup", "weather__search"}
+		if len(names) != len(expected) {
+			t.Fatalf("expected tools %v, got %v", expected, names)
+		}
+		for i := range expected {
+			if names[i] != expected[i] {
+				t.Errorf("expected tools %v, got %v", expected, names)
+				break
+			}
+		}
+	})
+
+	t.Run("CallTool routes namespaced names to the owning child", func(t *testing.T) {
+		for name, expected := range map[string]string{
+			"weather__search": "weather:search",
+			"docs__search":    "docs:search",
+			"forecast":        "weather:forecast",
+		} {
+			result, err := aggregate.CallTool(ctx, mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: name},
+			})
+			if err != nil {
+				t.Fatalf("CallTool(%s) failed: %v", name, err)
+			}
+			if got := result.Content[0].(mcp.TextContent).Text; got != expected {
+				t.Errorf("CallTool(%s): expected %s, got %s", name, expected, got)
+			}
+		}
+
+		if _, err := aggregate.CallTool(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "search"},
+		}); err == nil {
+			t.Error("expected ambiguous tool name to fail")
+		}
+	})
+
+	t.Run("prompts and resources", func(t *testing.T) {
+		prompts, err := aggregate.ListPrompts(ctx, mcp.ListPromptsRequest{})
+		if err != nil {
+			t.Fatalf("ListPrompts failed: %v", err)
+		}
+		if len(prompts.Prompts) != 2 {
+			t.Fatalf("expected 2 prompts, got %d", len(prompts.Prompts))
+		}
+		prompt, err := aggregate.GetPrompt(ctx, mcp.GetPromptRequest{
+			Params: mcp.GetPromptParams{Name: "docs__greet"},
+		})
+		if err != nil {
+			t.Fatalf("GetPrompt failed: %v", err)
+		}
+		if prompt.Description != "docs" {
+			t.Errorf("expected prompt from docs, got %q", prompt.Description)
+		}
+
+		resource, err := aggregate.ReadResource(ctx, mcp.ReadResourceRequest{
+			Params: mcp.ReadResourceParams{URI: "config://weather"},
+		})
+		if err != nil {
+			t.Fatalf("ReadResource failed: %v", err)
+		}
+		if got := resource.Contents[0].(mcp.TextResourceContents).Text; got != "weather" {
+			t.Errorf("expected weather resource, got %s", got)
+		}
+	})
+}
+
+func TestAggregateClient_Pagination(t *testing.T) {
+	ctx := context.Background()
+	newChild := func(name string, tools ...string) *Client {
+		mcpServer := server.NewMCPServer(name, "1.0.0",
+			server.WithToolCapabilities(true),
+			server.WithPaginationLimit(1),
+		)
+		for _, tool := range tools {
+			mcpServer.AddTool(mcp.NewTool(tool), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(name + ":" + request.Params.Name), nil
+			})
+		}
+		client, err := NewInProcessClient(mcpServer)
+		if err != nil {
+			t.Fatalf("NewInProcessClient failed: %v", err)
+		}
+		if err := client.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		if _, err := client.Initialize(ctx, initRequest); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		return client
+	}
+
+	aggregate := NewAggregateClient(newChild("weather", "alerts", "forecast", "radar"), newChild("docs", "lookup"))
+	defer aggregate.Close()
+
+	var pages [][]string
+	var request mcp.ListToolsRequest
+	for {
+		result, err := aggregate.ListToolsByPage(ctx, request)
+		if err != nil {
+			t.Fatalf("ListToolsByPage failed: %v", err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		pages = append(pages, names)
+		if result.NextCursor == "" {
+			break
+		}
+		if len(pages) > 5 {
+			t.Fatalf("listing did not end, pages so far: %v", pages)
+		}
+		request.Params.Cursor = result.NextCursor
+	}
+
+	// Each page continues every child that has tools left
+	var all []string
+	for _, page := range pages {
+		all = append(all, page...)
+	}
+	if expected := []string{"alerts", "lookup", "forecast", "radar"}; !reflect.DeepEqual(all, expected) {
+		t.Errorf("expected tools %v, got pages %v", expected, pages)
+	}
+	if expected := []string{"alerts", "lookup"}; !reflect.DeepEqual(pages[0], expected) {
+		t.Errorf("expected first page %v, got %v", expected, pages[0])
+	}
+
+	// Routes of later pages are kept
+	result, err := aggregate.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "radar"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if got := result.Content[0].(mcp.TextContent).Text; got != "weather:radar" {
+		t.Errorf("expected weather:radar, got %s", got)
+	}
+
+	// Resolving an unknown name lists all pages
+	fresh := NewAggregateClient(aggregate.Clients()...)
+	result, err = fresh.CallTool(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "radar"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if got := result.Content[0].(mcp.TextContent).Text; got != "weather:radar" {
+		t.Errorf("expected weather:radar, got %s", got)
+	}
+
+	// ListTools continues from a page cursor to the end
+	first, err := aggregate.ListToolsByPage(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListToolsByPage failed: %v", err)
+	}
+	request.Params.Cursor = first.NextCursor
+	rest, err := aggregate.ListTools(ctx, request)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(rest.Tools) != 2 || rest.NextCursor != "" {
+		t.Errorf("expected the 2 remaining tools and no cursor, got %d tools and cursor %q", len(rest.Tools), rest.NextCursor)
+	}
+
+	request.Params.Cursor = "not a cursor"
+	if _, err := aggregate.ListToolsByPage(ctx, request); err == nil {
+		t.Error("expected an invalid cursor to fail")
+	}
+}
+
+func TestAggregateClient_NotificationFanIn(t *testing.T) {
+	first := &mockNotifyingTransport{}
+	second := &mockNotifyingTransport{}
+	firstClient := NewClient(first)
+	secondClient := NewClient(second)
+	for _, c := range []*Client{firstClient, secondClient} {
+		if err := c.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+	}
+
+	aggregate := NewAggregateClient(firstClient, secondClient)
+	defer aggregate.Close()
+
+	var received []string
+	aggregate.OnNotification(func(notification mcp.JSONRPCNotification) {
+		received = append(received, notification.Method)
+	})
+
+	first.notify(mcp.MethodNotificationToolsListChanged)
+	second.notify(mcp.MethodNotificationResourcesListChanged)
+
+	if len(received) != 2 ||
+		received[0] != mcp.MethodNotificationToolsListChanged ||
+		received[1] != mcp.MethodNotificationResourcesListChanged {
+		t.Errorf("expected notifications from both children, got %v", received)
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithoutCapabilityChecks sends requests for resources, prompts, tools and
+// logging even when the server did not declare the matching capability at
+// initialization. By default such requests fail with
+// ErrCapabilityNotSupported without being sent.
+func WithoutCapabilityChecks() ClientOption {
+	return func(c *Client) {
+		c.noCapabilityChecks = true
+	}
+}
+
+// checkServerCapability returns an error matching ErrCapabilityNotSupported
+// if method needs a capability the server did not declare. Clients that did
+// not initialize themselves, such as those created with WithSession, do not
+// know the server capabilities and skip the check.
+func (c *Client) checkServerCapability(method string) error {
+	if c.noCapabilityChecks || !c.serverCapabilitiesKnown {
+		return nil
+	}
+
+	capabilities := c.serverCapabilities
+	var capability string
+	var supported bool
+	switch mcp.MCPMethod(method) {
+	case mcp.MethodResourcesList, mcp.MethodResourcesTemplatesList, mcp.MethodResourcesRead:
+		capability, supported = "resources", capabilities.Resources != nil
+	case "resources/subscribe", "resources/unsubscribe":
+		capability, supported = "resource subscriptions", capabilities.Resources != nil && capabilities.Resources.Subscribe
+	case mcp.MethodPromptsList, mcp.MethodPromptsGet:
+		capability, supported = "prompts", capabilities.Prompts != nil
+	case mcp.MethodToolsList, mcp.MethodToolsCall:
+		capability, supported = "tools", capabilities.Tools != nil
+	case mcp.MethodSetLogLevel:
+		capability, supported = "logging", capabilities.Logging != nil
+	default:
+		return nil
+	}
+	if supported {
+		return nil
+	}
+	return &kindError{
+		kind: ErrCapabilityNotSupported,
+		msg:  fmt.Sprintf("%s: server does not support %s", method, capability),
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newCapabilityTestClient returns an initialized client for a server
+// declaring capabilities, and the methods the server received after
+// initialize.
+func newCapabilityTestClient(t *testing.T, capabilities string, opts ...ClientOption) (*Client, *[]string) {
+	t.Helper()
+	var methods []string
+	client := NewClient(&errorInjectingTransport{
+		send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			if request.Method == string(mcp.MethodInitialize) {
+				return resultResponse(request, `{"protocolVersion":"`+mcp.LATEST_PROTOCOL_VERSION+`","capabilities":`+capabilities+`,"serverInfo":{"name":"test","version":"1.0.0"}}`), nil
+			}
+			methods = append(methods, request.Method)
+			return resultResponse(request, `{}`), nil
+		},
+	}, opts...)
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return client, &methods
+}
+
+func TestClient_CapabilityChecks(t *testing.T) {
+	ctx := context.Background()
+	calls := map[string]func(c *Client) error{
+		"resources/list": func(c *Client) error {
+			_, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+			return err
+		},
+		"resources/read": func(c *Client) error {
+			_, err := c.ReadResource(ctx, mcp.ReadResourceRequest{})
+			return err
+		},
+		"resources/subscribe": func(c *Client) error {
+			return c.Subscribe(ctx, mcp.SubscribeRequest{})
+		},
+		"prompts/list": func(c *Client) error {
+			_, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+			return err
+		},
+		"tools/call": func(c *Client) error {
+			_, err := c.CallTool(ctx, mcp.CallToolRequest{})
+			return err
+		},
+		"logging/setLevel": func(c *Client) error {
+			return c.SetLevel(ctx, mcp.SetLevelRequest{})
+		},
+	}
+
+	t.Run("unsupported capabilities are not requested", func(t *testing.T) {
+		client, methods := newCapabilityTestClient(t, `{"resources":{}}`)
+		for method, call := range calls {
+			err := call(client)
+			wantUnsupported := method != "resources/list" && method != "resources/read"
+			if got := errors.Is(err, ErrCapabilityNotSupported); got != wantUnsupported {
+				t.Errorf("%s: expected ErrCapabilityNotSupported=%v, got %v", method, wantUnsupported, err)
+			}
+		}
+		if len(*methods) != 2 {
+			t.Errorf("Expected only the resources requests to be sent, got %v", *methods)
+		}
+	})
+
+	t.Run("supported capabilities", func(t *testing.T) {
+		client, methods := newCapabilityTestClient(t, `{"resources":{"subscribe":true},"prompts":{},"tools":{},"logging":{}}`)
+		for method, call := range calls {
+			if err := call(client); errors.Is(err, ErrCapabilityNotSupported) {
+				t.Errorf("%s: unexpected error %v", method, err)
+			}
+		}
+		if len(*methods) != len(calls) {
+			t.Errorf("Expected every request to be sent, got %v", *methods)
+		}
+	})
+
+	t.Run("opt out", func(t *testing.T) {
+		client, methods := newCapabilityTestClient(t, `{}`, WithoutCapabilityChecks())
+		for method, call := range calls {
+			if err := call(client); errors.Is(err, ErrCapabilityNotSupported) {
+				t.Errorf("%s: unexpected error %v", method, err)
+			}
+		}
+		if len(*methods) != len(calls) {
+			t.Errorf("Expected every request to be sent, got %v", *methods)
+		}
+	})
+}
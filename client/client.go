@@ -2,29 +2,94 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // Client implements the MCP client.
 type Client struct {
 	transport transport.Interface
 
-	initialized        bool
+	initialized        atomic.Bool
 	notifications      []func(mcp.JSONRPCNotification)
 	notifyMu           sync.RWMutex
+	waitersMu          sync.Mutex
+	waiters            map[*notificationWaiter]struct{}
 	requestID          atomic.Int64
+	requestIDGenerator func() mcp.RequestId
 	clientCapabilities mcp.ClientCapabilities
+	clientInfo         mcp.Implementation
 	serverCapabilities mcp.ServerCapabilities
+	serverInfo         mcp.Implementation
 	protocolVersion    string
 	samplingHandler    SamplingHandler
+	// noVersionFallback disables retrying initialize with older protocol
+	// versions.
+	noVersionFallback        bool
+	versionNegotiatedHandler func(requested, negotiated string)
+	dryRunSupported          bool
+	// serverCapabilitiesKnown is set once Initialize has stored the server
+	// capabilities, which are then checked before sending requests unless
+	// noCapabilityChecks is set.
+	serverCapabilitiesKnown bool
+	noCapabilityChecks      bool
+	// toolExecutionErrors makes CallTool return a *ToolExecutionError for
+	// results with IsError set.
+	toolExecutionErrors bool
+	// schemaDefaults makes CallTool fill omitted arguments with the defaults
+	// declared in the cached tool schema.
+	schemaDefaults bool
+	toolsMu        sync.RWMutex
+	tools          map[string]mcp.Tool
+	// timeoutHints are the request timeouts suggested by the server, used
+	// when honorTimeoutHints is set, capped at maxTimeoutHint.
+	honorTimeoutHints bool
+	maxTimeoutHint    time.Duration
+	timeoutHints      map[string]time.Duration
+
+	// requestLogger logs the requests sent, redacted by redactor.
+	requestLogger util.Logger
+	redactor      server.Redactor
+
+	statusMu       sync.RWMutex
+	status         ClientStatus
+	statusHandlers []func(old, new ClientStatus)
+
+	connectionLostMu      sync.RWMutex
+	connectionLostHandler func(error)
+
+	pingMu       sync.RWMutex
+	pingHandlers []func()
+
+	// incoming holds the cancel functions of the server requests being
+	// handled, keyed by request ID, for notifications/cancelled.
+	incomingMu sync.Mutex
+	incoming   map[string]context.CancelFunc
+
+	resourceUpdatedMu       sync.RWMutex
+	resourceUpdatedHandlers []func(uri string, contents []mcp.ResourceContents)
+
+	listChangedMu       sync.RWMutex
+	listChangedHandlers map[string][]*listChangedHandler // notification method -> handlers
+
+	// watchDebounce is how long watchers wait for a burst of list_changed
+	// notifications to settle before listing again.
+	watchDebounce time.Duration
+	closed        chan struct{}
+	closeOnce     sync.Once
 }
 
 type ClientOption func(*Client)
@@ -36,6 +101,35 @@ func WithClientCapabilities(capabilities mcp.ClientCapabilities) ClientOption {
 	}
 }
 
+// WithClientInfo sets the implementation information the client reports to
+// the server during initialization. It is used when the initialize request
+// does not set Params.ClientInfo itself.
+func WithClientInfo(info mcp.Implementation) ClientOption {
+	return func(c *Client) {
+		c.clientInfo = info
+	}
+}
+
+// WithoutVersionFallback disables the protocol version fallback of
+// Initialize. By default, when the server rejects the requested protocol
+// version or answers with one the client does not support, Initialize retries
+// with each older supported version in turn until one is accepted. With this
+// option the first failure is returned.
+func WithoutVersionFallback() ClientOption {
+	return func(c *Client) {
+		c.noVersionFallback = true
+	}
+}
+
+// WithVersionNegotiatedHandler sets a function called after each successful
+// initialization with the protocol version the client requested and the one
+// negotiated with the server, which is older if the client fell back.
+func WithVersionNegotiatedHandler(handler func(requested, negotiated string)) ClientOption {
+	return func(c *Client) {
+		c.versionNegotiatedHandler = handler
+	}
+}
+
 // WithSamplingHandler sets the sampling handler for the client.
 // When set, the client will declare sampling capability during initialization.
 func WithSamplingHandler(handler SamplingHandler) ClientOption {
@@ -44,10 +138,64 @@ func WithSamplingHandler(handler SamplingHandler) ClientOption {
 	}
 }
 
+// WithRequestIDGenerator sets a custom generator for JSON-RPC request IDs.
+// The generator must return unique string or integer IDs and may be called
+// concurrently. By default the client uses an incrementing int64 counter.
+func WithRequestIDGenerator(generator func() mcp.RequestId) ClientOption {
+	return func(c *Client) {
+		c.requestIDGenerator = generator
+	}
+}
+
+// WithToolExecutionErrors makes CallTool return a *ToolExecutionError, which
+// matches ErrToolExecution, alongside the result when the tool reports
+// IsError. By default such results are returned with a nil error.
+func WithToolExecutionErrors() ClientOption {
+	return func(c *Client) {
+		c.toolExecutionErrors = true
+	}
+}
+
+// WithSchemaDefaults makes CallTool fill arguments the caller omitted with
+// the defaults declared in the tool's input schema before sending the request.
+// Schemas are taken from the tools returned by ListTools; if the tool has not
+// been listed yet, CallTool lists the tools once to learn its schema.
+// Explicitly provided values, including nulls, are never overwritten.
+func WithSchemaDefaults() ClientOption {
+	return func(c *Client) {
+		c.schemaDefaults = true
+	}
+}
+
+// DefaultMaxTimeoutHint caps the server timeout hints honored by clients
+// created with WithHonorServerTimeoutHints, unless WithMaxTimeoutHint sets
+// another maximum.
+const DefaultMaxTimeoutHint = 10 * time.Minute
+
+// WithHonorServerTimeoutHints makes the client use the timeouts the server
+// suggests in its initialize result, under mcp.MetaKeyTimeoutHints, for
+// requests whose context has no deadline. A hint for a tool applies to its
+// calls and takes precedence over a hint for tools/call; requests without a
+// matching hint are sent as usual. Hints longer than DefaultMaxTimeoutHint,
+// or the maximum set with WithMaxTimeoutHint, are capped.
+func WithHonorServerTimeoutHints() ClientOption {
+	return func(c *Client) {
+		c.honorTimeoutHints = true
+	}
+}
+
+// WithMaxTimeoutHint sets the longest timeout the client accepts from the
+// server's timeout hints, see WithHonorServerTimeoutHints.
+func WithMaxTimeoutHint(max time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxTimeoutHint = max
+	}
+}
+
 // WithSession assumes a MCP Session has already been initialized
 func WithSession() ClientOption {
 	return func(c *Client) {
-		c.initialized = true
+		c.initialized.Store(true)
 	}
 }
 
@@ -61,7 +209,10 @@ func WithSession() ClientOption {
 //	}
 func NewClient(transport transport.Interface, options ...ClientOption) *Client {
 	client := &Client{
-		transport: transport,
+		transport:      transport,
+		watchDebounce:  DefaultWatchDebounce,
+		maxTimeoutHint: DefaultMaxTimeoutHint,
+		closed:         make(chan struct{}),
 	}
 
 	for _, opt := range options {
@@ -77,29 +228,34 @@ func (c *Client) Start(ctx context.Context) error {
 	if c.transport == nil {
 		return fmt.Errorf("transport is nil")
 	}
+	c.setStatus(StatusConnecting)
 	err := c.transport.Start(ctx)
 	if err != nil {
-		return err
+		c.setStatus(StatusIdle)
+		return transport.NewError(err)
 	}
 
-	c.transport.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
-		c.notifyMu.RLock()
-		defer c.notifyMu.RUnlock()
-		for _, handler := range c.notifications {
-			handler(notification)
-		}
-	})
+	c.transport.SetNotificationHandler(c.dispatchNotification)
 
 	// Set up request handler for bidirectional communication (e.g., sampling)
 	if bidirectional, ok := c.transport.(transport.BidirectionalInterface); ok {
 		bidirectional.SetRequestHandler(c.handleIncomingRequest)
 	}
 
+	c.installConnectionLostHandler()
+	c.setStatus(c.readyOrConnected())
+
 	return nil
 }
 
 // Close shuts down the client and closes the transport.
 func (c *Client) Close() error {
+	defer c.setStatus(StatusClosed)
+	c.closeOnce.Do(func() {
+		if c.closed != nil {
+			close(c.closed)
+		}
+	})
 	return c.transport.Close()
 }
 
@@ -113,14 +269,183 @@ func (c *Client) OnNotification(
 	c.notifications = append(c.notifications, handler)
 }
 
+// WaitForNotification blocks until the next notification with the given
+// method is received and returns it. It returns the context's error if ctx is
+// done first. Only notifications that arrive after the call are considered.
+func (c *Client) WaitForNotification(ctx context.Context, method string) (mcp.JSONRPCNotification, error) {
+	waiter := &notificationWaiter{
+		method: method,
+		ch:     make(chan mcp.JSONRPCNotification, 1),
+	}
+
+	c.waitersMu.Lock()
+	if c.waiters == nil {
+		c.waiters = make(map[*notificationWaiter]struct{})
+	}
+	c.waiters[waiter] = struct{}{}
+	c.waitersMu.Unlock()
+
+	select {
+	case notification := <-waiter.ch:
+		return notification, nil
+	case <-ctx.Done():
+		c.waitersMu.Lock()
+		delete(c.waiters, waiter)
+		c.waitersMu.Unlock()
+		// The notification may have been delivered while we were removing the waiter
+		select {
+		case notification := <-waiter.ch:
+			return notification, nil
+		default:
+		}
+		return mcp.JSONRPCNotification{}, ctx.Err()
+	}
+}
+
+// notificationWaiter is a pending WaitForNotification call.
+type notificationWaiter struct {
+	method string
+	ch     chan mcp.JSONRPCNotification
+}
+
+// dispatchNotification delivers a notification to pending waiters and to the
+// handlers registered with OnNotification.
+func (c *Client) dispatchNotification(notification mcp.JSONRPCNotification) {
+	c.waitersMu.Lock()
+	for waiter := range c.waiters {
+		if waiter.method == notification.Method {
+			waiter.ch <- notification
+			delete(c.waiters, waiter)
+		}
+	}
+	c.waitersMu.Unlock()
+
+	switch notification.Method {
+	case mcp.MethodNotificationCancelled:
+		c.cancelIncomingRequest(notification)
+	case mcp.MethodNotificationResourceUpdated:
+		c.dispatchResourceUpdated(notification)
+	case mcp.MethodNotificationToolsListChanged,
+		mcp.MethodNotificationPromptsListChanged,
+		mcp.MethodNotificationResourcesListChanged:
+		c.dispatchListChanged(notification.Method)
+	}
+
+	c.notifyMu.RLock()
+	defer c.notifyMu.RUnlock()
+	for _, handler := range c.notifications {
+		handler(notification)
+	}
+}
+
+// OnPromptListChanged registers a handler function to be called when the
+// server reports that its list of prompts has changed, typically so the
+// client can call ListPrompts again. Multiple handlers can be registered and
+// will be called in the order they were added.
+func (c *Client) OnPromptListChanged(handler func()) {
+	_ = c.onListChanged(mcp.MethodNotificationPromptsListChanged, handler)
+}
+
+// listChangedHandler wraps a list_changed handler so it can be removed.
+type listChangedHandler struct {
+	fn func()
+}
+
+// onListChanged registers a handler for the list_changed notification with
+// the given method and returns a function removing it.
+func (c *Client) onListChanged(method string, handler func()) (remove func()) {
+	entry := &listChangedHandler{fn: handler}
+	c.listChangedMu.Lock()
+	defer c.listChangedMu.Unlock()
+	if c.listChangedHandlers == nil {
+		c.listChangedHandlers = make(map[string][]*listChangedHandler)
+	}
+	c.listChangedHandlers[method] = append(c.listChangedHandlers[method], entry)
+
+	return func() {
+		c.listChangedMu.Lock()
+		defer c.listChangedMu.Unlock()
+		handlers := c.listChangedHandlers[method]
+		for i, h := range handlers {
+			if h == entry {
+				c.listChangedHandlers[method] = append(handlers[:i:i], handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) dispatchListChanged(method string) {
+	c.listChangedMu.RLock()
+	handlers := make([]*listChangedHandler, len(c.listChangedHandlers[method]))
+	copy(handlers, c.listChangedHandlers[method])
+	c.listChangedMu.RUnlock()
+	for _, handler := range handlers {
+		handler.fn()
+	}
+}
+
+// OnResourceUpdated registers a handler function to be called when the server
+// reports that a resource has changed. If the server sent the new contents
+// along with the notification they are passed to the handler; otherwise
+// contents is nil and the resource has to be read again. Multiple handlers
+// can be registered and will be called in the order they were added.
+func (c *Client) OnResourceUpdated(handler func(uri string, contents []mcp.ResourceContents)) {
+	c.resourceUpdatedMu.Lock()
+	defer c.resourceUpdatedMu.Unlock()
+	c.resourceUpdatedHandlers = append(c.resourceUpdatedHandlers, handler)
+}
+
+func (c *Client) dispatchResourceUpdated(notification mcp.JSONRPCNotification) {
+	c.resourceUpdatedMu.RLock()
+	handlers := make([]func(string, []mcp.ResourceContents), len(c.resourceUpdatedHandlers))
+	copy(handlers, c.resourceUpdatedHandlers)
+	c.resourceUpdatedMu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	params, err := mcp.ParseResourceUpdatedNotification(notification)
+	if err != nil {
+		uri, ok := notification.Params.AdditionalFields["uri"].(string)
+		if !ok {
+			return
+		}
+		// Fall back to a plain update so the handler re-reads the resource
+		params = &mcp.ResourceUpdatedNotificationParams{URI: uri}
+	}
+	for _, handler := range handlers {
+		handler(params.URI, params.Contents)
+	}
+}
+
+// OnPingReceived registers a handler function to be called when the server
+// pings the client. The client answers pings automatically; handlers are only
+// informed, for example to track the liveness of the connection. Multiple
+// handlers can be registered and will be called in the order they were added.
+func (c *Client) OnPingReceived(handler func()) {
+	c.pingMu.Lock()
+	defer c.pingMu.Unlock()
+	c.pingHandlers = append(c.pingHandlers, handler)
+}
+
 // OnConnectionLost registers a handler function to be called when the connection is lost.
 // This is useful for handling HTTP2 idle timeout disconnections that should not be treated as errors.
 func (c *Client) OnConnectionLost(handler func(error)) {
+	c.connectionLostMu.Lock()
+	c.connectionLostHandler = handler
+	c.connectionLostMu.Unlock()
+	c.installConnectionLostHandler()
+}
+
+// installConnectionLostHandler hooks the client into the transport's
+// connection-lost notifications, if the transport supports them.
+func (c *Client) installConnectionLostHandler() {
 	type connectionLostSetter interface {
 		SetConnectionLostHandler(func(error))
 	}
 	if setter, ok := c.transport.(connectionLostSetter); ok {
-		setter.SetConnectionLostHandler(handler)
+		setter.SetConnectionLostHandler(c.handleConnectionLost)
 	}
 }
 
@@ -131,43 +456,102 @@ func (c *Client) sendRequest(
 	method string,
 	params any,
 ) (*json.RawMessage, error) {
-	if !c.initialized && method != "initialize" {
-		return nil, fmt.Errorf("client not initialized")
+	if !c.initialized.Load() && method != "initialize" {
+		return nil, ErrNotInitialized
+	}
+	if err := c.checkServerCapability(method); err != nil {
+		return nil, err
 	}
 
-	id := c.requestID.Add(1)
+	ctx, cancel := c.withTimeoutHint(ctx, method, params)
+	defer cancel()
 
 	request := transport.JSONRPCRequest{
 		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      mcp.NewRequestId(id),
+		ID:      c.nextRequestID(),
 		Method:  method,
 		Params:  params,
 	}
+	c.logRequest(request)
 
 	response, err := c.transport.SendRequest(ctx, request)
 	if err != nil {
+		c.logRequestError(request, err)
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return nil, ctxErr
+		}
 		return nil, transport.NewError(err)
 	}
+	c.markRecovered()
 
 	if response.Error != nil {
-		return nil, errors.New(response.Error.Message)
+		c.logRequestError(request, fmt.Errorf("%d: %s", response.Error.Code, response.Error.Message))
+		return nil, &JSONRPCError{
+			Code:    response.Error.Code,
+			Message: response.Error.Message,
+			Data:    response.Error.Data,
+		}
 	}
 
 	return &response.Result, nil
 }
 
+// withTimeoutHint returns ctx with the timeout the server suggested for the
+// request, if ctx has no deadline and there is a matching hint.
+func (c *Client) withTimeoutHint(ctx context.Context, method string, params any) (context.Context, context.CancelFunc) {
+	if len(c.timeoutHints) == 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	timeout, ok := c.timeoutHints[method]
+	if call, isCall := params.(mcp.CallToolParams); isCall {
+		if toolTimeout, found := c.timeoutHints[mcp.TimeoutHintKeyForTool(call.Name)]; found {
+			timeout, ok = toolTimeout, true
+		}
+	}
+	if !ok {
+		return ctx, func() {}
+	}
+	if c.maxTimeoutHint > 0 && timeout > c.maxTimeoutHint {
+		timeout = c.maxTimeoutHint
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// nextRequestID returns the ID for the next outgoing request.
+func (c *Client) nextRequestID() mcp.RequestId {
+	if c.requestIDGenerator != nil {
+		return c.requestIDGenerator()
+	}
+	return mcp.NewRequestId(c.requestID.Add(1))
+}
+
 // Initialize negotiates with the server.
 // Must be called after Start, and before any request methods.
 func (c *Client) Initialize(
 	ctx context.Context,
 	request mcp.InitializeRequest,
-) (*mcp.InitializeResult, error) {
+) (_ *mcp.InitializeResult, err error) {
+	c.setStatus(StatusInitializing)
+	defer func() {
+		if err != nil {
+			c.setStatus(c.readyOrConnected())
+		}
+	}()
+
 	// Merge client capabilities with sampling capability if handler is configured
 	capabilities := request.Params.Capabilities
 	if c.samplingHandler != nil {
 		capabilities.Sampling = &struct{}{}
 	}
 
+	clientInfo := request.Params.ClientInfo
+	if clientInfo.Name == "" && clientInfo.Version == "" {
+		clientInfo = c.clientInfo
+	}
+
 	// Ensure we send a params object with all required fields
 	params := struct {
 		ProtocolVersion string                 `json:"protocolVersion"`
@@ -175,28 +559,38 @@ func (c *Client) Initialize(
 		Capabilities    mcp.ClientCapabilities `json:"capabilities"`
 	}{
 		ProtocolVersion: request.Params.ProtocolVersion,
-		ClientInfo:      request.Params.ClientInfo,
+		ClientInfo:      clientInfo,
 		Capabilities:    capabilities,
 	}
 
-	response, err := c.sendRequest(ctx, "initialize", params)
+	result, err := c.sendInitialize(ctx, params)
+	if !c.noVersionFallback {
+		// Walk down the older versions while the server rejects ours
+		for _, version := range olderProtocolVersions(request.Params.ProtocolVersion) {
+			retry, supported := isVersionNegotiationError(err)
+			if !retry {
+				break
+			}
+			if len(supported) > 0 && !slices.Contains(supported, version) {
+				continue
+			}
+			params.ProtocolVersion = version
+			result, err = c.sendInitialize(ctx, params)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var result mcp.InitializeResult
-	if err := json.Unmarshal(*response, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	// Validate protocol version
-	if !slices.Contains(mcp.ValidProtocolVersions, result.ProtocolVersion) {
-		return nil, mcp.UnsupportedProtocolVersionError{Version: result.ProtocolVersion}
-	}
-
-	// Store serverCapabilities and protocol version
+	// Store serverCapabilities, server info and protocol version
 	c.serverCapabilities = result.Capabilities
+	c.serverCapabilitiesKnown = true
+	c.serverInfo = result.ServerInfo
 	c.protocolVersion = result.ProtocolVersion
+	c.dryRunSupported = result.GetMeta(mcp.MetaKeyDryRunSupported) == true
+	if c.honorTimeoutHints {
+		c.timeoutHints = result.TimeoutHints()
+	}
 
 	// Set protocol version on HTTP transports
 	if httpConn, ok := c.transport.(transport.HTTPConnection); ok {
@@ -215,25 +609,109 @@ func (c *Client) Initialize(
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to send initialized notification: %w",
-			err,
+			transport.NewError(err),
 		)
 	}
 
-	c.initialized = true
+	c.initialized.Store(true)
+	c.setStatus(StatusReady)
+	if c.versionNegotiatedHandler != nil {
+		c.versionNegotiatedHandler(request.Params.ProtocolVersion, result.ProtocolVersion)
+	}
+	return result, nil
+}
+
+// sendInitialize sends the initialize request and checks that the server
+// answered with a protocol version the client supports.
+func (c *Client) sendInitialize(ctx context.Context, params any) (*mcp.InitializeResult, error) {
+	response, err := c.sendRequest(ctx, "initialize", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result mcp.InitializeResult
+	if err := json.Unmarshal(*response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// Validate protocol version
+	if !slices.Contains(mcp.ValidProtocolVersions, result.ProtocolVersion) {
+		return nil, mcp.UnsupportedProtocolVersionError{Version: result.ProtocolVersion}
+	}
 	return &result, nil
 }
 
+// olderProtocolVersions returns the supported protocol versions older than
+// version, newest first. All supported versions are returned if version is
+// not one of them.
+func olderProtocolVersions(version string) []string {
+	i := slices.Index(mcp.ValidProtocolVersions, version)
+	return mcp.ValidProtocolVersions[i+1:]
+}
+
+// isVersionNegotiationError reports whether err shows that the server did not
+// accept the requested protocol version: either it answered with a version
+// the client does not support, or it rejected the request with an invalid
+// params error about the version. supported lists the versions the server
+// reported supporting in the error data, if any.
+func isVersionNegotiationError(err error) (retry bool, supported []string) {
+	if err == nil {
+		return false, nil
+	}
+	if mcp.IsUnsupportedProtocolVersion(err) {
+		return true, nil
+	}
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.INVALID_PARAMS ||
+		!strings.Contains(strings.ToLower(rpcErr.Message), "version") {
+		return false, nil
+	}
+	var data struct {
+		Supported []string `json:"supported"`
+	}
+	_ = json.Unmarshal(rpcErr.Data, &data)
+	return true, data.Supported
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.sendRequest(ctx, "ping", nil)
 	return err
 }
 
+// HealthCheck reports whether the connection is usable. It uses the
+// transport's own check if it implements transport.HealthChecker, which avoids
+// a protocol round trip, and falls back to Ping otherwise.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	if checker, ok := c.transport.(transport.HealthChecker); ok {
+		if err := checker.HealthCheck(ctx); err != nil {
+			return transport.NewError(err)
+		}
+		return nil
+	}
+	return c.Ping(ctx)
+}
+
+// IsConnected reports whether the connection is currently up, without
+// sending anything. It asks the transport if it implements
+// transport.ConnectionStateReporter, and otherwise goes by Status.
+func (c *Client) IsConnected() bool {
+	if reporter, ok := c.transport.(transport.ConnectionStateReporter); ok {
+		return reporter.IsConnected()
+	}
+	switch c.Status() {
+	case StatusConnected, StatusInitializing, StatusReady:
+		return true
+	default:
+		return false
+	}
+}
+
 // ListResourcesByPage manually list resources by page.
 func (c *Client) ListResourcesByPage(
 	ctx context.Context,
 	request mcp.ListResourcesRequest,
 ) (*mcp.ListResourcesResult, error) {
-	result, err := listByPage[mcp.ListResourcesResult](ctx, c, request.PaginatedRequest, "resources/list")
+	result, err := listByPage[mcp.ListResourcesResult](ctx, c, filteredListParams(request.Params, request.Filter), "resources/list")
 	if err != nil {
 		return nil, err
 	}
@@ -265,17 +743,55 @@ func (c *Client) ListResources(
 	return result, nil
 }
 
+// ListResourcesFiltered lists the resources of all pages that match filter,
+// such as those under a URI prefix or of a MIME type. The server filters
+// before paginating, so only matching resources are transferred. Servers that
+// don't support filtering ignore it and return every resource.
+func (c *Client) ListResourcesFiltered(
+	ctx context.Context,
+	filter mcp.ListResourcesFilter,
+) (*mcp.ListResourcesResult, error) {
+	return c.ListResources(ctx, mcp.ListResourcesRequest{Filter: filter})
+}
+
+// ListResourceTemplatesFiltered is like ListResourcesFiltered for resource
+// templates. A template matches a URI prefix if it could expand to a URI
+// starting with it.
+func (c *Client) ListResourceTemplatesFiltered(
+	ctx context.Context,
+	filter mcp.ListResourcesFilter,
+) (*mcp.ListResourceTemplatesResult, error) {
+	return c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{Filter: filter})
+}
+
+// filteredListParams returns the params of a resource list request, with the
+// filter added if it is set.
+func filteredListParams(params mcp.PaginatedParams, filter mcp.ListResourcesFilter) any {
+	if filter.IsZero() {
+		return params
+	}
+	return struct {
+		mcp.PaginatedParams
+		mcp.ListResourcesFilter
+	}{params, filter}
+}
+
+// ListResourceTemplatesByPage lists a single page of resource templates,
+// starting at request.Params.Cursor. The returned NextCursor is empty on the
+// last page.
 func (c *Client) ListResourceTemplatesByPage(
 	ctx context.Context,
 	request mcp.ListResourceTemplatesRequest,
 ) (*mcp.ListResourceTemplatesResult, error) {
-	result, err := listByPage[mcp.ListResourceTemplatesResult](ctx, c, request.PaginatedRequest, "resources/templates/list")
+	result, err := listByPage[mcp.ListResourceTemplatesResult](ctx, c, filteredListParams(request.Params, request.Filter), "resources/templates/list")
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// ListResourceTemplates lists the resource templates of all pages, following
+// the cursors returned by the server from request.Params.Cursor on.
 func (c *Client) ListResourceTemplates(
 	ctx context.Context,
 	request mcp.ListResourceTemplatesRequest,
@@ -301,6 +817,16 @@ func (c *Client) ListResourceTemplates(
 	return result, nil
 }
 
+// ListAllResourceTemplates returns every resource template offered by the
+// server, fetching as many pages as needed.
+func (c *Client) ListAllResourceTemplates(ctx context.Context) ([]mcp.ResourceTemplate, error) {
+	result, err := c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return result.ResourceTemplates, nil
+}
+
 func (c *Client) ReadResource(
 	ctx context.Context,
 	request mcp.ReadResourceRequest,
@@ -313,6 +839,56 @@ func (c *Client) ReadResource(
 	return mcp.ParseReadResourceResult(response)
 }
 
+// ReadResourceText reads the resource at uri and returns its text. The text
+// of multiple content parts is concatenated in order. It fails if any part
+// holds binary content.
+func (c *Client) ReadResourceText(ctx context.Context, uri string) (string, error) {
+	result, err := c.readResourceURI(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, contents := range result.Contents {
+		textContents, ok := contents.(mcp.TextResourceContents)
+		if !ok {
+			return "", fmt.Errorf("resource %q contains non-text content %T", uri, contents)
+		}
+		text.WriteString(textContents.Text)
+	}
+	return text.String(), nil
+}
+
+// ReadResourceBlob reads the resource at uri and returns its decoded binary
+// data. The data of multiple content parts is concatenated in order. It fails
+// if any part holds text content or invalid base64.
+func (c *Client) ReadResourceBlob(ctx context.Context, uri string) ([]byte, error) {
+	result, err := c.readResourceURI(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for _, contents := range result.Contents {
+		blobContents, ok := contents.(mcp.BlobResourceContents)
+		if !ok {
+			return nil, fmt.Errorf("resource %q contains non-binary content %T", uri, contents)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(blobContents.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q contains invalid base64 data: %w", uri, err)
+		}
+		data = append(data, decoded...)
+	}
+	return data, nil
+}
+
+func (c *Client) readResourceURI(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	return c.ReadResource(ctx, request)
+}
+
 func (c *Client) Subscribe(
 	ctx context.Context,
 	request mcp.SubscribeRequest,
@@ -333,7 +909,7 @@ func (c *Client) ListPromptsByPage(
 	ctx context.Context,
 	request mcp.ListPromptsRequest,
 ) (*mcp.ListPromptsResult, error) {
-	result, err := listByPage[mcp.ListPromptsResult](ctx, c, request.PaginatedRequest, "prompts/list")
+	result, err := listByPage[mcp.ListPromptsResult](ctx, c, request.Params, "prompts/list")
 	if err != nil {
 		return nil, err
 	}
@@ -381,10 +957,11 @@ func (c *Client) ListToolsByPage(
 	ctx context.Context,
 	request mcp.ListToolsRequest,
 ) (*mcp.ListToolsResult, error) {
-	result, err := listByPage[mcp.ListToolsResult](ctx, c, request.PaginatedRequest, "tools/list")
+	result, err := listByPage[mcp.ListToolsResult](ctx, c, request.Params, "tools/list")
 	if err != nil {
 		return nil, err
 	}
+	c.cacheTools(result.Tools)
 	return result, nil
 }
 
@@ -413,16 +990,140 @@ func (c *Client) ListTools(
 	return result, nil
 }
 
+// ListToolsFiltered lists all tools of the server and returns those for
+// which keep returns true. It is typically used with the annotation helpers
+// of mcp.Tool, e.g. to enumerate only tools that cannot modify anything:
+//
+//	tools, err := c.ListToolsFiltered(ctx, mcp.Tool.IsReadOnly)
+func (c *Client) ListToolsFiltered(
+	ctx context.Context,
+	keep func(mcp.Tool) bool,
+) ([]mcp.Tool, error) {
+	result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	tools := make([]mcp.Tool, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		if keep(tool) {
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
 func (c *Client) CallTool(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	return c.CallToolWithOptions(ctx, request)
+}
+
+// CallOption configures a single tool call made with CallToolWithOptions.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	dryRun bool
+}
+
+// WithDryRun asks the server to validate the call without executing it, by
+// setting _meta.dryRun. The server checks the arguments against the tool's
+// input schema and returns a result describing the outcome, with an
+// mcp.DryRunReport as structured content, or the preview of the tool's dry
+// run handler. Servers that do not support dry runs would execute the call,
+// so the call fails with ErrCapabilityNotSupported unless the server
+// advertised support during initialization.
+func WithDryRun() CallOption {
+	return func(o *callOptions) {
+		o.dryRun = true
+	}
+}
+
+// CallToolWithOptions calls a tool like CallTool, configured by opts.
+func (c *Client) CallToolWithOptions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	opts ...CallOption,
+) (*mcp.CallToolResult, error) {
+	var options callOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.dryRun {
+		if !c.SupportsDryRun() {
+			return nil, fmt.Errorf("dry run: %w", ErrCapabilityNotSupported)
+		}
+		// Copy the _meta so the caller's request is left untouched
+		meta := &mcp.Meta{}
+		if request.Params.Meta != nil {
+			meta.ProgressToken = request.Params.Meta.ProgressToken
+			meta.AdditionalFields = maps.Clone(request.Params.Meta.AdditionalFields)
+		}
+		meta.Set(mcp.MetaKeyDryRun, true)
+		request.Params.Meta = meta
+	}
+
+	if c.schemaDefaults {
+		request.Params.Arguments = c.applySchemaDefaults(ctx, request.Params.Name, request.Params.Arguments)
+	}
+
 	response, err := c.sendRequest(ctx, "tools/call", request.Params)
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.ParseCallToolResult(response)
+	result, err := mcp.ParseCallToolResult(response)
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError && c.toolExecutionErrors {
+		return result, &ToolExecutionError{Result: result}
+	}
+	return result, nil
+}
+
+// cacheTools remembers the definitions of the listed tools for
+// WithSchemaDefaults.
+func (c *Client) cacheTools(tools []mcp.Tool) {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	if c.tools == nil {
+		c.tools = make(map[string]mcp.Tool, len(tools))
+	}
+	for _, tool := range tools {
+		c.tools[tool.Name] = tool
+	}
+}
+
+// applySchemaDefaults fills omitted arguments of the named tool with their
+// schema defaults. Arguments that are not a map, and tools whose schema is
+// unknown, are left as they are.
+func (c *Client) applySchemaDefaults(ctx context.Context, name string, arguments any) any {
+	args, ok := arguments.(map[string]any)
+	if !ok && arguments != nil {
+		return arguments
+	}
+
+	c.toolsMu.RLock()
+	tool, ok := c.tools[name]
+	c.toolsMu.RUnlock()
+	if !ok {
+		if _, err := c.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+			return arguments
+		}
+		c.toolsMu.RLock()
+		tool, ok = c.tools[name]
+		c.toolsMu.RUnlock()
+		if !ok {
+			return arguments
+		}
+	}
+
+	filled := tool.ApplyArgumentDefaults(args)
+	if filled == nil {
+		return arguments
+	}
+	return filled
 }
 
 func (c *Client) SetLevel(
@@ -453,7 +1154,12 @@ func (c *Client) Complete(
 // handleIncomingRequest processes incoming requests from the server.
 // This is the main entry point for server-to-client requests like sampling.
 func (c *Client) handleIncomingRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	ctx, done := c.trackIncomingRequest(ctx, request.ID)
+	defer done()
+
 	switch request.Method {
+	case string(mcp.MethodPing):
+		return c.handlePingRequest(request), nil
 	case string(mcp.MethodSamplingCreateMessage):
 		return c.handleSamplingRequestTransport(ctx, request)
 	default:
@@ -461,10 +1167,62 @@ func (c *Client) handleIncomingRequest(ctx context.Context, request transport.JS
 	}
 }
 
+// trackIncomingRequest derives a context for a server request that is
+// cancelled when the server sends notifications/cancelled for its ID. The
+// returned function must be called once the request has been handled.
+func (c *Client) trackIncomingRequest(ctx context.Context, id mcp.RequestId) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := id.String()
+
+	c.incomingMu.Lock()
+	if c.incoming == nil {
+		c.incoming = make(map[string]context.CancelFunc)
+	}
+	c.incoming[key] = cancel
+	c.incomingMu.Unlock()
+
+	return ctx, func() {
+		c.incomingMu.Lock()
+		delete(c.incoming, key)
+		c.incomingMu.Unlock()
+		cancel()
+	}
+}
+
+// cancelIncomingRequest cancels the context of the server request named by
+// a notifications/cancelled notification, if it is still being handled.
+func (c *Client) cancelIncomingRequest(notification mcp.JSONRPCNotification) {
+	value, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	key := mcp.NewRequestId(value).String()
+
+	c.incomingMu.Lock()
+	cancel, ok := c.incoming[key]
+	c.incomingMu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// handlePingRequest answers a ping from the server with an empty result.
+func (c *Client) handlePingRequest(request transport.JSONRPCRequest) *transport.JSONRPCResponse {
+	c.pingMu.RLock()
+	handlers := make([]func(), len(c.pingHandlers))
+	copy(handlers, c.pingHandlers)
+	c.pingMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler()
+	}
+	return transport.NewPingResponse(request.ID)
+}
+
 // handleSamplingRequestTransport handles sampling requests at the transport level.
 func (c *Client) handleSamplingRequestTransport(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
 	if c.samplingHandler == nil {
-		return nil, fmt.Errorf("no sampling handler configured")
+		return nil, &kindError{kind: ErrCapabilityNotSupported, msg: "no sampling handler configured"}
 	}
 
 	// Parse the request parameters
@@ -511,10 +1269,10 @@ func (c *Client) handleSamplingRequestTransport(ctx context.Context, request tra
 func listByPage[T any](
 	ctx context.Context,
 	client *Client,
-	request mcp.PaginatedRequest,
+	params any,
 	method string,
 ) (*T, error) {
-	response, err := client.sendRequest(ctx, method, request.Params)
+	response, err := client.sendRequest(ctx, method, params)
 	if err != nil {
 		return nil, err
 	}
@@ -538,6 +1296,12 @@ func (c *Client) GetServerCapabilities() mcp.ServerCapabilities {
 	return c.serverCapabilities
 }
 
+// GetServerInfo returns the name and version the server reported during
+// initialization.
+func (c *Client) GetServerInfo() mcp.Implementation {
+	return c.serverInfo
+}
+
 // GetClientCapabilities returns the client capabilities.
 func (c *Client) GetClientCapabilities() mcp.ClientCapabilities {
 	return c.clientCapabilities
@@ -552,7 +1316,20 @@ func (c *Client) GetSessionId() string {
 	return c.transport.GetSessionId()
 }
 
+// GetProtocolVersion returns the protocol version negotiated during
+// initialization, or an empty string before the client is initialized.
+func (c *Client) GetProtocolVersion() string {
+	return c.protocolVersion
+}
+
+// SupportsDryRun reports whether the server advertised during initialization
+// that it validates tool calls flagged with _meta.dryRun instead of executing
+// them. See WithDryRun.
+func (c *Client) SupportsDryRun() bool {
+	return c.dryRunSupported
+}
+
 // IsInitialized returns true if the client has been initialized.
 func (c *Client) IsInitialized() bool {
-	return c.initialized
+	return c.initialized.Load()
 }
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Error kinds returned by the client. Every error returned from a Client
+// method matches at most one of these with errors.Is, so callers can tell
+// failure classes apart without inspecting messages:
+//
+//   - ErrTransport: the transport failed to deliver the message or receive a
+//     reply. Use errors.As with *transport.Error to get the underlying error.
+//   - ErrJSONRPC: the server replied with a JSON-RPC error. Use errors.As
+//     with *JSONRPCError to get the code, message and data.
+//   - ErrToolExecution: the tool ran but reported IsError. Only returned by
+//     CallTool when WithToolExecutionErrors is set. Use errors.As with
+//     *ToolExecutionError to get the result.
+//   - ErrNotInitialized: a request was sent before Initialize.
+//   - ErrCapabilityNotSupported: the operation requires a capability that was
+//     not negotiated or configured.
+//
+// Context cancellation and deadlines are returned as the context's error and
+// match context.Canceled or context.DeadlineExceeded rather than ErrTransport.
+var (
+	ErrTransport              = transport.ErrTransport
+	ErrJSONRPC                = errors.New("JSON-RPC error")
+	ErrToolExecution          = errors.New("tool execution error")
+	ErrNotInitialized         = errors.New("client not initialized")
+	ErrCapabilityNotSupported = errors.New("capability not supported")
+)
+
+// JSONRPCError is a JSON-RPC error returned by the server.
+type JSONRPCError struct {
+	Code    int
+	Message string
+	Data    json.RawMessage
+}
+
+// Error returns the message sent by the server.
+func (e *JSONRPCError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is ErrJSONRPC.
+func (e *JSONRPCError) Is(target error) bool {
+	return target == ErrJSONRPC
+}
+
+// ToolExecutionError reports a tool call whose result has IsError set.
+type ToolExecutionError struct {
+	Result *mcp.CallToolResult
+}
+
+func (e *ToolExecutionError) Error() string {
+	for _, content := range e.Result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return fmt.Sprintf("tool execution error: %s", text.Text)
+		}
+	}
+	return "tool execution error"
+}
+
+// Is reports whether target is ErrToolExecution.
+func (e *ToolExecutionError) Is(target error) bool {
+	return target == ErrToolExecution
+}
+
+// kindError keeps a specific message while matching one of the error kinds
+// with errors.Is.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errorInjectingTransport lets each test decide how requests fail.
+type errorInjectingTransport struct {
+	startErr error
+	send     func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error)
+}
+
+func (m *errorInjectingTransport) Start(ctx context.Context) error {
+	return m.startErr
+}
+
+func (m *errorInjectingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	return m.send(ctx, request)
+}
+
+func (m *errorInjectingTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return nil
+}
+
+func (m *errorInjectingTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+}
+
+func (m *errorInjectingTransport) Close() error {
+	return nil
+}
+
+func (m *errorInjectingTransport) GetSessionId() string {
+	return ""
+}
+
+func resultResponse(request transport.JSONRPCRequest, result string) *transport.JSONRPCResponse {
+	return &transport.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  json.RawMessage(result),
+	}
+}
+
+func TestClient_ErrorKinds(t *testing.T) {
+	kinds := []error{ErrTransport, ErrJSONRPC, ErrToolExecution, ErrNotInitialized, ErrCapabilityNotSupported}
+
+	tests := []struct {
+		name     string
+		run      func(t *testing.T) error
+		wantKind error
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name: "transport start failure",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{startErr: errors.New("exec: not found")})
+				return client.Start(context.Background())
+			},
+			wantKind: ErrTransport,
+		},
+		{
+			name: "transport send failure",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{
+					send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+						return nil, errors.New("broken pipe")
+					},
+				}, WithSession())
+				return client.Ping(context.Background())
+			},
+			wantKind: ErrTransport,
+			check: func(t *testing.T, err error) {
+				var transportErr *transport.Error
+				if !errors.As(err, &transportErr) {
+					t.Fatalf("expected *transport.Error, got %T", err)
+				}
+				if transportErr.Err.Error() != "broken pipe" {
+					t.Errorf("expected underlying error %q, got %q", "broken pipe", transportErr.Err)
+				}
+			},
+		},
+		{
+			name: "transport not started",
+			run: func(t *testing.T) error {
+				client := NewClient(transport.NewStdio("unused", nil), WithSession())
+				return client.Ping(context.Background())
+			},
+			wantKind: ErrTransport,
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, transport.ErrNotStarted) {
+					t.Errorf("expected transport.ErrNotStarted, got %v", err)
+				}
+			},
+		},
+		{
+			name: "JSON-RPC error response",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{
+					send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+						response := resultResponse(request, "")
+						response.Error = &struct {
+							Code    int             `json:"code"`
+							Message string          `json:"message"`
+							Data    json.RawMessage `json:"data"`
+						}{Code: mcp.METHOD_NOT_FOUND, Message: "Method not found", Data: json.RawMessage(`{"method":"ping"}`)}
+						return response, nil
+					},
+				}, WithSession())
+				return client.Ping(context.Background())
+			},
+			wantKind: ErrJSONRPC,
+			check: func(t *testing.T, err error) {
+				var rpcErr *JSONRPCError
+				if !errors.As(err, &rpcErr) {
+					t.Fatalf("expected *JSONRPCError, got %T", err)
+				}
+				if rpcErr.Code != mcp.METHOD_NOT_FOUND {
+					t.Errorf("expected code %d, got %d", mcp.METHOD_NOT_FOUND, rpcErr.Code)
+				}
+				if string(rpcErr.Data) != `{"method":"ping"}` {
+					t.Errorf("unexpected data: %s", rpcErr.Data)
+				}
+				// The message is unchanged from earlier releases
+				if err.Error() != "Method not found" {
+					t.Errorf("expected message %q, got %q", "Method not found", err.Error())
+				}
+			},
+		},
+		{
+			name: "tool reports IsError",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{
+					send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+						return resultResponse(request, `{"content":[{"type":"text","text":"division by zero"}],"isError":true}`), nil
+					},
+				}, WithSession(), WithToolExecutionErrors())
+				result, err := client.CallTool(context.Background(), mcp.CallToolRequest{})
+				if result == nil {
+					t.Error("expected the result to be returned alongside the error")
+				}
+				return err
+			},
+			wantKind: ErrToolExecution,
+			check: func(t *testing.T, err error) {
+				var toolErr *ToolExecutionError
+				if !errors.As(err, &toolErr) {
+					t.Fatalf("expected *ToolExecutionError, got %T", err)
+				}
+				if !toolErr.Result.IsError {
+					t.Error("expected result to carry IsError")
+				}
+				if err.Error() != "tool execution error: division by zero" {
+					t.Errorf("unexpected message %q", err.Error())
+				}
+			},
+		},
+		{
+			name: "not initialized",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{})
+				_, err := client.ListTools(context.Background(), mcp.ListToolsRequest{})
+				return err
+			},
+			wantKind: ErrNotInitialized,
+		},
+		{
+			name: "sampling without handler",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{})
+				_, err := client.handleIncomingRequest(context.Background(), transport.JSONRPCRequest{
+					Method: string(mcp.MethodSamplingCreateMessage),
+				})
+				return err
+			},
+			wantKind: ErrCapabilityNotSupported,
+		},
+		{
+			name: "context deadline",
+			run: func(t *testing.T) error {
+				client := NewClient(&errorInjectingTransport{
+					send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					},
+				}, WithSession())
+				ctx, cancel := context.WithTimeout(context.Background(), 0)
+				defer cancel()
+				return client.Ping(ctx)
+			},
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected context.DeadlineExceeded, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(t)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, kind := range kinds {
+				if got, want := errors.Is(err, kind), kind == tt.wantKind; got != want {
+					t.Errorf("errors.Is(err, %q) = %v, want %v (err: %v)", kind, got, want, err)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// unhealthyTransport reports a failed transport-level health check.
+type unhealthyTransport struct {
+	mockStatusTransport
+}
+
+func (u *unhealthyTransport) HealthCheck(ctx context.Context) error {
+	return errors.New("stream disconnected")
+}
+
+func TestClient_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("in-process transport is always healthy", func(t *testing.T) {
+		client, err := NewInProcessClient(server.NewMCPServer("test-server", "1.0.0"))
+		if err != nil {
+			t.Fatalf("NewInProcessClient failed: %v", err)
+		}
+		if err := client.HealthCheck(ctx); err != nil {
+			t.Errorf("expected healthy client, got %v", err)
+		}
+	})
+
+	t.Run("transport check is preferred", func(t *testing.T) {
+		client := NewClient(&unhealthyTransport{})
+		err := client.HealthCheck(ctx)
+		if !errors.Is(err, ErrTransport) {
+			t.Errorf("expected transport error, got %v", err)
+		}
+	})
+
+	t.Run("falls back to ping", func(t *testing.T) {
+		mockTransport := &mockStatusTransport{}
+		client := NewClient(mockTransport, WithSession())
+		if err := client.HealthCheck(ctx); err != nil {
+			t.Errorf("expected healthy client, got %v", err)
+		}
+
+		mockTransport.setFailRequests(true)
+		if err := client.HealthCheck(ctx); err == nil {
+			t.Error("expected ping fallback to fail")
+		}
+	})
+}
+
+func TestClient_IsConnected(t *testing.T) {
+	t.Run("asks the transport", func(t *testing.T) {
+		client, err := NewInProcessClient(server.NewMCPServer("test-server", "1.0.0"))
+		if err != nil {
+			t.Fatalf("NewInProcessClient failed: %v", err)
+		}
+		if client.IsConnected() {
+			t.Error("expected an unstarted client to be disconnected")
+		}
+		if err := client.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if !client.IsConnected() {
+			t.Error("expected a started client to be connected")
+		}
+		_ = client.Close()
+		if client.IsConnected() {
+			t.Error("expected a closed client to be disconnected")
+		}
+	})
+
+	t.Run("falls back to the status", func(t *testing.T) {
+		client := NewClient(&mockStatusTransport{})
+		if client.IsConnected() {
+			t.Error("expected an idle client to be disconnected")
+		}
+		if err := client.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if !client.IsConnected() {
+			t.Error("expected a started client to be connected")
+		}
+		_ = client.Close()
+		if client.IsConnected() {
+			t.Error("expected a closed client to be disconnected")
+		}
+	})
+}
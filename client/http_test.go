@@ -2,7 +2,9 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -231,3 +233,128 @@ func (sm *SafeMap) Len() int {
 	defer sm.mu.RUnlock()
 	return len(sm.data)
 }
+
+func TestHTTPClient_ToolErrorDetail(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(
+		mcp.NewTool("lookup"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultErrorWithDetail(
+				mcp.ToolErrorInvalidArguments,
+				"field 'id' must be a positive integer",
+				map[string]any{"field": "id", "got": -1},
+			), nil
+		},
+	)
+	mcpServer.AddTool(
+		mcp.NewTool("plain-error"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("something went wrong"), nil
+		},
+	)
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	client, err := NewStreamableHttpClient(testServer.URL)
+	if err != nil {
+		t.Fatalf("create client failed %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "lookup"
+	result, err := client.CallTool(ctx, request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected IsError to be set")
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "field 'id' must be a positive integer" {
+		t.Errorf("Unexpected text content: %q", text)
+	}
+
+	code, detail, ok := result.GetErrorDetail()
+	if !ok {
+		t.Fatal("Expected error detail to be present")
+	}
+	if code != mcp.ToolErrorInvalidArguments {
+		t.Errorf("Expected code %q, got %q", mcp.ToolErrorInvalidArguments, code)
+	}
+	var parsed struct {
+		Field string `json:"field"`
+		Got   int    `json:"got"`
+	}
+	if err := json.Unmarshal(detail, &parsed); err != nil {
+		t.Fatalf("Failed to decode detail %s: %v", detail, err)
+	}
+	if parsed.Field != "id" || parsed.Got != -1 {
+		t.Errorf("Unexpected detail: %+v", parsed)
+	}
+
+	request.Params.Name = "plain-error"
+	result, err = client.CallTool(ctx, request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if _, _, ok := result.GetErrorDetail(); ok {
+		t.Error("Expected no error detail for a plain error result")
+	}
+}
+
+func TestHTTPClient_Compression(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(
+		mcp.NewTool("analyze", mcp.WithString("document")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			document := request.GetString("document", "")
+			return mcp.NewToolResultText(fmt.Sprintf("%d:%s", len(document), document[len(document)-5:])), nil
+		},
+	)
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer,
+		server.WithCompression(),
+		server.WithMaxRequestBodySize(8<<20),
+	)
+	defer testServer.Close()
+
+	client, err := NewStreamableHttpClient(testServer.URL, transport.WithRequestCompression())
+	if err != nil {
+		t.Fatalf("create client failed %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	document := strings.Repeat("lorem ipsum ", 5<<20/12) + "tail!"
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "analyze"
+	request.Params.Arguments = map[string]any{"document": document}
+	result, err := client.CallTool(ctx, request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	expected := fmt.Sprintf("%d:tail!", len(document))
+	if text := result.Content[0].(mcp.TextContent).Text; text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
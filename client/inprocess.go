@@ -14,6 +14,15 @@ func NewInProcessClient(server *server.MCPServer) (*Client, error) {
 	return NewClient(inProcessTransport), nil
 }
 
+// NewInProcessClientWithContextFunc creates an in-process client that passes
+// the context of every call through fn before the server handles it, so tool
+// handlers can see request-scoped values such as auth principals.
+func NewInProcessClientWithContextFunc(server *server.MCPServer, fn transport.InProcessContextFunc) (*Client, error) {
+	inProcessTransport := transport.NewInProcessTransportWithOptions(server,
+		transport.WithInProcessContextFunc(fn))
+	return NewClient(inProcessTransport), nil
+}
+
 // NewInProcessClientWithSamplingHandler creates an in-process client with sampling support
 func NewInProcessClientWithSamplingHandler(server *server.MCPServer, handler SamplingHandler) (*Client, error) {
 	// Create a wrapper that implements server.SamplingHandler
@@ -2,7 +2,9 @@ package client
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -146,3 +148,108 @@ func TestInProcessSampling(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expectedText, textContent.Text)
 	}
 }
+
+// blockingSamplingHandler blocks until its context is done or release is
+// closed, whichever it waits for.
+type blockingSamplingHandler struct {
+	ignoreContext bool
+	started       chan struct{}
+	release       chan struct{}
+	unwound       chan error
+}
+
+func (h *blockingSamplingHandler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	close(h.started)
+	if h.ignoreContext {
+		<-h.release
+	} else {
+		<-ctx.Done()
+	}
+	h.unwound <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestInProcessSampling_CancelledToolCall(t *testing.T) {
+	for _, ignoreContext := range []bool{false, true} {
+		name := "handler honours context"
+		if ignoreContext {
+			name = "handler ignores context"
+		}
+		t.Run(name, func(t *testing.T) {
+			mcpServer := server.NewMCPServer("test-server", "1.0.0")
+			mcpServer.EnableSampling()
+
+			toolErr := make(chan error, 1)
+			mcpServer.AddTool(mcp.NewTool("sample"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				_, err := mcpServer.RequestSampling(ctx, mcp.CreateMessageRequest{
+					CreateMessageParams: mcp.CreateMessageParams{MaxTokens: 10},
+				})
+				toolErr <- err
+				return nil, err
+			})
+
+			handler := &blockingSamplingHandler{
+				ignoreContext: ignoreContext,
+				started:       make(chan struct{}),
+				release:       make(chan struct{}),
+				unwound:       make(chan error, 1),
+			}
+			defer close(handler.release)
+			client, err := NewInProcessClientWithSamplingHandler(mcpServer, handler)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			defer client.Close()
+
+			if err := client.Start(context.Background()); err != nil {
+				t.Fatalf("Failed to start client: %v", err)
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+				t.Fatalf("Failed to initialize: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			callErr := make(chan error, 1)
+			go func() {
+				_, err := client.CallTool(ctx, mcp.NewCallToolRequest("sample", nil))
+				callErr <- err
+			}()
+
+			select {
+			case <-handler.started:
+			case <-time.After(2 * time.Second):
+				t.Fatal("Sampling handler was not called")
+			}
+			cancel()
+
+			select {
+			case err := <-toolErr:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("Expected RequestSampling to fail with context.Canceled, got %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("RequestSampling kept waiting after the tool call was cancelled")
+			}
+			select {
+			case err := <-callErr:
+				if err == nil {
+					t.Error("Expected the cancelled tool call to fail")
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("CallTool did not return after cancellation")
+			}
+			if !ignoreContext {
+				select {
+				case err := <-handler.unwound:
+					if !errors.Is(err, context.Canceled) {
+						t.Errorf("Expected the sampling handler context to be cancelled, got %v", err)
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatal("Sampling handler context was not cancelled")
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,13 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
 	"testing"
 
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -419,3 +424,416 @@ func TestInProcessMCPClient(t *testing.T) {
 		}
 	})
 }
+
+type principalKey struct{}
+
+func TestInProcessMCPClient_ContextFunc(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		principal, ok := ctx.Value(principalKey{}).(string)
+		if !ok {
+			return mcp.NewToolResultError("unauthenticated"), nil
+		}
+		return mcp.NewToolResultText(principal), nil
+	})
+
+	client, err := NewInProcessClientWithContextFunc(mcpServer, func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, principalKey{}, "alice")
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "test-client",
+		Version: "1.0.0",
+	}
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "whoami"
+	result, err := client.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected the principal to reach the handler, got %+v", result.Content)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "alice" {
+		t.Errorf("Expected principal %q, got %q", "alice", text)
+	}
+}
+
+func TestClient_ListToolsFiltered(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithPaginationLimit(1))
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	mcpServer.AddTool(mcp.NewTool("read", mcp.WithReadOnlyHintAnnotation(true)), handler)
+	mcpServer.AddTool(mcp.NewTool("write", mcp.WithReadOnlyHintAnnotation(false), mcp.WithDestructiveHintAnnotation(false)), handler)
+	mcpServer.AddTool(mcp.NewTool("delete", mcp.WithDestructiveHintAnnotation(true)), handler)
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	names := func(tools []mcp.Tool) []string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	readOnly, err := client.ListToolsFiltered(context.Background(), mcp.Tool.IsReadOnly)
+	if err != nil {
+		t.Fatalf("ListToolsFiltered failed: %v", err)
+	}
+	if got := names(readOnly); !reflect.DeepEqual(got, []string{"read"}) {
+		t.Errorf("Expected only the read-only tool, got %v", got)
+	}
+
+	safe, err := client.ListToolsFiltered(context.Background(), func(tool mcp.Tool) bool {
+		return !tool.IsDestructive()
+	})
+	if err != nil {
+		t.Fatalf("ListToolsFiltered failed: %v", err)
+	}
+	if got := names(safe); !reflect.DeepEqual(got, []string{"read", "write"}) {
+		t.Errorf("Expected the non-destructive tools, got %v", got)
+	}
+}
+
+func TestInProcessMCPClient_TitlesAndIcons(t *testing.T) {
+	icon := mcp.Icon{Src: "data:image/svg+xml;base64,PHN2Zy8+", MIMEType: "image/svg+xml", Sizes: []string{"any"}}
+	meta := map[string]any{"vendor/color": "blue"}
+
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
+		server.WithToolCapabilities(true),
+	)
+	mcpServer.AddTool(mcp.NewTool("search",
+		mcp.WithTitle("Search"),
+		mcp.WithTitleAnnotation("Search (legacy)"),
+		mcp.WithIcons(icon),
+		mcp.WithToolMeta(meta),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	mcpServer.AddPrompt(mcp.NewPrompt("summarize",
+		mcp.WithPromptTitle("Summarize"),
+		mcp.WithPromptIcons(icon),
+		mcp.WithPromptMeta(meta),
+	), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("", nil), nil
+	})
+	mcpServer.AddResource(mcp.NewResource("file:///readme", "readme",
+		mcp.WithResourceTitle("Read Me"),
+		mcp.WithResourceIcons(icon),
+		mcp.WithResourceMeta(meta),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	})
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("file:///docs/{name}", "docs",
+		mcp.WithTemplateTitle("Documentation"),
+		mcp.WithTemplateIcons(icon),
+		mcp.WithTemplateMeta(meta),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	check := func(kind, title, wantTitle string, icons []mcp.Icon, m *mcp.Meta) {
+		t.Helper()
+		if title != wantTitle {
+			t.Errorf("Expected %s title %q, got %q", kind, wantTitle, title)
+		}
+		if !reflect.DeepEqual(icons, []mcp.Icon{icon}) {
+			t.Errorf("Expected %s icons %v, got %v", kind, []mcp.Icon{icon}, icons)
+		}
+		if m == nil || m.AdditionalFields["vendor/color"] != "blue" {
+			t.Errorf("Expected %s _meta to carry vendor/color, got %+v", kind, m)
+		}
+	}
+
+	tools, err := client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(tools.Tools))
+	}
+	tool := tools.Tools[0]
+	check("tool", tool.Title, "Search", tool.Icons, tool.Meta)
+	if tool.Annotations.Title != "Search (legacy)" {
+		t.Errorf("Expected the annotation title to be kept, got %q", tool.Annotations.Title)
+	}
+	if got := tool.DisplayTitle(); got != "Search" {
+		t.Errorf("Expected the top-level title to take precedence, got %q", got)
+	}
+
+	prompts, err := client.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(prompts.Prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(prompts.Prompts))
+	}
+	prompt := prompts.Prompts[0]
+	check("prompt", prompt.Title, "Summarize", prompt.Icons, prompt.Meta)
+
+	resources, err := client.ListResources(context.Background(), mcp.ListResourcesRequest{})
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(resources.Resources) != 1 {
+		t.Fatalf("Expected 1 resource, got %d", len(resources.Resources))
+	}
+	resource := resources.Resources[0]
+	check("resource", resource.Title, "Read Me", resource.Icons, resource.Meta)
+
+	templates, err := client.ListResourceTemplates(context.Background(), mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		t.Fatalf("ListResourceTemplates failed: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 1 {
+		t.Fatalf("Expected 1 resource template, got %d", len(templates.ResourceTemplates))
+	}
+	template := templates.ResourceTemplates[0]
+	check("resource template", template.Title, "Documentation", template.Icons, template.Meta)
+}
+
+func TestClient_ListAllResourceTemplates(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithResourceCapabilities(true, true),
+		server.WithPaginationLimit(2),
+	)
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	want := []string{"a", "b", "c", "d", "e"}
+	for _, name := range want {
+		mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("file:///"+name+"/{path}", name), handler)
+	}
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	page, err := client.ListResourceTemplatesByPage(context.Background(), mcp.ListResourceTemplatesRequest{})
+	if err != nil {
+		t.Fatalf("ListResourceTemplatesByPage failed: %v", err)
+	}
+	if len(page.ResourceTemplates) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a first page of 2 templates with a cursor, got %d templates and cursor %q",
+			len(page.ResourceTemplates), page.NextCursor)
+	}
+
+	templates, err := client.ListAllResourceTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllResourceTemplates failed: %v", err)
+	}
+	var got []string
+	for _, template := range templates {
+		got = append(got, template.Name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected templates %v, got %v", want, got)
+	}
+}
+
+func TestClient_ImplementationInfo(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithToolCapabilities(false),
+		server.WithServerInfo(mcp.Implementation{
+			Title:            "Test Server",
+			WebsiteURL:       "https://server.example.com",
+			AdditionalFields: &map[string]any{"x-build": "abc123"},
+		}),
+	)
+	mcpServer.AddTool(mcp.NewTool("client-info"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+		if !ok {
+			return mcp.NewToolResultError("no client info"), nil
+		}
+		return mcp.NewToolResultStructuredOnly(session.GetClientInfo()), nil
+	})
+
+	client := NewClient(transport.NewInProcessTransport(mcpServer), WithClientInfo(mcp.Implementation{
+		Name:             "test-client",
+		Title:            "Test Client",
+		Version:          "2.0.0",
+		WebsiteURL:       "https://client.example.com",
+		AdditionalFields: &map[string]any{"x-platform": "desktop"},
+	}))
+	defer client.Close()
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	result, err := client.Initialize(context.Background(), initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	want := mcp.Implementation{
+		Name:             "test-server",
+		Title:            "Test Server",
+		Version:          "1.0.0",
+		WebsiteURL:       "https://server.example.com",
+		AdditionalFields: &map[string]any{"x-build": "abc123"},
+	}
+	if !reflect.DeepEqual(result.ServerInfo, want) {
+		t.Errorf("Expected server info %+v, got %+v", want, result.ServerInfo)
+	}
+	if got := client.GetServerInfo(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected stored server info %+v, got %+v", want, got)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "client-info"
+	toolResult, err := client.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if toolResult.IsError {
+		t.Fatalf("Expected the session to hold the client info, got %+v", toolResult.Content)
+	}
+	data, err := json.Marshal(toolResult.StructuredContent)
+	if err != nil {
+		t.Fatalf("Failed to marshal client info: %v", err)
+	}
+	var clientInfo mcp.Implementation
+	if err := json.Unmarshal(data, &clientInfo); err != nil {
+		t.Fatalf("Failed to unmarshal client info: %v", err)
+	}
+	if clientInfo.Title != "Test Client" || clientInfo.WebsiteURL != "https://client.example.com" {
+		t.Errorf("Expected the client title and website to reach the server, got %+v", clientInfo)
+	}
+	if clientInfo.AdditionalFields == nil || (*clientInfo.AdditionalFields)["x-platform"] != "desktop" {
+		t.Errorf("Expected the unknown client field to reach the server, got %+v", clientInfo.AdditionalFields)
+	}
+}
+
+func TestClient_CallToolDryRun(t *testing.T) {
+	executed := false
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(
+		mcp.NewTool("delete-file",
+			mcp.WithString("path", mcp.Required()),
+			mcp.WithDestructiveHintAnnotation(true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			executed = true
+			return mcp.NewToolResultText("deleted"), nil
+		},
+	)
+
+	client := NewClient(transport.NewInProcessTransport(mcpServer))
+	defer client.Close()
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	if !client.SupportsDryRun() {
+		t.Fatal("Expected the server to advertise dry run support")
+	}
+
+	request := mcp.NewCallToolRequest("delete-file", map[string]any{"path": 42})
+	result, err := client.CallToolWithOptions(context.Background(), request, WithDryRun())
+	if err != nil {
+		t.Fatalf("CallToolWithOptions failed: %v", err)
+	}
+	if executed {
+		t.Fatal("The handler must not run during a dry run")
+	}
+	if request.Params.Meta != nil {
+		t.Error("The caller's request must not be modified")
+	}
+	if !result.IsError {
+		t.Error("Expected the invalid arguments to be reported")
+	}
+	var report mcp.DryRunReport
+	data, _ := json.Marshal(result.StructuredContent)
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to unmarshal the report: %v", err)
+	}
+	if report.Valid || len(report.Errors) != 1 || report.Annotations.DestructiveHint == nil || !*report.Annotations.DestructiveHint {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	result, err = client.CallTool(context.Background(), mcp.NewCallToolRequest("delete-file", map[string]any{"path": "/tmp/a"}))
+	if err != nil || result.IsError || !executed {
+		t.Fatalf("Expected the call to run without dry run, got %+v, %v", result, err)
+	}
+}
+
+func TestClient_CallToolDryRunUnsupported(t *testing.T) {
+	mockTransport := &mockProtocolTransport{
+		responses: map[string]string{
+			"initialize": `{"protocolVersion": "2025-06-18", "capabilities": {"tools": {}}, "serverInfo": {"name": "old", "version": "1.0"}}`,
+			"tools/call": `{"content": [{"type": "text", "text": "executed"}]}`,
+		},
+	}
+	client := NewClient(mockTransport)
+	if _, err := client.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	_, err := client.CallToolWithOptions(context.Background(), mcp.NewCallToolRequest("delete-file", nil), WithDryRun())
+	if !errors.Is(err, ErrCapabilityNotSupported) {
+		t.Fatalf("Expected ErrCapabilityNotSupported, got %v", err)
+	}
+}
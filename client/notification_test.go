@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mockNotifyingTransport lets tests push server notifications to the client.
+type mockNotifyingTransport struct {
+	mockStatusTransport
+	handlerMu sync.Mutex
+	handler   func(mcp.JSONRPCNotification)
+}
+
+func (m *mockNotifyingTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	m.handlerMu.Lock()
+	defer m.handlerMu.Unlock()
+	m.handler = handler
+}
+
+func (m *mockNotifyingTransport) notify(method string) {
+	m.handlerMu.Lock()
+	handler := m.handler
+	m.handlerMu.Unlock()
+	handler(mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: method},
+	})
+}
+
+func TestClient_WaitForNotification(t *testing.T) {
+	mockTransport := &mockNotifyingTransport{}
+	client := NewClient(mockTransport)
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer client.Close()
+
+	var received []string
+	var receivedMu sync.Mutex
+	client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		receivedMu.Lock()
+		defer receivedMu.Unlock()
+		received = append(received, notification.Method)
+	})
+
+	t.Run("returns the next matching notification", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		done := make(chan struct{})
+		var got mcp.JSONRPCNotification
+		var err error
+		go func() {
+			defer close(done)
+			got, err = client.WaitForNotification(ctx, mcp.MethodNotificationToolsListChanged)
+		}()
+
+		// Give the waiter time to register, then send an unrelated
+		// notification followed by the one we are waiting for.
+		time.Sleep(10 * time.Millisecond)
+		mockTransport.notify(mcp.MethodNotificationResourcesListChanged)
+		mockTransport.notify(mcp.MethodNotificationToolsListChanged)
+		<-done
+
+		if err != nil {
+			t.Fatalf("WaitForNotification failed: %v", err)
+		}
+		if got.Method != mcp.MethodNotificationToolsListChanged {
+			t.Errorf("expected %s, got %s", mcp.MethodNotificationToolsListChanged, got.Method)
+		}
+
+		// Registered handlers still see every notification
+		receivedMu.Lock()
+		defer receivedMu.Unlock()
+		if len(received) != 2 {
+			t.Errorf("expected OnNotification handler to see 2 notifications, got %v", received)
+		}
+	})
+
+	t.Run("returns context error on cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.WaitForNotification(ctx, mcp.MethodNotificationToolsListChanged)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+
+		client.waitersMu.Lock()
+		defer client.waitersMu.Unlock()
+		if len(client.waiters) != 0 {
+			t.Errorf("expected cancelled waiter to be removed, got %d waiters", len(client.waiters))
+		}
+	})
+}
+
+func TestClient_OnPromptListChanged(t *testing.T) {
+	mockTransport := &mockNotifyingTransport{}
+	client := NewClient(mockTransport)
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer client.Close()
+
+	var calls []string
+	client.OnPromptListChanged(func() { calls = append(calls, "first") })
+	client.OnPromptListChanged(func() { calls = append(calls, "second") })
+
+	mockTransport.notify(mcp.MethodNotificationToolsListChanged)
+	if len(calls) != 0 {
+		t.Fatalf("Expected handlers to ignore other notifications, got %v", calls)
+	}
+
+	mockTransport.notify(mcp.MethodNotificationPromptsListChanged)
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Expected both handlers to be called in order, got %v", calls)
+	}
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClient_AnswersPing(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	defer stdoutWriter.Close()
+
+	// A sampling handler must not keep the client from answering pings
+	client := NewClient(
+		transport.NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader(""))),
+		WithSamplingHandler(&mockSamplingHandler{}),
+	)
+	var pings atomic.Int32
+	client.OnPingReceived(func() {
+		pings.Add(1)
+	})
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer client.Close()
+
+	go func() {
+		_, _ = stdoutWriter.Write([]byte(`{"jsonrpc":"2.0","id":7,"method":"ping"}` + "\n"))
+	}()
+
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdinReader).ReadString('\n')
+		lines <- line
+	}()
+
+	var line string
+	select {
+	case line = <-lines:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client to answer the ping")
+	}
+
+	var response transport.JSONRPCResponse
+	if err := json.Unmarshal([]byte(line), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", line, err)
+	}
+	if response.ID.String() != mcp.NewRequestId(int64(7)).String() {
+		t.Errorf("Expected response to request 7, got %v", response.ID)
+	}
+	if response.Error != nil {
+		t.Errorf("Expected empty result, got error %v", response.Error)
+	}
+	if string(response.Result) != "{}" {
+		t.Errorf("Expected empty result, got %s", response.Result)
+	}
+	if got := pings.Load(); got != 1 {
+		t.Errorf("Expected OnPingReceived to be called once, got %d", got)
+	}
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -229,3 +230,136 @@ func TestUnsupportedProtocolVersionError_Is(t *testing.T) {
 		t.Error("expected IsUnsupportedProtocolVersion to return false for different error type")
 	}
 }
+
+// mockVersionTransport only accepts one protocol version. Other versions are
+// rejected with an invalid params error, or answered with a version the
+// client does not know if unknownVersion is set.
+type mockVersionTransport struct {
+	mockProtocolTransport
+	accepted       string
+	unknownVersion bool
+	errorData      string
+	requested      []string
+}
+
+func (m *mockVersionTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method != "initialize" {
+		return m.mockProtocolTransport.SendRequest(ctx, request)
+	}
+	data, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	var params mcp.InitializeParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	m.requested = append(m.requested, params.ProtocolVersion)
+
+	version := params.ProtocolVersion
+	var response string
+	switch {
+	case version != m.accepted && m.unknownVersion:
+		version = "2099-01-01"
+		fallthrough
+	case version == m.accepted:
+		response = fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":%q,"capabilities":{},"serverInfo":{"name":"old","version":"1.0"}}}`, version)
+	default:
+		errorData := m.errorData
+		if errorData == "" {
+			errorData = "null"
+		}
+		response = fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"error":{"code":%d,"message":"Unsupported protocol version","data":%s}}`, mcp.INVALID_PARAMS, errorData)
+	}
+	var resp transport.JSONRPCResponse
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func TestInitializeVersionFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		transport     *mockVersionTransport
+		wantRequested []string
+	}{
+		{
+			name:          "JSON-RPC error",
+			transport:     &mockVersionTransport{accepted: "2024-11-05"},
+			wantRequested: []string{mcp.LATEST_PROTOCOL_VERSION, "2025-03-26", "2024-11-05"},
+		},
+		{
+			name:          "unsupported version in result",
+			transport:     &mockVersionTransport{accepted: "2024-11-05", unknownVersion: true},
+			wantRequested: []string{mcp.LATEST_PROTOCOL_VERSION, "2025-03-26", "2024-11-05"},
+		},
+		{
+			name:          "supported versions in error data",
+			transport:     &mockVersionTransport{accepted: "2024-11-05", errorData: `{"supported":["2024-11-05"],"requested":"x"}`},
+			wantRequested: []string{mcp.LATEST_PROTOCOL_VERSION, "2024-11-05"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested, negotiated string
+			client := NewClient(tt.transport, WithVersionNegotiatedHandler(func(r, n string) {
+				requested, negotiated = r, n
+			}))
+
+			result, err := client.Initialize(context.Background(), mcp.InitializeRequest{
+				Params: mcp.InitializeParams{
+					ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+					ClientInfo:      mcp.Implementation{Name: "test-client", Version: "1.0"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProtocolVersion != "2024-11-05" {
+				t.Errorf("expected result version 2024-11-05, got %q", result.ProtocolVersion)
+			}
+			if got := client.GetProtocolVersion(); got != "2024-11-05" {
+				t.Errorf("expected negotiated version 2024-11-05, got %q", got)
+			}
+			if requested != mcp.LATEST_PROTOCOL_VERSION || negotiated != "2024-11-05" {
+				t.Errorf("unexpected callback arguments %q, %q", requested, negotiated)
+			}
+			if fmt.Sprint(tt.transport.requested) != fmt.Sprint(tt.wantRequested) {
+				t.Errorf("expected attempts %v, got %v", tt.wantRequested, tt.transport.requested)
+			}
+		})
+	}
+}
+
+func TestInitializeWithoutVersionFallback(t *testing.T) {
+	mockTransport := &mockVersionTransport{accepted: "2024-11-05"}
+	client := NewClient(mockTransport, WithoutVersionFallback())
+
+	_, err := client.Initialize(context.Background(), mcp.InitializeRequest{
+		Params: mcp.InitializeParams{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION},
+	})
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcp.INVALID_PARAMS {
+		t.Fatalf("expected the invalid params error, got %v", err)
+	}
+	if len(mockTransport.requested) != 1 {
+		t.Errorf("expected a single attempt, got %v", mockTransport.requested)
+	}
+	if client.IsInitialized() || client.GetProtocolVersion() != "" {
+		t.Error("the client must not be initialized")
+	}
+}
+
+func TestInitializeVersionFallbackStopsOnOtherErrors(t *testing.T) {
+	mockTransport := &mockProtocolTransport{responses: map[string]string{}}
+	client := NewClient(mockTransport)
+
+	_, err := client.Initialize(context.Background(), mcp.InitializeRequest{
+		Params: mcp.InitializeParams{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no mock response") {
+		t.Fatalf("expected the transport error, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// WithRequestLogger logs every request the client sends, with its params,
+// and every request that fails. Set a redactor with WithRedactor to keep
+// secrets in the params out of the log.
+func WithRequestLogger(logger util.Logger) ClientOption {
+	return func(c *Client) {
+		c.requestLogger = logger
+	}
+}
+
+// WithRedactor sets the redactor applied to requests before they are logged
+// by WithRequestLogger, such as server.RedactKeys("password", "token"). The
+// requests sent to the server are never redacted.
+func WithRedactor(redactor server.Redactor) ClientOption {
+	return func(c *Client) {
+		c.redactor = redactor
+	}
+}
+
+// logRequest logs a request about to be sent, if a request logger is set.
+func (c *Client) logRequest(request transport.JSONRPCRequest) {
+	if c.requestLogger == nil {
+		return
+	}
+	message, err := json.Marshal(request)
+	if err != nil {
+		return
+	}
+	if c.redactor != nil {
+		if message = c.redactor.RedactJSON(message); message == nil {
+			return
+		}
+	}
+	c.requestLogger.Infof("sending request %s: %s", request.Method, message)
+}
+
+// logRequestError logs a request that failed, if a request logger is set.
+func (c *Client) logRequestError(request transport.JSONRPCRequest, err error) {
+	if c.requestLogger == nil {
+		return
+	}
+	c.requestLogger.Errorf("request %s (id %v) failed: %v", request.Method, request.ID.Value(), err)
+}
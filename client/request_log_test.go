@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// captureLogger records the lines logged through it.
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) Infof(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, "INFO: "+fmt.Sprintf(format, v...))
+}
+
+func (l *captureLogger) Errorf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, "ERROR: "+fmt.Sprintf(format, v...))
+}
+
+func TestClient_RequestLoggerRedactsParams(t *testing.T) {
+	logger := &captureLogger{}
+	var sent []byte
+	client := NewClient(&errorInjectingTransport{
+		send: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			if request.Method == string(mcp.MethodToolsCall) {
+				sent, _ = json.Marshal(request.Params)
+				return &transport.JSONRPCResponse{
+					JSONRPC: mcp.JSONRPC_VERSION,
+					ID:      request.ID,
+					Error: &struct {
+						Code    int             `json:"code"`
+						Message string          `json:"message"`
+						Data    json.RawMessage `json:"data"`
+					}{Code: mcp.INVALID_PARAMS, Message: "bad key"},
+				}, nil
+			}
+			return resultResponse(request, `{"protocolVersion":"`+mcp.LATEST_PROTOCOL_VERSION+`","capabilities":{"tools":{}},"serverInfo":{"name":"test","version":"1.0.0"}}`), nil
+		},
+	}, WithRequestLogger(logger), WithRedactor(server.RedactKeys("apiKey")))
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "search"
+	request.Params.Arguments = map[string]any{"query": "weather", "auth": map[string]any{"APIKEY": "secret"}}
+	if _, err := client.CallTool(context.Background(), request); err == nil {
+		t.Fatal("expected the call to fail")
+	}
+
+	// The server receives the real value
+	if !strings.Contains(string(sent), `"APIKEY":"secret"`) {
+		t.Errorf("expected the unredacted arguments to be sent, got %s", sent)
+	}
+
+	logged := strings.Join(logger.lines, "\n")
+	if strings.Contains(logged, "secret") {
+		t.Errorf("secret leaked into the log:\n%s", logged)
+	}
+	if !strings.Contains(logged, `INFO: sending request tools/call:`) || !strings.Contains(logged, `"APIKEY":"[REDACTED]"`) {
+		t.Errorf("expected the redacted request to be logged, got:\n%s", logged)
+	}
+	if !strings.Contains(logged, "ERROR: request tools/call (id 2) failed: -32602: bad key") {
+		t.Errorf("expected the failure to be logged, got:\n%s", logged)
+	}
+}
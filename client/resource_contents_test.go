@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_ReadResourceTextAndBlob(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithResourceCapabilities(false, false))
+	mcpServer.AddResource(mcp.NewResource("docs://readme", "readme"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, Text: "Hello, "},
+			mcp.TextResourceContents{URI: request.Params.URI, Text: "world"},
+		}, nil
+	})
+	mcpServer.AddResource(mcp.NewResource("files://image", "image"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.BlobResourceContents{URI: request.Params.URI, Blob: base64.StdEncoding.EncodeToString([]byte{0x89, 'P'})},
+			mcp.BlobResourceContents{URI: request.Params.URI, Blob: base64.StdEncoding.EncodeToString([]byte{'N', 'G'})},
+		}, nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("NewInProcessClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	text, err := client.ReadResourceText(ctx, "docs://readme")
+	if err != nil {
+		t.Fatalf("ReadResourceText failed: %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("expected %q, got %q", "Hello, world", text)
+	}
+
+	data, err := client.ReadResourceBlob(ctx, "files://image")
+	if err != nil {
+		t.Fatalf("ReadResourceBlob failed: %v", err)
+	}
+	if string(data) != "\x89PNG" {
+		t.Errorf("expected %q, got %q", "\x89PNG", data)
+	}
+
+	if _, err := client.ReadResourceText(ctx, "files://image"); err == nil {
+		t.Error("expected ReadResourceText to fail on binary content")
+	}
+	if _, err := client.ReadResourceBlob(ctx, "docs://readme"); err == nil {
+		t.Error("expected ReadResourceBlob to fail on text content")
+	}
+	if _, err := client.ReadResourceText(ctx, "docs://missing"); err == nil {
+		t.Error("expected ReadResourceText to fail for an unknown resource")
+	}
+}
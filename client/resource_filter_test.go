@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_ListResourcesFiltered(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithResourceCapabilities(false, false),
+		server.WithPaginationLimit(1),
+	)
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	mcpServer.AddResource(mcp.NewResource("file:///a.md", "a", mcp.WithMIMEType("text/markdown")), handler)
+	mcpServer.AddResource(mcp.NewResource("file:///b.go", "b", mcp.WithMIMEType("text/x-go")), handler)
+	mcpServer.AddResource(mcp.NewResource("file:///c.md", "c", mcp.WithMIMEType("text/markdown")), handler)
+	mcpServer.AddResource(mcp.NewResource("db://d", "d", mcp.WithMIMEType("text/markdown")), handler)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("db://{table}", "tables"), handler)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate("file:///{path}", "files"), handler)
+
+	c, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	result, err := c.ListResourcesFiltered(ctx, mcp.ListResourcesFilter{URIPrefix: "file://", MIMEType: "text/markdown"})
+	if err != nil {
+		t.Fatalf("list resources: %v", err)
+	}
+	var uris []string
+	for _, resource := range result.Resources {
+		uris = append(uris, resource.URI)
+	}
+	if want := []string{"file:///a.md", "file:///c.md"}; !reflect.DeepEqual(uris, want) {
+		t.Errorf("expected %v, got %v", want, uris)
+	}
+
+	templates, err := c.ListResourceTemplatesFiltered(ctx, mcp.ListResourcesFilter{URIPrefix: "db://"})
+	if err != nil {
+		t.Fatalf("list resource templates: %v", err)
+	}
+	if len(templates.ResourceTemplates) != 1 || templates.ResourceTemplates[0].Name != "tables" {
+		t.Errorf("expected the tables template, got %+v", templates.ResourceTemplates)
+	}
+
+	// An empty filter lists everything
+	result, err = c.ListResourcesFiltered(ctx, mcp.ListResourcesFilter{})
+	if err != nil {
+		t.Fatalf("list resources: %v", err)
+	}
+	if len(result.Resources) != 4 {
+		t.Errorf("expected 4 resources, got %d", len(result.Resources))
+	}
+}
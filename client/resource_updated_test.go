@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClient_OnResourceUpdated(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinReader.Close()
+	defer stdoutWriter.Close()
+
+	client := NewClient(transport.NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader(""))))
+
+	type update struct {
+		uri      string
+		contents []mcp.ResourceContents
+	}
+	updates := make(chan update, 2)
+	client.OnResourceUpdated(func(uri string, contents []mcp.ResourceContents) {
+		updates <- update{uri: uri, contents: contents}
+	})
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer client.Close()
+
+	go func() {
+		_, _ = stdoutWriter.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///a.txt"}}` + "\n"))
+		_, _ = stdoutWriter.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"file:///b.txt","contents":[{"uri":"file:///b.txt","mimeType":"text/plain","text":"new"}]}}` + "\n"))
+	}()
+
+	next := func() update {
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(time.Second):
+			t.Fatal("Expected a resource update")
+			return update{}
+		}
+	}
+
+	plain := next()
+	if plain.uri != "file:///a.txt" || plain.contents != nil {
+		t.Errorf("Expected a plain update for file:///a.txt, got %+v", plain)
+	}
+
+	inline := next()
+	if inline.uri != "file:///b.txt" {
+		t.Errorf("Expected update for file:///b.txt, got %s", inline.uri)
+	}
+	if len(inline.contents) != 1 {
+		t.Fatalf("Expected 1 inline content, got %d", len(inline.contents))
+	}
+	text, ok := inline.contents[0].(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("Expected TextResourceContents, got %T", inline.contents[0])
+	}
+	if text.Text != "new" || text.MIMEType != "text/plain" {
+		t.Errorf("Unexpected inline contents: %+v", text)
+	}
+}
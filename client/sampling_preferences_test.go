@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// preferencesSamplingHandler records the model preferences of every sampling
+// request it receives, then modifies them to check that the server's copy is
+// not shared.
+type preferencesSamplingHandler struct {
+	mu       sync.Mutex
+	received []*mcp.ModelPreferences
+}
+
+func (h *preferencesSamplingHandler) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var received *mcp.ModelPreferences
+	if prefs := request.ModelPreferences; prefs != nil {
+		copied := *prefs
+		copied.Hints = append([]mcp.ModelHint(nil), prefs.Hints...)
+		received = &copied
+		for i := range prefs.Hints {
+			prefs.Hints[i].Name = "modified by handler"
+		}
+	}
+	h.received = append(h.received, received)
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent("ok"),
+		},
+		Model: "mock-model",
+	}, nil
+}
+
+func (h *preferencesSamplingHandler) last() *mcp.ModelPreferences {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.received[len(h.received)-1]
+}
+
+func newModelPreferencesServer() *server.MCPServer {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithDefaultModelPreferences(mcp.NewModelPreferences(
+			mcp.WithModelHint("default-model"),
+			mcp.WithSpeedPriority(0.9),
+		)),
+	)
+	mcpServer.EnableSampling()
+	mcpServer.AddTool(mcp.NewTool("sample", mcp.WithString("hint")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		samplingRequest := mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("hello")}},
+				MaxTokens: 10,
+			},
+		}
+		if hint := request.GetString("hint", ""); hint != "" {
+			samplingRequest.ModelPreferences = mcp.NewModelPreferences(
+				mcp.WithModelHint(hint),
+				mcp.WithModelHint("sonnet"),
+				mcp.WithCostPriority(0.25),
+				mcp.WithIntelligencePriority(0.75),
+			)
+		}
+		return mcpServer.SampleToolResult(ctx, samplingRequest)
+	})
+	return mcpServer
+}
+
+func TestClient_SamplingModelPreferences(t *testing.T) {
+	transports := map[string]func(t *testing.T, mcpServer *server.MCPServer, handler SamplingHandler) *Client{
+		"in-process": func(t *testing.T, mcpServer *server.MCPServer, handler SamplingHandler) *Client {
+			c, err := NewInProcessClientWithSamplingHandler(mcpServer, handler)
+			if err != nil {
+				t.Fatalf("create client: %v", err)
+			}
+			t.Cleanup(func() { _ = c.Close() })
+			if err := c.Start(context.Background()); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+			if _, err := c.Initialize(context.Background(), initRequest); err != nil {
+				t.Fatalf("initialize: %v", err)
+			}
+			return c
+		},
+		"stdio": func(t *testing.T, mcpServer *server.MCPServer, handler SamplingHandler) *Client {
+			_, c := startStdioPair(t, mcpServer, []ClientOption{WithSamplingHandler(handler)})
+			return c
+		},
+	}
+
+	for name, connect := range transports {
+		t.Run(name, func(t *testing.T) {
+			handler := &preferencesSamplingHandler{}
+			c := connect(t, newModelPreferencesServer(), handler)
+
+			callTool := func(args map[string]any) {
+				t.Helper()
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+				request := mcp.CallToolRequest{}
+				request.Params.Name = "sample"
+				request.Params.Arguments = args
+				result, err := c.CallTool(ctx, request)
+				if err != nil {
+					t.Fatalf("call tool: %v", err)
+				}
+				if result.IsError {
+					t.Fatalf("tool failed: %v", result.Content)
+				}
+			}
+
+			// Preferences set by the tool arrive verbatim
+			callTool(map[string]any{"hint": "claude-3-5-sonnet"})
+			want := &mcp.ModelPreferences{
+				Hints:                []mcp.ModelHint{{Name: "claude-3-5-sonnet"}, {Name: "sonnet"}},
+				CostPriority:         0.25,
+				IntelligencePriority: 0.75,
+			}
+			if got := handler.last(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+
+			// Requests without preferences get the server's defaults, every time
+			want = &mcp.ModelPreferences{
+				Hints:         []mcp.ModelHint{{Name: "default-model"}},
+				SpeedPriority: 0.9,
+			}
+			for range 2 {
+				callTool(nil)
+				if got := handler.last(); !reflect.DeepEqual(got, want) {
+					t.Errorf("expected defaults %+v, got %+v", want, got)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -272,3 +273,40 @@ func mockJSONRPCRequest(mcpRequest mcp.CreateMessageRequest) transport.JSONRPCRe
 		Params:  mcpRequest.CreateMessageParams,
 	}
 }
+
+func TestClient_CancelledNotificationCancelsSamplingHandler(t *testing.T) {
+	handler := &blockingSamplingHandler{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		unwound: make(chan error, 1),
+	}
+	client := &Client{samplingHandler: handler}
+
+	request := mockJSONRPCRequest(mcp.CreateMessageRequest{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.handleIncomingRequest(context.Background(), request)
+		done <- err
+	}()
+	<-handler.started
+
+	// Request IDs decode as float64 from the notification params
+	client.dispatchNotification(mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{"requestId": float64(1), "reason": "tool call cancelled"},
+			},
+		},
+	})
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected the cancelled sampling request to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sampling handler was not cancelled")
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_WithSchemaDefaults(t *testing.T) {
+	newClient := func(t *testing.T, opts ...ClientOption) (*Client, *map[string]any) {
+		t.Helper()
+
+		var received map[string]any
+		mcpServer := server.NewMCPServer("test-server", "1.0.0")
+		mcpServer.AddTool(mcp.NewTool("search",
+			mcp.WithString("query"),
+			mcp.WithString("sort", mcp.DefaultString("relevance")),
+			mcp.WithNumber("limit", mcp.DefaultNumber(10)),
+			mcp.WithObject("filter", mcp.Properties(map[string]any{
+				"lang": map[string]any{"type": "string", "default": "en"},
+			})),
+		), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			received = request.GetArguments()
+			return mcp.NewToolResultText("ok"), nil
+		})
+
+		client := NewClient(transport.NewInProcessTransport(mcpServer), opts...)
+		if err := client.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		return client, &received
+	}
+
+	call := func(t *testing.T, client *Client, args map[string]any) {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "search"
+		request.Params.Arguments = args
+		if _, err := client.CallTool(context.Background(), request); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		client, received := newClient(t)
+		defer client.Close()
+
+		call(t, client, map[string]any{"query": "go"})
+		expected := map[string]any{"query": "go"}
+		if !reflect.DeepEqual(*received, expected) {
+			t.Errorf("expected %v, got %v", expected, *received)
+		}
+	})
+
+	t.Run("fills omitted arguments", func(t *testing.T) {
+		client, received := newClient(t, WithSchemaDefaults())
+		defer client.Close()
+
+		// The schema is fetched on demand when the tool was not listed yet
+		args := map[string]any{"query": "go", "filter": map[string]any{}}
+		call(t, client, args)
+		expected := map[string]any{
+			"query":  "go",
+			"sort":   "relevance",
+			"limit":  json.Number("10"),
+			"filter": map[string]any{"lang": "en"},
+		}
+		if !reflect.DeepEqual(*received, expected) {
+			t.Errorf("expected %v, got %v", expected, *received)
+		}
+		if len(args) != 2 || len(args["filter"].(map[string]any)) != 0 {
+			t.Errorf("caller's arguments were modified: %v", args)
+		}
+	})
+
+	t.Run("explicit values and nulls win", func(t *testing.T) {
+		client, received := newClient(t, WithSchemaDefaults())
+		defer client.Close()
+
+		if _, err := client.ListTools(context.Background(), mcp.ListToolsRequest{}); err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		call(t, client, map[string]any{"sort": nil, "limit": 3, "filter": map[string]any{"lang": "de"}})
+		expected := map[string]any{
+			"sort":   nil,
+			"limit":  json.Number("3"),
+			"filter": map[string]any{"lang": "de"},
+		}
+		if !reflect.DeepEqual(*received, expected) {
+			t.Errorf("expected %v, got %v", expected, *received)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package client
+
+// ClientStatus describes the connection state of a Client.
+type ClientStatus int
+
+const (
+	// StatusIdle means the client has been created but not started.
+	StatusIdle ClientStatus = iota
+	// StatusConnecting means the transport is being started.
+	StatusConnecting
+	// StatusConnected means the transport is running but the session has
+	// not been initialized yet.
+	StatusConnected
+	// StatusInitializing means the initialize handshake is in progress.
+	StatusInitializing
+	// StatusReady means the session is initialized and requests can be sent.
+	StatusReady
+	// StatusReconnecting means the transport reported a lost connection and
+	// the client is waiting for it to recover.
+	StatusReconnecting
+	// StatusClosed means the client has been closed.
+	StatusClosed
+)
+
+// String returns a human-readable name for the status.
+func (s ClientStatus) String() string {
+	switch s {
+	case StatusIdle:
+		return "idle"
+	case StatusConnecting:
+		return "connecting"
+	case StatusConnected:
+		return "connected"
+	case StatusInitializing:
+		return "initializing"
+	case StatusReady:
+		return "ready"
+	case StatusReconnecting:
+		return "reconnecting"
+	case StatusClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Status returns the current connection status of the client.
+func (c *Client) Status() ClientStatus {
+	c.statusMu.RLock()
+	defer c.statusMu.RUnlock()
+	return c.status
+}
+
+// OnStatusChange registers a handler function to be called whenever the
+// connection status changes. Handlers are invoked synchronously, in the order
+// they were added, and never while the client holds internal locks, so they
+// may safely call back into the client.
+func (c *Client) OnStatusChange(handler func(old, new ClientStatus)) {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	c.statusHandlers = append(c.statusHandlers, handler)
+}
+
+// setStatus moves the client to the given status and notifies the registered
+// handlers if it changed.
+func (c *Client) setStatus(status ClientStatus) {
+	c.transitionStatus(func(ClientStatus) bool { return true }, status)
+}
+
+// transitionStatus moves the client to the given status only if allowed
+// reports true for the current status. Closed is terminal.
+func (c *Client) transitionStatus(allowed func(ClientStatus) bool, status ClientStatus) {
+	c.statusMu.Lock()
+	old := c.status
+	if old == status || old == StatusClosed || !allowed(old) {
+		c.statusMu.Unlock()
+		return
+	}
+	c.status = status
+	handlers := make([]func(old, new ClientStatus), len(c.statusHandlers))
+	copy(handlers, c.statusHandlers)
+	c.statusMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, status)
+	}
+}
+
+// handleConnectionLost marks the client as reconnecting and forwards the
+// error to the handler registered with OnConnectionLost, if any.
+func (c *Client) handleConnectionLost(err error) {
+	c.transitionStatus(func(old ClientStatus) bool {
+		return old == StatusReady || old == StatusConnected || old == StatusInitializing
+	}, StatusReconnecting)
+
+	c.connectionLostMu.RLock()
+	handler := c.connectionLostHandler
+	c.connectionLostMu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// markRecovered moves a reconnecting client back to the state it would be in
+// had the connection never been lost.
+func (c *Client) markRecovered() {
+	c.transitionStatus(func(old ClientStatus) bool {
+		return old == StatusReconnecting
+	}, c.readyOrConnected())
+}
+
+func (c *Client) readyOrConnected() ClientStatus {
+	if c.initialized.Load() {
+		return StatusReady
+	}
+	return StatusConnected
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mockStatusTransport implements transport.Interface and supports connection-lost
+// notifications, allowing tests to simulate a dropped connection.
+type mockStatusTransport struct {
+	mu             sync.Mutex
+	failRequests   bool
+	connectionLost func(error)
+}
+
+func (m *mockStatusTransport) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockStatusTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	m.mu.Lock()
+	fail := m.failRequests
+	m.mu.Unlock()
+	if fail {
+		return nil, errors.New("connection refused")
+	}
+
+	result := `{}`
+	if request.Method == "initialize" {
+		result = fmt.Sprintf(`{"protocolVersion":%q,"capabilities":{},"serverInfo":{"name":"mock","version":"1.0"}}`, mcp.LATEST_PROTOCOL_VERSION)
+	}
+	return &transport.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  json.RawMessage(result),
+	}, nil
+}
+
+func (m *mockStatusTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	return nil
+}
+
+func (m *mockStatusTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+}
+
+func (m *mockStatusTransport) SetConnectionLostHandler(handler func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connectionLost = handler
+}
+
+func (m *mockStatusTransport) Close() error {
+	return nil
+}
+
+func (m *mockStatusTransport) GetSessionId() string {
+	return "mock-session"
+}
+
+func (m *mockStatusTransport) setFailRequests(fail bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failRequests = fail
+}
+
+func (m *mockStatusTransport) loseConnection(err error) {
+	m.mu.Lock()
+	handler := m.connectionLost
+	m.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+func TestClient_StatusLifecycle(t *testing.T) {
+	mockTransport := &mockStatusTransport{}
+	client := NewClient(mockTransport)
+
+	var transitions []string
+	client.OnStatusChange(func(old, new ClientStatus) {
+		transitions = append(transitions, fmt.Sprintf("%s->%s", old, new))
+		// Calling back into the client must not deadlock
+		if got := client.Status(); got != new {
+			t.Errorf("Status() inside callback = %s, want %s", got, new)
+		}
+	})
+
+	var lostErr error
+	client.OnConnectionLost(func(err error) {
+		lostErr = err
+	})
+
+	if client.Status() != StatusIdle {
+		t.Fatalf("expected initial status %s, got %s", StatusIdle, client.Status())
+	}
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if client.Status() != StatusConnected {
+		t.Fatalf("expected status %s after Start, got %s", StatusConnected, client.Status())
+	}
+
+	// A failed initialize returns to connected
+	mockTransport.setFailRequests(true)
+	if _, err := client.Initialize(ctx, mcp.InitializeRequest{}); err == nil {
+		t.Fatal("expected Initialize to fail")
+	}
+	if client.Status() != StatusConnected {
+		t.Fatalf("expected status %s after failed Initialize, got %s", StatusConnected, client.Status())
+	}
+
+	mockTransport.setFailRequests(false)
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if client.Status() != StatusReady {
+		t.Fatalf("expected status %s after Initialize, got %s", StatusReady, client.Status())
+	}
+
+	// Simulate a connection loss
+	connErr := errors.New("http2: server sent GOAWAY and closed the connection; ErrCode=NO_ERROR")
+	mockTransport.loseConnection(connErr)
+	if client.Status() != StatusReconnecting {
+		t.Fatalf("expected status %s after connection loss, got %s", StatusReconnecting, client.Status())
+	}
+	if !errors.Is(lostErr, connErr) {
+		t.Errorf("expected OnConnectionLost handler to receive %v, got %v", connErr, lostErr)
+	}
+
+	// Requests failing while reconnecting keep the client reconnecting
+	mockTransport.setFailRequests(true)
+	if err := client.Ping(ctx); err == nil {
+		t.Fatal("expected Ping to fail")
+	}
+	if client.Status() != StatusReconnecting {
+		t.Fatalf("expected status %s after failed Ping, got %s", StatusReconnecting, client.Status())
+	}
+
+	// A successful request marks the connection as recovered
+	mockTransport.setFailRequests(false)
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if client.Status() != StatusReady {
+		t.Fatalf("expected status %s after recovery, got %s", StatusReady, client.Status())
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if client.Status() != StatusClosed {
+		t.Fatalf("expected status %s after Close, got %s", StatusClosed, client.Status())
+	}
+
+	// Closed is terminal
+	mockTransport.loseConnection(connErr)
+	if client.Status() != StatusClosed {
+		t.Fatalf("expected status to stay %s, got %s", StatusClosed, client.Status())
+	}
+
+	expected := []string{
+		"idle->connecting",
+		"connecting->connected",
+		"connected->initializing",
+		"initializing->connected",
+		"connected->initializing",
+		"initializing->ready",
+		"ready->reconnecting",
+		"reconnecting->ready",
+		"ready->closed",
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("transition %d: expected %s, got %s", i, expected[i], transitions[i])
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_MultiplexedStdioSessions(t *testing.T) {
+	newServer := func(name string) *server.MCPServer {
+		s := server.NewMCPServer(name, "1.0.0")
+		s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name), nil
+		})
+		return s
+	}
+
+	clientToServerReader, clientToServerWriter := io.Pipe()
+	serverToClientReader, serverToClientWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	multiplexer := server.NewStdioMultiplexer(map[string]*server.MCPServer{
+		"workspace-a": newServer("server-a"),
+		"workspace-b": newServer("server-b"),
+	}, server.WithErrorLogger(log.New(io.Discard, "", 0)))
+	go func() {
+		_ = multiplexer.Listen(ctx, clientToServerReader, serverToClientWriter)
+		serverToClientWriter.Close()
+	}()
+
+	mux := transport.NewStdioMux(serverToClientReader, clientToServerWriter, io.NopCloser(strings.NewReader("")))
+	defer mux.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, tc := range []struct{ key, want string }{
+		{"workspace-a", "server-a"},
+		{"workspace-b", "server-b"},
+	} {
+		wg.Add(1)
+		go func(key, want string) {
+			defer wg.Done()
+			c := NewClient(mux.Session(key))
+			defer c.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := c.Start(ctx); err != nil {
+				errs <- fmt.Errorf("%s: start: %w", key, err)
+				return
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			initRequest.Params.ClientInfo = mcp.Implementation{Name: key, Version: "1.0.0"}
+			result, err := c.Initialize(ctx, initRequest)
+			if err != nil {
+				errs <- fmt.Errorf("%s: initialize: %w", key, err)
+				return
+			}
+			if result.ServerInfo.Name != want {
+				errs <- fmt.Errorf("%s: expected server %s, got %s", key, want, result.ServerInfo.Name)
+				return
+			}
+
+			for i := 0; i < 10; i++ {
+				callRequest := mcp.CallToolRequest{}
+				callRequest.Params.Name = "whoami"
+				callResult, err := c.CallTool(ctx, callRequest)
+				if err != nil {
+					errs <- fmt.Errorf("%s: call tool: %w", key, err)
+					return
+				}
+				if text := callResult.Content[0].(mcp.TextContent).Text; text != want {
+					errs <- fmt.Errorf("%s: expected answer from %s, got %s", key, want, text)
+					return
+				}
+			}
+		}(tc.key, tc.want)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// startStdioPair serves mcpServer over pipes and returns an initialized
+// client connected to it.
+func startStdioPair(t *testing.T, mcpServer *server.MCPServer, clientOpts []ClientOption, opts ...server.StdioOption) (*server.StdioServer, *Client) {
+	t.Helper()
+	clientToServerReader, clientToServerWriter := io.Pipe()
+	serverToClientReader, serverToClientWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stdioServer := server.NewStdioServer(mcpServer)
+	for _, opt := range opts {
+		opt(stdioServer)
+	}
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = stdioServer.Listen(ctx, clientToServerReader, serverToClientWriter)
+		serverToClientWriter.Close()
+	}()
+
+	c := NewClient(transport.NewIO(serverToClientReader, clientToServerWriter, io.NopCloser(strings.NewReader(""))), clientOpts...)
+	t.Cleanup(func() {
+		cancel()
+		_ = c.Close()
+		_ = clientToServerReader.Close()
+		<-done
+	})
+
+	// The transport handles server requests with the start context
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	initCtx, initCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer initCancel()
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := c.Initialize(initCtx, initRequest); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	return stdioServer, c
+}
+
+func TestClient_StdioNotificationsOutsideRequests(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	first, firstClient := startStdioPair(t, mcpServer, nil)
+	second, secondClient := startStdioPair(t, mcpServer, nil, server.WithStdioSessionID("stdio-2"))
+
+	if first.SessionID() == "" || first.SessionID() == second.SessionID() {
+		t.Fatalf("expected distinct session IDs, got %q and %q", first.SessionID(), second.SessionID())
+	}
+
+	received := func(c *Client) <-chan mcp.JSONRPCNotification {
+		ch := make(chan mcp.JSONRPCNotification, 10)
+		c.OnNotification(func(notification mcp.JSONRPCNotification) {
+			ch <- notification
+		})
+		return ch
+	}
+	firstReceived := received(firstClient)
+	secondReceived := received(secondClient)
+
+	expect := func(ch <-chan mcp.JSONRPCNotification, method string) {
+		t.Helper()
+		select {
+		case notification := <-ch:
+			if notification.Method != method {
+				t.Fatalf("expected %s, got %s", method, notification.Method)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", method)
+		}
+	}
+
+	// Broadcast from outside any request
+	go mcpServer.SendNotificationToAllClients(string(mcp.MethodNotificationToolsListChanged), nil)
+	expect(firstReceived, string(mcp.MethodNotificationToolsListChanged))
+	expect(secondReceived, string(mcp.MethodNotificationToolsListChanged))
+
+	// Target a single client by the ID of its session
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- mcpServer.SendNotificationToSpecificClient(second.SessionID(), string(mcp.MethodNotificationResourcesListChanged), nil)
+	}()
+	if err := <-errCh; err != nil {
+		t.Fatalf("send to session: %v", err)
+	}
+	expect(secondReceived, string(mcp.MethodNotificationResourcesListChanged))
+	select {
+	case notification := <-firstReceived:
+		t.Fatalf("unexpected notification %s for the other session", notification.Method)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
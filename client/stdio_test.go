@@ -1,17 +1,21 @@
 package client
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mark3labs/mcp-go/client/transport"
@@ -348,3 +352,93 @@ func TestStdio_NewStdioMCPClientWithOptions_FailsToStart(t *testing.T) {
 	require.EqualError(t, err, "failed to start stdio transport: failed to start command: fork/exec /nonexistent/bar: no such file or directory")
 	require.Nil(t, client)
 }
+
+func TestStdio_CustomRequestIDGenerator(t *testing.T) {
+	clientToServerReader, clientToServerWriter := io.Pipe()
+	serverToClientReader, serverToClientWriter := io.Pipe()
+
+	// Fake server answering requests in reverse order, echoing the token from
+	// the request params so that misrouted responses can be detected.
+	const numRequests = 20
+	go func() {
+		var writeMu sync.Mutex
+		var wg sync.WaitGroup
+		scanner := bufio.NewScanner(clientToServerReader)
+		for i := 0; scanner.Scan(); i++ {
+			var request struct {
+				ID     json.RawMessage   `json:"id"`
+				Params map[string]string `json:"params"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+				continue
+			}
+			wg.Add(1)
+			go func(delay time.Duration) {
+				defer wg.Done()
+				time.Sleep(delay)
+				response := fmt.Sprintf(
+					`{"jsonrpc":"2.0","id":%s,"result":{"token":%q}}`+"\n",
+					request.ID, request.Params["token"],
+				)
+				writeMu.Lock()
+				defer writeMu.Unlock()
+				_, _ = serverToClientWriter.Write([]byte(response))
+			}(time.Duration(numRequests-i%numRequests) * time.Millisecond)
+		}
+		wg.Wait()
+		_ = serverToClientWriter.Close()
+	}()
+
+	var generated sync.Map
+	stdio := transport.NewIO(serverToClientReader, clientToServerWriter, io.NopCloser(strings.NewReader("")))
+	client := NewClient(stdio,
+		WithSession(),
+		WithRequestIDGenerator(func() mcp.RequestId {
+			id := uuid.NewString()
+			generated.Store(id, true)
+			return mcp.NewRequestId(id)
+		}),
+	)
+	require.NoError(t, client.Start(context.Background()))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numRequests)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			result, err := client.sendRequest(ctx, "test/echo", map[string]string{"token": token})
+			if err != nil {
+				errs <- err
+				return
+			}
+			var echoed struct {
+				Token string `json:"token"`
+			}
+			if err := json.Unmarshal(*result, &echoed); err != nil {
+				errs <- err
+				return
+			}
+			if echoed.Token != token {
+				errs <- fmt.Errorf("expected token %s, got %s", token, echoed.Token)
+			}
+		}(fmt.Sprintf("token-%d", i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	count := 0
+	generated.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	require.Equal(t, numRequests, count)
+}
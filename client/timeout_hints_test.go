@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_WithHonorServerTimeoutHints(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithTimeoutHints(map[string]time.Duration{
+			mcp.TimeoutHintKeyForTool("slow"):   50 * time.Millisecond,
+			mcp.TimeoutHintKeyForTool("capped"): time.Hour,
+		}),
+	)
+	// Every tool takes 150ms, longer than the hint for slow
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(150 * time.Millisecond):
+			return mcp.NewToolResultText("done"), nil
+		}
+	}
+	for _, name := range []string{"slow", "other", "capped"} {
+		mcpServer.AddTool(mcp.NewTool(name), handler)
+	}
+
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer httpServer.Close()
+
+	newClient := func(t *testing.T, opts ...ClientOption) *Client {
+		t.Helper()
+		trans, err := transport.NewStreamableHTTP(httpServer.URL)
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+		client := NewClient(trans, opts...)
+		if err := client.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		t.Cleanup(func() { client.Close() })
+		return client
+	}
+	// call calls the tool and returns how long it took
+	call := func(ctx context.Context, client *Client, name string) (time.Duration, error) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		start := time.Now()
+		_, err := client.CallTool(ctx, request)
+		return time.Since(start), err
+	}
+
+	t.Run("hinted calls time out", func(t *testing.T) {
+		client := newClient(t, WithHonorServerTimeoutHints())
+		elapsed, err := call(context.Background(), client, "slow")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected a deadline error, got %v", err)
+		}
+		if elapsed < 40*time.Millisecond || elapsed > 140*time.Millisecond {
+			t.Errorf("expected the call to time out after about 50ms, took %v", elapsed)
+		}
+
+		// Calls without a hint are unaffected
+		if _, err := call(context.Background(), client, "other"); err != nil {
+			t.Errorf("expected the call without a hint to succeed, got %v", err)
+		}
+	})
+
+	t.Run("caller deadline wins", func(t *testing.T) {
+		client := newClient(t, WithHonorServerTimeoutHints())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := call(ctx, client, "slow"); err != nil {
+			t.Errorf("expected the caller's deadline to replace the hint, got %v", err)
+		}
+	})
+
+	t.Run("hints are capped", func(t *testing.T) {
+		client := newClient(t, WithHonorServerTimeoutHints(), WithMaxTimeoutHint(50*time.Millisecond))
+		if _, err := call(context.Background(), client, "capped"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the capped hint to time out, got %v", err)
+		}
+	})
+
+	t.Run("ignored by default", func(t *testing.T) {
+		client := newClient(t)
+		if _, err := call(context.Background(), client, "slow"); err != nil {
+			t.Errorf("expected hints to be ignored, got %v", err)
+		}
+	})
+}
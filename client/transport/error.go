@@ -1,6 +1,44 @@
 package transport
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	// ErrTransport is matched by errors.Is for every *Error.
+	ErrTransport = errors.New("transport error")
+	// ErrNotStarted is returned when a message is sent before Start.
+	ErrNotStarted = errors.New("transport not started")
+	// ErrClosed is returned when a message is sent after the transport was closed.
+	ErrClosed = errors.New("transport closed")
+	// ErrConnectionClosed is returned when the server ends the connection
+	// while requests are pending, such as a stdio server exiting.
+	ErrConnectionClosed = errors.New("connection closed")
+	// ErrResponseTimeout is matched by errors.Is for every
+	// *ResponseTimeoutError.
+	ErrResponseTimeout = errors.New("response timeout")
+)
+
+// ResponseTimeoutError is returned when the server accepted a request but did
+// not deliver the correlated response within the configured timeout.
+type ResponseTimeoutError struct {
+	Method  string
+	ID      mcp.RequestId
+	Timeout time.Duration
+}
+
+func (e *ResponseTimeoutError) Error() string {
+	return fmt.Sprintf("no response to %s request %v within %s", e.Method, e.ID.Value(), e.Timeout)
+}
+
+// Is reports whether target is ErrResponseTimeout.
+func (e *ResponseTimeoutError) Is(target error) bool {
+	return target == ErrResponseTimeout
+}
 
 // Error wraps a low-level transport error in a concrete type.
 type Error struct {
@@ -15,8 +53,28 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is ErrTransport.
+func (e *Error) Is(target error) bool {
+	return target == ErrTransport
+}
+
 func NewError(err error) *Error {
 	return &Error{
 		Err: err,
 	}
 }
+
+// stateError keeps a transport-specific message while matching one of the
+// sentinel errors above with errors.Is.
+type stateError struct {
+	kind error
+	msg  string
+}
+
+func (e *stateError) Error() string {
+	return e.msg
+}
+
+func (e *stateError) Is(target error) bool {
+	return target == e.kind
+}
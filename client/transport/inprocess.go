@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -15,13 +16,31 @@ type InProcessTransport struct {
 	samplingHandler server.SamplingHandler
 	session         *server.InProcessSession
 	sessionID       string
+	contextFunc     InProcessContextFunc
 
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+
+	started   atomic.Bool
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 type InProcessOption func(*InProcessTransport)
 
+// InProcessContextFunc derives the context the server handlers see from the
+// context of a client call.
+type InProcessContextFunc func(ctx context.Context) context.Context
+
+// WithInProcessContextFunc sets a function applied to the context of every
+// request and notification before it is handed to the server, for example to
+// attach an auth principal or tracing span to what tool handlers see.
+func WithInProcessContextFunc(fn InProcessContextFunc) InProcessOption {
+	return func(t *InProcessTransport) {
+		t.contextFunc = fn
+	}
+}
+
 func WithSamplingHandler(handler server.SamplingHandler) InProcessOption {
 	return func(t *InProcessTransport) {
 		t.samplingHandler = handler
@@ -29,15 +48,14 @@ func WithSamplingHandler(handler server.SamplingHandler) InProcessOption {
 }
 
 func NewInProcessTransport(server *server.MCPServer) *InProcessTransport {
-	return &InProcessTransport{
-		server: server,
-	}
+	return NewInProcessTransportWithOptions(server)
 }
 
 func NewInProcessTransportWithOptions(server *server.MCPServer, opts ...InProcessOption) *InProcessTransport {
 	t := &InProcessTransport{
 		server:    server,
 		sessionID: server.GenerateInProcessSessionID(),
+		done:      make(chan struct{}),
 	}
 
 	for _, opt := range opts {
@@ -48,16 +66,35 @@ func NewInProcessTransportWithOptions(server *server.MCPServer, opts ...InProces
 }
 
 func (c *InProcessTransport) Start(ctx context.Context) error {
-	// Create and register session if we have a sampling handler
-	if c.samplingHandler != nil {
-		c.session = server.NewInProcessSession(c.sessionID, c.samplingHandler)
-		if err := c.server.RegisterSession(ctx, c.session); err != nil {
-			return fmt.Errorf("failed to register session: %w", err)
-		}
+	// Register a session so the server can send notifications, and sampling
+	// requests if we have a handler
+	c.session = server.NewInProcessSession(c.sessionID, c.samplingHandler)
+	if err := c.server.RegisterSession(ctx, c.session); err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
 	}
+	go c.forwardNotifications(c.session.Notifications())
+	c.started.Store(true)
 	return nil
 }
 
+// forwardNotifications hands the notifications the server sends to the
+// session to the notification handler until the transport is closed.
+func (c *InProcessTransport) forwardNotifications(notifications <-chan mcp.JSONRPCNotification) {
+	for {
+		select {
+		case notification := <-notifications:
+			c.notifyMu.RLock()
+			handler := c.onNotification
+			c.notifyMu.RUnlock()
+			if handler != nil {
+				handler(notification)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
 func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
@@ -69,6 +106,10 @@ func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCReq
 	if c.session != nil {
 		ctx = c.server.WithContext(ctx, c.session)
 	}
+	ctx = server.WithTransportType(ctx, server.TransportInProcess)
+	if c.contextFunc != nil {
+		ctx = c.contextFunc(ctx)
+	}
 
 	respMessage := c.server.HandleMessage(ctx, requestBytes)
 	respByte, err := json.Marshal(respMessage)
@@ -90,6 +131,10 @@ func (c *InProcessTransport) SendNotification(ctx context.Context, notification
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 	notificationBytes = append(notificationBytes, '\n')
+	ctx = server.WithTransportType(ctx, server.TransportInProcess)
+	if c.contextFunc != nil {
+		ctx = c.contextFunc(ctx)
+	}
 	c.server.HandleMessage(ctx, notificationBytes)
 
 	return nil
@@ -101,10 +146,28 @@ func (c *InProcessTransport) SetNotificationHandler(handler func(notification mc
 	c.onNotification = handler
 }
 
-func (c *InProcessTransport) Close() error {
-	if c.session != nil {
-		c.server.UnregisterSession(context.Background(), c.sessionID)
+// HealthCheck always succeeds: the server runs in the same process.
+func (c *InProcessTransport) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// IsConnected reports whether the transport has been started and not closed.
+func (c *InProcessTransport) IsConnected() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return c.started.Load()
 	}
+}
+
+func (c *InProcessTransport) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		if c.session != nil {
+			c.server.UnregisterSession(context.Background(), c.sessionID)
+		}
+	})
 	return nil
 }
 
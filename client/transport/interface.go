@@ -47,6 +47,31 @@ type BidirectionalInterface interface {
 	SetRequestHandler(handler RequestHandler)
 }
 
+// NewPingResponse returns the empty result that answers a ping request from
+// the server. Bidirectional transports use it to answer pings themselves when
+// no request handler is set.
+func NewPingResponse(id mcp.RequestId) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Result:  json.RawMessage(`{}`),
+	}
+}
+
+// HealthChecker is implemented by transports that can cheaply report whether
+// the connection is usable, without a protocol-level round trip. HealthCheck
+// returns nil if the transport is healthy.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// ConnectionStateReporter is implemented by transports that can tell whether
+// they are currently connected, without any I/O. Supervisors can poll it to
+// decide when to recreate a client.
+type ConnectionStateReporter interface {
+	IsConnected() bool
+}
+
 // HTTPConnection is a Transport that runs over HTTP and supports
 // protocol version headers.
 type HTTPConnection interface {
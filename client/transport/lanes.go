@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// isControlMethod reports whether messages for method belong in the control
+// lane: pings, cancellations and responses to sampling requests are small
+// and time sensitive, and must not queue behind large payloads.
+func isControlMethod(method string) bool {
+	switch method {
+	case string(mcp.MethodPing),
+		string(mcp.MethodNotificationCancelled),
+		string(mcp.MethodSamplingCreateMessage):
+		return true
+	}
+	return false
+}
+
+// writeLanes serializes the writes of a transport so that a message is never
+// interleaved with another. Writers waiting in the control lane are served
+// before those in the normal lane, so a control message waits for at most
+// the one write in flight. The zero value is ready to use.
+type writeLanes struct {
+	// disabled puts every message in the normal lane, so writes are served
+	// in arrival order.
+	disabled bool
+
+	mu      sync.Mutex
+	busy    bool
+	control []chan struct{}
+	normal  []chan struct{}
+}
+
+// acquire waits until the caller may write. Every successful call must be
+// followed by a call to release.
+func (l *writeLanes) acquire(ctx context.Context, control bool) error {
+	l.mu.Lock()
+	if !l.busy {
+		l.busy = true
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	queue := &l.normal
+	if control && !l.disabled {
+		queue = &l.control
+	}
+	*queue = append(*queue, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if i := slices.Index(*queue, ready); i >= 0 {
+			*queue = slices.Delete(*queue, i, i+1)
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		l.mu.Unlock()
+		// The turn was handed to us concurrently, pass it on
+		l.release()
+		return ctx.Err()
+	}
+}
+
+// release hands the turn to the next waiting writer, control lane first.
+func (l *writeLanes) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var next chan struct{}
+	switch {
+	case len(l.control) > 0:
+		next, l.control = l.control[0], l.control[1:]
+	case len(l.normal) > 0:
+		next, l.normal = l.normal[0], l.normal[1:]
+	default:
+		l.busy = false
+		return
+	}
+	close(next)
+}
+
+// waiting returns the number of writers queued in each lane.
+func (l *writeLanes) waiting() (control, normal int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.control), len(l.normal)
+}
+
+// laneBody is an HTTP request body that holds a turn of its lanes while it
+// is being uploaded. The turn is taken on the first read and released once
+// the body is exhausted or closed.
+type laneBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	lanes   *writeLanes
+	control bool
+
+	mu       sync.Mutex
+	holding  bool
+	finished bool
+}
+
+func (b *laneBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	if !b.holding && !b.finished {
+		if err := b.lanes.acquire(b.ctx, b.control); err != nil {
+			b.finished = true
+			b.mu.Unlock()
+			return 0, err
+		}
+		b.holding = true
+	}
+	b.mu.Unlock()
+
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *laneBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *laneBody) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.holding {
+		b.lanes.release()
+		b.holding = false
+	}
+	b.finished = true
+}
@@ -0,0 +1,282 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// laneLoad is the number of large requests that saturate a transport.
+const laneLoad = 16
+
+// wireLog records the order in which messages arrive on the wire.
+type wireLog struct {
+	mu      sync.Mutex
+	methods []string
+}
+
+func (w *wireLog) record(t *testing.T, message []byte) string {
+	var msg struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	// A message interleaved with another would not be valid JSON
+	if err := json.Unmarshal(message, &msg); err != nil {
+		t.Errorf("invalid message on the wire: %v", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.methods = append(w.methods, msg.Method)
+	return msg.Method
+}
+
+func (w *wireLog) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.methods)
+}
+
+// requestsBefore returns how many tools/call requests arrived between the
+// first from messages and the ping.
+func (w *wireLog) requestsBefore(t *testing.T, from int) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, method := range w.methods {
+		if method == string(mcp.MethodPing) {
+			require.GreaterOrEqual(t, i, from)
+			return i - from
+		}
+	}
+	t.Fatal("ping never reached the wire")
+	return 0
+}
+
+func largeToolCall(id int) JSONRPCRequest {
+	return JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(int64(id)),
+		Method:  string(mcp.MethodToolsCall),
+		Params: map[string]any{
+			"name":      "upload",
+			"arguments": map[string]any{"data": strings.Repeat("x", 256<<10)},
+		},
+	}
+}
+
+// saturate sends laneLoad large requests in the background and waits until
+// at least half of them are queued. The requests are abandoned when the test
+// ends.
+func saturate(t *testing.T, lanes *writeLanes, send func(context.Context, JSONRPCRequest)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	for i := range laneLoad {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(ctx, largeToolCall(i+1))
+		}()
+	}
+	require.Eventually(t, func() bool {
+		_, normal := lanes.waiting()
+		return normal >= laneLoad/2
+	}, 5*time.Second, time.Millisecond)
+}
+
+// startSlowStdioServer reads the client's messages slowly, recording their
+// order and answering pings.
+func startSlowStdioServer(t *testing.T, opts ...StdioOption) (*Stdio, *wireLog) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		_ = stdinReader.Close()
+		_ = stdoutWriter.Close()
+	})
+
+	stdio := NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader("")))
+	for _, opt := range opts {
+		opt(stdio)
+	}
+	require.NoError(t, stdio.Start(context.Background()))
+
+	wire := &wireLog{}
+	go func() {
+		reader := bufio.NewReader(stdinReader)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return
+			}
+			if wire.record(t, line) != string(mcp.MethodPing) {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			var ping JSONRPCRequest
+			_ = json.Unmarshal(line, &ping)
+			response, _ := json.Marshal(NewPingResponse(ping.ID))
+			_, _ = stdoutWriter.Write(append(response, '\n'))
+		}
+	}()
+	return stdio, wire
+}
+
+func TestStdio_PriorityLanes(t *testing.T) {
+	stdio, wire := startSlowStdioServer(t)
+	saturate(t, &stdio.lanes, func(ctx context.Context, request JSONRPCRequest) {
+		_, _ = stdio.SendRequest(ctx, request)
+	})
+
+	before := wire.len()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := stdio.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId("ping"),
+		Method:  string(mcp.MethodPing),
+	})
+	require.NoError(t, err)
+
+	// At most the request being written when the ping was sent, and the one
+	// that started while the ping was being queued
+	require.LessOrEqual(t, wire.requestsBefore(t, before), 2)
+}
+
+func TestStdio_WithoutPriorityLanes(t *testing.T) {
+	stdio, wire := startSlowStdioServer(t, WithoutPriorityLanes())
+	saturate(t, &stdio.lanes, func(ctx context.Context, request JSONRPCRequest) {
+		_, _ = stdio.SendRequest(ctx, request)
+	})
+
+	before := wire.len()
+	_, queued := stdio.lanes.waiting()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := stdio.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId("ping"),
+		Method:  string(mcp.MethodPing),
+	})
+	require.NoError(t, err)
+
+	// The ping is written after every queued request
+	require.GreaterOrEqual(t, wire.requestsBefore(t, before), queued)
+}
+
+// slowRoundTripper uploads request bodies slowly, recording the order in
+// which they complete, and answers every request with an empty result.
+type slowRoundTripper struct {
+	t    *testing.T
+	wire wireLog
+}
+
+func (rt *slowRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer req.Body.Close()
+	var body bytes.Buffer
+	chunk := make([]byte, 64<<10)
+	for {
+		n, err := req.Body.Read(chunk)
+		body.Write(chunk[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var request JSONRPCRequest
+	_ = json.Unmarshal(body.Bytes(), &request)
+	rt.wire.record(rt.t, body.Bytes())
+	response, _ := json.Marshal(JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  json.RawMessage(`{}`),
+	})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(response)),
+		Request:    req,
+	}, nil
+}
+
+func TestStreamableHTTP_PriorityLanes(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []StreamableHTTPCOption
+		assert func(t *testing.T, requests, queued int)
+	}{
+		{
+			name: "enabled",
+			assert: func(t *testing.T, requests, queued int) {
+				require.LessOrEqual(t, requests, 2)
+			},
+		},
+		{
+			name: "disabled",
+			opts: []StreamableHTTPCOption{WithoutHTTPPriorityLanes()},
+			assert: func(t *testing.T, requests, queued int) {
+				require.GreaterOrEqual(t, requests, queued)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &slowRoundTripper{t: t}
+			opts := append([]StreamableHTTPCOption{WithHTTPBasicClient(&http.Client{Transport: rt})}, tt.opts...)
+			trans, err := NewStreamableHTTP("http://mcp.example/mcp", opts...)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = trans.Close() })
+
+			saturate(t, &trans.lanes, func(ctx context.Context, request JSONRPCRequest) {
+				_, _ = trans.SendRequest(ctx, request)
+			})
+
+			before := rt.wire.len()
+			_, queued := trans.lanes.waiting()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, err = trans.SendRequest(ctx, JSONRPCRequest{
+				JSONRPC: mcp.JSONRPC_VERSION,
+				ID:      mcp.NewRequestId("ping"),
+				Method:  string(mcp.MethodPing),
+			})
+			require.NoError(t, err)
+			tt.assert(t, rt.wire.requestsBefore(t, before), queued)
+		})
+	}
+}
+
+func TestWriteLanes_CancelWhileWaiting(t *testing.T) {
+	var lanes writeLanes
+	require.NoError(t, lanes.acquire(context.Background(), false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- lanes.acquire(ctx, true) }()
+	require.Eventually(t, func() bool {
+		control, _ := lanes.waiting()
+		return control == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+
+	// The cancelled writer gave up its place
+	lanes.release()
+	require.NoError(t, lanes.acquire(context.Background(), false))
+	lanes.release()
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +32,11 @@ type OAuthConfig struct {
 	AuthServerMetadataURL string
 	// PKCEEnabled enables PKCE for the OAuth flow (recommended for public clients)
 	PKCEEnabled bool
+	// Resource is the canonical URI of the MCP server, sent as the resource
+	// parameter (RFC 8707) in authorization and token requests so that
+	// issued tokens are bound to this server. If empty, the server URL the
+	// transport was created with is used.
+	Resource string
 }
 
 // TokenStore is an interface for storing and retrieving OAuth tokens
@@ -115,9 +121,12 @@ type OAuthHandler struct {
 	metadataFetchErr error
 	metadataOnce     sync.Once
 	baseURL          string
+	defaultResource  string // Server URL used when config.Resource is empty
 
-	mu            sync.RWMutex // Protects expectedState
-	expectedState string       // Expected state value for CSRF protection
+	mu                  sync.RWMutex // Protects expectedState, scopes and resourceMetadataURL
+	expectedState       string       // Expected state value for CSRF protection
+	scopes              []string     // Scopes requested by the authorization flow
+	resourceMetadataURL string       // Protected resource metadata URL from a WWW-Authenticate challenge
 }
 
 // NewOAuthHandler creates a new OAuth handler
@@ -129,6 +138,7 @@ func NewOAuthHandler(config OAuthConfig) *OAuthHandler {
 	return &OAuthHandler{
 		config:     config,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		scopes:     append([]string(nil), config.Scopes...),
 	}
 }
 
@@ -179,6 +189,9 @@ func (h *OAuthHandler) refreshToken(ctx context.Context, refreshToken string) (*
 	data.Set("grant_type", "refresh_token")
 	data.Set("refresh_token", refreshToken)
 	data.Set("client_id", h.config.ClientID)
+	if resource := h.GetResource(); resource != "" {
+		data.Set("resource", resource)
+	}
 	if h.config.ClientSecret != "" {
 		data.Set("client_secret", h.config.ClientSecret)
 	}
@@ -263,6 +276,41 @@ func (h *OAuthHandler) SetBaseURL(baseURL string) {
 	h.baseURL = baseURL
 }
 
+// setDefaultResource sets the resource indicator used when OAuthConfig.Resource
+// is empty. Transports call it with the server URL they were created with.
+func (h *OAuthHandler) setDefaultResource(serverURL *url.URL) {
+	resource := *serverURL
+	// The canonical URI of a resource must not contain a fragment
+	resource.Fragment = ""
+	resource.RawFragment = ""
+	h.defaultResource = resource.String()
+}
+
+// GetResource returns the resource indicator (RFC 8707) sent in authorization
+// and token requests: OAuthConfig.Resource if set, otherwise the server URL
+// the transport was created with.
+func (h *OAuthHandler) GetResource() string {
+	if h.config.Resource != "" {
+		return h.config.Resource
+	}
+	return h.defaultResource
+}
+
+// setResourceMetadataURL records the protected resource metadata URL
+// advertised in a WWW-Authenticate challenge. It is used for authorization
+// server discovery unless the metadata has already been fetched.
+func (h *OAuthHandler) setResourceMetadataURL(metadataURL string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resourceMetadataURL = metadataURL
+}
+
+func (h *OAuthHandler) getResourceMetadataURL() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.resourceMetadataURL
+}
+
 // GetExpectedState returns the expected state value (for testing purposes)
 func (h *OAuthHandler) GetExpectedState() string {
 	h.mu.RLock()
@@ -286,6 +334,28 @@ func (h *OAuthHandler) SetExpectedState(expectedState string) {
 	h.expectedState = expectedState
 }
 
+// GetScopes returns the scopes the authorization flow requests. They start out
+// as OAuthConfig.Scopes and grow with every call to RequestScopes.
+func (h *OAuthHandler) GetScopes() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]string(nil), h.scopes...)
+}
+
+// RequestScopes adds scopes to those requested by the next authorization
+// flow, keeping the scopes already granted. It is typically called with the
+// RequiredScopes of an OAuthAuthorizationRequiredError before the
+// application re-runs the flow to obtain a token with the additional scopes.
+func (h *OAuthHandler) RequestScopes(scopes ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, scope := range scopes {
+		if scope != "" && !slices.Contains(h.scopes, scope) {
+			h.scopes = append(h.scopes, scope)
+		}
+	}
+}
+
 // OAuthError represents a standard OAuth 2.0 error response
 type OAuthError struct {
 	ErrorCode        string `json:"error"`
@@ -325,8 +395,12 @@ func (h *OAuthHandler) getServerMetadata(ctx context.Context) (*AuthServerMetada
 			return
 		}
 
-		// Try to fetch the OAuth Protected Resource metadata
-		protectedResourceURL := baseURL + "/.well-known/oauth-protected-resource"
+		// Try to fetch the OAuth Protected Resource metadata, preferring the
+		// location the server advertised in its WWW-Authenticate challenge
+		protectedResourceURL := h.getResourceMetadataURL()
+		if protectedResourceURL == "" {
+			protectedResourceURL = baseURL + "/.well-known/oauth-protected-resource"
+		}
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, protectedResourceURL, nil)
 		if err != nil {
 			h.metadataFetchErr = fmt.Errorf("failed to create protected resource request: %w", err)
@@ -506,7 +580,7 @@ func (h *OAuthHandler) RegisterClient(ctx context.Context, clientName string) er
 		"token_endpoint_auth_method": "none", // For public clients
 		"grant_types":                []string{"authorization_code", "refresh_token"},
 		"response_types":             []string{"code"},
-		"scope":                      strings.Join(h.config.Scopes, " "),
+		"scope":                      strings.Join(h.GetScopes(), " "),
 	}
 
 	// Add client_secret if this is a confidential client
@@ -602,6 +676,10 @@ func (h *OAuthHandler) ProcessAuthorizationResponse(ctx context.Context, code, s
 		data.Set("code_verifier", codeVerifier)
 	}
 
+	if resource := h.GetResource(); resource != "" {
+		data.Set("resource", resource)
+	}
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
@@ -660,8 +738,8 @@ func (h *OAuthHandler) GetAuthorizationURL(ctx context.Context, state, codeChall
 	params.Set("redirect_uri", h.config.RedirectURI)
 	params.Set("state", state)
 
-	if len(h.config.Scopes) > 0 {
-		params.Set("scope", strings.Join(h.config.Scopes, " "))
+	if scopes := h.GetScopes(); len(scopes) > 0 {
+		params.Set("scope", strings.Join(scopes, " "))
 	}
 
 	if h.config.PKCEEnabled && codeChallenge != "" {
@@ -669,5 +747,115 @@ func (h *OAuthHandler) GetAuthorizationURL(ctx context.Context, state, codeChall
 		params.Set("code_challenge_method", "S256")
 	}
 
+	if resource := h.GetResource(); resource != "" {
+		params.Set("resource", resource)
+	}
+
 	return metadata.AuthorizationEndpoint + "?" + params.Encode(), nil
 }
+
+// authorizationHeader returns the Authorization header for a request, or an
+// empty string if no token is available yet. Such requests are sent without
+// credentials, so the server's 401 challenge, including its resource_metadata,
+// is seen by oauthErrorFromResponse before authorization is requested.
+func authorizationHeader(ctx context.Context, handler *OAuthHandler) (string, error) {
+	authHeader, err := handler.GetAuthorizationHeader(ctx)
+	if errors.Is(err, ErrOAuthAuthorizationRequired) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get authorization header: %w", err)
+	}
+	return authHeader, nil
+}
+
+// oauthErrorFromResponse returns an OAuthAuthorizationRequiredError if resp
+// asks the client to (re-)authorize: a 401, or a 403 whose Bearer challenge
+// reports an insufficient_scope error (RFC 6750 section 3.1). The scopes named
+// in the challenge are carried in the error, and its resource_metadata URL
+// (RFC 9728 section 5.1) is used for authorization server discovery. It
+// returns nil otherwise, or if OAuth is not configured.
+func oauthErrorFromResponse(resp *http.Response, handler *OAuthHandler) error {
+	if handler == nil {
+		return nil
+	}
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+
+	params := parseBearerChallenge(resp.Header.Values("WWW-Authenticate"))
+	insufficientScope := params["error"] == "insufficient_scope"
+	if resp.StatusCode == http.StatusForbidden && !insufficientScope {
+		return nil
+	}
+	if metadataURL := params["resource_metadata"]; metadataURL != "" {
+		handler.setResourceMetadataURL(metadataURL)
+	}
+	return &OAuthAuthorizationRequiredError{
+		Handler:             handler,
+		InsufficientScope:   insufficientScope,
+		RequiredScopes:      strings.Fields(params["scope"]),
+		ResourceMetadataURL: params["resource_metadata"],
+	}
+}
+
+// parseBearerChallenge returns the auth-params of the first Bearer challenge
+// in the given WWW-Authenticate header values, with lower-cased names.
+func parseBearerChallenge(headers []string) map[string]string {
+	for _, header := range headers {
+		rest := strings.TrimSpace(header)
+		for rest != "" {
+			scheme, params, next := parseChallenge(rest)
+			if strings.EqualFold(scheme, "Bearer") {
+				return params
+			}
+			rest = next
+		}
+	}
+	return nil
+}
+
+// parseChallenge parses one challenge at the start of s, returning its scheme,
+// its auth-params and the remainder of s.
+func parseChallenge(s string) (string, map[string]string, string) {
+	end := strings.IndexAny(s, " ,")
+	if end < 0 {
+		return s, nil, ""
+	}
+	scheme := s[:end]
+	s = strings.TrimLeft(s[end:], " ")
+
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || strings.ContainsAny(s[:eq], " ,") {
+			// Either the end of the header or the start of the next challenge
+			return scheme, params, s
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[name] = value
+	}
+}
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"errors"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -393,3 +394,70 @@ func TestOAuthHandler_SetExpectedState_CrossRequestScenario(t *testing.T) {
 		t.Errorf("Expected ErrInvalidState with wrong state, got %v", err)
 	}
 }
+
+func TestParseBearerChallenge(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    map[string]string
+	}{
+		{
+			name:    "quoted params",
+			headers: []string{`Bearer realm="mcp", error="insufficient_scope", scope="files:read files:write"`},
+			want:    map[string]string{"realm": "mcp", "error": "insufficient_scope", "scope": "files:read files:write"},
+		},
+		{
+			name:    "bearer after another challenge",
+			headers: []string{`Basic realm="legacy, api", Bearer error=invalid_token`},
+			want:    map[string]string{"error": "invalid_token"},
+		},
+		{
+			name:    "bearer in a later header",
+			headers: []string{`Basic realm="legacy"`, `bearer Scope="a\"b"`},
+			want:    map[string]string{"scope": `a"b`},
+		},
+		{
+			name:    "no bearer challenge",
+			headers: []string{`Basic realm="legacy"`},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseBearerChallenge(tt.headers)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("expected %s=%q, got %q", k, v, got[k])
+				}
+			}
+		})
+	}
+}
+
+func TestOAuthHandler_RequestScopes(t *testing.T) {
+	handler := NewOAuthHandler(OAuthConfig{
+		ClientID:    "test-client",
+		RedirectURI: "http://localhost:8085/callback",
+		Scopes:      []string{"read"},
+	})
+	handler.serverMetadata = &AuthServerMetadata{AuthorizationEndpoint: "https://auth.example.com/authorize"}
+	handler.metadataOnce.Do(func() {})
+
+	handler.RequestScopes("read", "write", "")
+
+	authURL, err := handler.GetAuthorizationURL(context.Background(), "state", "")
+	if err != nil {
+		t.Fatalf("GetAuthorizationURL failed: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("failed to parse authorization URL: %v", err)
+	}
+	if got := parsed.Query().Get("scope"); got != "read write" {
+		t.Errorf("expected scope %q, got %q", "read write", got)
+	}
+}
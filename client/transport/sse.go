@@ -26,8 +26,9 @@ import (
 type SSE struct {
 	baseURL        *url.URL
 	endpoint       *url.URL
+	endpointMu     sync.RWMutex
 	httpClient     *http.Client
-	responses      map[string]chan *JSONRPCResponse
+	responses      map[string]*sseRequest
 	mu             sync.RWMutex
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
@@ -38,10 +39,14 @@ type SSE struct {
 
 	started           atomic.Bool
 	closed            atomic.Bool
+	streamConnected   atomic.Bool
 	cancelSSEStream   context.CancelFunc
 	protocolVersion   atomic.Value // string
 	onConnectionLost  func(error)
 	connectionLostMu  sync.RWMutex
+	responseTimeout   time.Duration
+	sweepInterval     time.Duration
+	reconnectBackoff  BackoffFunc
 
 	// OAuth support
 	oauthHandler *OAuthHandler
@@ -49,6 +54,67 @@ type SSE struct {
 
 type ClientOption func(*SSE)
 
+// BackoffFunc returns how long to wait before the given reconnection
+// attempt. Attempts are numbered from zero and start over once a connection
+// is re-established.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a BackoffFunc that waits initial before the
+// first attempt and doubles the delay on each further attempt, up to max.
+func ExponentialBackoff(initial, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		d := initial
+		for range attempt {
+			if d >= max/2 {
+				return max
+			}
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// sseRequest is a request waiting for its response to arrive on the stream.
+type sseRequest struct {
+	ch  chan *JSONRPCResponse
+	ctx context.Context
+}
+
+// pendingSweepInterval is how often the SSE transport removes requests
+// abandoned by their callers.
+const pendingSweepInterval = 30 * time.Second
+
+// WithResponseTimeout bounds how long SendRequest waits for the response
+// event once the server has accepted a request, independently of the
+// caller's context. Some servers acknowledge a request but never deliver its
+// response; after the timeout the request is forgotten and SendRequest
+// returns a *ResponseTimeoutError. Zero, the default, waits until the
+// caller's context is done.
+func WithResponseTimeout(d time.Duration) ClientOption {
+	return func(sc *SSE) {
+		sc.responseTimeout = d
+	}
+}
+
+// WithSSEAutoReconnect makes the transport re-establish the SSE stream
+// when it drops, waiting backoff(attempt) before each attempt. After each
+// reconnection the transport waits for a new endpoint event and sends later
+// messages there. The connection-lost handler is invoked on every failure,
+// both when the stream drops and when a reconnection attempt fails, so
+// clients can re-initialize the new session. Reconnection stops when the
+// transport is closed or the context passed to Start is done. Requests
+// whose responses were lost with the stream are not retried.
+//
+// A nil backoff retries every second.
+func WithSSEAutoReconnect(backoff BackoffFunc) ClientOption {
+	return func(sc *SSE) {
+		if backoff == nil {
+			backoff = func(int) time.Duration { return retryInterval }
+		}
+		sc.reconnectBackoff = backoff
+	}
+}
+
 // WithSSELogger sets a custom logger for the SSE client.
 func WithSSELogger(logger util.Logger) ClientOption {
 	return func(sc *SSE) {
@@ -91,7 +157,7 @@ func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
 	smc := &SSE{
 		baseURL:      parsedURL,
 		httpClient:   &http.Client{},
-		responses:    make(map[string]chan *JSONRPCResponse),
+		responses:    make(map[string]*sseRequest),
 		endpointChan: make(chan struct{}),
 		headers:      make(map[string]string),
 		logger:       util.DefaultLogger(),
@@ -106,6 +172,7 @@ func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
 		// Extract base URL from server URL for metadata discovery
 		baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
 		smc.oauthHandler.SetBaseURL(baseURL)
+		smc.oauthHandler.setDefaultResource(parsedURL)
 	}
 
 	return smc, nil
@@ -121,9 +188,41 @@ func (c *SSE) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	c.cancelSSEStream = cancel
 
+	body, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.streamConnected.Store(true)
+	readDone := make(chan error, 1)
+	go func() { readDone <- c.readSSE(body) }()
+
+	// Wait for the endpoint to be received
+	timeout := time.NewTimer(30 * time.Second)
+	defer timeout.Stop()
+	select {
+	case <-c.endpointReady():
+		// Endpoint received, proceed
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled while waiting for endpoint")
+	case <-timeout.C: // Add a timeout
+		cancel()
+		return fmt.Errorf("timeout waiting for endpoint")
+	}
+
+	if c.reconnectBackoff != nil {
+		go c.reconnectLoop(ctx, readDone)
+	}
+	c.started.Store(true)
+	go c.sweepPending(ctx)
+	return nil
+}
+
+// connect opens the SSE stream and returns its body.
+func (c *SSE) connect(ctx context.Context) (io.ReadCloser, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL.String(), nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
@@ -142,58 +241,163 @@ func (c *SSE) Start(ctx context.Context) error {
 
 	// Add OAuth authorization if configured
 	if c.oauthHandler != nil {
-		authHeader, err := c.oauthHandler.GetAuthorizationHeader(ctx)
+		authHeader, err := authorizationHeader(ctx, c.oauthHandler)
 		if err != nil {
-			// If we get an authorization error, return a specific error that can be handled by the client
-			if err.Error() == "no valid token available, authorization required" {
-				return &OAuthAuthorizationRequiredError{
-					Handler: c.oauthHandler,
-				}
-			}
-			return fmt.Errorf("failed to get authorization header: %w", err)
+			return nil, err
+		}
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
 		}
-		req.Header.Set("Authorization", authHeader)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSE stream: %w", err)
+		return nil, fmt.Errorf("failed to connect to SSE stream: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
 		// Handle OAuth unauthorized error
-		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return &OAuthAuthorizationRequiredError{
-				Handler: c.oauthHandler,
-			}
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	go c.readSSE(resp.Body)
+	return resp.Body, nil
+}
+
+// endpointReady returns a channel that is closed once the current stream
+// has announced its endpoint.
+func (c *SSE) endpointReady() <-chan struct{} {
+	c.endpointMu.RLock()
+	defer c.endpointMu.RUnlock()
+	return c.endpointChan
+}
+
+// getEndpoint returns the endpoint announced by the current stream.
+func (c *SSE) getEndpoint() *url.URL {
+	c.endpointMu.RLock()
+	defer c.endpointMu.RUnlock()
+	return c.endpoint
+}
+
+// reconnectLoop re-establishes the SSE stream each time it ends, until ctx
+// is done or the transport is closed. readDone receives the error that ended
+// the current stream.
+func (c *SSE) reconnectLoop(ctx context.Context, readDone chan error) {
+	err := <-readDone
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil || c.closed.Load() {
+			return
+		}
+		if err == nil || errors.Is(err, io.EOF) {
+			err = fmt.Errorf("SSE stream closed by server: %w", io.ErrUnexpectedEOF)
+		}
+		c.logger.Errorf("SSE stream lost, reconnecting: %v", err)
+		c.connectionLost(err)
+
+		select {
+		case <-time.After(c.reconnectBackoff(attempt)):
+		case <-ctx.Done():
+			return
+		}
+
+		if err = c.reconnect(ctx, readDone); err == nil {
+			attempt = -1
+			err = <-readDone
+		}
+	}
+}
+
+// reconnect opens a new SSE stream and waits for its endpoint event. On
+// success the stream is being read and its end is reported on readDone.
+func (c *SSE) reconnect(ctx context.Context, readDone chan error) error {
+	connCtx, cancel := context.WithCancel(ctx)
+	body, err := c.connect(connCtx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	c.endpointMu.Lock()
+	c.endpointChan = make(chan struct{})
+	ready := c.endpointChan
+	c.endpointMu.Unlock()
+
+	c.streamConnected.Store(true)
+	go func() {
+		err := c.readSSE(body)
+		cancel()
+		readDone <- err
+	}()
 
-	// Wait for the endpoint to be received
 	timeout := time.NewTimer(30 * time.Second)
 	defer timeout.Stop()
 	select {
-	case <-c.endpointChan:
-		// Endpoint received, proceed
-	case <-ctx.Done():
-		return fmt.Errorf("context cancelled while waiting for endpoint")
-	case <-timeout.C: // Add a timeout
+	case <-ready:
+		return nil
+	case <-timeout.C:
 		cancel()
+		<-readDone
 		return fmt.Errorf("timeout waiting for endpoint")
+	case err := <-readDone:
+		if err == nil || errors.Is(err, io.EOF) {
+			err = fmt.Errorf("SSE stream closed before endpoint was received: %w", io.ErrUnexpectedEOF)
+		}
+		return err
 	}
+}
 
-	c.started.Store(true)
-	return nil
+// connectionLost reports err to the connection-lost handler, if any.
+func (c *SSE) connectionLost(err error) {
+	c.connectionLostMu.RLock()
+	handler := c.onConnectionLost
+	c.connectionLostMu.RUnlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// sweepPending periodically removes the pending requests whose callers have
+// given up, until ctx is done. SendRequest removes its own request when it
+// returns; the sweep guarantees the map cannot grow without bound regardless.
+func (c *SSE) sweepPending(ctx context.Context) {
+	interval := c.sweepInterval
+	if interval <= 0 {
+		interval = pendingSweepInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			for idKey, pending := range c.responses {
+				if pending.ctx.Err() != nil {
+					delete(c.responses, idKey)
+				}
+			}
+			c.mu.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// PendingRequests returns the number of requests waiting for a response.
+func (c *SSE) PendingRequests() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.responses)
 }
 
 // readSSE continuously reads the SSE stream and processes events.
-// It runs until the connection is closed or an error occurs.
-func (c *SSE) readSSE(reader io.ReadCloser) {
+// It runs until the connection is closed or an error occurs, and returns
+// the error that ended the stream.
+func (c *SSE) readSSE(reader io.ReadCloser) error {
 	defer reader.Close()
+	defer c.streamConnected.Store(false)
 
 	br := bufio.NewReader(reader)
 	var event, data string
@@ -212,11 +416,12 @@ func (c *SSE) readSSE(reader io.ReadCloser) {
 					}
 					c.handleSSEEvent(event, data)
 				}
-				break
+				return err
 			}
 			// Checking whether the connection was terminated due to NO_ERROR in HTTP2 based on RFC9113
-			// Only handle NO_ERROR specially if onConnectionLost handler is set to maintain backward compatibility
-			if strings.Contains(err.Error(), "NO_ERROR") {
+			// Only handle NO_ERROR specially if onConnectionLost handler is set to maintain backward compatibility.
+			// With auto reconnect the reconnect loop reports every failure instead.
+			if c.reconnectBackoff == nil && strings.Contains(err.Error(), "NO_ERROR") {
 				c.connectionLostMu.RLock()
 				handler := c.onConnectionLost
 				c.connectionLostMu.RUnlock()
@@ -224,13 +429,13 @@ func (c *SSE) readSSE(reader io.ReadCloser) {
 				if handler != nil {
 					// This is not actually an error - HTTP2 idle timeout disconnection
 					handler(err)
-					return
+					return err
 				}
 			}
 			if !c.closed.Load() {
 				c.logger.Errorf("SSE stream error: %v", err)
 			}
-			return
+			return err
 		}
 
 		// Remove only newline markers
@@ -271,8 +476,15 @@ func (c *SSE) handleSSEEvent(event, data string) {
 			c.logger.Errorf("Endpoint origin does not match connection origin")
 			return
 		}
+		c.endpointMu.Lock()
 		c.endpoint = endpoint
-		close(c.endpointChan)
+		select {
+		case <-c.endpointChan:
+			// A repeated endpoint event on the same stream
+		default:
+			close(c.endpointChan)
+		}
+		c.endpointMu.Unlock()
 
 	case "message":
 		var baseMessage JSONRPCResponse
@@ -298,15 +510,13 @@ func (c *SSE) handleSSEEvent(event, data string) {
 		// Create string key for map lookup
 		idKey := baseMessage.ID.String()
 
-		c.mu.RLock()
-		ch, exists := c.responses[idKey]
-		c.mu.RUnlock()
+		c.mu.Lock()
+		pending, exists := c.responses[idKey]
+		delete(c.responses, idKey)
+		c.mu.Unlock()
 
 		if exists {
-			ch <- &baseMessage
-			c.mu.Lock()
-			delete(c.responses, idKey)
-			c.mu.Unlock()
+			pending.ch <- &baseMessage
 		}
 	}
 }
@@ -330,12 +540,13 @@ func (c *SSE) SendRequest(
 	request JSONRPCRequest,
 ) (*JSONRPCResponse, error) {
 	if !c.started.Load() {
-		return nil, fmt.Errorf("transport not started yet")
+		return nil, &stateError{kind: ErrNotStarted, msg: "transport not started yet"}
 	}
 	if c.closed.Load() {
-		return nil, fmt.Errorf("transport has been closed")
+		return nil, &stateError{kind: ErrClosed, msg: "transport has been closed"}
 	}
-	if c.endpoint == nil {
+	endpoint := c.getEndpoint()
+	if endpoint == nil {
 		return nil, fmt.Errorf("endpoint not received")
 	}
 
@@ -346,7 +557,7 @@ func (c *SSE) SendRequest(
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String(), bytes.NewReader(requestBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(requestBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -365,17 +576,13 @@ func (c *SSE) SendRequest(
 
 	// Add OAuth authorization if configured
 	if c.oauthHandler != nil {
-		authHeader, err := c.oauthHandler.GetAuthorizationHeader(ctx)
+		authHeader, err := authorizationHeader(ctx, c.oauthHandler)
 		if err != nil {
-			// If we get an authorization error, return a specific error that can be handled by the client
-			if err.Error() == "no valid token available, authorization required" {
-				return nil, &OAuthAuthorizationRequiredError{
-					Handler: c.oauthHandler,
-				}
-			}
-			return nil, fmt.Errorf("failed to get authorization header: %w", err)
+			return nil, err
+		}
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
 		}
-		req.Header.Set("Authorization", authHeader)
 	}
 
 	if c.headerFunc != nil {
@@ -390,7 +597,7 @@ func (c *SSE) SendRequest(
 	// Register response channel
 	responseChan := make(chan *JSONRPCResponse, 1)
 	c.mu.Lock()
-	c.responses[idKey] = responseChan
+	c.responses[idKey] = &sseRequest{ch: responseChan, ctx: ctx}
 	c.mu.Unlock()
 	deleteResponseChan := func() {
 		c.mu.Lock()
@@ -410,6 +617,7 @@ func (c *SSE) SendRequest(
 	resp.Body.Close()
 
 	if err != nil {
+		deleteResponseChan()
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
@@ -418,24 +626,32 @@ func (c *SSE) SendRequest(
 		deleteResponseChan()
 
 		// Handle OAuth unauthorized error
-		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return nil, &OAuthAuthorizationRequiredError{
-				Handler: c.oauthHandler,
-			}
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return nil, err
 		}
 
 		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, body)
 	}
 
+	var timeout <-chan time.Time
+	if c.responseTimeout > 0 {
+		timer := time.NewTimer(c.responseTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	select {
 	case <-ctx.Done():
 		deleteResponseChan()
 		return nil, ctx.Err()
+	case <-timeout:
+		deleteResponseChan()
+		return nil, &ResponseTimeoutError{Method: request.Method, ID: request.ID, Timeout: c.responseTimeout}
 	case response, ok := <-responseChan:
 		if ok {
 			return response, nil
 		}
-		return nil, fmt.Errorf("connection has been closed")
+		return nil, &stateError{kind: ErrClosed, msg: "connection has been closed"}
 	}
 }
 
@@ -454,15 +670,35 @@ func (c *SSE) Close() error {
 
 	// Clean up any pending responses
 	c.mu.Lock()
-	for _, ch := range c.responses {
-		close(ch)
+	for _, pending := range c.responses {
+		close(pending.ch)
 	}
-	c.responses = make(map[string]chan *JSONRPCResponse)
+	c.responses = make(map[string]*sseRequest)
 	c.mu.Unlock()
 
 	return nil
 }
 
+// HealthCheck reports whether the SSE stream is still connected.
+func (c *SSE) HealthCheck(ctx context.Context) error {
+	if !c.started.Load() {
+		return &stateError{kind: ErrNotStarted, msg: "transport not started yet"}
+	}
+	if c.closed.Load() {
+		return &stateError{kind: ErrClosed, msg: "transport has been closed"}
+	}
+	if !c.streamConnected.Load() {
+		return fmt.Errorf("SSE stream is not connected")
+	}
+	return nil
+}
+
+// IsConnected reports whether the transport has been started, has not been
+// closed and its SSE stream is open.
+func (c *SSE) IsConnected() bool {
+	return c.HealthCheck(context.Background()) == nil
+}
+
 // GetSessionId returns the session ID of the transport.
 // Since SSE does not maintain a session ID, it returns an empty string.
 func (c *SSE) GetSessionId() string {
@@ -476,7 +712,8 @@ func (c *SSE) SetProtocolVersion(version string) {
 
 // SendNotification sends a JSON-RPC notification to the server without expecting a response.
 func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
-	if c.endpoint == nil {
+	endpoint := c.getEndpoint()
+	if endpoint == nil {
 		return fmt.Errorf("endpoint not received")
 	}
 
@@ -488,7 +725,7 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		c.endpoint.String(),
+		endpoint.String(),
 		bytes.NewReader(notificationBytes),
 	)
 	if err != nil {
@@ -509,17 +746,13 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 	// Add OAuth authorization if configured
 	if c.oauthHandler != nil {
-		authHeader, err := c.oauthHandler.GetAuthorizationHeader(ctx)
+		authHeader, err := authorizationHeader(ctx, c.oauthHandler)
 		if err != nil {
-			// If we get an authorization error, return a specific error that can be handled by the client
-			if errors.Is(err, ErrOAuthAuthorizationRequired) {
-				return &OAuthAuthorizationRequiredError{
-					Handler: c.oauthHandler,
-				}
-			}
-			return fmt.Errorf("failed to get authorization header: %w", err)
+			return err
+		}
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
 		}
-		req.Header.Set("Authorization", authHeader)
 	}
 
 	if c.headerFunc != nil {
@@ -536,10 +769,8 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		// Handle OAuth unauthorized error
-		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return &OAuthAuthorizationRequiredError{
-				Handler: c.oauthHandler,
-			}
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return err
 		}
 
 		body, _ := io.ReadAll(resp.Body)
@@ -555,7 +786,7 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 // GetEndpoint returns the current endpoint URL for the SSE connection.
 func (c *SSE) GetEndpoint() *url.URL {
-	return c.endpoint
+	return c.getEndpoint()
 }
 
 // GetBaseURL returns the base URL set in the SSE constructor.
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -913,3 +914,241 @@ func TestSSEErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestSSE_HealthCheck(t *testing.T) {
+	drop := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /message\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-drop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer testServer.Close()
+
+	trans, err := NewSSE(testServer.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.ErrorIs(t, trans.HealthCheck(ctx), ErrNotStarted)
+
+	require.NoError(t, trans.Start(ctx))
+	require.NoError(t, trans.HealthCheck(ctx))
+
+	// The server ends the stream
+	close(drop)
+	require.Eventually(t, func() bool {
+		return trans.HealthCheck(ctx) != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, trans.Close())
+	require.ErrorIs(t, trans.HealthCheck(ctx), ErrClosed)
+}
+
+// startSwallowingSSEServer starts an SSE server that accepts every request
+// but never delivers the response to "debug/swallow" requests.
+func startSwallowingSSEServer(t *testing.T) string {
+	t.Helper()
+	events := make(chan []byte, 10)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", "/message")
+		flusher.Flush()
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/message", func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		if request["method"] == "debug/swallow" {
+			return
+		}
+		response, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  map[string]any{"ok": true},
+		})
+		events <- response
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL + "/sse"
+}
+
+func TestSSE_ResponseTimeout(t *testing.T) {
+	trans, err := NewSSE(startSwallowingSSEServer(t), WithResponseTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, trans.Start(context.Background()))
+	defer trans.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "debug/swallow",
+	})
+	require.ErrorIs(t, err, ErrResponseTimeout)
+	var timeoutErr *ResponseTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, "debug/swallow", timeoutErr.Method)
+	require.Equal(t, mcp.NewRequestId(int64(1)).String(), timeoutErr.ID.String())
+	require.Contains(t, err.Error(), "debug/swallow")
+	require.Equal(t, 0, trans.PendingRequests(), "the swallowed request must be forgotten")
+
+	// Later requests are unaffected
+	response, err := trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(2)),
+		Method:  "debug/echo",
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"ok":true}`, string(response.Result))
+	require.Equal(t, 0, trans.PendingRequests())
+}
+
+func TestSSE_SweepsAbandonedRequests(t *testing.T) {
+	trans, err := NewSSE(startSwallowingSSEServer(t))
+	require.NoError(t, err)
+	trans.sweepInterval = 10 * time.Millisecond
+	require.NoError(t, trans.Start(context.Background()))
+	defer trans.Close()
+
+	// Simulate requests whose callers went away without cleaning up
+	abandoned, cancel := context.WithCancel(context.Background())
+	cancel()
+	trans.mu.Lock()
+	trans.responses["orphan"] = &sseRequest{ch: make(chan *JSONRPCResponse, 1), ctx: abandoned}
+	trans.responses["waiting"] = &sseRequest{ch: make(chan *JSONRPCResponse, 1), ctx: context.Background()}
+	trans.mu.Unlock()
+	require.Equal(t, 2, trans.PendingRequests())
+
+	require.Eventually(t, func() bool {
+		return trans.PendingRequests() == 1
+	}, time.Second, 10*time.Millisecond)
+	trans.mu.RLock()
+	_, stillWaiting := trans.responses["waiting"]
+	trans.mu.RUnlock()
+	require.True(t, stillWaiting, "requests with live callers must be kept")
+}
+
+func TestSSE_AutoReconnect(t *testing.T) {
+	var connections atomic.Int32
+	var mu sync.Mutex
+	var sessions []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		n := connections.Add(1)
+		if n == 2 {
+			// The first reconnection attempt fails
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: endpoint\ndata: /message?session=%d\n\n", n)
+		w.(http.Flusher).Flush()
+		if n == 1 {
+			// Drop the first stream right after announcing the endpoint
+			return
+		}
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/message", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sessions = append(sessions, r.URL.Query().Get("session"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	trans, err := NewSSE(server.URL+"/sse", WithSSEAutoReconnect(func(int) time.Duration {
+		return 10 * time.Millisecond
+	}))
+	require.NoError(t, err)
+
+	lost := make(chan error, 10)
+	trans.SetConnectionLostHandler(func(err error) { lost <- err })
+
+	require.NoError(t, trans.Start(context.Background()))
+
+	// Once for the dropped stream and once for the failed attempt
+	for range 2 {
+		select {
+		case err := <-lost:
+			require.Error(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("connection-lost handler was not called")
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		endpoint := trans.GetEndpoint()
+		return trans.IsConnected() && endpoint != nil && endpoint.Query().Get("session") == "3"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, trans.SendNotification(ctx, mcp.JSONRPCNotification{
+		JSONRPC:      "2.0",
+		Notification: mcp.Notification{Method: "notifications/initialized"},
+	}))
+	mu.Lock()
+	require.Equal(t, []string{"3"}, sessions, "messages must go to the new endpoint")
+	mu.Unlock()
+
+	// Closing the transport stops reconnecting
+	require.NoError(t, trans.Close())
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(3), connections.Load())
+	require.Empty(t, lost)
+}
+
+func TestSSE_WithoutAutoReconnect(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /message\n\n")
+		w.(http.Flusher).Flush()
+	}))
+	defer server.Close()
+
+	trans, err := NewSSE(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, trans.Start(context.Background()))
+	defer trans.Close()
+
+	require.Eventually(t, func() bool {
+		return !trans.IsConnected()
+	}, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), connections.Load())
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	require.Equal(t, 100*time.Millisecond, backoff(0))
+	require.Equal(t, 200*time.Millisecond, backoff(1))
+	require.Equal(t, 800*time.Millisecond, backoff(3))
+	require.Equal(t, time.Second, backoff(4))
+	require.Equal(t, time.Second, backoff(100))
+}
@@ -10,6 +10,9 @@ import (
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/util"
@@ -39,8 +42,42 @@ type Stdio struct {
 	ctx            context.Context
 	ctxMu          sync.RWMutex
 	logger         util.Logger
+
+	// processGroup starts the subprocess in its own process group so Close
+	// can terminate the helpers it spawned as well.
+	processGroup    bool
+	shutdownTimeout time.Duration
+	waited          atomic.Bool
+	stdoutClosed    atomic.Bool
+
+	// started is set by Start and cleared by Close. Transports created
+	// with NewIO have their streams before they are started.
+	started atomic.Bool
+
+	// outputClosed is closed once the server's output has ended, failing
+	// pending requests with outputErr. onClose is told about it.
+	outputClosed chan struct{}
+	outputErr    error
+	onClose      func(error)
+
+	// sessionKey selects a logical session of a server that multiplexes
+	// several sessions over one pair of stdio streams.
+	sessionKey string
+
+	// cleanEnv starts the subprocess with only env and the variables of
+	// the parent environment named in keepEnv.
+	cleanEnv bool
+	keepEnv  []string
+
+	// lanes serializes writes to stdin, letting control messages overtake
+	// queued requests.
+	lanes writeLanes
 }
 
+// defaultGracefulShutdownTimeout is how long Close waits for the subprocess
+// to exit after closing its stdin before killing it.
+const defaultGracefulShutdownTimeout = 5 * time.Second
+
 // StdioOption defines a function that configures a Stdio transport instance.
 // Options can be used to customize the behavior of the transport before it starts,
 // such as setting a custom command function.
@@ -66,6 +103,95 @@ func WithCommandLogger(logger util.Logger) StdioOption {
 	}
 }
 
+// WithProcessGroup starts the subprocess in its own process group (a new
+// process group on Unix, CREATE_NEW_PROCESS_GROUP on Windows). Close then kills
+// the whole group, so helper processes spawned by the server are not left
+// orphaned. On Windows the tree can only be killed while the server itself is
+// still running, that is when the graceful shutdown timeout expires.
+func WithProcessGroup() StdioOption {
+	return func(s *Stdio) {
+		s.processGroup = true
+	}
+}
+
+// WithGracefulShutdownTimeout sets how long Close waits for the subprocess to
+// exit after closing its stdin before killing it. The default is 5 seconds.
+// A non-positive duration waits indefinitely.
+func WithGracefulShutdownTimeout(timeout time.Duration) StdioOption {
+	return func(s *Stdio) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// WithStdioSessionKey binds the transport to the logical session with the
+// given key on a server that multiplexes sessions over one pair of stdio
+// streams. Outgoing messages are tagged with the key in the top-level
+// mcp.StdioSessionField, and only incoming messages tagged with it are
+// processed. To share one pair of streams between several sessions, use
+// NewStdioMux.
+func WithStdioSessionKey(key string) StdioOption {
+	return func(s *Stdio) {
+		s.sessionKey = key
+	}
+}
+
+// WithCleanEnv starts the subprocess with exactly the env given to the
+// constructor instead of appending it to the parent's environment. The
+// parent's variables named in keep, such as "PATH", are passed through when
+// set; variables in env take precedence over them. It has no effect when a
+// CommandFunc builds the command.
+func WithCleanEnv(keep ...string) StdioOption {
+	return func(s *Stdio) {
+		s.cleanEnv = true
+		s.keepEnv = keep
+	}
+}
+
+// WithOnClose sets a function called when the server closes its output or
+// reading from it fails, typically because the server exited. It receives the
+// error pending and later requests fail with, which matches ErrConnectionClosed.
+// It is not called when the connection ends because of Close.
+func WithOnClose(handler func(err error)) StdioOption {
+	return func(s *Stdio) {
+		s.onClose = handler
+	}
+}
+
+// WithoutPriorityLanes disables the control lane of the stdio transport.
+// By default pings, cancellations and responses to sampling requests are
+// written before queued requests, so they wait for at most the one message
+// being written; with this option every message is written in the order it
+// was sent.
+func WithoutPriorityLanes() StdioOption {
+	return func(s *Stdio) {
+		s.lanes.disabled = true
+	}
+}
+
+// ProcessExitError reports that the subprocess exited unsuccessfully. It is
+// returned by Close and wraps the underlying *exec.ExitError.
+type ProcessExitError struct {
+	// Pid is the process ID of the subprocess.
+	Pid int
+	// ExitCode is the exit code of the subprocess, or -1 if it was
+	// terminated by a signal.
+	ExitCode int
+	// Signal is the signal that terminated the subprocess, if any.
+	Signal os.Signal
+	Err    error
+}
+
+func (e *ProcessExitError) Error() string {
+	if e.Signal != nil {
+		return fmt.Sprintf("stdio server (pid %d) was terminated by signal: %v", e.Pid, e.Signal)
+	}
+	return fmt.Sprintf("stdio server (pid %d) exited with code %d", e.Pid, e.ExitCode)
+}
+
+func (e *ProcessExitError) Unwrap() error {
+	return e.Err
+}
+
 // NewIO returns a new stdio-based transport using existing input, output, and
 // logging streams instead of spawning a subprocess.
 // This is useful for testing and simulating client behavior.
@@ -75,10 +201,11 @@ func NewIO(input io.Reader, output io.WriteCloser, logging io.ReadCloser) *Stdio
 		stdout: bufio.NewReader(input),
 		stderr: logging,
 
-		responses: make(map[string]chan *JSONRPCResponse),
-		done:      make(chan struct{}),
-		ctx:       context.Background(),
-		logger:    util.DefaultLogger(),
+		responses:    make(map[string]chan *JSONRPCResponse),
+		done:         make(chan struct{}),
+		outputClosed: make(chan struct{}),
+		ctx:          context.Background(),
+		logger:       util.DefaultLogger(),
 	}
 }
 
@@ -109,10 +236,13 @@ func NewStdioWithOptions(
 		args:    args,
 		env:     env,
 
-		responses: make(map[string]chan *JSONRPCResponse),
-		done:      make(chan struct{}),
-		ctx:       context.Background(),
-		logger:    util.DefaultLogger(),
+		responses:    make(map[string]chan *JSONRPCResponse),
+		done:         make(chan struct{}),
+		outputClosed: make(chan struct{}),
+		ctx:          context.Background(),
+		logger:       util.DefaultLogger(),
+
+		shutdownTimeout: defaultGracefulShutdownTimeout,
 	}
 
 	for _, opt := range opts {
@@ -131,6 +261,7 @@ func (c *Stdio) Start(ctx context.Context) error {
 	if err := c.spawnCommand(ctx); err != nil {
 		return err
 	}
+	c.started.Store(true)
 
 	ready := make(chan struct{})
 	go func() {
@@ -157,11 +288,15 @@ func (c *Stdio) spawnCommand(ctx context.Context) error {
 	// Standard behavior if no command func present.
 	if c.cmdFunc == nil {
 		cmd = exec.CommandContext(ctx, c.command, c.args...)
-		cmd.Env = append(os.Environ(), c.env...)
+		cmd.Env = c.environ()
 	} else if cmd, err = c.cmdFunc(ctx, c.command, c.env, c.args); err != nil {
 		return err
 	}
 
+	if c.processGroup {
+		setProcessGroup(cmd)
+	}
+
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdin pipe: %w", err)
@@ -189,8 +324,61 @@ func (c *Stdio) spawnCommand(ctx context.Context) error {
 	return nil
 }
 
+// environ returns the environment of a subprocess built without a
+// CommandFunc.
+func (c *Stdio) environ() []string {
+	if !c.cleanEnv {
+		return append(os.Environ(), c.env...)
+	}
+	// A nil Env would inherit the parent's environment
+	env := make([]string, 0, len(c.keepEnv)+len(c.env))
+	for _, name := range c.keepEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return append(env, c.env...)
+}
+
+// ProcessInfo returns the process ID of the subprocess and whether it is
+// still running. It returns 0 and false if no subprocess has been started.
+func (c *Stdio) ProcessInfo() (pid int, running bool) {
+	if c.cmd == nil || c.cmd.Process == nil {
+		return 0, false
+	}
+	return c.cmd.Process.Pid, !c.waited.Load() && processAlive(c.cmd.Process)
+}
+
+// HealthCheck reports whether the subprocess is still running and its output
+// can still be read. Transports created with NewIO only check the streams.
+func (c *Stdio) HealthCheck(ctx context.Context) error {
+	select {
+	case <-c.done:
+		return &stateError{kind: ErrClosed, msg: "stdio client closed"}
+	default:
+	}
+	if !c.started.Load() {
+		return &stateError{kind: ErrNotStarted, msg: "stdio client not started"}
+	}
+	if pid, running := c.ProcessInfo(); c.cmd != nil && !running {
+		return fmt.Errorf("stdio server (pid %d) is not running", pid)
+	}
+	if c.stdoutClosed.Load() {
+		return fmt.Errorf("stdio server closed its output")
+	}
+	return nil
+}
+
+// IsConnected reports whether the transport has been started and not closed,
+// the subprocess is still running and its output can still be read.
+func (c *Stdio) IsConnected() bool {
+	return c.HealthCheck(context.Background()) == nil
+}
+
 // Close shuts down the stdio client, closing the stdin pipe and waiting for the subprocess to exit.
-// Returns an error if there are issues closing stdin or waiting for the subprocess to terminate.
+// If the subprocess does not exit within the graceful shutdown timeout it is killed, together
+// with its process group if WithProcessGroup is set. An unsuccessful exit is reported as a
+// *ProcessExitError.
 func (c *Stdio) Close() error {
 	select {
 	case <-c.done:
@@ -199,6 +387,7 @@ func (c *Stdio) Close() error {
 	}
 	// cancel all in-flight request
 	close(c.done)
+	c.started.Store(false)
 
 	if err := c.stdin.Close(); err != nil {
 		return fmt.Errorf("failed to close stdin: %w", err)
@@ -208,12 +397,65 @@ func (c *Stdio) Close() error {
 	}
 
 	if c.cmd != nil {
-		return c.cmd.Wait()
+		return c.waitForExit()
 	}
 
 	return nil
 }
 
+// waitForExit waits for the subprocess to exit, killing it once the graceful
+// shutdown timeout expires. With a process group, any remaining members of the
+// group are killed after the server itself has exited.
+func (c *Stdio) waitForExit() error {
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- c.cmd.Wait()
+	}()
+
+	var timeout <-chan time.Time
+	if c.shutdownTimeout > 0 {
+		timer := time.NewTimer(c.shutdownTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case err = <-waitErr:
+	case <-timeout:
+		c.logger.Infof("stdio server (pid %d) did not exit within %v, killing it", c.cmd.Process.Pid, c.shutdownTimeout)
+		if c.processGroup {
+			if killErr := killProcessGroup(c.cmd); killErr != nil {
+				c.logger.Errorf("Failed to kill process group: %v", killErr)
+				_ = c.cmd.Process.Kill()
+			}
+		} else {
+			_ = c.cmd.Process.Kill()
+		}
+		err = <-waitErr
+	}
+	c.waited.Store(true)
+
+	if c.processGroup {
+		// Kill helpers left behind by a server that exited on its own
+		reapProcessGroup(c.cmd)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		processErr := &ProcessExitError{
+			Pid:      c.cmd.Process.Pid,
+			ExitCode: exitErr.ExitCode(),
+			Err:      err,
+		}
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			processErr.Signal = status.Signal()
+		}
+		return processErr
+	}
+	return err
+}
+
 // GetSessionId returns the session ID of the transport.
 // Since stdio does not maintain a session ID, it returns an empty string.
 func (c *Stdio) GetSessionId() string {
@@ -240,11 +482,13 @@ func (c *Stdio) SetRequestHandler(handler RequestHandler) {
 
 // readResponses continuously reads and processes responses from the server's stdout.
 // It handles both responses to requests and notifications, routing them appropriately.
-// Runs until the done channel is closed or an error occurs reading from stdout.
+// Runs until the done channel is closed or an error occurs reading from stdout,
+// then fails the requests still waiting for a response.
 func (c *Stdio) readResponses() {
 	for {
 		select {
 		case <-c.done:
+			c.closeOutput(nil)
 			return
 		default:
 			line, err := c.stdout.ReadString('\n')
@@ -252,9 +496,17 @@ func (c *Stdio) readResponses() {
 				if err != io.EOF && !errors.Is(err, context.Canceled) {
 					c.logger.Errorf("Error reading from stdout: %v", err)
 				}
+				c.closeOutput(err)
 				return
 			}
 
+			// Skip messages of other multiplexed sessions
+			key, message, err := mcp.UntagStdioSession([]byte(line))
+			if err != nil || key != c.sessionKey {
+				continue
+			}
+			line = string(message)
+
 			// First try to parse as a generic message to check for ID field
 			var baseMessage struct {
 				JSONRPC string         `json:"jsonrpc"`
@@ -311,10 +563,39 @@ func (c *Stdio) readResponses() {
 	}
 }
 
+// closeOutput records why the server's output ended, releases the requests
+// waiting for a response and calls the WithOnClose handler, unless the
+// transport was closed by Close.
+func (c *Stdio) closeOutput(readErr error) {
+	closedByClient := false
+	select {
+	case <-c.done:
+		closedByClient = true
+	default:
+	}
+
+	switch {
+	case closedByClient:
+		c.outputErr = &stateError{kind: ErrClosed, msg: "stdio client closed"}
+	case readErr == nil || readErr == io.EOF:
+		c.outputErr = &stateError{kind: ErrConnectionClosed, msg: "connection closed: stdio server closed its output"}
+	default:
+		c.outputErr = fmt.Errorf("%w: %w", ErrConnectionClosed, readErr)
+	}
+	c.stdoutClosed.Store(true)
+	close(c.outputClosed)
+
+	if !closedByClient && c.onClose != nil {
+		c.onClose(c.outputErr)
+	}
+}
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response.
 // It creates a unique request ID, sends the request over stdin, and waits for
 // the corresponding response or context cancellation.
 // Returns the raw JSON response message or an error if the request fails.
+// Requests pending when the server's output ends fail immediately with an
+// error matching ErrConnectionClosed.
 func (c *Stdio) SendRequest(
 	ctx context.Context,
 	request JSONRPCRequest,
@@ -327,7 +608,7 @@ func (c *Stdio) SendRequest(
 	}
 
 	if c.stdin == nil {
-		return nil, fmt.Errorf("stdio client not started")
+		return nil, &stateError{kind: ErrNotStarted, msg: "stdio client not started"}
 	}
 
 	// Marshal request
@@ -352,7 +633,7 @@ func (c *Stdio) SendRequest(
 	}
 
 	// Send request
-	if _, err := c.stdin.Write(requestBytes); err != nil {
+	if err := c.writeMessage(ctx, requestBytes, isControlMethod(request.Method)); err != nil {
 		deleteResponseChan()
 		return nil, fmt.Errorf("failed to write request: %w", err)
 	}
@@ -363,6 +644,15 @@ func (c *Stdio) SendRequest(
 		return nil, ctx.Err()
 	case response := <-responseChan:
 		return response, nil
+	case <-c.outputClosed:
+		deleteResponseChan()
+		// The response may have been read just before the output ended
+		select {
+		case response := <-responseChan:
+			return response, nil
+		default:
+			return nil, c.outputErr
+		}
 	}
 }
 
@@ -372,7 +662,7 @@ func (c *Stdio) SendNotification(
 	notification mcp.JSONRPCNotification,
 ) error {
 	if c.stdin == nil {
-		return fmt.Errorf("stdio client not started")
+		return &stateError{kind: ErrNotStarted, msg: "stdio client not started"}
 	}
 
 	notificationBytes, err := json.Marshal(notification)
@@ -381,7 +671,7 @@ func (c *Stdio) SendNotification(
 	}
 	notificationBytes = append(notificationBytes, '\n')
 
-	if _, err := c.stdin.Write(notificationBytes); err != nil {
+	if err := c.writeMessage(ctx, notificationBytes, isControlMethod(notification.Method)); err != nil {
 		return fmt.Errorf("failed to write notification: %w", err)
 	}
 
@@ -395,6 +685,12 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 	handler := c.onRequest
 	c.requestMu.RUnlock()
 
+	control := isControlMethod(request.Method)
+	if handler == nil && request.Method == string(mcp.MethodPing) {
+		c.sendResponse(*NewPingResponse(request.ID), control)
+		return
+	}
+
 	if handler == nil {
 		// Send error response if no handler is configured
 		errorResponse := JSONRPCResponse{
@@ -409,7 +705,7 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 				Message: "No request handler configured",
 			},
 		}
-		c.sendResponse(errorResponse)
+		c.sendResponse(errorResponse, control)
 		return
 	}
 
@@ -434,7 +730,7 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 					Message: ctx.Err().Error(),
 				},
 			}
-			c.sendResponse(errorResponse)
+			c.sendResponse(errorResponse, control)
 			return
 		default:
 		}
@@ -453,18 +749,19 @@ func (c *Stdio) handleIncomingRequest(request JSONRPCRequest) {
 					Message: err.Error(),
 				},
 			}
-			c.sendResponse(errorResponse)
+			c.sendResponse(errorResponse, control)
 			return
 		}
 
 		if response != nil {
-			c.sendResponse(*response)
+			c.sendResponse(*response, control)
 		}
 	}()
 }
 
-// sendResponse sends a response back to the server.
-func (c *Stdio) sendResponse(response JSONRPCResponse) {
+// sendResponse sends a response back to the server, in the control lane if
+// control is set.
+func (c *Stdio) sendResponse(response JSONRPCResponse, control bool) {
 	responseBytes, err := json.Marshal(response)
 	if err != nil {
 		c.logger.Errorf("Error marshaling response: %v", err)
@@ -472,11 +769,22 @@ func (c *Stdio) sendResponse(response JSONRPCResponse) {
 	}
 	responseBytes = append(responseBytes, '\n')
 
-	if _, err := c.stdin.Write(responseBytes); err != nil {
+	if err := c.writeMessage(context.Background(), responseBytes, control); err != nil {
 		c.logger.Errorf("Error writing response: %v", err)
 	}
 }
 
+// writeMessage writes a newline-terminated message to stdin once it is its
+// turn in the given lane.
+func (c *Stdio) writeMessage(ctx context.Context, data []byte, control bool) error {
+	if err := c.lanes.acquire(ctx, control); err != nil {
+		return err
+	}
+	defer c.lanes.release()
+	_, err := c.stdin.Write(mcp.TagStdioSession(data, c.sessionKey))
+	return err
+}
+
 // Stderr returns a reader for the stderr output of the subprocess.
 // This can be used to capture error messages or logs from the subprocess.
 func (c *Stdio) Stderr() io.Reader {
@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// StdioMux shares one pair of stdio streams between several Stdio
+// transports, each bound to a different logical session of a server that
+// multiplexes sessions (see WithStdioSessionKey). Incoming messages are
+// routed to the transport of the session they are tagged with.
+type StdioMux struct {
+	input   *bufio.Reader
+	output  io.WriteCloser
+	logging io.ReadCloser
+	logger  util.Logger
+
+	writeMu   sync.Mutex
+	mu        sync.Mutex
+	sessions  map[string]*io.PipeWriter
+	readOnce  sync.Once
+	closeOnce sync.Once
+}
+
+// NewStdioMux creates a multiplexer over existing input, output and logging
+// streams, such as the pipes of a subprocess started by the caller.
+func NewStdioMux(input io.Reader, output io.WriteCloser, logging io.ReadCloser) *StdioMux {
+	return &StdioMux{
+		input:    bufio.NewReader(input),
+		output:   output,
+		logging:  logging,
+		logger:   util.DefaultLogger(),
+		sessions: make(map[string]*io.PipeWriter),
+	}
+}
+
+// Session returns a transport for the logical session with the given key.
+// The empty key selects the server's default session. Closing the transport
+// detaches it from the multiplexer but leaves the shared streams open.
+func (m *StdioMux) Session(key string, opts ...StdioOption) *Stdio {
+	reader, writer := io.Pipe()
+
+	m.mu.Lock()
+	if previous, ok := m.sessions[key]; ok {
+		_ = previous.Close()
+	}
+	m.sessions[key] = writer
+	m.mu.Unlock()
+
+	m.readOnce.Do(func() {
+		go m.route()
+	})
+
+	s := &Stdio{
+		stdin:  &stdioMuxWriter{mux: m, key: key, pipe: writer},
+		stdout: bufio.NewReader(reader),
+		stderr: io.NopCloser(m.logging),
+
+		responses:    make(map[string]chan *JSONRPCResponse),
+		done:         make(chan struct{}),
+		outputClosed: make(chan struct{}),
+		ctx:          context.Background(),
+		logger:       m.logger,
+		sessionKey:   key,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close closes the shared streams, ending all sessions.
+func (m *StdioMux) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		err = m.output.Close()
+		if closeErr := m.logging.Close(); err == nil {
+			err = closeErr
+		}
+		m.closeSessions()
+	})
+	return err
+}
+
+// route reads the shared input and hands every message to the session it is
+// tagged with, until the input is closed.
+func (m *StdioMux) route() {
+	defer m.closeSessions()
+	for {
+		line, err := m.input.ReadBytes('\n')
+		if len(line) > 0 {
+			key, _, untagErr := mcp.UntagStdioSession(line)
+			if untagErr == nil {
+				m.mu.Lock()
+				session := m.sessions[key]
+				m.mu.Unlock()
+				if session != nil {
+					// The session's transport untags the message itself
+					_, _ = session.Write(line)
+				}
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				m.logger.Errorf("Error reading from stdout: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (m *StdioMux) closeSessions() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, session := range m.sessions {
+		_ = session.Close()
+		delete(m.sessions, key)
+	}
+}
+
+func (m *StdioMux) write(p []byte) (int, error) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return m.output.Write(p)
+}
+
+// stdioMuxWriter is the stdin of a multiplexed session. Closing it detaches
+// the session instead of closing the shared output.
+type stdioMuxWriter struct {
+	mux  *StdioMux
+	key  string
+	pipe *io.PipeWriter
+}
+
+func (w *stdioMuxWriter) Write(p []byte) (int, error) {
+	return w.mux.write(p)
+}
+
+func (w *stdioMuxWriter) Close() error {
+	w.mux.mu.Lock()
+	if w.mux.sessions[w.key] == w.pipe {
+		delete(w.mux.sessions, w.key)
+	}
+	w.mux.mu.Unlock()
+	return w.pipe.Close()
+}
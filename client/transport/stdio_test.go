@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -650,6 +651,48 @@ func TestStdio_SpawnCommand(t *testing.T) {
 	require.Contains(t, stdio.cmd.Env, "TEST_ENVIRON_VAR=true")
 }
 
+func TestStdio_SpawnCommand_WithCleanEnv(t *testing.T) {
+	t.Setenv("TEST_ENVIRON_VAR", "true")
+	t.Setenv("TEST_KEPT_VAR", "parent")
+
+	tests := []struct {
+		name string
+		env  []string
+		keep []string
+		want []string
+	}{
+		{
+			name: "only explicit env",
+			env:  []string{"TOKEN=secret"},
+			want: []string{"TOKEN=secret"},
+		},
+		{
+			name: "kept parent variables",
+			env:  []string{"TOKEN=secret"},
+			keep: []string{"TEST_KEPT_VAR", "TEST_UNSET_VAR"},
+			want: []string{"TEST_KEPT_VAR=parent", "TOKEN=secret"},
+		},
+		{
+			name: "empty env",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdio := NewStdioWithOptions("echo", tt.env, []string{"hello"}, WithCleanEnv(tt.keep...))
+			require.NoError(t, stdio.spawnCommand(context.Background()))
+			t.Cleanup(func() {
+				_ = stdio.cmd.Process.Kill()
+			})
+
+			// A nil Env would inherit the parent's environment
+			require.NotNil(t, stdio.cmd.Env)
+			require.Equal(t, tt.want, stdio.cmd.Env)
+			require.NotContains(t, stdio.cmd.Env, "TEST_ENVIRON_VAR=true")
+		})
+	}
+}
+
 func TestStdio_SpawnCommand_UsesCommandFunc(t *testing.T) {
 	ctx := context.Background()
 	t.Setenv("TEST_ENVIRON_VAR", "true")
@@ -707,3 +750,127 @@ func TestStdio_NewStdioWithOptions_AppliesOptions(t *testing.T) {
 	require.NotNil(t, stdio)
 	require.True(t, configured, "option was not applied")
 }
+
+func TestStdio_AnswersPingWithoutHandler(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		_ = stdinWriter.Close()
+		_ = stdoutWriter.Close()
+	})
+
+	stdio := NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader("")))
+	require.NoError(t, stdio.Start(context.Background()))
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	go func() {
+		_, _ = stdoutWriter.Write([]byte(`{"jsonrpc":"2.0","id":"ping-1","method":"ping"}` + "\n"))
+	}()
+
+	line, err := bufio.NewReader(stdinReader).ReadString('\n')
+	require.NoError(t, err)
+
+	var response JSONRPCResponse
+	require.NoError(t, json.Unmarshal([]byte(line), &response))
+	require.Equal(t, mcp.NewRequestId("ping-1").String(), response.ID.String())
+	require.Nil(t, response.Error)
+	require.JSONEq(t, `{}`, string(response.Result))
+}
+
+func TestStdio_IsConnected(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		_ = stdinReader.Close()
+		_ = stdinWriter.Close()
+	})
+
+	stdio := NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader("")))
+	require.False(t, stdio.IsConnected(), "unstarted transport")
+	require.NoError(t, stdio.Start(context.Background()))
+	t.Cleanup(func() { _ = stdio.Close() })
+	require.True(t, stdio.IsConnected())
+
+	// The server closes its output
+	require.NoError(t, stdoutWriter.Close())
+	require.Eventually(t, func() bool { return !stdio.IsConnected() }, time.Second, 10*time.Millisecond)
+}
+
+func TestStdio_FailsPendingRequestsOnEOF(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		_ = stdinReader.Close()
+		_ = stdinWriter.Close()
+	})
+
+	closed := make(chan error, 1)
+	stdio := NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader("")))
+	WithOnClose(func(err error) { closed <- err })(stdio)
+	require.NoError(t, stdio.Start(context.Background()))
+	t.Cleanup(func() { _ = stdio.Close() })
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := stdio.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "tools/list",
+		})
+		result <- err
+	}()
+
+	// The server reads the request and exits without answering
+	_, err := bufio.NewReader(stdinReader).ReadString('\n')
+	require.NoError(t, err)
+	require.NoError(t, stdoutWriter.Close())
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrConnectionClosed)
+		require.Contains(t, err.Error(), "connection closed")
+	case <-time.After(time.Second):
+		t.Fatal("pending request was not failed when the server closed its output")
+	}
+	select {
+	case err := <-closed:
+		require.ErrorIs(t, err, ErrConnectionClosed)
+	case <-time.After(time.Second):
+		t.Fatal("close handler was not called")
+	}
+
+	// Later requests fail the same way instead of blocking
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _, _ = io.Copy(io.Discard, stdinReader) }()
+	_, err = stdio.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(int64(2)),
+		Method:  "tools/list",
+	})
+	require.ErrorIs(t, err, ErrConnectionClosed)
+}
+
+func TestStdio_CloseDoesNotCallCloseHandler(t *testing.T) {
+	_, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	called := make(chan error, 1)
+	stdio := NewIO(stdoutReader, stdinWriter, io.NopCloser(strings.NewReader("")))
+	WithOnClose(func(err error) { called <- err })(stdio)
+	require.NoError(t, stdio.Start(context.Background()))
+
+	require.NoError(t, stdio.Close())
+	require.NoError(t, stdoutWriter.Close())
+	select {
+	case <-stdio.outputClosed:
+	case <-time.After(time.Second):
+		t.Fatal("reader did not stop")
+	}
+
+	select {
+	case err := <-called:
+		t.Fatalf("close handler called after Close: %v", err)
+	default:
+	}
+}
@@ -0,0 +1,140 @@
+//go:build !windows
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startShellServer starts a stdio transport running script with sh. The
+// script receives a file path as $1 it can use to report a process ID.
+func startShellServer(t *testing.T, script string, opts ...StdioOption) (*Stdio, string) {
+	t.Helper()
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	stdio := NewStdioWithOptions("sh", nil, []string{"-c", script, "sh", pidFile}, opts...)
+	require.NoError(t, stdio.Start(context.Background()))
+	return stdio, pidFile
+}
+
+// readPidFile waits until the script has written a process ID to path.
+func readPidFile(t *testing.T, path string) int {
+	t.Helper()
+
+	var pid int
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return pid
+}
+
+// processGone reports whether pid no longer refers to a running process.
+// Zombies waiting to be reaped by init count as gone.
+func processGone(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return true
+	}
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	// The state follows the parenthesized command name
+	stat := string(data)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+func TestStdio_ProcessGroupKillsGrandchildren(t *testing.T) {
+	// The server spawns a helper that outlives it once stdin is closed
+	stdio, pidFile := startShellServer(t, `sleep 300 & echo $! > "$1"; cat > /dev/null`, WithProcessGroup())
+
+	pid, running := stdio.ProcessInfo()
+	require.NotZero(t, pid)
+	require.True(t, running)
+
+	grandchild := readPidFile(t, pidFile)
+	require.False(t, processGone(grandchild))
+
+	require.NoError(t, stdio.Close())
+
+	_, running = stdio.ProcessInfo()
+	require.False(t, running)
+	require.Eventually(t, func() bool { return processGone(grandchild) }, 5*time.Second, 10*time.Millisecond,
+		"grandchild %d should be killed with the process group", grandchild)
+}
+
+func TestStdio_GracefulShutdownTimeout(t *testing.T) {
+	// The server ignores both stdin EOF and SIGTERM
+	stdio, pidFile := startShellServer(t, `trap '' TERM; echo $$ > "$1"; cat > /dev/null; sleep 300`,
+		WithProcessGroup(),
+		WithGracefulShutdownTimeout(100*time.Millisecond),
+	)
+	readPidFile(t, pidFile)
+
+	start := time.Now()
+	err := stdio.Close()
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	var exitErr *ProcessExitError
+	require.True(t, errors.As(err, &exitErr), "expected *ProcessExitError, got %v", err)
+	require.Equal(t, syscall.SIGKILL, exitErr.Signal)
+	require.Equal(t, -1, exitErr.ExitCode)
+}
+
+func TestStdio_CloseReportsExitCode(t *testing.T) {
+	stdio, _ := startShellServer(t, `cat > /dev/null; exit 3`)
+
+	err := stdio.Close()
+	var exitErr *ProcessExitError
+	require.True(t, errors.As(err, &exitErr), "expected *ProcessExitError, got %v", err)
+	require.Equal(t, 3, exitErr.ExitCode)
+	require.Nil(t, exitErr.Signal)
+	require.Contains(t, err.Error(), "exited with code 3")
+}
+
+func TestStdio_ProcessInfoBeforeStart(t *testing.T) {
+	pid, running := NewStdio("sh", nil).ProcessInfo()
+	require.Zero(t, pid)
+	require.False(t, running)
+}
+
+func TestStdio_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+	require.ErrorIs(t, NewStdio("sh", nil).HealthCheck(ctx), ErrNotStarted)
+
+	t.Run("running server", func(t *testing.T) {
+		stdio, _ := startShellServer(t, `cat > /dev/null`)
+		require.NoError(t, stdio.HealthCheck(ctx))
+
+		require.NoError(t, stdio.Close())
+		require.ErrorIs(t, stdio.HealthCheck(ctx), ErrClosed)
+	})
+
+	t.Run("exited server", func(t *testing.T) {
+		stdio, _ := startShellServer(t, `exit 0`)
+		defer stdio.Close()
+
+		require.Eventually(t, func() bool {
+			return stdio.HealthCheck(ctx) != nil
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
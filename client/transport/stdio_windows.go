@@ -0,0 +1,38 @@
+//go:build windows
+
+package transport
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// killProcessGroup forcefully terminates the running command and all
+// processes it started.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// reapProcessGroup is a no-op on Windows: once the command has exited its
+// process ID may be reused, so the tree can no longer be identified safely.
+func reapProcessGroup(cmd *exec.Cmd) {}
+
+// processAlive reports whether the process still exists. Windows has no
+// equivalent of signal 0, so a started process is considered alive until it
+// has been waited for.
+func processAlive(process *os.Process) bool {
+	return true
+}
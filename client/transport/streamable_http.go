@@ -3,6 +3,7 @@ package transport
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -14,6 +15,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -35,6 +37,49 @@ func WithContinuousListening() StreamableHTTPCOption {
 	}
 }
 
+// ListenState describes the state of the standalone GET connection used for
+// continuous listening.
+type ListenState int
+
+const (
+	// ListenStateConnected means the listening stream is established.
+	ListenStateConnected ListenState = iota
+	// ListenStateDisconnected means an established listening stream was closed.
+	ListenStateDisconnected
+	// ListenStateReconnecting means the transport is waiting to retry the
+	// listening connection.
+	ListenStateReconnecting
+	// ListenStateFailed means listening stopped for good, for example because
+	// the server does not support it.
+	ListenStateFailed
+)
+
+// String returns a human-readable name for the state.
+func (s ListenState) String() string {
+	switch s {
+	case ListenStateConnected:
+		return "connected"
+	case ListenStateDisconnected:
+		return "disconnected"
+	case ListenStateReconnecting:
+		return "reconnecting"
+	case ListenStateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// WithListenStateHandler sets a handler called whenever the state of the
+// continuous listening connection changes. It only has an effect together
+// with WithContinuousListening. The handler is called from the listening
+// goroutine and should not block.
+func WithListenStateHandler(handler func(state ListenState)) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.listenStateHandler = handler
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client on the StreamableHTTP transport.
 func WithHTTPBasicClient(client *http.Client) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
@@ -54,6 +99,103 @@ func WithHTTPHeaderFunc(headerFunc HTTPHeaderFunc) StreamableHTTPCOption {
 	}
 }
 
+// WithHTTPTransportTuning configures connection pooling on the underlying
+// *http.Transport. maxIdleConns bounds the number of idle (keep-alive)
+// connections kept to the server, maxConnsPerHost bounds the total number of
+// connections, and idleTimeout controls how long an idle connection is kept
+// before it is closed. Zero values leave the corresponding setting unchanged.
+//
+// It has no effect if a custom http.Client with a RoundTripper other than
+// *http.Transport is supplied via WithHTTPBasicClient.
+func WithHTTPTransportTuning(maxIdleConns, maxConnsPerHost int, idleTimeout time.Duration) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.transportTuning = append(sc.transportTuning, func(t *http.Transport) {
+			if maxIdleConns > 0 {
+				t.MaxIdleConns = maxIdleConns
+				t.MaxIdleConnsPerHost = maxIdleConns
+			}
+			if maxConnsPerHost > 0 {
+				t.MaxConnsPerHost = maxConnsPerHost
+				if t.MaxIdleConnsPerHost > maxConnsPerHost {
+					t.MaxIdleConnsPerHost = maxConnsPerHost
+				}
+			}
+			if idleTimeout > 0 {
+				t.IdleConnTimeout = idleTimeout
+			}
+		})
+	}
+}
+
+// WithForceHTTP2 controls whether the underlying *http.Transport attempts
+// HTTP/2 even when custom dial or TLS settings are configured.
+//
+// It has no effect if a custom http.Client with a RoundTripper other than
+// *http.Transport is supplied via WithHTTPBasicClient.
+func WithForceHTTP2(force bool) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.transportTuning = append(sc.transportTuning, func(t *http.Transport) {
+			t.ForceAttemptHTTP2 = force
+		})
+	}
+}
+
+// WithHealthCheckMaxAge makes HealthCheck report the transport as healthy
+// without contacting the server if a request succeeded within maxAge. The
+// default of 0 always probes the server.
+func WithHealthCheckMaxAge(maxAge time.Duration) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.healthCheckMaxAge = maxAge
+	}
+}
+
+// defaultCompressionThreshold is the request size from which
+// WithRequestCompression gzips request bodies.
+const defaultCompressionThreshold = 64 << 10
+
+// WithRequestCompression gzips request bodies of 64 KiB or more and sends them
+// with "Content-Encoding: gzip". Compressed bodies are streamed to the server
+// instead of being buffered a second time. The server must support it, e.g.
+// via server.WithCompression. Compressed responses are decoded transparently
+// by the default http.Transport.
+func WithRequestCompression() StreamableHTTPCOption {
+	return WithRequestCompressionThreshold(defaultCompressionThreshold)
+}
+
+// WithRequestCompressionThreshold is like WithRequestCompression, but gzips
+// request bodies of at least threshold bytes. A non-positive threshold
+// disables compression.
+func WithRequestCompressionThreshold(threshold int) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.compressionThreshold = threshold
+	}
+}
+
+// WithJSONResponseOnly makes the transport ask for plain JSON responses by
+// sending "Accept: application/json" with requests, for networks where
+// proxies break streamed responses. A response that still arrives as an SSE
+// stream fails with ErrEventStreamNotAccepted. Notifications the server
+// inlines in a JSON batch ahead of the response are passed to the
+// notification handler. The server must support it, e.g. via
+// server.WithJSONResponseNegotiation. Server-to-client requests such as
+// sampling can't be delivered in this mode.
+func WithJSONResponseOnly() StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.jsonResponseOnly = true
+	}
+}
+
+// WithoutHTTPPriorityLanes disables the control lane of the streamable HTTP
+// transport. By default request bodies are uploaded one at a time and pings,
+// cancellations and responses to sampling requests are uploaded before
+// queued requests, so they wait for at most the one body being uploaded;
+// with this option bodies are uploaded in the order they were sent.
+func WithoutHTTPPriorityLanes() StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.lanes.disabled = true
+	}
+}
+
 // WithHTTPTimeout sets the timeout for a HTTP request and stream.
 func WithHTTPTimeout(timeout time.Duration) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
@@ -105,10 +247,43 @@ type StreamableHTTP struct {
 	headerFunc          HTTPHeaderFunc
 	logger              util.Logger
 	getListeningEnabled bool
+	listenStateHandler  func(state ListenState)
+	listenState         ListenState
+	listenStateSet      bool
+
+	// transportTuning is applied to a clone of the *http.Transport in use.
+	transportTuning []func(*http.Transport)
+	// listenClient is used for the standalone GET stream so that it never
+	// shares a connection with POST requests.
+	listenClient *http.Client
 
 	sessionID       atomic.Value // string
 	protocolVersion atomic.Value // string
 
+	// lastSuccess is the time of the last successful response in Unix
+	// nanoseconds; headSupported records whether the server answered a
+	// HEAD probe, so a 404 can be trusted to mean the session is gone.
+	lastSuccess       atomic.Int64
+	headSupported     atomic.Bool
+	healthCheckMaxAge time.Duration
+
+	// disconnected is set when the last request failed to reach the server
+	// or found the session terminated, and cleared by the next response.
+	started      atomic.Bool
+	disconnected atomic.Bool
+
+	// compressionThreshold is the body size from which requests are gzipped;
+	// 0 disables compression.
+	compressionThreshold int
+
+	// jsonResponseOnly makes requests accept only application/json
+	// responses.
+	jsonResponseOnly bool
+
+	// lanes serializes the upload of request bodies, letting control
+	// messages overtake queued requests.
+	lanes writeLanes
+
 	initialized     chan struct{}
 	initializedOnce sync.Once
 
@@ -150,18 +325,65 @@ func NewStreamableHTTP(serverURL string, options ...StreamableHTTPCOption) (*Str
 		}
 	}
 
+	smc.configureHTTPTransport()
+
 	// If OAuth is configured, set the base URL for metadata discovery
 	if smc.oauthHandler != nil {
 		// Extract base URL from server URL for metadata discovery
 		baseURL := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
 		smc.oauthHandler.SetBaseURL(baseURL)
+		smc.oauthHandler.setDefaultResource(parsedURL)
 	}
 
 	return smc, nil
 }
 
+// configureHTTPTransport applies the transport tuning options and prepares a
+// dedicated client for the continuous listening stream. The http.Client
+// supplied by the caller is never modified.
+func (c *StreamableHTTP) configureHTTPTransport() {
+	if len(c.transportTuning) > 0 {
+		if transport, ok := cloneHTTPTransport(c.httpClient.Transport); ok {
+			for _, tune := range c.transportTuning {
+				tune(transport)
+			}
+			client := *c.httpClient
+			client.Transport = transport
+			c.httpClient = &client
+		} else {
+			c.logger.Infof("HTTP transport tuning ignored: custom RoundTripper %T is not an *http.Transport", c.httpClient.Transport)
+		}
+	}
+
+	if !c.getListeningEnabled {
+		return
+	}
+	// A separate connection pool keeps the long-lived GET from occupying a
+	// pooled HTTP/1.1 connection or head-of-line blocking POSTs on a shared
+	// HTTP/2 connection.
+	if transport, ok := cloneHTTPTransport(c.httpClient.Transport); ok {
+		client := *c.httpClient
+		client.Transport = transport
+		c.listenClient = &client
+	}
+}
+
+// cloneHTTPTransport returns a copy of rt if it is an *http.Transport, or of
+// http.DefaultTransport if rt is nil.
+func cloneHTTPTransport(rt http.RoundTripper) (*http.Transport, bool) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, false
+	}
+	return transport.Clone(), true
+}
+
 // Start initiates the HTTP connection to the server.
 func (c *StreamableHTTP) Start(ctx context.Context) error {
+	c.started.Store(true)
 	// For Streamable HTTP, we don't need to establish a persistent connection by default
 	if c.getListeningEnabled {
 		go func() {
@@ -233,9 +455,26 @@ var ErrOAuthAuthorizationRequired = errors.New("no valid token available, author
 // OAuthAuthorizationRequiredError is returned when OAuth authorization is required
 type OAuthAuthorizationRequiredError struct {
 	Handler *OAuthHandler
+
+	// InsufficientScope is set when the server accepted the token but
+	// rejected the request because the token lacks a required scope.
+	InsufficientScope bool
+	// RequiredScopes lists the scopes the server asked for in its
+	// WWW-Authenticate challenge, if any. Pass them to
+	// OAuthHandler.RequestScopes before re-running the authorization flow.
+	RequiredScopes []string
+	// ResourceMetadataURL is the protected resource metadata URL the server
+	// advertised in its WWW-Authenticate challenge, if any.
+	ResourceMetadataURL string
 }
 
 func (e *OAuthAuthorizationRequiredError) Error() string {
+	if e.InsufficientScope {
+		if len(e.RequiredScopes) > 0 {
+			return fmt.Sprintf("insufficient scope, authorization required for scopes: %s", strings.Join(e.RequiredScopes, " "))
+		}
+		return "insufficient scope, authorization required"
+	}
 	return ErrOAuthAuthorizationRequired.Error()
 }
 
@@ -258,7 +497,7 @@ func (c *StreamableHTTP) SendRequest(
 	ctx, cancel := c.contextAwareOfClientClose(ctx)
 	defer cancel()
 
-	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(requestBody), "application/json, text/event-stream")
+	resp, err := c.sendHTTP(ctx, http.MethodPost, requestBody, c.requestAcceptType(), isControlMethod(request.Method))
 	if err != nil {
 		if errors.Is(err, ErrSessionTerminated) && request.Method == string(mcp.MethodInitialize) {
 			// If the request is initialize, should not return a SessionTerminated error
@@ -281,10 +520,8 @@ func (c *StreamableHTTP) SendRequest(
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 
 		// Handle OAuth unauthorized error
-		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return nil, &OAuthAuthorizationRequiredError{
-				Handler: c.oauthHandler,
-			}
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return nil, err
 		}
 
 		// handle error response
@@ -312,20 +549,12 @@ func (c *StreamableHTTP) SendRequest(
 	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
 	switch mediaType {
 	case "application/json":
-		// Single response
-		var response JSONRPCResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
-
-		// should not be a notification
-		if response.ID.IsNil() {
-			return nil, fmt.Errorf("response should contain RPC id: %v", response)
-		}
-
-		return &response, nil
+		return c.handleJSONResponse(resp.Body)
 
 	case "text/event-stream":
+		if c.jsonResponseOnly {
+			return nil, ErrEventStreamNotAccepted
+		}
 		// Server is using SSE for streaming responses
 		return c.handleSSEResponse(ctx, resp.Body, false)
 
@@ -334,17 +563,100 @@ func (c *StreamableHTTP) SendRequest(
 	}
 }
 
+// requestAcceptType returns the Accept header for POST requests.
+func (c *StreamableHTTP) requestAcceptType() string {
+	if c.jsonResponseOnly {
+		return "application/json"
+	}
+	return "application/json, text/event-stream"
+}
+
+// handleJSONResponse decodes a JSON response body. The body is either a
+// single response or a batch in which notifications, inlined by the server,
+// precede the response.
+func (c *StreamableHTTP) handleJSONResponse(body io.Reader) (*JSONRPCResponse, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	messages := []json.RawMessage{raw}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		messages = nil
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	var response *JSONRPCResponse
+	for _, message := range messages {
+		var decoded JSONRPCResponse
+		if err := json.Unmarshal(message, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if !decoded.ID.IsNil() {
+			response = &decoded
+			continue
+		}
+
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(message, &notification); err != nil || notification.Method == "" {
+			// should not be a notification
+			return nil, fmt.Errorf("response should contain RPC id: %v", decoded)
+		}
+		c.notifyMu.RLock()
+		if c.notificationHandler != nil {
+			c.notificationHandler(notification)
+		}
+		c.notifyMu.RUnlock()
+	}
+
+	if response == nil {
+		return nil, fmt.Errorf("response should contain RPC id: %s", raw)
+	}
+	return response, nil
+}
+
 func (c *StreamableHTTP) sendHTTP(
 	ctx context.Context,
 	method string,
-	body io.Reader,
+	body []byte,
 	acceptType string,
+	control bool,
 ) (resp *http.Response, err error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	compress := c.compressionThreshold > 0 && len(body) >= c.compressionThreshold
+	if compress {
+		reqBody = gzipStream(body)
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, method, c.serverURL.String(), body)
+	req, err := http.NewRequestWithContext(ctx, method, c.serverURL.String(), reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+		req.GetBody = func() (io.ReadCloser, error) {
+			return gzipStream(body), nil
+		}
+	}
+	if req.Body != nil {
+		// Upload the body only once it is its turn in the lane
+		req.Body = &laneBody{ReadCloser: req.Body, ctx: ctx, lanes: &c.lanes, control: control}
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				rc, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return &laneBody{ReadCloser: rc, ctx: ctx, lanes: &c.lanes, control: control}, nil
+			}
+		}
+	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
@@ -365,17 +677,13 @@ func (c *StreamableHTTP) sendHTTP(
 
 	// Add OAuth authorization if configured
 	if c.oauthHandler != nil {
-		authHeader, err := c.oauthHandler.GetAuthorizationHeader(ctx)
+		authHeader, err := authorizationHeader(ctx, c.oauthHandler)
 		if err != nil {
-			// If we get an authorization error, return a specific error that can be handled by the client
-			if err.Error() == "no valid token available, authorization required" {
-				return nil, &OAuthAuthorizationRequiredError{
-					Handler: c.oauthHandler,
-				}
-			}
-			return nil, fmt.Errorf("failed to get authorization header: %w", err)
+			return nil, err
+		}
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
 		}
-		req.Header.Set("Authorization", authHeader)
 	}
 
 	if c.headerFunc != nil {
@@ -385,20 +693,125 @@ func (c *StreamableHTTP) sendHTTP(
 	}
 
 	// Send request
-	resp, err = c.httpClient.Do(req)
+	client := c.httpClient
+	if method == http.MethodGet && c.listenClient != nil {
+		client = c.listenClient
+	}
+	resp, err = client.Do(req)
+	if err != nil && method == http.MethodPost && req.GetBody != nil && ctx.Err() == nil && isRetryableConnError(err) {
+		// The connection was dropped before any response arrived, so the
+		// server cannot have processed the request. Retry exactly once.
+		var retryBody io.ReadCloser
+		if retryBody, err = req.GetBody(); err == nil {
+			retryReq := req.Clone(ctx)
+			retryReq.Body = retryBody
+			resp, err = client.Do(retryReq)
+		}
+	}
 	if err != nil {
+		if ctx.Err() == nil {
+			c.disconnected.Store(true)
+		}
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
-	// universal handling for session terminated
-	if resp.StatusCode == http.StatusNotFound {
+	// universal handling for session terminated; health check probes
+	// interpret the status themselves
+	if resp.StatusCode == http.StatusNotFound && method != http.MethodHead {
 		c.sessionID.CompareAndSwap(sessionID, "")
+		c.disconnected.Store(true)
 		return nil, ErrSessionTerminated
 	}
+	c.disconnected.Store(false)
 
+	if resp.StatusCode < http.StatusMultipleChoices {
+		c.lastSuccess.Store(time.Now().UnixNano())
+	}
 	return resp, nil
 }
 
+// gzipStream returns a reader yielding the gzip compression of data. The
+// data is compressed while the reader is consumed, so the compressed body is
+// never held in memory in full.
+func gzipStream(data []byte) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		if _, err := zw.Write(data); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(zw.Close())
+	}()
+	return pr
+}
+
+// IsConnected reports whether the transport has been started and not closed,
+// and its last request reached the server without finding the session
+// terminated. It does not send anything; use HealthCheck to probe the server.
+func (c *StreamableHTTP) IsConnected() bool {
+	select {
+	case <-c.closed:
+		return false
+	default:
+	}
+	return c.started.Load() && !c.disconnected.Load()
+}
+
+// HealthCheck reports whether the server is reachable and, once a session
+// has been established, whether the session is still valid. It sends a
+// lightweight HEAD request to the endpoint. Servers that don't support HEAD
+// are considered healthy as long as they answer. See WithHealthCheckMaxAge to
+// skip the probe while the connection is in active use.
+func (c *StreamableHTTP) HealthCheck(ctx context.Context) error {
+	select {
+	case <-c.closed:
+		return &stateError{kind: ErrClosed, msg: "transport has been closed"}
+	default:
+	}
+
+	if c.healthCheckMaxAge > 0 {
+		if last := c.lastSuccess.Load(); last != 0 && time.Since(time.Unix(0, last)) < c.healthCheckMaxAge {
+			return nil
+		}
+	}
+
+	sessionID := c.sessionID.Load().(string)
+	resp, err := c.sendHTTP(ctx, http.MethodHead, nil, "application/json, text/event-stream", false)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < http.StatusMultipleChoices:
+		c.headSupported.Store(true)
+		return nil
+	case resp.StatusCode == http.StatusNotFound && sessionID != "" && c.headSupported.Load():
+		c.sessionID.CompareAndSwap(sessionID, "")
+		return ErrSessionTerminated
+	case resp.StatusCode == http.StatusNotFound,
+		resp.StatusCode == http.StatusMethodNotAllowed,
+		resp.StatusCode == http.StatusNotImplemented:
+		// The server doesn't support HEAD, but it is reachable
+		return nil
+	default:
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return err
+		}
+		return fmt.Errorf("health check failed with status %d", resp.StatusCode)
+	}
+}
+
+// isRetryableConnError reports whether err indicates that the connection was
+// closed by the server (reset, GOAWAY or EOF) before any response was read.
+func isRetryableConnError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	return strings.Contains(err.Error(), "GOAWAY")
+}
+
 // handleSSEResponse processes an SSE stream for a specific request.
 // It returns the final result for the request once received, or an error.
 // If ignoreResponse is true, it won't return when a response messge is received. This is for continuous listening.
@@ -546,7 +959,7 @@ func (c *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.
 	ctx, cancel := c.contextAwareOfClientClose(ctx)
 	defer cancel()
 
-	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(requestBody), "application/json, text/event-stream")
+	resp, err := c.sendHTTP(ctx, http.MethodPost, requestBody, "application/json, text/event-stream", isControlMethod(notification.Method))
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -554,10 +967,8 @@ func (c *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		// Handle OAuth unauthorized error
-		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return &OAuthAuthorizationRequiredError{
-				Handler: c.oauthHandler,
-			}
+		if err := oauthErrorFromResponse(resp, c.oauthHandler); err != nil {
+			return err
 		}
 
 		body, _ := io.ReadAll(resp.Body)
@@ -609,9 +1020,15 @@ func (c *StreamableHTTP) listenForever(ctx context.Context) {
 		if errors.Is(err, ErrGetMethodNotAllowed) {
 			// server does not support listening
 			c.logger.Errorf("server does not support listening")
+			c.setListenState(ListenStateFailed)
 			return
 		}
 
+		// Only an established stream can become disconnected
+		if c.listenStateSet && c.listenState == ListenStateConnected {
+			c.setListenState(ListenStateDisconnected)
+		}
+
 		select {
 		case <-ctx.Done():
 			return
@@ -621,6 +1038,7 @@ func (c *StreamableHTTP) listenForever(ctx context.Context) {
 		if err != nil {
 			c.logger.Errorf("failed to listen to server. retry in 1 second: %v", err)
 		}
+		c.setListenState(ListenStateReconnecting)
 		
 		// Use context-aware sleep
 		select {
@@ -631,15 +1049,32 @@ func (c *StreamableHTTP) listenForever(ctx context.Context) {
 	}
 }
 
+// setListenState records the state of the listening connection and reports
+// changes to the handler. It is only called from the listening goroutine.
+func (c *StreamableHTTP) setListenState(state ListenState) {
+	if c.listenStateSet && c.listenState == state {
+		return
+	}
+	c.listenState = state
+	c.listenStateSet = true
+	if c.listenStateHandler != nil {
+		c.listenStateHandler(state)
+	}
+}
+
 var (
 	ErrSessionTerminated   = fmt.Errorf("session terminated (404). need to re-initialize")
 	ErrGetMethodNotAllowed = fmt.Errorf("GET method not allowed")
 
+	// ErrEventStreamNotAccepted is returned when WithJSONResponseOnly is set
+	// but the server still answers with an SSE stream.
+	ErrEventStreamNotAccepted = fmt.Errorf("server responded with an event stream, but only JSON responses are accepted")
+
 	retryInterval = 1 * time.Second // a variable is convenient for testing
 )
 
 func (c *StreamableHTTP) createGETConnectionToServer(ctx context.Context) error {
-	resp, err := c.sendHTTP(ctx, http.MethodGet, nil, "text/event-stream")
+	resp, err := c.sendHTTP(ctx, http.MethodGet, nil, "text/event-stream", false)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -660,6 +1095,7 @@ func (c *StreamableHTTP) createGETConnectionToServer(ctx context.Context) error
 	if contentType != "text/event-stream" {
 		return fmt.Errorf("unexpected content type: %s", contentType)
 	}
+	c.setListenState(ListenStateConnected)
 
 	// When ignoreResponse is true, the function will never return expect context is done.
 	// NOTICE: Due to the ambiguity of the specification, other SDKs may use the GET connection to transfer the response
@@ -680,6 +1116,12 @@ func (c *StreamableHTTP) handleIncomingRequest(ctx context.Context, request JSON
 	handler := c.requestHandler
 	c.requestMu.RUnlock()
 
+	control := isControlMethod(request.Method)
+	if handler == nil && request.Method == string(mcp.MethodPing) {
+		c.sendResponseToServer(ctx, NewPingResponse(request.ID), control)
+		return
+	}
+
 	if handler == nil {
 		c.logger.Errorf("received request from server but no handler set: %s", request.Method)
 		// Send method not found error
@@ -695,7 +1137,7 @@ func (c *StreamableHTTP) handleIncomingRequest(ctx context.Context, request JSON
 				Message: fmt.Sprintf("no handler configured for method: %s", request.Method),
 			},
 		}
-		c.sendResponseToServer(ctx, errorResponse)
+		c.sendResponseToServer(ctx, errorResponse, control)
 		return
 	}
 
@@ -745,18 +1187,19 @@ func (c *StreamableHTTP) handleIncomingRequest(ctx context.Context, request JSON
 					Message: errorMessage,
 				},
 			}
-			c.sendResponseToServer(requestCtx, errorResponse)
+			c.sendResponseToServer(requestCtx, errorResponse, control)
 			return
 		}
 
 		if response != nil {
-			c.sendResponseToServer(requestCtx, response)
+			c.sendResponseToServer(requestCtx, response, control)
 		}
 	}()
 }
 
-// sendResponseToServer sends a response back to the server via HTTP POST
-func (c *StreamableHTTP) sendResponseToServer(ctx context.Context, response *JSONRPCResponse) {
+// sendResponseToServer sends a response back to the server via HTTP POST,
+// in the control lane if control is set.
+func (c *StreamableHTTP) sendResponseToServer(ctx context.Context, response *JSONRPCResponse, control bool) {
 	if response == nil {
 		c.logger.Errorf("cannot send nil response to server")
 		return
@@ -771,7 +1214,7 @@ func (c *StreamableHTTP) sendResponseToServer(ctx context.Context, response *JSO
 	ctx, cancel := c.contextAwareOfClientClose(ctx)
 	defer cancel()
 
-	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(responseBody), "application/json")
+	resp, err := c.sendHTTP(ctx, http.MethodPost, responseBody, "application/json", control)
 	if err != nil {
 		c.logger.Errorf("failed to send response to server: %v", err)
 		return
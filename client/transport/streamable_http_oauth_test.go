@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -191,6 +195,96 @@ func TestStreamableHTTP_WithOAuth_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestStreamableHTTP_WithOAuth_InsufficientScope(t *testing.T) {
+	// Create a test server that accepts the token but requires another scope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp", error="insufficient_scope", scope="mcp.read mcp.admin"`)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tokenStore := NewMemoryTokenStore()
+	if err := tokenStore.SaveToken(&Token{
+		AccessToken: "test-token",
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(1 * time.Hour),
+	}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	oauthConfig := OAuthConfig{
+		ClientID:    "test-client",
+		RedirectURI: "http://localhost:8085/callback",
+		Scopes:      []string{"mcp.read"},
+		TokenStore:  tokenStore,
+	}
+
+	transport, err := NewStreamableHTTP(server.URL, WithHTTPOAuth(oauthConfig))
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP: %v", err)
+	}
+
+	_, err = transport.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(1),
+		Method:  "test",
+	})
+
+	var oauthErr *OAuthAuthorizationRequiredError
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("Expected OAuthAuthorizationRequiredError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, ErrOAuthAuthorizationRequired) {
+		t.Errorf("Expected error to wrap ErrOAuthAuthorizationRequired")
+	}
+	if !oauthErr.InsufficientScope {
+		t.Errorf("Expected InsufficientScope to be set")
+	}
+	if len(oauthErr.RequiredScopes) != 2 || oauthErr.RequiredScopes[0] != "mcp.read" || oauthErr.RequiredScopes[1] != "mcp.admin" {
+		t.Errorf("Expected required scopes [mcp.read mcp.admin], got %v", oauthErr.RequiredScopes)
+	}
+
+	// Requesting the additional scopes extends those of the next authorization
+	oauthErr.Handler.RequestScopes(oauthErr.RequiredScopes...)
+	scopes := oauthErr.Handler.GetScopes()
+	if len(scopes) != 2 || scopes[0] != "mcp.read" || scopes[1] != "mcp.admin" {
+		t.Errorf("Expected scopes [mcp.read mcp.admin], got %v", scopes)
+	}
+}
+
+func TestStreamableHTTP_WithOAuth_ForbiddenWithoutChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tokenStore := NewMemoryTokenStore()
+	if err := tokenStore.SaveToken(&Token{
+		AccessToken: "test-token",
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(1 * time.Hour),
+	}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	transport, err := NewStreamableHTTP(server.URL, WithHTTPOAuth(OAuthConfig{TokenStore: tokenStore}))
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP: %v", err)
+	}
+
+	_, err = transport.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(1),
+		Method:  "test",
+	})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if errors.Is(err, ErrOAuthAuthorizationRequired) {
+		t.Errorf("Expected a plain 403 not to require authorization, got %v", err)
+	}
+}
+
 func TestStreamableHTTP_IsOAuthEnabled(t *testing.T) {
 	// Create StreamableHTTP without OAuth
 	transport1, err := NewStreamableHTTP("http://example.com")
@@ -216,3 +310,193 @@ func TestStreamableHTTP_IsOAuthEnabled(t *testing.T) {
 		t.Errorf("Expected IsOAuthEnabled() to return true")
 	}
 }
+
+func TestStreamableHTTP_WithOAuth_ResourceIndicators(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		tokenForms []url.Values
+	)
+
+	// Fake identity provider serving metadata and a token endpoint
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/oauth-authorization-server":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(AuthServerMetadata{
+				Issuer:                idp.URL,
+				AuthorizationEndpoint: idp.URL + "/authorize",
+				TokenEndpoint:         idp.URL + "/token",
+			})
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("Failed to parse token request: %v", err)
+			}
+			mu.Lock()
+			tokenForms = append(tokenForms, r.PostForm)
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(Token{
+				AccessToken:  "access-token",
+				TokenType:    "Bearer",
+				RefreshToken: "refresh-token",
+				ExpiresIn:    3600,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer idp.Close()
+
+	// MCP server that advertises its protected resource metadata at a
+	// non-default location
+	var mcpServer *httptest.Server
+	mcpServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metadata/mcp" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(OAuthProtectedResource{
+				Resource:             mcpServer.URL + "/mcp",
+				AuthorizationServers: []string{idp.URL},
+			})
+			return
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s/metadata/mcp"`, mcpServer.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer mcpServer.Close()
+
+	serverURL := mcpServer.URL + "/mcp"
+	transport, err := NewStreamableHTTP(serverURL+"#fragment", WithHTTPOAuth(OAuthConfig{
+		ClientID:    "test-client",
+		RedirectURI: "http://localhost:8085/callback",
+		TokenStore:  NewMemoryTokenStore(),
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP: %v", err)
+	}
+
+	_, err = transport.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(1),
+		Method:  "test",
+	})
+	var oauthErr *OAuthAuthorizationRequiredError
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("Expected OAuthAuthorizationRequiredError, got %T: %v", err, err)
+	}
+	if oauthErr.ResourceMetadataURL != mcpServer.URL+"/metadata/mcp" {
+		t.Errorf("Expected resource metadata URL from the challenge, got %q", oauthErr.ResourceMetadataURL)
+	}
+	handler := oauthErr.Handler
+	if got := handler.GetResource(); got != serverURL {
+		t.Errorf("Expected resource %q, got %q", serverURL, got)
+	}
+
+	authURL, err := handler.GetAuthorizationURL(context.Background(), "state", "")
+	if err != nil {
+		t.Fatalf("GetAuthorizationURL failed: %v", err)
+	}
+	if !strings.HasPrefix(authURL, idp.URL+"/authorize?") {
+		t.Errorf("Expected the authorization server from the resource metadata, got %s", authURL)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Failed to parse authorization URL: %v", err)
+	}
+	if got := parsed.Query().Get("resource"); got != serverURL {
+		t.Errorf("Expected resource %q in the authorization URL, got %q", serverURL, got)
+	}
+
+	if err := handler.ProcessAuthorizationResponse(context.Background(), "code", "state", ""); err != nil {
+		t.Fatalf("ProcessAuthorizationResponse failed: %v", err)
+	}
+	if _, err := handler.RefreshToken(context.Background(), "refresh-token"); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tokenForms) != 2 {
+		t.Fatalf("Expected 2 token requests, got %d", len(tokenForms))
+	}
+	for i, grantType := range []string{"authorization_code", "refresh_token"} {
+		if got := tokenForms[i].Get("grant_type"); got != grantType {
+			t.Errorf("Expected grant_type %s, got %s", grantType, got)
+		}
+		if got := tokenForms[i].Get("resource"); got != serverURL {
+			t.Errorf("Expected resource %q in the %s request, got %q", serverURL, grantType, got)
+		}
+	}
+}
+
+func TestStreamableHTTP_WithOAuth_StoredToken(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		authHeaders []string
+	)
+
+	// Server that answers authenticated requests and challenges the rest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer stored-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="https://example.com/metadata"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"result":  "success",
+		})
+	}))
+	defer server.Close()
+
+	tokenStore := NewMemoryTokenStore()
+	if err := tokenStore.SaveToken(&Token{
+		AccessToken: "stored-token",
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	transport, err := NewStreamableHTTP(server.URL, WithHTTPOAuth(OAuthConfig{
+		ClientID:   "test-client",
+		TokenStore: tokenStore,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP: %v", err)
+	}
+
+	// The stored token is sent with the very first request
+	if _, err := transport.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(1),
+		Method:  "test",
+	}); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(authHeaders) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(authHeaders))
+	}
+	if authHeaders[0] != "Bearer stored-token" {
+		t.Errorf("Expected Authorization header 'Bearer stored-token', got %q", authHeaders[0])
+	}
+}
+
+func TestOAuthHandler_ExplicitResource(t *testing.T) {
+	transport, err := NewStreamableHTTP("https://mcp.example.com/mcp", WithHTTPOAuth(OAuthConfig{
+		Resource: "https://mcp.example.com",
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP: %v", err)
+	}
+	if got := transport.GetOAuthHandler().GetResource(); got != "https://mcp.example.com" {
+		t.Errorf("Expected the configured resource, got %q", got)
+	}
+}
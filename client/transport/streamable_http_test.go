@@ -1,14 +1,17 @@
 package transport
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -767,6 +770,92 @@ func TestContinuousListeningMethodNotAllowed(t *testing.T) {
 	}
 }
 
+func TestContinuousListeningStateHandler(t *testing.T) {
+	retryInterval = 10 * time.Millisecond
+
+	waitForState := func(t *testing.T, states <-chan ListenState, expected ListenState) {
+		t.Helper()
+		select {
+		case state := <-states:
+			if state != expected {
+				t.Fatalf("Expected listen state %s, got %s", expected, state)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timed out waiting for listen state %s", expected)
+		}
+	}
+
+	initialize := func(t *testing.T, trans *StreamableHTTP) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := trans.SendRequest(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(0)),
+			Method:  "initialize",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("reports reconnection", func(t *testing.T) {
+		url, closeServer, _, _ := startMockStreamableWithGETSupport(true)
+
+		states := make(chan ListenState, 100)
+		trans, err := NewStreamableHTTP(url,
+			WithContinuousListening(),
+			WithListenStateHandler(func(state ListenState) {
+				states <- state
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			trans.Close()
+			closeServer()
+		}()
+
+		if err := trans.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		initialize(t, trans)
+
+		// The mock server closes the stream after its second notification
+		waitForState(t, states, ListenStateConnected)
+		waitForState(t, states, ListenStateDisconnected)
+		waitForState(t, states, ListenStateReconnecting)
+		waitForState(t, states, ListenStateConnected)
+	})
+
+	t.Run("reports failure when listening is not supported", func(t *testing.T) {
+		url, closeServer, _, _ := startMockStreamableWithGETSupport(false)
+
+		states := make(chan ListenState, 100)
+		trans, err := NewStreamableHTTP(url,
+			WithContinuousListening(),
+			WithListenStateHandler(func(state ListenState) {
+				states <- state
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			trans.Close()
+			closeServer()
+		}()
+
+		if err := trans.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		initialize(t, trans)
+
+		waitForState(t, states, ListenStateFailed)
+	})
+}
+
 // testLogger is a simple logger for testing
 type testLogger struct {
 	logChan chan string
@@ -779,3 +868,517 @@ func (l *testLogger) Infof(format string, args ...any) {
 func (l *testLogger) Errorf(format string, args ...any) {
 	l.logChan <- fmt.Sprintf(format, args...)
 }
+
+// startDroppingStreamableServer starts a server that abruptly closes the
+// connection for the first dropCount POSTs without writing a response, as a
+// restarting server would, and answers normally afterwards.
+func startDroppingStreamableServer(t *testing.T, dropCount int32) (string, *atomic.Int32, func()) {
+	t.Helper()
+	var attempts atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		if attempts.Add(1) <= dropCount {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Failed to hijack connection: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  request,
+		})
+	})
+
+	testServer := httptest.NewServer(handler)
+	return testServer.URL, &attempts, testServer.Close
+}
+
+func TestStreamableHTTP_RetryOnDroppedConnection(t *testing.T) {
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "debug/echo",
+	}
+
+	t.Run("retries once silently", func(t *testing.T) {
+		url, attempts, closeServer := startDroppingStreamableServer(t, 1)
+		defer closeServer()
+
+		trans, err := NewStreamableHTTP(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		response, err := trans.SendRequest(ctx, request)
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.ID.String() != request.ID.String() {
+			t.Errorf("Expected ID %s, got %s", request.ID, response.ID)
+		}
+		if got := attempts.Load(); got != 2 {
+			t.Errorf("Expected 2 attempts, got %d", got)
+		}
+	})
+
+	t.Run("does not retry more than once", func(t *testing.T) {
+		url, attempts, closeServer := startDroppingStreamableServer(t, 2)
+		defer closeServer()
+
+		trans, err := NewStreamableHTTP(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := trans.SendRequest(ctx, request); err == nil {
+			t.Fatal("Expected SendRequest to fail")
+		}
+		if got := attempts.Load(); got != 2 {
+			t.Errorf("Expected 2 attempts, got %d", got)
+		}
+	})
+}
+
+func TestStreamableHTTP_TransportTuning(t *testing.T) {
+	base := &http.Client{Timeout: 7 * time.Second}
+	trans, err := NewStreamableHTTP("http://localhost:0",
+		WithHTTPBasicClient(base),
+		WithHTTPTransportTuning(64, 16, 30*time.Second),
+		WithForceHTTP2(true),
+		WithContinuousListening(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if base.Transport != nil {
+		t.Error("Expected the caller's http.Client to be left untouched")
+	}
+	if trans.httpClient.Timeout != base.Timeout {
+		t.Errorf("Expected timeout %s to be preserved, got %s", base.Timeout, trans.httpClient.Timeout)
+	}
+
+	transport, ok := trans.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", trans.httpClient.Transport)
+	}
+	if transport.MaxIdleConns != 64 || transport.MaxIdleConnsPerHost != 16 || transport.MaxConnsPerHost != 16 {
+		t.Errorf("Unexpected pool settings: MaxIdleConns=%d MaxIdleConnsPerHost=%d MaxConnsPerHost=%d",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 30*time.Second {
+		t.Errorf("Expected IdleConnTimeout 30s, got %s", transport.IdleConnTimeout)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("Expected ForceAttemptHTTP2 to be set")
+	}
+
+	if trans.listenClient == nil {
+		t.Fatal("Expected a dedicated client for the listening stream")
+	}
+	listenTransport, ok := trans.listenClient.Transport.(*http.Transport)
+	if !ok || listenTransport == transport {
+		t.Error("Expected the listening stream to use its own connection pool")
+	}
+}
+
+func TestStreamableHTTP_HealthCheck(t *testing.T) {
+	var status atomic.Int32
+	var probes atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		probes.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer testServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("healthy and terminated session", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(testServer.URL, WithSession("session-1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		status.Store(http.StatusOK)
+		if err := trans.HealthCheck(ctx); err != nil {
+			t.Fatalf("Expected healthy transport, got %v", err)
+		}
+
+		status.Store(http.StatusNotFound)
+		if err := trans.HealthCheck(ctx); !errors.Is(err, ErrSessionTerminated) {
+			t.Errorf("Expected ErrSessionTerminated, got %v", err)
+		}
+		if trans.GetSessionId() != "" {
+			t.Errorf("Expected session ID to be cleared, got %q", trans.GetSessionId())
+		}
+	})
+
+	t.Run("server without HEAD support", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(testServer.URL, WithSession("session-1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		for _, code := range []int{http.StatusNotFound, http.StatusMethodNotAllowed} {
+			status.Store(int32(code))
+			if err := trans.HealthCheck(ctx); err != nil {
+				t.Errorf("Expected reachable server answering %d to be healthy, got %v", code, err)
+			}
+		}
+		if trans.GetSessionId() != "session-1" {
+			t.Errorf("Expected session ID to be kept, got %q", trans.GetSessionId())
+		}
+	})
+
+	t.Run("broken server", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(testServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		status.Store(http.StatusBadGateway)
+		if err := trans.HealthCheck(ctx); err == nil {
+			t.Error("Expected health check to fail")
+		}
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		trans, err := NewStreamableHTTP(unreachable.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		if err := trans.HealthCheck(ctx); err == nil {
+			t.Error("Expected health check to fail")
+		}
+	})
+
+	t.Run("recent success skips the probe", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(testServer.URL, WithHealthCheckMaxAge(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		status.Store(http.StatusOK)
+		if err := trans.HealthCheck(ctx); err != nil {
+			t.Fatalf("Expected healthy transport, got %v", err)
+		}
+		before := probes.Load()
+		if err := trans.HealthCheck(ctx); err != nil {
+			t.Fatalf("Expected healthy transport, got %v", err)
+		}
+		if got := probes.Load(); got != before {
+			t.Errorf("Expected no new probe, got %d", got-before)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(testServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trans.Close()
+
+		if err := trans.HealthCheck(ctx); !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	})
+}
+
+func TestStreamableHTTP_RequestCompression(t *testing.T) {
+	var mu sync.Mutex
+	var encodings []string
+	var sizes []int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		sizes = append(sizes, len(data))
+		mu.Unlock()
+
+		var request JSONRPCRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  map[string]any{},
+		})
+	}))
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL, WithRequestCompressionThreshold(1024))
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer trans.Close()
+
+	for i, size := range []int{10, 4096} {
+		_, err := trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(i + 1)),
+			Method:  "test",
+			Params:  map[string]any{"data": strings.Repeat("x", size)},
+		})
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(encodings) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(encodings))
+	}
+	if encodings[0] != "" {
+		t.Errorf("Expected small request to be sent uncompressed, got %q", encodings[0])
+	}
+	if encodings[1] != "gzip" {
+		t.Errorf("Expected large request to be gzipped, got %q", encodings[1])
+	}
+	if sizes[1] < 4096 {
+		t.Errorf("Expected decompressed body of at least 4096 bytes, got %d", sizes[1])
+	}
+}
+
+func TestStreamableHTTP_AnswersPingWithoutHandler(t *testing.T) {
+	pongs := make(chan JSONRPCResponse, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var message struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(body, &message); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The client's answer to our ping
+		if message.Method == "" {
+			var response JSONRPCResponse
+			_ = json.Unmarshal(body, &response)
+			pongs <- response
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Ping the client on the response stream, then answer the request
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","id":42,"method":"ping"}`)
+		w.(http.Flusher).Flush()
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","id":1,"result":{}}`)
+		w.(http.Flusher).Flush()
+	}))
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer trans.Close()
+
+	_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "tools/list",
+	})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	select {
+	case pong := <-pongs:
+		if pong.ID.String() != mcp.NewRequestId(int64(42)).String() {
+			t.Errorf("Expected pong for request 42, got %v", pong.ID)
+		}
+		if pong.Error != nil {
+			t.Errorf("Expected empty result, got error %v", pong.Error)
+		}
+		if string(pong.Result) != "{}" {
+			t.Errorf("Expected empty result, got %s", pong.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the client to answer the ping")
+	}
+}
+
+func TestStreamableHTTP_IsConnected(t *testing.T) {
+	url, closeF := startMockStreamableHTTPServer()
+	defer closeF()
+
+	trans, err := NewStreamableHTTP(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trans.Close()
+
+	if trans.IsConnected() {
+		t.Error("Expected an unstarted transport to be disconnected")
+	}
+	if err := trans.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !trans.IsConnected() {
+		t.Error("Expected a started transport to be connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(0)),
+		Method:  "initialize",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !trans.IsConnected() {
+		t.Error("Expected the transport to be connected after a successful request")
+	}
+
+	// The server goes away
+	closeF()
+	if _, err := trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "debug/echo",
+	}); err == nil {
+		t.Fatal("Expected the request to fail")
+	}
+	if trans.IsConnected() {
+		t.Error("Expected the transport to be disconnected after the server went away")
+	}
+
+	if err := trans.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if trans.IsConnected() {
+		t.Error("Expected a closed transport to be disconnected")
+	}
+}
+
+func TestStreamableHTTP_JSONResponseOnly(t *testing.T) {
+	var accept atomic.Value
+	var stream atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept.Store(r.Header.Get("Accept"))
+		var request JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		notification := map[string]any{
+			"jsonrpc": "2.0",
+			"method":  "test/notification",
+			"params":  map[string]any{"value": 1},
+		}
+		response := map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  map[string]any{"ok": true},
+		}
+		if stream.Load() {
+			w.Header().Set("Content-Type", "text/event-stream")
+			data, _ := json.Marshal(response)
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{notification, response})
+	}))
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL, WithJSONResponseOnly())
+	if err != nil {
+		t.Fatalf("Failed to create transport: %v", err)
+	}
+	defer trans.Close()
+
+	var notifications []mcp.JSONRPCNotification
+	trans.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		notifications = append(notifications, notification)
+	})
+
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "tools/call",
+	}
+
+	t.Run("inlined notifications", func(t *testing.T) {
+		response, err := trans.SendRequest(context.Background(), request)
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if got := accept.Load(); got != "application/json" {
+			t.Errorf("Expected Accept application/json, got %v", got)
+		}
+		if response.ID.String() != "int64:1" {
+			t.Errorf("Expected response for request 1, got %v", response.ID)
+		}
+		if len(notifications) != 1 || notifications[0].Method != "test/notification" {
+			t.Errorf("Expected the inlined notification to be delivered, got %v", notifications)
+		}
+	})
+
+	t.Run("event stream rejected", func(t *testing.T) {
+		stream.Store(true)
+		_, err := trans.SendRequest(context.Background(), request)
+		if !errors.Is(err, ErrEventStreamNotAccepted) {
+			t.Errorf("Expected ErrEventStreamNotAccepted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultWatchDebounce is how long WatchTools, WatchResources and
+// WatchPrompts wait for a burst of list_changed notifications to settle
+// before listing again, unless changed with WithWatchDebounce.
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// WithWatchDebounce sets how long watchers wait after a list_changed
+// notification before listing again. Notifications arriving in the meantime
+// are folded into the same refresh. Zero or less refreshes immediately.
+func WithWatchDebounce(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.watchDebounce = d
+	}
+}
+
+// WatchTools returns a channel that first delivers the full list of tools of
+// the server, following pagination, and then a fresh list every time the
+// server reports that its tools changed. If the consumer falls behind, only
+// the latest list is kept. The channel is closed when ctx is done or the
+// client is closed. An error is returned if the initial list cannot be
+// fetched; failures of later refreshes are skipped until the next change.
+func (c *Client) WatchTools(ctx context.Context) (<-chan []mcp.Tool, error) {
+	return watchList(ctx, c, mcp.MethodNotificationToolsListChanged, func(ctx context.Context) ([]mcp.Tool, error) {
+		result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return result.Tools, nil
+	})
+}
+
+// WatchResources is like WatchTools, for the resources of the server.
+func (c *Client) WatchResources(ctx context.Context) (<-chan []mcp.Resource, error) {
+	return watchList(ctx, c, mcp.MethodNotificationResourcesListChanged, func(ctx context.Context) ([]mcp.Resource, error) {
+		result, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return result.Resources, nil
+	})
+}
+
+// WatchPrompts is like WatchTools, for the prompts of the server.
+func (c *Client) WatchPrompts(ctx context.Context) (<-chan []mcp.Prompt, error) {
+	return watchList(ctx, c, mcp.MethodNotificationPromptsListChanged, func(ctx context.Context) ([]mcp.Prompt, error) {
+		result, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return result.Prompts, nil
+	})
+}
+
+// watchList implements the Watch methods: it delivers list() once, then again
+// after every notification with the given method.
+func watchList[T any](
+	ctx context.Context,
+	c *Client,
+	method string,
+	list func(ctx context.Context) ([]T, error),
+) (<-chan []T, error) {
+	// Listen before the initial listing so no change is missed in between
+	changed := make(chan struct{}, 1)
+	remove := c.onListChanged(method, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	initial, err := list(ctx)
+	if err != nil {
+		remove()
+		return nil, err
+	}
+
+	snapshots := make(chan []T, 1)
+	snapshots <- initial
+
+	go func() {
+		defer close(snapshots)
+		defer remove()
+		for {
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			case <-c.closed:
+				return
+			}
+
+			if c.watchDebounce > 0 {
+				timer := time.NewTimer(c.watchDebounce)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-c.closed:
+					timer.Stop()
+					return
+				}
+				// The listing below covers the changes reported meanwhile
+				select {
+				case <-changed:
+				default:
+				}
+			}
+
+			snapshot, err := list(ctx)
+			if err != nil {
+				continue
+			}
+
+			// Replace a snapshot the consumer has not picked up yet. This
+			// goroutine is the only sender, so the send cannot block.
+			select {
+			case <-snapshots:
+			default:
+			}
+			snapshots <- snapshot
+		}
+	}()
+
+	return snapshots, nil
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func startWatchClient(t *testing.T, mcpServer *server.MCPServer) *Client {
+	t.Helper()
+	client := NewClient(transport.NewInProcessTransport(mcpServer), WithWatchDebounce(50*time.Millisecond))
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	return client
+}
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func TestClient_WatchTools(t *testing.T) {
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithPaginationLimit(1),
+	)
+	mcpServer.AddTool(mcp.NewTool("first"), handler)
+	mcpServer.AddTool(mcp.NewTool("second"), handler)
+
+	client := startWatchClient(t, mcpServer)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots, err := client.WatchTools(ctx)
+	if err != nil {
+		t.Fatalf("WatchTools failed: %v", err)
+	}
+
+	select {
+	case tools := <-snapshots:
+		if len(tools) != 2 {
+			t.Fatalf("Expected the initial snapshot to hold both pages, got %v", toolNames(tools))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the initial snapshot")
+	}
+
+	mcpServer.AddTool(mcp.NewTool("third"), handler)
+
+	select {
+	case tools := <-snapshots:
+		if len(tools) != 3 {
+			t.Fatalf("Expected the new tool in the snapshot, got %v", toolNames(tools))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the updated snapshot")
+	}
+
+	select {
+	case tools := <-snapshots:
+		t.Fatalf("Expected exactly one updated snapshot, got another: %v", toolNames(tools))
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case _, ok := <-snapshots:
+		if ok {
+			t.Fatal("Expected no snapshot after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the channel to be closed after cancellation")
+	}
+}
+
+func TestClient_WatchPrompts_DebouncesAndClosesWithClient(t *testing.T) {
+	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{}, nil
+	}
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithPromptCapabilities(true))
+	client := startWatchClient(t, mcpServer)
+
+	snapshots, err := client.WatchPrompts(context.Background())
+	if err != nil {
+		t.Fatalf("WatchPrompts failed: %v", err)
+	}
+	if prompts := <-snapshots; len(prompts) != 0 {
+		t.Fatalf("Expected no prompts, got %d", len(prompts))
+	}
+
+	// A burst of changes results in a single refresh
+	mcpServer.AddPrompt(mcp.NewPrompt("a"), handler)
+	mcpServer.AddPrompt(mcp.NewPrompt("b"), handler)
+	mcpServer.AddPrompt(mcp.NewPrompt("c"), handler)
+
+	select {
+	case prompts := <-snapshots:
+		if len(prompts) != 3 {
+			t.Fatalf("Expected 3 prompts, got %d", len(prompts))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the updated snapshot")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case prompts, ok := <-snapshots:
+		if ok {
+			t.Fatalf("Expected the channel to be closed, got %d prompts", len(prompts))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the channel to be closed with the client")
+	}
+}
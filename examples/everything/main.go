@@ -80,7 +80,7 @@ func NewMCPServer() *server.MCPServer {
 			"test://dynamic/resource/{id}",
 			"Dynamic Resource",
 		),
-		handleResourceTemplate,
+		mcp.NewTypedResourceHandler(handleResourceTemplate),
 	)
 
 	resources := generateResources()
@@ -234,15 +234,21 @@ func handleReadResource(
 	}, nil
 }
 
+// resourceTemplateVars holds the variables of the dynamic resource template.
+type resourceTemplateVars struct {
+	ID string `uri:"id"`
+}
+
 func handleResourceTemplate(
 	ctx context.Context,
 	request mcp.ReadResourceRequest,
+	vars resourceTemplateVars,
 ) ([]mcp.ResourceContents, error) {
 	return []mcp.ResourceContents{
 		mcp.TextResourceContents{
 			URI:      request.Params.URI,
 			MIMEType: "text/plain",
-			Text:     "This is a sample resource",
+			Text:     fmt.Sprintf("This is sample resource %s", vars.ID),
 		},
 	}, nil
 }
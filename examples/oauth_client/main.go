@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -111,6 +112,13 @@ func maybeAuthorize(err error) {
 		// Get the OAuth handler from the error
 		oauthHandler := client.GetOAuthHandler(err)
 
+		// If the token lacks a scope, request it in addition to the current ones
+		var oauthErr *client.OAuthAuthorizationRequiredError
+		if errors.As(err, &oauthErr) && oauthErr.InsufficientScope {
+			fmt.Printf("Additional scopes required: %v\n", oauthErr.RequiredScopes)
+			oauthHandler.RequestScopes(oauthErr.RequiredScopes...)
+		}
+
 		// Start a local server to handle the OAuth callback
 		callbackChan := make(chan map[string]string)
 		server := startCallbackServer(callbackChan)
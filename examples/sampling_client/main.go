@@ -83,7 +83,13 @@ func main() {
 	samplingHandler := &MockSamplingHandler{}
 
 	// Create client with sampling capability
-	mcpClient := client.NewClient(stdio, client.WithSamplingHandler(samplingHandler))
+	mcpClient := client.NewClient(stdio,
+		client.WithSamplingHandler(samplingHandler),
+		client.WithClientInfo(mcp.Implementation{
+			Name:    "sampling-example-client",
+			Version: "1.0.0",
+		}),
+	)
 
 	ctx := context.Background()
 
@@ -115,10 +121,6 @@ func main() {
 	initResult, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{
 		Params: mcp.InitializeParams{
 			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
-			ClientInfo: mcp.Implementation{
-				Name:    "sampling-example-client",
-				Version: "1.0.0",
-			},
 			Capabilities: mcp.ClientCapabilities{
 				// Sampling capability will be automatically added by WithSamplingHandler
 			},
@@ -68,6 +68,10 @@ func main() {
 	mcpClient := client.NewClient(
 		httpTransport,
 		client.WithSamplingHandler(samplingHandler),
+		client.WithClientInfo(mcp.Implementation{
+			Name:    "sampling-http-client",
+			Version: "1.0.0",
+		}),
 	)
 
 	// Start the client
@@ -84,10 +88,6 @@ func main() {
 			Capabilities: mcp.ClientCapabilities{
 				// Sampling capability will be automatically added by the client
 			},
-			ClientInfo: mcp.Implementation{
-				Name:    "sampling-http-client",
-				Version: "1.0.0",
-			},
 		},
 	}
 	
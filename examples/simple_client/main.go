@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -31,6 +32,14 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Identify ourselves to the server during initialization
+	clientInfo := mcp.Implementation{
+		Name:       "MCP-Go Simple Client Example",
+		Title:      "MCP-Go Simple Client",
+		Version:    "1.0.0",
+		WebsiteURL: "https://github.com/mark3labs/mcp-go",
+	}
+
 	// Create client based on transport type
 	var c *client.Client
 	var err error
@@ -52,7 +61,7 @@ func main() {
 		stdioTransport := transport.NewStdio(command, nil, cmdArgs...)
 
 		// Create client with the transport
-		c = client.NewClient(stdioTransport)
+		c = client.NewClient(stdioTransport, client.WithClientInfo(clientInfo))
 
 		// Start the client
 		if err := c.Start(ctx); err != nil {
@@ -92,7 +101,7 @@ func main() {
 		}
 
 		// Create client with the transport
-		c = client.NewClient(httpTransport)
+		c = client.NewClient(httpTransport, client.WithClientInfo(clientInfo))
 	}
 
 	// Set up notification handler
@@ -104,10 +113,6 @@ func main() {
 	fmt.Println("Initializing client...")
 	initRequest := mcp.InitializeRequest{}
 	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
-	initRequest.Params.ClientInfo = mcp.Implementation{
-		Name:    "MCP-Go Simple Client Example",
-		Version: "1.0.0",
-	}
 	initRequest.Params.Capabilities = mcp.ClientCapabilities{}
 
 	serverInfo, err := c.Initialize(ctx, initRequest)
@@ -121,33 +126,35 @@ func main() {
 		serverInfo.ServerInfo.Version)
 	fmt.Printf("Server capabilities: %+v\n", serverInfo.Capabilities)
 
-	// List available tools if the server supports them
-	if serverInfo.Capabilities.Tools != nil {
-		fmt.Println("Fetching available tools...")
-		toolsRequest := mcp.ListToolsRequest{}
-		toolsResult, err := c.ListTools(ctx, toolsRequest)
-		if err != nil {
-			log.Printf("Failed to list tools: %v", err)
-		} else {
-			fmt.Printf("Server has %d tools available\n", len(toolsResult.Tools))
-			for i, tool := range toolsResult.Tools {
-				fmt.Printf("  %d. %s - %s\n", i+1, tool.Name, tool.Description)
-			}
+	// List available tools. The client checks the server capabilities and
+	// fails with ErrCapabilityNotSupported without sending the request if
+	// the server has no tools.
+	fmt.Println("Fetching available tools...")
+	toolsResult, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	switch {
+	case errors.Is(err, client.ErrCapabilityNotSupported):
+		fmt.Println("Server does not support tools")
+	case err != nil:
+		log.Printf("Failed to list tools: %v", err)
+	default:
+		fmt.Printf("Server has %d tools available\n", len(toolsResult.Tools))
+		for i, tool := range toolsResult.Tools {
+			fmt.Printf("  %d. %s - %s\n", i+1, tool.Name, tool.Description)
 		}
 	}
 
-	// List available resources if the server supports them
-	if serverInfo.Capabilities.Resources != nil {
-		fmt.Println("Fetching available resources...")
-		resourcesRequest := mcp.ListResourcesRequest{}
-		resourcesResult, err := c.ListResources(ctx, resourcesRequest)
-		if err != nil {
-			log.Printf("Failed to list resources: %v", err)
-		} else {
-			fmt.Printf("Server has %d resources available\n", len(resourcesResult.Resources))
-			for i, resource := range resourcesResult.Resources {
-				fmt.Printf("  %d. %s - %s\n", i+1, resource.URI, resource.Name)
-			}
+	// List available resources
+	fmt.Println("Fetching available resources...")
+	resourcesResult, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+	switch {
+	case errors.Is(err, client.ErrCapabilityNotSupported):
+		fmt.Println("Server does not support resources")
+	case err != nil:
+		log.Printf("Failed to list resources: %v", err)
+	default:
+		fmt.Printf("Server has %d resources available\n", len(resourcesResult.Resources))
+		for i, resource := range resourcesResult.Resources {
+			fmt.Printf("  %d. %s - %s\n", i+1, resource.URI, resource.Name)
 		}
 	}
 
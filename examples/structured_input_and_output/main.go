@@ -51,6 +51,7 @@ func main() {
 		"Structured Input/Output Example",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithOutputValidation(),
 	)
 
 	// Example 1: Auto-generated schema from struct
@@ -8,16 +8,17 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// Define a struct for our typed arguments
+// Define a struct for our typed arguments. The tool's input schema is
+// generated from it, so the field tags double as the schema definition.
 type GreetingArgs struct {
-	Name      string   `json:"name"`
-	Age       int      `json:"age"`
-	IsVIP     bool     `json:"is_vip"`
-	Languages []string `json:"languages"`
-	Metadata  struct {
-		Location string `json:"location"`
-		Timezone string `json:"timezone"`
-	} `json:"metadata"`
+	Name      string   `json:"name" jsonschema_description:"Name of the person to greet"`
+	Age       int      `json:"age,omitempty" jsonschema_description:"Age of the person" jsonschema:"minimum=0,maximum=150"`
+	IsVIP     bool     `json:"is_vip,omitempty" jsonschema_description:"Whether the person is a VIP" jsonschema:"default=false"`
+	Languages []string `json:"languages,omitempty" jsonschema_description:"Languages the person speaks"`
+	Metadata  *struct {
+		Location string `json:"location,omitempty" jsonschema_description:"Current location"`
+		Timezone string `json:"timezone,omitempty" jsonschema_description:"Timezone"`
+	} `json:"metadata" jsonschema_description:"Additional information about the person"`
 }
 
 func main() {
@@ -28,39 +29,10 @@ func main() {
 		server.WithToolCapabilities(false),
 	)
 
-	// Add tool with complex schema
+	// Add tool with a schema derived from GreetingArgs
 	tool := mcp.NewTool("greeting",
 		mcp.WithDescription("Generate a personalized greeting"),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the person to greet"),
-		),
-		mcp.WithNumber("age",
-			mcp.Description("Age of the person"),
-			mcp.Min(0),
-			mcp.Max(150),
-		),
-		mcp.WithBoolean("is_vip",
-			mcp.Description("Whether the person is a VIP"),
-			mcp.DefaultBool(false),
-		),
-		mcp.WithArray("languages",
-			mcp.Description("Languages the person speaks"),
-			mcp.Items(map[string]any{"type": "string"}),
-		),
-		mcp.WithObject("metadata",
-			mcp.Description("Additional information about the person"),
-			mcp.Properties(map[string]any{
-				"location": map[string]any{
-					"type":        "string",
-					"description": "Current location",
-				},
-				"timezone": map[string]any{
-					"type":        "string",
-					"description": "Timezone",
-				},
-			}),
-		),
+		mcp.WithInputSchema[GreetingArgs](),
 	)
 
 	// Add tool handler using the typed handler
@@ -93,7 +65,7 @@ func typedGreetingHandler(ctx context.Context, request mcp.CallToolRequest, args
 		greeting += fmt.Sprintf(" You speak %d languages: %v.", len(args.Languages), args.Languages)
 	}
 
-	if args.Metadata.Location != "" {
+	if args.Metadata != nil && args.Metadata.Location != "" {
 		greeting += fmt.Sprintf(" I see you're from %s.", args.Metadata.Location)
 
 		if args.Metadata.Timezone != "" {
@@ -7,3 +7,57 @@ const (
 	ContentTypeLink     = "resource_link"
 	ContentTypeResource = "resource"
 )
+
+const (
+	// MetaKeyGeneratedFallback marks text content that was generated
+	// automatically from a tool result's structured content.
+	MetaKeyGeneratedFallback = "mcp-go/generatedFallback"
+	// MetaKeyTruncated marks text content that was cut short to respect a size limit.
+	MetaKeyTruncated = "mcp-go/truncated"
+	// MetaKeyDryRun, when set to true in a tools/call request's _meta, asks the
+	// server to validate the call without executing it.
+	MetaKeyDryRun = "dryRun"
+	// MetaKeyDryRunSupported is set to true in the _meta of an initialize
+	// result by servers that honor MetaKeyDryRun. Other servers ignore the
+	// flag and execute the call.
+	MetaKeyDryRunSupported = "mcp-go/dryRun"
+	// MetaKeyToolError holds the machine-readable error attached to a tool
+	// result by NewToolResultErrorWithDetail.
+	MetaKeyToolError = "error"
+	// MetaKeySampling holds the model and stop reason of the sampling request
+	// a tool result was produced from, as attached by AttachSamplingMeta.
+	MetaKeySampling = "sampling"
+	// MetaKeyOutputSchemaVersion holds an output schema version: in a
+	// tools/call request's _meta the version the client expects, in a tool
+	// result's _meta the version the structured content conforms to.
+	MetaKeyOutputSchemaVersion = "outputSchemaVersion"
+	// MetaKeyArgumentsContentType holds the media type of the arguments of a
+	// tools/call request sent as an encoded string rather than a JSON object.
+	// Servers decode them with the decoder registered for that type.
+	MetaKeyArgumentsContentType = "argumentsContentType"
+	// MetaKeyTimeoutHints holds, in the _meta of an initialize result, the
+	// timeouts the server suggests for slow requests: an object of
+	// milliseconds keyed by method name, or by TimeoutHintKeyForTool for the
+	// calls of a single tool.
+	MetaKeyTimeoutHints = "mcp-go/timeoutHints"
+)
+
+// StdioSessionField is the top-level JSON field that identifies the logical
+// session a message belongs to when several sessions are multiplexed over one
+// pair of stdio streams. Messages without it belong to the default session.
+const StdioSessionField = "mcpSession"
+
+// Recommended codes for NewToolResultErrorWithDetail. Tools may use any other
+// string; clients should treat unknown codes like ToolErrorInternal.
+const (
+	// ToolErrorInvalidArguments means the call may succeed if retried with
+	// corrected arguments.
+	ToolErrorInvalidArguments = "invalid_arguments"
+	// ToolErrorNotFound means the entity the call refers to does not exist.
+	ToolErrorNotFound = "not_found"
+	// ToolErrorUnavailable means a dependency is temporarily unavailable and
+	// the call may succeed if retried later.
+	ToolErrorUnavailable = "unavailable"
+	// ToolErrorInternal means the tool failed unexpectedly.
+	ToolErrorInternal = "internal"
+)
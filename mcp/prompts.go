@@ -51,6 +51,11 @@ type Prompt struct {
 	Meta *Meta `json:"_meta,omitempty"`
 	// The name of the prompt or prompt template.
 	Name string `json:"name"`
+	// A display title for the prompt, preferred over Name by clients that
+	// render it.
+	Title string `json:"title,omitempty"`
+	// Icons clients can display for the prompt.
+	Icons []Icon `json:"icons,omitempty"`
 	// An optional description of what this prompt provides
 	Description string `json:"description,omitempty"`
 	// A list of arguments to use for templating the prompt.
@@ -63,6 +68,15 @@ func (p Prompt) GetName() string {
 	return p.Name
 }
 
+// ForProtocolVersion returns the prompt as it is sent to clients that
+// negotiated the protocol version, without a title if they don't know it.
+func (p Prompt) ForProtocolVersion(version string) Prompt {
+	if !SupportsTitles(version) {
+		p.Title = ""
+	}
+	return p
+}
+
 // PromptArgument describes an argument that a prompt template can accept.
 // When a prompt includes arguments, clients must provide values for all
 // required arguments when making a prompts/get request.
@@ -136,6 +150,28 @@ func WithPromptDescription(description string) PromptOption {
 	}
 }
 
+// WithPromptTitle sets a human-friendly title for the Prompt, shown by
+// clients instead of its name.
+func WithPromptTitle(title string) PromptOption {
+	return func(p *Prompt) {
+		p.Title = title
+	}
+}
+
+// WithPromptIcons adds icons clients can display for the Prompt.
+func WithPromptIcons(icons ...Icon) PromptOption {
+	return func(p *Prompt) {
+		p.Icons = append(p.Icons, icons...)
+	}
+}
+
+// WithPromptMeta merges fields into the Prompt's _meta.
+func WithPromptMeta(fields map[string]any) PromptOption {
+	return func(p *Prompt) {
+		p.Meta = withMetaFields(p.Meta, fields)
+	}
+}
+
 // WithArgument adds an argument to the prompt's argument list.
 // The argument will be configured based on the provided options.
 func WithArgument(name string, opts ...ArgumentOption) PromptOption {
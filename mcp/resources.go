@@ -30,6 +30,28 @@ func WithResourceDescription(description string) ResourceOption {
 	}
 }
 
+// WithResourceTitle sets a human-friendly title for the Resource, shown by
+// clients instead of its name.
+func WithResourceTitle(title string) ResourceOption {
+	return func(r *Resource) {
+		r.Title = title
+	}
+}
+
+// WithResourceIcons adds icons clients can display for the Resource.
+func WithResourceIcons(icons ...Icon) ResourceOption {
+	return func(r *Resource) {
+		r.Icons = append(r.Icons, icons...)
+	}
+}
+
+// WithResourceMeta merges fields into the Resource's _meta.
+func WithResourceMeta(fields map[string]any) ResourceOption {
+	return func(r *Resource) {
+		r.Meta = withMetaFields(r.Meta, fields)
+	}
+}
+
 // WithMIMEType sets the MIME type for the Resource.
 // This should indicate the format of the resource's contents.
 func WithMIMEType(mimeType string) ResourceOption {
@@ -78,6 +100,28 @@ func WithTemplateDescription(description string) ResourceTemplateOption {
 	}
 }
 
+// WithTemplateTitle sets a human-friendly title for the ResourceTemplate,
+// shown by clients instead of its name.
+func WithTemplateTitle(title string) ResourceTemplateOption {
+	return func(t *ResourceTemplate) {
+		t.Title = title
+	}
+}
+
+// WithTemplateIcons adds icons clients can display for the ResourceTemplate.
+func WithTemplateIcons(icons ...Icon) ResourceTemplateOption {
+	return func(t *ResourceTemplate) {
+		t.Icons = append(t.Icons, icons...)
+	}
+}
+
+// WithTemplateMeta merges fields into the ResourceTemplate's _meta.
+func WithTemplateMeta(fields map[string]any) ResourceTemplateOption {
+	return func(t *ResourceTemplate) {
+		t.Meta = withMetaFields(t.Meta, fields)
+	}
+}
+
 // WithTemplateMIMEType sets the MIME type for the ResourceTemplate.
 // This should only be set if all resources matching this template will have the same type.
 func WithTemplateMIMEType(mimeType string) ResourceTemplateOption {
@@ -0,0 +1,51 @@
+package mcp
+
+// ModelPreferencesOption is a function that configures ModelPreferences.
+type ModelPreferencesOption func(*ModelPreferences)
+
+// NewModelPreferences creates ModelPreferences configured by opts, for use as
+// the ModelPreferences of a CreateMessageRequest:
+//
+//	request.ModelPreferences = mcp.NewModelPreferences(
+//		mcp.WithModelHint("claude-3-5-sonnet"),
+//		mcp.WithModelHint("sonnet"),
+//		mcp.WithIntelligencePriority(0.8),
+//	)
+func NewModelPreferences(opts ...ModelPreferencesOption) *ModelPreferences {
+	prefs := &ModelPreferences{}
+	for _, opt := range opts {
+		opt(prefs)
+	}
+	return prefs
+}
+
+// WithModelHint appends a hint for a model name. Clients evaluate hints in
+// the order they were added and take the first match.
+func WithModelHint(name string) ModelPreferencesOption {
+	return func(p *ModelPreferences) {
+		p.Hints = append(p.Hints, ModelHint{Name: name})
+	}
+}
+
+// WithCostPriority sets how much to prioritize cost, from 0 to 1. A priority
+// of 0 is not sent, as it is indistinguishable from leaving it unset.
+func WithCostPriority(priority float64) ModelPreferencesOption {
+	return func(p *ModelPreferences) {
+		p.CostPriority = priority
+	}
+}
+
+// WithSpeedPriority sets how much to prioritize sampling speed, from 0 to 1.
+func WithSpeedPriority(priority float64) ModelPreferencesOption {
+	return func(p *ModelPreferences) {
+		p.SpeedPriority = priority
+	}
+}
+
+// WithIntelligencePriority sets how much to prioritize intelligence and
+// capabilities, from 0 to 1.
+func WithIntelligencePriority(priority float64) ModelPreferencesOption {
+	return func(p *ModelPreferences) {
+		p.IntelligencePriority = priority
+	}
+}
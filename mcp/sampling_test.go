@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModelPreferences(t *testing.T) {
+	prefs := NewModelPreferences(
+		WithModelHint("claude-3-5-sonnet"),
+		WithModelHint("sonnet"),
+		WithCostPriority(0.3),
+		WithSpeedPriority(0.5),
+		WithIntelligencePriority(0.8),
+	)
+	assert.Equal(t, &ModelPreferences{
+		Hints:                []ModelHint{{Name: "claude-3-5-sonnet"}, {Name: "sonnet"}},
+		CostPriority:         0.3,
+		SpeedPriority:        0.5,
+		IntelligencePriority: 0.8,
+	}, prefs)
+
+	request := CreateMessageRequest{
+		CreateMessageParams: CreateMessageParams{
+			MaxTokens:        10,
+			ModelPreferences: prefs,
+		},
+	}
+	data, err := json.Marshal(request.CreateMessageParams)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"messages": null,
+		"maxTokens": 10,
+		"modelPreferences": {
+			"hints": [{"name": "claude-3-5-sonnet"}, {"name": "sonnet"}],
+			"costPriority": 0.3,
+			"speedPriority": 0.5,
+			"intelligencePriority": 0.8
+		}
+	}`, string(data))
+
+	var decoded CreateMessageParams
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, prefs, decoded.ModelPreferences)
+}
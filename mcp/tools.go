@@ -1,18 +1,29 @@
 package mcp
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"net/http"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/invopop/jsonschema"
 )
 
 var errToolSchemaConflict = errors.New("provide either InputSchema or RawInputSchema, not both")
 
+// DefaultMaxBlobSize is the maximum number of decoded bytes accepted by
+// CallToolRequest.GetBlob. Use GetBlobWithLimit for a different limit.
+const DefaultMaxBlobSize = 10 * 1024 * 1024
+
 // ListToolsRequest is sent from the client to request a list of tools the
 // server has.
 type ListToolsRequest struct {
@@ -47,9 +58,149 @@ type CallToolResult struct {
 	// Whether the tool call ended in an error.
 	//
 	// If not set, this is assumed to be false (the call was successful).
+	// An error result may still carry meaningful content, for example the
+	// items a partially successful call did process; see
+	// NewToolResultPartial.
 	IsError bool `json:"isError,omitempty"`
 }
 
+// GetErrorDetail returns the machine-readable error attached with
+// NewToolResultErrorWithDetail. ok is false if the result carries no such
+// error. detail is nil if the error has no detail payload.
+func (r *CallToolResult) GetErrorDetail() (code string, detail json.RawMessage, ok bool) {
+	value := r.GetMeta(MetaKeyToolError)
+	if value == nil {
+		return "", nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", nil, false
+	}
+	var payload struct {
+		Code   string          `json:"code"`
+		Detail json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Code == "" {
+		return "", nil, false
+	}
+	return payload.Code, payload.Detail, true
+}
+
+// GetSamplingMeta returns the sampling metadata attached with
+// AttachSamplingMeta, if any.
+func (r *CallToolResult) GetSamplingMeta() (SamplingMeta, bool) {
+	value := r.GetMeta(MetaKeySampling)
+	if value == nil {
+		return SamplingMeta{}, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return SamplingMeta{}, false
+	}
+	var meta SamplingMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Model == "" {
+		return SamplingMeta{}, false
+	}
+	return meta, true
+}
+
+// OutputSchemaVersion returns the version of the tool's output schema the
+// structured content conforms to, as recorded in the result's _meta. It
+// returns an empty string if the result does not say.
+func (r *CallToolResult) OutputSchemaVersion() string {
+	version, _ := r.GetMeta(MetaKeyOutputSchemaVersion).(string)
+	return version
+}
+
+// StructuredResultError is returned by UnmarshalStructuredResult when the
+// structured content of a tool result cannot be decoded into the requested
+// type, which usually means that client and server disagree on the version of
+// the tool's output schema.
+type StructuredResultError struct {
+	// Tool is the name of the tool, if known.
+	Tool string
+	// SchemaVersion is the output schema version the tool declares, if known.
+	SchemaVersion string
+	// ResultSchemaVersion is the version the result says it conforms to.
+	ResultSchemaVersion string
+	// Schema is the output schema the tool declares, if known.
+	Schema json.RawMessage
+	// Err is the decoding error.
+	Err error
+}
+
+func (e *StructuredResultError) Error() string {
+	var b strings.Builder
+	b.WriteString("failed to decode structured result")
+	if e.Tool != "" {
+		fmt.Fprintf(&b, " of tool %q", e.Tool)
+	}
+	if e.ResultSchemaVersion != "" {
+		fmt.Fprintf(&b, " (result schema version %q)", e.ResultSchemaVersion)
+	}
+	if e.SchemaVersion != "" && e.SchemaVersion != e.ResultSchemaVersion {
+		fmt.Fprintf(&b, " (declared schema version %q)", e.SchemaVersion)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if len(e.Schema) > 0 {
+		fmt.Fprintf(&b, "; declared output schema: %s", e.Schema)
+	}
+	return b.String()
+}
+
+func (e *StructuredResultError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalStructuredResult decodes the structured content of result into v
+// following the rules of json.Unmarshal. A failure is reported as a
+// *StructuredResultError.
+func UnmarshalStructuredResult(result *CallToolResult, v any) error {
+	return unmarshalStructuredResult(result, nil, v)
+}
+
+// UnmarshalStructuredResult is like the package-level function of the same
+// name, but a failure also reports the output schema and version the tool
+// declares.
+func (t Tool) UnmarshalStructuredResult(result *CallToolResult, v any) error {
+	return unmarshalStructuredResult(result, &t, v)
+}
+
+func unmarshalStructuredResult(result *CallToolResult, tool *Tool, v any) error {
+	fail := func(err error) error {
+		structuredErr := &StructuredResultError{
+			ResultSchemaVersion: result.OutputSchemaVersion(),
+			Err:                 err,
+		}
+		if tool != nil {
+			structuredErr.Tool = tool.Name
+			structuredErr.SchemaVersion = tool.OutputSchemaVersion()
+			structuredErr.Schema = tool.RawOutputSchema
+		}
+		return structuredErr
+	}
+
+	if result == nil || result.StructuredContent == nil {
+		return fail(errors.New("result has no structured content"))
+	}
+
+	var data []byte
+	switch content := result.StructuredContent.(type) {
+	case json.RawMessage:
+		data = content
+	default:
+		var err error
+		if data, err = json.Marshal(content); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
 // CallToolRequest is used by the client to invoke a tool provided by the server.
 type CallToolRequest struct {
 	Request
@@ -63,6 +214,90 @@ type CallToolParams struct {
 	Meta      *Meta  `json:"_meta,omitempty"`
 }
 
+// DryRunReport is the structured content of the result of a dry run
+// without a dry run handler, describing whether the call would be accepted.
+type DryRunReport struct {
+	// Tool is the name of the tool.
+	Tool string `json:"tool"`
+	// Valid is true if the arguments passed validation.
+	Valid bool `json:"valid"`
+	// Errors lists the validation problems found.
+	Errors []string `json:"errors,omitempty"`
+	// Annotations are the tool's annotations, such as whether it is
+	// destructive.
+	Annotations ToolAnnotation `json:"annotations"`
+}
+
+// GetMeta returns the _meta value stored under key, or nil if it is not set.
+func (r CallToolRequest) GetMeta(key string) any {
+	return r.Params.Meta.Get(key)
+}
+
+// SetMeta stores value under key in the request's _meta, creating it if needed.
+func (r *CallToolRequest) SetMeta(key string, value any) {
+	if r.Params.Meta == nil {
+		r.Params.Meta = &Meta{}
+	}
+	r.Params.Meta.Set(key, value)
+}
+
+// OutputSchemaVersion returns the output schema version the client asked the
+// tool to produce, or an empty string if it did not ask for one. Tools that
+// support several versions can use it to pick the shape of their result.
+func (r CallToolRequest) OutputSchemaVersion() string {
+	version, _ := r.GetMeta(MetaKeyOutputSchemaVersion).(string)
+	return version
+}
+
+// NewCallToolRequest creates a tools/call request for the named tool. args may
+// be a map, a struct, json.RawMessage or any other JSON-encodable value; it is
+// normalized to the representation the server decodes from the wire, so that
+// GetArguments, the typed getters and BindArguments behave the same whether the
+// request is handled in-process or after a round trip through a transport.
+// Objects become map[string]any with numbers as json.Number. Values that cannot
+// be encoded are kept as given.
+func NewCallToolRequest(name string, args any) CallToolRequest {
+	return CallToolRequest{
+		Request: Request{
+			Method: string(MethodToolsCall),
+		},
+		Params: CallToolParams{
+			Name:      name,
+			Arguments: normalizeArguments(args),
+		},
+	}
+}
+
+// normalizeArguments round-trips args through JSON, decoding numbers as
+// json.Number like the server does.
+func normalizeArguments(args any) any {
+	if args == nil {
+		return nil
+	}
+	var data []byte
+	switch v := args.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		if !json.Valid(v) {
+			return args
+		}
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(args); err != nil {
+			return args
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized any
+	if err := decoder.Decode(&normalized); err != nil {
+		return args
+	}
+	return normalized
+}
+
 // GetArguments returns the Arguments as map[string]any for backward compatibility
 // If Arguments is not a map, it returns an empty map
 func (r CallToolRequest) GetArguments() map[string]any {
@@ -130,6 +365,10 @@ func (r CallToolRequest) GetInt(key string, defaultValue int) int {
 			return v
 		case float64:
 			return int(v)
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return int(f)
+			}
 		case string:
 			if i, err := strconv.Atoi(v); err == nil {
 				return i
@@ -148,6 +387,11 @@ func (r CallToolRequest) RequireInt(key string) (int, error) {
 			return v, nil
 		case float64:
 			return int(v), nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return int(f), nil
+			}
+			return 0, fmt.Errorf("argument %q cannot be converted to int", key)
 		case string:
 			if i, err := strconv.Atoi(v); err == nil {
 				return i, nil
@@ -169,6 +413,10 @@ func (r CallToolRequest) GetFloat(key string, defaultValue float64) float64 {
 			return v
 		case int:
 			return float64(v)
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
 		case string:
 			if f, err := strconv.ParseFloat(v, 64); err == nil {
 				return f
@@ -187,6 +435,11 @@ func (r CallToolRequest) RequireFloat(key string) (float64, error) {
 			return v, nil
 		case int:
 			return float64(v), nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f, nil
+			}
+			return 0, fmt.Errorf("argument %q cannot be converted to float64", key)
 		case string:
 			if f, err := strconv.ParseFloat(v, 64); err == nil {
 				return f, nil
@@ -214,6 +467,10 @@ func (r CallToolRequest) GetBool(key string, defaultValue bool) bool {
 			return v != 0
 		case float64:
 			return v != 0
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f != 0
+			}
 		}
 	}
 	return defaultValue
@@ -235,6 +492,11 @@ func (r CallToolRequest) RequireBool(key string) (bool, error) {
 			return v != 0, nil
 		case float64:
 			return v != 0, nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f != 0, nil
+			}
+			return false, fmt.Errorf("argument %q cannot be converted to bool", key)
 		default:
 			return false, fmt.Errorf("argument %q is not a bool", key)
 		}
@@ -301,6 +563,10 @@ func (r CallToolRequest) GetIntSlice(key string, defaultValue []int) []int {
 					result = append(result, num)
 				case float64:
 					result = append(result, int(num))
+				case json.Number:
+					if f, err := num.Float64(); err == nil {
+						result = append(result, int(f))
+					}
 				case string:
 					if i, err := strconv.Atoi(num); err == nil {
 						result = append(result, i)
@@ -328,6 +594,12 @@ func (r CallToolRequest) RequireIntSlice(key string) ([]int, error) {
 					result = append(result, num)
 				case float64:
 					result = append(result, int(num))
+				case json.Number:
+					if f, err := num.Float64(); err == nil {
+						result = append(result, int(f))
+					} else {
+						return nil, fmt.Errorf("item %d in argument %q cannot be converted to int", i, key)
+					}
 				case string:
 					if i, err := strconv.Atoi(num); err == nil {
 						result = append(result, i)
@@ -361,6 +633,10 @@ func (r CallToolRequest) GetFloatSlice(key string, defaultValue []float64) []flo
 					result = append(result, num)
 				case int:
 					result = append(result, float64(num))
+				case json.Number:
+					if f, err := num.Float64(); err == nil {
+						result = append(result, f)
+					}
 				case string:
 					if f, err := strconv.ParseFloat(num, 64); err == nil {
 						result = append(result, f)
@@ -388,6 +664,12 @@ func (r CallToolRequest) RequireFloatSlice(key string) ([]float64, error) {
 					result = append(result, num)
 				case int:
 					result = append(result, float64(num))
+				case json.Number:
+					if f, err := num.Float64(); err == nil {
+						result = append(result, f)
+					} else {
+						return nil, fmt.Errorf("item %d in argument %q cannot be converted to float64", i, key)
+					}
 				case string:
 					if f, err := strconv.ParseFloat(num, 64); err == nil {
 						result = append(result, f)
@@ -470,6 +752,55 @@ func (r CallToolRequest) RequireBoolSlice(key string) ([]bool, error) {
 	return nil, fmt.Errorf("required argument %q not found", key)
 }
 
+// GetBlob returns the decoded bytes of a binary argument declared with WithBlob.
+// The argument may be a base64 string, or an object carrying the base64 data in
+// its "blob" field, like BlobResourceContents. Blobs larger than
+// DefaultMaxBlobSize are rejected.
+func (r CallToolRequest) GetBlob(key string) ([]byte, error) {
+	return r.GetBlobWithLimit(key, DefaultMaxBlobSize)
+}
+
+// GetBlobWithLimit is like GetBlob but rejects blobs larger than maxBytes
+// decoded bytes. A maxBytes of zero or less means no limit.
+func (r CallToolRequest) GetBlobWithLimit(key string, maxBytes int) ([]byte, error) {
+	args := r.GetArguments()
+	val, ok := args[key]
+	if !ok {
+		return nil, fmt.Errorf("required argument %q not found", key)
+	}
+
+	var encoded string
+	switch v := val.(type) {
+	case string:
+		encoded = v
+	case map[string]any:
+		blob, ok := v["blob"].(string)
+		if !ok {
+			if _, isRef := v["uri"]; isRef {
+				return nil, fmt.Errorf("argument %q is a resource reference without inline blob data", key)
+			}
+			return nil, fmt.Errorf("argument %q has no blob field", key)
+		}
+		encoded = blob
+	default:
+		return nil, fmt.Errorf("argument %q is not a blob", key)
+	}
+
+	// Check the size before decoding to avoid allocating oversized buffers
+	if maxBytes > 0 && len(encoded) > base64.StdEncoding.EncodedLen(maxBytes) {
+		return nil, fmt.Errorf("argument %q exceeds the maximum blob size of %d bytes", key, maxBytes)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("argument %q is not valid base64: %w", key, err)
+	}
+	if maxBytes > 0 && len(data) > maxBytes {
+		return nil, fmt.Errorf("argument %q exceeds the maximum blob size of %d bytes", key, maxBytes)
+	}
+	return data, nil
+}
+
 // MarshalJSON implements custom JSON marshaling for CallToolResult
 func (r CallToolResult) MarshalJSON() ([]byte, error) {
 	m := make(map[string]any)
@@ -559,6 +890,11 @@ type Tool struct {
 	Meta *Meta `json:"_meta,omitempty"`
 	// The name of the tool.
 	Name string `json:"name"`
+	// A display title for the tool, preferred over Name and
+	// Annotations.Title by clients that render it.
+	Title string `json:"title,omitempty"`
+	// Icons clients can display for the tool.
+	Icons []Icon `json:"icons,omitempty"`
 	// A human-readable description of the tool.
 	Description string `json:"description,omitempty"`
 	// A JSON Schema object defining the expected parameters for the tool.
@@ -576,6 +912,85 @@ func (t Tool) GetName() string {
 	return t.Name
 }
 
+// DisplayTitle returns the title to show for the tool: its Title, else the
+// title of its annotations, else its name.
+func (t Tool) DisplayTitle() string {
+	switch {
+	case t.Title != "":
+		return t.Title
+	case t.Annotations.Title != "":
+		return t.Annotations.Title
+	default:
+		return t.Name
+	}
+}
+
+// ForProtocolVersion returns the tool as it is sent to clients that
+// negotiated the protocol version. Clients that don't know the top-level
+// title get it as the title of the annotations instead, unless that is set.
+func (t Tool) ForProtocolVersion(version string) Tool {
+	if SupportsTitles(version) || t.Title == "" {
+		return t
+	}
+	if t.Annotations.Title == "" {
+		t.Annotations.Title = t.Title
+	}
+	t.Title = ""
+	return t
+}
+
+// IsReadOnly reports whether the tool is annotated as not modifying its
+// environment. Tools without a ReadOnlyHint are assumed to modify it.
+func (t Tool) IsReadOnly() bool {
+	return hintOr(t.Annotations.ReadOnlyHint, false)
+}
+
+// IsDestructive reports whether the tool may perform destructive updates.
+// Read-only tools are never destructive; other tools without a
+// DestructiveHint are assumed to be.
+func (t Tool) IsDestructive() bool {
+	return !t.IsReadOnly() && hintOr(t.Annotations.DestructiveHint, true)
+}
+
+// IsIdempotent reports whether repeated calls with the same arguments are
+// annotated as having no additional effect. Tools without an IdempotentHint
+// are assumed not to be idempotent.
+func (t Tool) IsIdempotent() bool {
+	return hintOr(t.Annotations.IdempotentHint, false)
+}
+
+// IsOpenWorld reports whether the tool may interact with external entities.
+// Tools without an OpenWorldHint are assumed to.
+func (t Tool) IsOpenWorld() bool {
+	return hintOr(t.Annotations.OpenWorldHint, true)
+}
+
+func hintOr(hint *bool, fallback bool) bool {
+	if hint == nil {
+		return fallback
+	}
+	return *hint
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Tool. The
+// output schema, which MarshalJSON writes from RawOutputSchema, is read back
+// into RawOutputSchema.
+func (t *Tool) UnmarshalJSON(data []byte) error {
+	type toolAlias Tool
+	var raw struct {
+		toolAlias
+		OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = Tool(raw.toolAlias)
+	if len(raw.OutputSchema) > 0 && string(raw.OutputSchema) != "null" {
+		t.RawOutputSchema = raw.OutputSchema
+	}
+	return nil
+}
+
 // MarshalJSON implements the json.Marshaler interface for Tool.
 // It handles marshaling either InputSchema or RawInputSchema based on which is set.
 func (t Tool) MarshalJSON() ([]byte, error) {
@@ -584,9 +999,18 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 
 	// Add the name and description
 	m["name"] = t.Name
+	if t.Title != "" {
+		m["title"] = t.Title
+	}
+	if len(t.Icons) > 0 {
+		m["icons"] = t.Icons
+	}
 	if t.Description != "" {
 		m["description"] = t.Description
 	}
+	if t.Meta != nil {
+		m["_meta"] = t.Meta
+	}
 
 	// Determine which input schema to use
 	if t.RawInputSchema != nil {
@@ -637,6 +1061,297 @@ func (tis ToolInputSchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
+// ApplyArgumentDefaults sets every argument omitted from args to the default
+// declared in the tool's input schema. Properties of object arguments are
+// filled one level deep when the object itself was provided. Values present in
+// args, including explicit nulls, are never overwritten. args is not modified;
+// a copy is returned if any default was applied.
+//
+// Numeric defaults are set as json.Number, the representation the server
+// decodes arguments with, so a handler sees the same type for a number whether
+// the client sent it or it was filled in from the schema.
+func (t Tool) ApplyArgumentDefaults(args map[string]any) map[string]any {
+	properties := t.inputSchemaProperties()
+	if len(properties) == 0 {
+		return args
+	}
+	return applyPropertyDefaults(properties, args, true)
+}
+
+// ValidateArguments checks args against the tool's input schema. Required
+// arguments must be present, and each argument must have the type declared
+// for its property and be one of the property's enum values, if any. Nested
+// schemas are not checked. All problems found are joined with errors.Join.
+func (t Tool) ValidateArguments(args map[string]any) error {
+	schema := t.InputSchema
+	if t.RawInputSchema != nil {
+		schema = ToolInputSchema{}
+		if err := json.Unmarshal(t.RawInputSchema, &schema); err != nil {
+			return fmt.Errorf("invalid input schema for tool '%s': %w", t.Name, err)
+		}
+	}
+
+	var errs []error
+	for _, name := range schema.Required {
+		if _, ok := args[name]; !ok {
+			errs = append(errs, fmt.Errorf("required argument %q not found", name))
+		}
+	}
+	names := slices.Sorted(maps.Keys(args))
+	for _, name := range names {
+		property, ok := schema.Properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		value := args[name]
+		if types := schemaTypes(property["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(typ string) bool {
+			return matchesSchemaType(typ, value)
+		}) {
+			errs = append(errs, fmt.Errorf("argument %q must be of type %s", name, strings.Join(types, " or ")))
+			continue
+		}
+		if enum, ok := schemaEnum(property["enum"]); ok && !slices.ContainsFunc(enum, func(allowed any) bool {
+			return jsonEqual(allowed, value)
+		}) {
+			errs = append(errs, fmt.Errorf("argument %q must be one of %v", name, enum))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateStructuredResult checks the structured content of result against
+// the tool's output schema. The top-level value may be an object or, for
+// tools declared with a slice type such as WithOutputSchema[[]Asset], an
+// array whose items are checked against the schema's "items". At every level
+// the value must have the declared type and be one of the enum values, if
+// any, and objects must contain their required properties. Results without
+// an output schema and error results are not checked. All problems found are
+// joined with errors.Join.
+func (t Tool) ValidateStructuredResult(result *CallToolResult) error {
+	if len(t.RawOutputSchema) == 0 || result == nil || result.IsError {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(t.RawOutputSchema, &schema); err != nil {
+		return fmt.Errorf("invalid output schema for tool '%s': %w", t.Name, err)
+	}
+	if result.StructuredContent == nil {
+		return errors.New("result has no structured content")
+	}
+
+	// Decode the content the way a client would see it on the wire
+	data, ok := result.StructuredContent.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(result.StructuredContent); err != nil {
+			return fmt.Errorf("invalid structured content: %w", err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var content any
+	if err := decoder.Decode(&content); err != nil {
+		return fmt.Errorf("invalid structured content: %w", err)
+	}
+	return errors.Join(validateSchemaValue(schema, content, "structured content")...)
+}
+
+// validateSchemaValue checks value against schema, recursing into object
+// properties and array items. path names value in error messages.
+func validateSchemaValue(schema map[string]any, value any, path string) []error {
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(typ string) bool {
+		return matchesSchemaType(typ, value)
+	}) {
+		return []error{fmt.Errorf("%s must be of type %s", path, strings.Join(types, " or "))}
+	}
+	if enum, ok := schemaEnum(schema["enum"]); ok && !slices.ContainsFunc(enum, func(allowed any) bool {
+		return jsonEqual(allowed, value)
+	}) {
+		return []error{fmt.Errorf("%s must be one of %v", path, enum)}
+	}
+
+	var errs []error
+	switch value := value.(type) {
+	case map[string]any:
+		required, _ := schemaEnum(schema["required"])
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := value[name]; !ok {
+					errs = append(errs, fmt.Errorf("%s: required property %q not found", path, name))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(value)) {
+			if property, ok := properties[name].(map[string]any); ok {
+				errs = append(errs, validateSchemaValue(property, value[name], fmt.Sprintf("%s.%s", path, name))...)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				errs = append(errs, validateSchemaValue(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+// schemaTypes returns the types allowed by a JSON Schema "type" keyword.
+func schemaTypes(typ any) []string {
+	switch v := typ.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		types := make([]string, 0, len(v))
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaEnum returns the values of a JSON Schema "enum" keyword, which is a
+// []string when set with Enum and a []any when decoded from JSON.
+func schemaEnum(enum any) ([]any, bool) {
+	v := reflect.ValueOf(enum)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// matchesSchemaType reports whether the decoded JSON value has the JSON
+// Schema type typ.
+func matchesSchemaType(typ string, value any) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		return value != nil && reflect.TypeOf(value).Kind() == reflect.Slice
+	case "null":
+		return value == nil
+	case "number", "integer":
+		var f float64
+		switch v := value.(type) {
+		case json.Number:
+			var err error
+			if f, err = v.Float64(); err != nil {
+				return false
+			}
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		default:
+			return false
+		}
+		return typ == "number" || f == math.Trunc(f)
+	}
+	return true
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding.
+func jsonEqual(a, b any) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// inputSchemaProperties returns the properties of the tool's input schema,
+// decoding RawInputSchema if it is set.
+func (t Tool) inputSchemaProperties() map[string]any {
+	if t.RawInputSchema == nil {
+		return t.InputSchema.Properties
+	}
+	var schema ToolInputSchema
+	if err := json.Unmarshal(t.RawInputSchema, &schema); err != nil {
+		return nil
+	}
+	return schema.Properties
+}
+
+// defaultArgumentValue returns a copy of a schema default as it would be
+// decoded from a request, with numbers as json.Number.
+func defaultArgumentValue(def any) any {
+	data, err := json.Marshal(def)
+	if err != nil {
+		return def
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return def
+	}
+	return value
+}
+
+func applyPropertyDefaults(properties map[string]any, args map[string]any, nested bool) map[string]any {
+	var result map[string]any
+	ensureCopy := func() {
+		if result == nil {
+			result = make(map[string]any, len(args)+len(properties))
+			for k, v := range args {
+				result[k] = v
+			}
+		}
+	}
+
+	for name, raw := range properties {
+		property, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		value, present := args[name]
+		if !present {
+			if def, ok := property["default"]; ok {
+				ensureCopy()
+				result[name] = defaultArgumentValue(def)
+			}
+			continue
+		}
+		if !nested {
+			continue
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		subProperties, ok := property["properties"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if filled := applyPropertyDefaults(subProperties, object, false); len(filled) != len(object) {
+			ensureCopy()
+			result[name] = filled
+		}
+	}
+
+	if result == nil {
+		return args
+	}
+	return result
+}
+
 type ToolAnnotation struct {
 	// Human-readable title for the tool
 	Title string `json:"title,omitempty"`
@@ -716,18 +1431,18 @@ func WithDescription(description string) ToolOption {
 
 // WithInputSchema creates a ToolOption that sets the input schema for a tool.
 // It accepts any Go type, usually a struct, and automatically generates a JSON schema from it.
+//
+// Property names follow the `json` tags and descriptions come from
+// `jsonschema_description` (or `jsonschema:"description=..."`) tags. A field
+// is required unless it is a pointer or tagged omitempty; `jsonschema:"required"`
+// forces a field to be required. Types registered with RegisterSchemaOverride
+// use their registered schema.
 func WithInputSchema[T any]() ToolOption {
 	return func(t *Tool) {
 		var zero T
 
-		// Generate schema using invopop/jsonschema library
-		// Configure reflector to generate clean, MCP-compatible schemas
-		reflector := jsonschema.Reflector{
-			DoNotReference:            true, // Removes $defs map, outputs entire structure inline
-			Anonymous:                 true, // Hides auto-generated Schema IDs
-			AllowAdditionalProperties: true, // Removes additionalProperties: false
-		}
-		schema := reflector.Reflect(zero)
+		schema := newSchemaReflector().Reflect(zero)
+		relaxPointerRequirements(schema, reflect.TypeOf(zero))
 
 		// Clean up schema for MCP compliance
 		schema.Version = "" // Remove $schema field
@@ -744,6 +1459,139 @@ func WithInputSchema[T any]() ToolOption {
 	}
 }
 
+// schemaOverrides maps the types registered with RegisterSchemaOverride to
+// their schema.
+var schemaOverrides sync.Map // reflect.Type -> json.RawMessage
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// RegisterSchemaOverride makes WithInputSchema and WithOutputSchema describe
+// values of type t with schema instead of the schema reflected from the type,
+// for example to document a custom string encoding of a struct. Pointers to t
+// use the same schema. A nil schema removes the override. It panics if schema
+// is not a valid JSON schema object.
+//
+// Without an override, json.RawMessage is described by the empty schema {},
+// which accepts any value.
+func RegisterSchemaOverride(t reflect.Type, schema json.RawMessage) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if schema == nil {
+		schemaOverrides.Delete(t)
+		return
+	}
+	if _, err := parseSchemaOverride(schema); err != nil {
+		panic(fmt.Sprintf("mcp: invalid schema override for %v: %v", t, err))
+	}
+	schemaOverrides.Store(t, append(json.RawMessage(nil), schema...))
+}
+
+// newSchemaReflector returns the reflector used to generate tool schemas from
+// Go types.
+func newSchemaReflector() *jsonschema.Reflector {
+	// Configure reflector to generate clean, MCP-compatible schemas
+	return &jsonschema.Reflector{
+		DoNotReference:            true, // Removes $defs map, outputs entire structure inline
+		Anonymous:                 true, // Hides auto-generated Schema IDs
+		AllowAdditionalProperties: true, // Removes additionalProperties: false
+		Mapper:                    schemaOverride,
+	}
+}
+
+// schemaOverride returns the schema registered for t, or nil to reflect it.
+func schemaOverride(t reflect.Type) *jsonschema.Schema {
+	raw, ok := schemaOverrides.Load(t)
+	if !ok {
+		if t == rawMessageType {
+			// A non-nil Extras keeps the empty schema from being written as true
+			return &jsonschema.Schema{Extras: map[string]any{}}
+		}
+		return nil
+	}
+	// Parse a fresh copy every time, as the reflector adds field tags to it
+	schema, err := parseSchemaOverride(raw.(json.RawMessage))
+	if err != nil {
+		return nil
+	}
+	return schema
+}
+
+// parseSchemaOverride parses a schema object, keeping the keywords that
+// jsonschema.Schema has no field for in its Extras.
+func parseSchemaOverride(raw json.RawMessage) (*jsonschema.Schema, error) {
+	var keywords map[string]any
+	if err := json.Unmarshal(raw, &keywords); err != nil {
+		return nil, err
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+
+	var known map[string]any
+	if data, err := json.Marshal(&schema); err == nil {
+		_ = json.Unmarshal(data, &known)
+	}
+	// A non-nil Extras also keeps an empty schema from being written as true
+	schema.Extras = map[string]any{}
+	for keyword, value := range keywords {
+		if _, ok := known[keyword]; !ok {
+			schema.Extras[keyword] = value
+		}
+	}
+	return &schema, nil
+}
+
+// relaxPointerRequirements removes pointer fields of typ from the required
+// list of schema, recursing into nested structs, unless they are explicitly
+// tagged `jsonschema:"required"`. The reflector only treats omitempty fields as
+// optional, while a nil pointer is the idiomatic way to express an absent
+// argument.
+func relaxPointerRequirements(schema *jsonschema.Schema, typ reflect.Type) {
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		if typ.Kind() != reflect.Ptr && schema != nil {
+			schema = schema.Items
+		}
+		typ = typ.Elem()
+	}
+	if schema == nil || schema.Properties == nil || typ == nil || typ.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(jsonTag, ",")
+		if field.Anonymous && name == "" {
+			// Embedded struct fields are inlined into the parent schema
+			relaxPointerRequirements(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, ok := schema.Properties.Get(name)
+		if !ok {
+			continue
+		}
+		explicitlyRequired := slices.Contains(strings.Split(field.Tag.Get("jsonschema"), ","), "required")
+		if field.Type.Kind() == reflect.Ptr && !explicitlyRequired {
+			schema.Required = slices.DeleteFunc(schema.Required, func(required string) bool {
+				return required == name
+			})
+		}
+		relaxPointerRequirements(property, field.Type)
+	}
+}
+
 // WithRawInputSchema sets a raw JSON schema for the tool's input.
 // Use this when you need full control over the schema or when working with
 // complex schemas that can't be generated from Go types. The jsonschema library
@@ -757,18 +1605,12 @@ func WithRawInputSchema(schema json.RawMessage) ToolOption {
 
 // WithOutputSchema creates a ToolOption that sets the output schema for a tool.
 // It accepts any Go type, usually a struct, and automatically generates a JSON schema from it.
+// Types registered with RegisterSchemaOverride use their registered schema.
 func WithOutputSchema[T any]() ToolOption {
 	return func(t *Tool) {
 		var zero T
 
-		// Generate schema using invopop/jsonschema library
-		// Configure reflector to generate clean, MCP-compatible schemas
-		reflector := jsonschema.Reflector{
-			DoNotReference:            true, // Removes $defs map, outputs entire structure inline
-			Anonymous:                 true, // Hides auto-generated Schema IDs
-			AllowAdditionalProperties: true, // Removes additionalProperties: false
-		}
-		schema := reflector.Reflect(zero)
+		schema := newSchemaReflector().Reflect(zero)
 
 		// Clean up schema for MCP compliance
 		schema.Version = "" // Remove $schema field
@@ -780,7 +1622,7 @@ func WithOutputSchema[T any]() ToolOption {
 			return
 		}
 
-		t.RawOutputSchema = json.RawMessage(mcpSchema)
+		t.setOutputSchema(json.RawMessage(mcpSchema))
 	}
 }
 
@@ -791,10 +1633,63 @@ func WithOutputSchema[T any]() ToolOption {
 // check that out before using this.
 func WithRawOutputSchema(schema json.RawMessage) ToolOption {
 	return func(t *Tool) {
-		t.RawOutputSchema = schema
+		t.setOutputSchema(schema)
+	}
+}
+
+// outputSchemaVersionKeyword is the output schema keyword holding the schema
+// version set with WithOutputSchemaVersion.
+const outputSchemaVersionKeyword = "version"
+
+// WithOutputSchemaVersion declares the version of the tool's output schema in
+// its "version" keyword, so clients can tell which shape of structured content
+// to expect. It may be given before or after the option setting the schema.
+// Structured results of the tool are marked with the version in their _meta.
+func WithOutputSchemaVersion(version string) ToolOption {
+	return func(t *Tool) {
+		schema := map[string]any{}
+		if len(t.RawOutputSchema) > 0 {
+			if err := json.Unmarshal(t.RawOutputSchema, &schema); err != nil {
+				return
+			}
+		}
+		schema[outputSchemaVersionKeyword] = version
+		if data, err := json.Marshal(schema); err == nil {
+			t.RawOutputSchema = data
+		}
+	}
+}
+
+// setOutputSchema replaces the output schema, keeping a version declared
+// earlier with WithOutputSchemaVersion.
+func (t *Tool) setOutputSchema(schema json.RawMessage) {
+	version := t.OutputSchemaVersion()
+	t.RawOutputSchema = schema
+	if version != "" && t.OutputSchemaVersion() == "" {
+		WithOutputSchemaVersion(version)(t)
 	}
 }
 
+// OutputSchemaVersion returns the version declared in the tool's output
+// schema: its "version" keyword, or else its "$id". It returns an empty string
+// if the tool declares neither.
+func (t Tool) OutputSchemaVersion() string {
+	if len(t.RawOutputSchema) == 0 {
+		return ""
+	}
+	var schema struct {
+		Version string `json:"version"`
+		ID      string `json:"$id"`
+	}
+	if err := json.Unmarshal(t.RawOutputSchema, &schema); err != nil {
+		return ""
+	}
+	if schema.Version != "" {
+		return schema.Version
+	}
+	return schema.ID
+}
+
 // WithToolAnnotation adds optional hints about the Tool.
 func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
 	return func(t *Tool) {
@@ -802,6 +1697,29 @@ func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
 	}
 }
 
+// WithTitle sets a human-friendly title for the Tool, shown by clients
+// instead of its name. It takes precedence over WithTitleAnnotation.
+func WithTitle(title string) ToolOption {
+	return func(t *Tool) {
+		t.Title = title
+	}
+}
+
+// WithIcons adds icons clients can display for the Tool.
+func WithIcons(icons ...Icon) ToolOption {
+	return func(t *Tool) {
+		t.Icons = append(t.Icons, icons...)
+	}
+}
+
+// WithToolMeta merges fields into the Tool's _meta, letting servers pass
+// metadata that has no dedicated field through to clients.
+func WithToolMeta(fields map[string]any) ToolOption {
+	return func(t *Tool) {
+		t.Meta = withMetaFields(t.Meta, fields)
+	}
+}
+
 // WithTitleAnnotation sets the Title field of the Tool's Annotations.
 // It provides a human-readable title for the tool.
 func WithTitleAnnotation(title string) ToolOption {
@@ -1044,6 +1962,32 @@ func WithString(name string, opts ...PropertyOption) ToolOption {
 	}
 }
 
+// WithBlob adds a binary property to the tool schema. Clients send the data as a
+// base64 encoded string, and handlers read it with CallToolRequest.GetBlob.
+// The schema advertises a maximum length matching DefaultMaxBlobSize, which
+// can be overridden with MaxLength.
+func WithBlob(name string, opts ...PropertyOption) ToolOption {
+	return func(t *Tool) {
+		schema := map[string]any{
+			"type":            "string",
+			"contentEncoding": "base64",
+			"maxLength":       base64.StdEncoding.EncodedLen(DefaultMaxBlobSize),
+		}
+
+		for _, opt := range opts {
+			opt(schema)
+		}
+
+		// Remove required from property schema and add to InputSchema.required
+		if required, ok := schema["required"].(bool); ok && required {
+			delete(schema, "required")
+			t.InputSchema.Required = append(t.InputSchema.Required, name)
+		}
+
+		t.InputSchema.Properties[name] = schema
+	}
+}
+
 // WithObject adds an object property to the tool schema.
 // It accepts property options to configure the object property's behavior and constraints.
 func WithObject(name string, opts ...PropertyOption) ToolOption {
@@ -1,11 +1,18 @@
 package mcp
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestToolWithBothSchemasError verifies that there will be feedback if the
@@ -308,6 +315,116 @@ func TestParseToolCallToolRequest(t *testing.T) {
 	t.Logf("param15 type: %T,value:%v", param15, param15)
 }
 
+func TestParseArgumentsE(t *testing.T) {
+	request := NewCallToolRequest("test-tool", map[string]any{
+		"bool":      "true",
+		"bad_bool":  "maybe",
+		"int":       42,
+		"int_str":   "42",
+		"bad_int":   "forty-two",
+		"float":     3.5,
+		"bad_float": "pi",
+		"object":    map[string]any{"a": 1},
+		"bad_map":   "not a map",
+	})
+
+	tests := []struct {
+		name    string
+		parse   func() (any, error)
+		want    any
+		wantErr string
+	}{
+		{"bool valid", func() (any, error) { return ParseBooleanE(request, "bool", false) }, true, ""},
+		{"bool missing", func() (any, error) { return ParseBooleanE(request, "missing", true) }, true, ""},
+		{"bool malformed", func() (any, error) { return ParseBooleanE(request, "bad_bool", true) }, false, `argument "bad_bool": invalid bool value "maybe"`},
+		{"int64 valid", func() (any, error) { return ParseInt64E(request, "int", 0) }, int64(42), ""},
+		{"int64 string", func() (any, error) { return ParseInt64E(request, "int_str", 0) }, int64(42), ""},
+		{"int64 missing", func() (any, error) { return ParseInt64E(request, "missing", 7) }, int64(7), ""},
+		{"int64 malformed", func() (any, error) { return ParseInt64E(request, "bad_int", 7) }, int64(0), `argument "bad_int": invalid int64 value "forty-two"`},
+		{"int valid", func() (any, error) { return ParseIntE(request, "int", 0) }, 42, ""},
+		{"int malformed", func() (any, error) { return ParseIntE(request, "bad_int", 7) }, 0, `argument "bad_int": invalid int value "forty-two"`},
+		{"uint valid", func() (any, error) { return ParseUInt32E(request, "int", 0) }, uint32(42), ""},
+		{"uint malformed", func() (any, error) { return ParseUInt32E(request, "bad_int", 7) }, uint32(0), `argument "bad_int": invalid uint32 value "forty-two"`},
+		{"float64 valid", func() (any, error) { return ParseFloat64E(request, "float", 0) }, 3.5, ""},
+		{"float64 missing", func() (any, error) { return ParseFloat64E(request, "missing", 1.5) }, 1.5, ""},
+		{"float64 malformed", func() (any, error) { return ParseFloat64E(request, "bad_float", 1.5) }, 0.0, `argument "bad_float": invalid float64 value "pi"`},
+		{"string valid", func() (any, error) { return ParseStringE(request, "int_str", "") }, "42", ""},
+		{"string missing", func() (any, error) { return ParseStringE(request, "missing", "default") }, "default", ""},
+		{"map valid", func() (any, error) { return ParseStringMapE(request, "object", nil) }, map[string]any{"a": json.Number("1")}, ""},
+		{"map malformed", func() (any, error) { return ParseStringMapE(request, "bad_map", nil) }, map[string]any(nil), `argument "bad_map": invalid object value "not a map"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// The silent helpers keep their behavior
+	assert.Equal(t, int64(0), ParseInt64(request, "bad_int", 7))
+	assert.Equal(t, int64(7), ParseInt64(request, "missing", 7))
+	assert.False(t, ParseBoolean(request, "bad_bool", true))
+	assert.Equal(t, map[string]any{}, ParseStringMap(request, "bad_map", nil))
+}
+
+func TestParseTimeDurationEnum(t *testing.T) {
+	request := NewCallToolRequest("test-tool", map[string]any{
+		"rfc3339":  "2025-06-01T12:30:00Z",
+		"date":     "2025-06-01",
+		"bad_time": "yesterday",
+		"duration": "1h30m",
+		"bad_dur":  "90",
+		"number":   90,
+		"mode":     "fast",
+		"bad_mode": "warp",
+	})
+	fallbackTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	allowed := []string{"fast", "slow"}
+
+	tests := []struct {
+		name    string
+		parse   func() (any, error)
+		want    any
+		wantErr string
+	}{
+		{"time RFC 3339", func() (any, error) { return ParseTimeE(request, "rfc3339", fallbackTime) }, time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC), ""},
+		{"time layouts", func() (any, error) { return ParseTimeE(request, "date", fallbackTime, time.RFC3339, time.DateOnly) }, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"time missing", func() (any, error) { return ParseTimeE(request, "missing", fallbackTime) }, fallbackTime, ""},
+		{"time malformed", func() (any, error) { return ParseTimeE(request, "bad_time", fallbackTime) }, time.Time{}, `argument "bad_time": invalid time value "yesterday"`},
+		{"time wrong layout", func() (any, error) { return ParseTimeE(request, "date", fallbackTime) }, time.Time{}, `argument "date": invalid time value "2025-06-01"`},
+		{"duration valid", func() (any, error) { return ParseDurationE(request, "duration", time.Second) }, 90 * time.Minute, ""},
+		{"duration missing", func() (any, error) { return ParseDurationE(request, "missing", time.Second) }, time.Second, ""},
+		{"duration malformed", func() (any, error) { return ParseDurationE(request, "bad_dur", time.Second) }, time.Duration(0), `argument "bad_dur": invalid duration value "90"`},
+		{"duration number", func() (any, error) { return ParseDurationE(request, "number", time.Second) }, time.Duration(0), `argument "number": invalid duration value "90"`},
+		{"enum valid", func() (any, error) { return ParseEnumE(request, "mode", allowed, "slow") }, "fast", ""},
+		{"enum missing", func() (any, error) { return ParseEnumE(request, "missing", allowed, "slow") }, "slow", ""},
+		{"enum not allowed", func() (any, error) { return ParseEnumE(request, "bad_mode", allowed, "slow") }, "", `argument "bad_mode": "warp" is not one of ["fast" "slow"]`},
+		{"enum not a string", func() (any, error) { return ParseEnumE(request, "number", allowed, "slow") }, "", `argument "number": invalid string value "90"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// The silent variants fall back to the default
+	assert.Equal(t, fallbackTime, ParseTime(request, "bad_time", fallbackTime))
+	assert.Equal(t, time.Second, ParseDuration(request, "bad_dur", time.Second))
+	assert.Equal(t, "slow", ParseEnum(request, "bad_mode", allowed, "slow"))
+	assert.Equal(t, "fast", ParseEnum(request, "mode", allowed, "slow"))
+}
+
 func TestCallToolRequestBindArguments(t *testing.T) {
 	// Define a struct to bind to
 	type TestArgs struct {
@@ -438,6 +555,59 @@ func TestCallToolRequestHelperFunctions(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCallToolRequestGetBlob(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	req := CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"image":     encoded,
+		"resource":  map[string]any{"uri": "file:///image.png", "mimeType": "image/png", "blob": encoded},
+		"reference": map[string]any{"uri": "file:///image.png"},
+		"invalid":   "not base64!",
+		"number":    42,
+	}
+
+	blob, err := req.GetBlob("image")
+	assert.NoError(t, err)
+	assert.Equal(t, data, blob)
+
+	blob, err = req.GetBlob("resource")
+	assert.NoError(t, err)
+	assert.Equal(t, data, blob)
+
+	_, err = req.GetBlob("reference")
+	assert.ErrorContains(t, err, "resource reference")
+
+	_, err = req.GetBlob("invalid")
+	assert.ErrorContains(t, err, "not valid base64")
+
+	_, err = req.GetBlob("number")
+	assert.Error(t, err)
+
+	_, err = req.GetBlob("missing")
+	assert.Error(t, err)
+
+	_, err = req.GetBlobWithLimit("image", len(data)-1)
+	assert.ErrorContains(t, err, "exceeds the maximum blob size")
+
+	blob, err = req.GetBlobWithLimit("image", len(data))
+	assert.NoError(t, err)
+	assert.Equal(t, data, blob)
+}
+
+func TestToolWithBlob(t *testing.T) {
+	tool := NewTool("ocr", WithBlob("image", Required(), Description("Image to scan")))
+
+	schema, ok := tool.InputSchema.Properties["image"].(map[string]any)
+	assert.True(t, ok)
+	assert.Equal(t, "string", schema["type"])
+	assert.Equal(t, "base64", schema["contentEncoding"])
+	assert.Equal(t, base64.StdEncoding.EncodedLen(DefaultMaxBlobSize), schema["maxLength"])
+	assert.Equal(t, "Image to scan", schema["description"])
+	assert.Contains(t, tool.InputSchema.Required, "image")
+}
+
 func TestFlexibleArgumentsWithMap(t *testing.T) {
 	// Create a request with map arguments
 	req := CallToolRequest{}
@@ -504,6 +674,50 @@ func TestFlexibleArgumentsWithStruct(t *testing.T) {
 	assert.Equal(t, 42, structArg.Field2)
 }
 
+func TestNewCallToolRequest(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type CustomArgs struct {
+		Name    string   `json:"name"`
+		Count   int      `json:"count"`
+		Ratio   float64  `json:"ratio"`
+		Tags    []int    `json:"tags"`
+		Address *Address `json:"address"`
+	}
+	args := CustomArgs{Name: "test", Count: 42, Ratio: 0.5, Tags: []int{1, 2}, Address: &Address{City: "Paris"}}
+
+	req := NewCallToolRequest("test-tool", args)
+	assert.Equal(t, string(MethodToolsCall), req.Method)
+	assert.Equal(t, "test-tool", req.Params.Name)
+	assert.Equal(t, "test", req.GetString("name", ""))
+	assert.Equal(t, 42, req.GetInt("count", 0))
+	assert.Equal(t, 0.5, req.GetFloat("ratio", 0))
+	assert.Equal(t, []int{1, 2}, req.GetIntSlice("tags", nil))
+	assert.Equal(t, map[string]any{"city": "Paris"}, req.GetArguments()["address"])
+
+	var bound CustomArgs
+	require.NoError(t, req.BindArguments(&bound))
+	assert.Equal(t, args, bound)
+
+	// The request is the same as one decoded from the wire
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	var wire CallToolRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&wire))
+	assert.Equal(t, req.Params, wire.Params)
+
+	// Raw JSON and maps are normalized the same way
+	raw := NewCallToolRequest("test-tool", json.RawMessage(`{"count":7}`))
+	assert.Equal(t, 7, raw.GetInt("count", 0))
+	fromMap := NewCallToolRequest("test-tool", map[string]any{"count": 7})
+	assert.Equal(t, raw.Params.Arguments, fromMap.Params.Arguments)
+
+	assert.Nil(t, NewCallToolRequest("test-tool", nil).Params.Arguments)
+}
+
 func TestFlexibleArgumentsJSONMarshalUnmarshal(t *testing.T) {
 	// Create a request with map arguments
 	req := CallToolRequest{}
@@ -577,6 +791,48 @@ func TestToolWithInputSchema(t *testing.T) {
 	assert.Contains(t, propertiesMap, "email")
 }
 
+func TestToolWithInputSchemaRequiredFields(t *testing.T) {
+	type Address struct {
+		Street string  `json:"street"`
+		Unit   *string `json:"unit"`
+	}
+	type Embedded struct {
+		TraceID *string `json:"trace_id"`
+	}
+	type Input struct {
+		Embedded
+		Name     string    `json:"name" jsonschema_description:"Person's name"`
+		Nickname *string   `json:"nickname" jsonschema_description:"Optional nickname"`
+		Email    string    `json:"email,omitempty"`
+		Manager  *string   `json:"manager" jsonschema:"required"`
+		Address  *Address  `json:"address"`
+		Previous []Address `json:"previous,omitempty"`
+	}
+
+	tool := NewTool("people", WithInputSchema[Input]())
+
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Description string   `json:"description"`
+			Required    []string `json:"required"`
+			Items       *struct {
+				Required []string `json:"required"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(tool.RawInputSchema, &schema))
+
+	assert.ElementsMatch(t, []string{"name", "manager"}, schema.Required)
+	assert.Equal(t, "Person's name", schema.Properties["name"].Description)
+	assert.Equal(t, "Optional nickname", schema.Properties["nickname"].Description)
+	assert.Contains(t, schema.Properties, "trace_id")
+
+	assert.Equal(t, []string{"street"}, schema.Properties["address"].Required)
+	require.NotNil(t, schema.Properties["previous"].Items)
+	assert.Equal(t, []string{"street"}, schema.Properties["previous"].Items.Required)
+}
+
 // TestToolWithOutputSchema tests that the WithOutputSchema function
 // generates an MCP-compatible JSON output schema for a tool
 func TestToolWithOutputSchema(t *testing.T) {
@@ -628,6 +884,72 @@ func TestNewToolResultStructured(t *testing.T) {
 	assert.NotNil(t, result.StructuredContent)
 }
 
+// TestNewToolResultErrorf tests that NewToolResultErrorf formats the error
+// message like fmt.Sprintf and marks the result as an error
+func TestNewToolResultErrorf(t *testing.T) {
+	result := NewToolResultErrorf("failed to fetch %s: status %d", "users", 503)
+
+	assert.True(t, result.IsError)
+	assert.Len(t, result.Content, 1)
+	textContent, ok := result.Content[0].(TextContent)
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeText, textContent.Type)
+	assert.Equal(t, "failed to fetch users: status 503", textContent.Text)
+}
+
+// TestNewToolResultStructuredAuto tests that the generated fallback text
+// matches the structured content and is marked as generated
+func TestNewToolResultStructuredAuto(t *testing.T) {
+	testData := map[string]any{
+		"message": "Success",
+		"count":   42,
+	}
+
+	result := NewToolResultStructuredAuto(testData)
+
+	assert.Len(t, result.Content, 1)
+	textContent, ok := result.Content[0].(TextContent)
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeText, textContent.Type)
+
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &decoded))
+	assert.Equal(t, map[string]any{"message": "Success", "count": float64(42)}, decoded)
+	assert.Contains(t, textContent.Text, "\n  ")
+
+	assert.NotNil(t, textContent.Meta)
+	assert.Equal(t, true, textContent.Meta.AdditionalFields[MetaKeyGeneratedFallback])
+	assert.NotContains(t, textContent.Meta.AdditionalFields, MetaKeyTruncated)
+	assert.Equal(t, testData, result.StructuredContent)
+}
+
+// TestNewToolResultStructuredAutoWithLimit tests that the generated fallback
+// text respects the size limit and notes the truncation
+func TestNewToolResultStructuredAutoWithLimit(t *testing.T) {
+	testData := map[string]any{
+		"message": strings.Repeat("é", 100),
+	}
+
+	result := NewToolResultStructuredAutoWithLimit(testData, 50)
+
+	textContent, ok := result.Content[0].(TextContent)
+	assert.True(t, ok)
+	prefix, note, found := strings.Cut(textContent.Text, "\n... [truncated")
+	assert.True(t, found)
+	assert.LessOrEqual(t, len(prefix), 50)
+	assert.True(t, utf8.ValidString(prefix))
+	assert.Contains(t, note, "see structuredContent for the full result")
+	assert.Equal(t, true, textContent.Meta.AdditionalFields[MetaKeyTruncated])
+	assert.Equal(t, testData, result.StructuredContent)
+
+	// Results under the limit are not truncated
+	result = NewToolResultStructuredAutoWithLimit(map[string]any{"ok": true}, 1024)
+	textContent, ok = result.Content[0].(TextContent)
+	assert.True(t, ok)
+	assert.NotContains(t, textContent.Text, "truncated")
+	assert.NotContains(t, textContent.Meta.AdditionalFields, MetaKeyTruncated)
+}
+
 // TestCallToolResultMarshalJSON tests the custom JSON marshaling of CallToolResult
 func TestCallToolResultMarshalJSON(t *testing.T) {
 	tests := []struct {
@@ -1372,3 +1694,593 @@ func TestNewItemsAPICompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestCallToolMetaAccessors(t *testing.T) {
+	var request CallToolRequest
+	assert.Nil(t, request.GetMeta("traceparent"))
+
+	request.SetMeta("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	request.SetMeta("progressToken", "tok-1")
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", request.GetMeta("traceparent"))
+	assert.Equal(t, "tok-1", request.GetMeta("progressToken"))
+	assert.Equal(t, ProgressToken("tok-1"), request.Params.Meta.ProgressToken)
+
+	data, err := json.Marshal(request.Params)
+	require.NoError(t, err)
+	var decoded CallToolParams
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "tok-1", decoded.Meta.Get("progressToken"))
+	assert.Equal(t, request.GetMeta("traceparent"), decoded.Meta.Get("traceparent"))
+
+	result := NewToolResultText("done")
+	assert.Nil(t, result.GetMeta("idempotencyKey"))
+	result.SetMeta("idempotencyKey", "abc-123")
+
+	data, err = json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_meta":{"idempotencyKey":"abc-123"}`)
+
+	var decodedResult CallToolResult
+	require.NoError(t, json.Unmarshal(data, &decodedResult))
+	assert.Equal(t, "abc-123", decodedResult.GetMeta("idempotencyKey"))
+}
+
+func TestNotificationMetaAccessors(t *testing.T) {
+	notification := JSONRPCNotification{
+		JSONRPC:      JSONRPC_VERSION,
+		Notification: Notification{Method: "notifications/message"},
+	}
+	assert.Nil(t, notification.GetMeta("traceparent"))
+
+	notification.SetMeta("traceparent", "trace-1")
+	data, err := json.Marshal(notification)
+	require.NoError(t, err)
+
+	var decoded JSONRPCNotification
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "trace-1", decoded.GetMeta("traceparent"))
+}
+
+func TestNewToolResultErrorWithDetail(t *testing.T) {
+	result := NewToolResultErrorWithDetail(ToolErrorUnavailable, "database is down", nil)
+	assert.True(t, result.IsError)
+
+	code, detail, ok := result.GetErrorDetail()
+	require.True(t, ok)
+	assert.Equal(t, ToolErrorUnavailable, code)
+	assert.Nil(t, detail)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_meta":{"error":{"code":"unavailable","message":"database is down"}}`)
+
+	_, _, ok = NewToolResultText("ok").GetErrorDetail()
+	assert.False(t, ok)
+}
+
+func TestNewToolResultPartial(t *testing.T) {
+	result := NewToolResultPartial([]Content{
+		NewTextContent("processed: a, b, c"),
+		NewImageContent("aGVsbG8=", "image/png"),
+	}, "failed to process 2 of 5 items: d, e")
+
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 3)
+	assert.Equal(t, NewTextContent("failed to process 2 of 5 items: d, e"), result.Content[0])
+	assert.Equal(t, NewTextContent("processed: a, b, c"), result.Content[1])
+	assert.Equal(t, NewImageContent("aGVsbG8=", "image/png"), result.Content[2])
+
+	// The content survives the round trip to the client
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	assert.True(t, parsed.IsError)
+	assert.Len(t, parsed.Content, 3)
+}
+
+func TestToolValidateArguments(t *testing.T) {
+	tool := NewTool("test",
+		WithString("name", Required()),
+		WithNumber("ratio"),
+		WithString("mode", Enum("fast", "slow")),
+		WithArray("tags"),
+		WithBoolean("verbose"),
+	)
+	tool.InputSchema.Properties["count"] = map[string]any{"type": "integer", "enum": []any{1, 2, 3}}
+	tool.InputSchema.Properties["nullable"] = map[string]any{"type": []any{"string", "null"}}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "valid", args: map[string]any{"name": "a", "ratio": 0.5, "mode": "fast", "tags": []any{"x"}, "verbose": true}},
+		{name: "json numbers", args: map[string]any{"name": "a", "ratio": json.Number("2.5"), "count": json.Number("2")}},
+		{name: "union type", args: map[string]any{"name": "a", "nullable": nil}},
+		{name: "unknown arguments are ignored", args: map[string]any{"name": "a", "extra": 1}},
+		{name: "missing required", args: map[string]any{}, wantErr: `required argument "name" not found`},
+		{name: "wrong type", args: map[string]any{"name": 1, "verbose": "yes"}, wantErr: "argument \"name\" must be of type string\nargument \"verbose\" must be of type boolean"},
+		{name: "not an integer", args: map[string]any{"name": "a", "count": 1.5}, wantErr: `argument "count" must be of type integer`},
+		{name: "not in enum", args: map[string]any{"name": "a", "mode": "warp", "count": json.Number("4")}, wantErr: "argument \"count\" must be one of [1 2 3]\nargument \"mode\" must be one of [fast slow]"},
+		{name: "union type mismatch", args: map[string]any{"name": "a", "nullable": 1}, wantErr: `argument "nullable" must be of type string or null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tool.ValidateArguments(tt.args)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	raw := NewToolWithRawSchema("raw", "", json.RawMessage(`{"type":"object","properties":{"n":{"type":"integer"}},"required":["n"]}`))
+	assert.NoError(t, raw.ValidateArguments(map[string]any{"n": 3}))
+	assert.EqualError(t, raw.ValidateArguments(map[string]any{"n": "3"}), `argument "n" must be of type integer`)
+}
+
+func TestToolApplyArgumentDefaults(t *testing.T) {
+	tool := NewTool("search",
+		WithString("query", Required()),
+		WithString("sort", DefaultString("relevance")),
+		WithNumber("limit", DefaultNumber(10)),
+		WithBoolean("exact", DefaultBool(false)),
+		WithObject("filter", Properties(map[string]any{
+			"lang":     map[string]any{"type": "string", "default": "en"},
+			"archived": map[string]any{"type": "boolean", "default": false},
+		})),
+	)
+
+	t.Run("fills omitted arguments", func(t *testing.T) {
+		args := map[string]any{"query": "go"}
+		filled := tool.ApplyArgumentDefaults(args)
+		assert.Equal(t, map[string]any{
+			"query": "go",
+			"sort":  "relevance",
+			"limit": json.Number("10"),
+			"exact": false,
+		}, filled)
+		assert.Equal(t, map[string]any{"query": "go"}, args, "input must not be modified")
+	})
+
+	t.Run("explicit values and nulls win", func(t *testing.T) {
+		filled := tool.ApplyArgumentDefaults(map[string]any{
+			"query": "go",
+			"sort":  nil,
+			"limit": 3,
+			"exact": true,
+		})
+		assert.Equal(t, map[string]any{
+			"query": "go",
+			"sort":  nil,
+			"limit": 3,
+			"exact": true,
+		}, filled)
+	})
+
+	t.Run("fills nested object properties one level deep", func(t *testing.T) {
+		filter := map[string]any{"archived": nil}
+		filled := tool.ApplyArgumentDefaults(map[string]any{"query": "go", "filter": filter})
+		assert.Equal(t, map[string]any{"lang": "en", "archived": nil}, filled["filter"])
+		assert.Equal(t, map[string]any{"archived": nil}, filter, "nested input must not be modified")
+	})
+
+	t.Run("nil arguments", func(t *testing.T) {
+		filled := tool.ApplyArgumentDefaults(nil)
+		assert.Equal(t, "relevance", filled["sort"])
+		assert.NotContains(t, filled, "filter")
+	})
+
+	t.Run("raw input schema", func(t *testing.T) {
+		raw := NewToolWithRawSchema("raw", "", json.RawMessage(`{
+			"type": "object",
+			"properties": {"unit": {"type": "string", "default": "celsius"}}
+		}`))
+		assert.Equal(t, map[string]any{"unit": "celsius"}, raw.ApplyArgumentDefaults(nil))
+	})
+}
+
+func TestNewToolResultEmpty(t *testing.T) {
+	result := NewToolResultEmpty()
+	assert.False(t, result.IsError)
+	assert.Empty(t, result.Content)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[]}`, string(data))
+
+	tests := []struct {
+		name string
+		json string
+	}{
+		{name: "empty content", json: `{"content":[]}`},
+		{name: "null content", json: `{"content":null}`},
+		{name: "missing content", json: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := json.RawMessage(tt.json)
+			parsed, err := ParseCallToolResult(&raw)
+			require.NoError(t, err)
+			assert.Empty(t, parsed.Content)
+			assert.False(t, parsed.IsError)
+		})
+	}
+}
+
+func TestNewToolResultStructuredJSON(t *testing.T) {
+	structured := map[string]any{"name": "report", "rows": []int{1, 2, 3}}
+
+	result := NewToolResultStructuredJSON(structured)
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(TextContent)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"name":"report","rows":[1,2,3]}`, text.Text)
+
+	raw, ok := result.StructuredContent.(json.RawMessage)
+	require.True(t, ok, "structured content should be pre-encoded")
+	assert.Equal(t, text.Text, string(raw))
+
+	// The wire format is the same as for the regular constructor
+	got, err := json.Marshal(result)
+	require.NoError(t, err)
+	want, err := json.Marshal(NewToolResultStructuredOnly(structured))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+
+	t.Run("unmarshalable value", func(t *testing.T) {
+		result := NewToolResultStructuredJSON(map[string]any{"ch": make(chan int)})
+		text, ok := result.Content[0].(TextContent)
+		require.True(t, ok)
+		assert.Contains(t, text.Text, "Error serializing structured content")
+	})
+}
+
+func benchmarkStructuredOutput() []map[string]any {
+	rows := make([]map[string]any, 10000)
+	for i := range rows {
+		rows[i] = map[string]any{
+			"id":    i,
+			"name":  fmt.Sprintf("row-%d", i),
+			"score": float64(i) / 3,
+			"tags":  []string{"a", "b", "c"},
+		}
+	}
+	return rows
+}
+
+func BenchmarkToolResultStructuredOnly(b *testing.B) {
+	rows := benchmarkStructuredOutput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(NewToolResultStructuredOnly(rows)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToolResultStructuredJSON(b *testing.B) {
+	rows := benchmarkStructuredOutput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(NewToolResultStructuredJSON(rows)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestOutputSchemaVersion(t *testing.T) {
+	type Report struct {
+		Total int `json:"total"`
+	}
+
+	before := NewTool("report", WithOutputSchemaVersion("2"), WithOutputSchema[Report]())
+	after := NewTool("report", WithOutputSchema[Report](), WithOutputSchemaVersion("2"))
+	for _, tool := range []Tool{before, after} {
+		assert.Equal(t, "2", tool.OutputSchemaVersion())
+		assert.Contains(t, string(tool.RawOutputSchema), `"total"`)
+	}
+
+	withID := NewTool("report", WithRawOutputSchema(json.RawMessage(`{"$id":"https://example.com/report/v3","type":"object"}`)))
+	assert.Equal(t, "https://example.com/report/v3", withID.OutputSchemaVersion())
+	assert.Empty(t, NewTool("plain").OutputSchemaVersion())
+
+	// The schema and its version survive the trip to the client
+	data, err := json.Marshal(after)
+	require.NoError(t, err)
+	var decoded Tool
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "2", decoded.OutputSchemaVersion())
+	assert.JSONEq(t, string(after.RawOutputSchema), string(decoded.RawOutputSchema))
+
+	request := CallToolRequest{}
+	assert.Empty(t, request.OutputSchemaVersion())
+	request.SetMeta(MetaKeyOutputSchemaVersion, "1")
+	assert.Equal(t, "1", request.OutputSchemaVersion())
+}
+
+func TestUnmarshalStructuredResult(t *testing.T) {
+	type ReportV1 struct {
+		Total int `json:"total"`
+	}
+	type ReportV2 struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+	}
+	tool := NewTool("report", WithOutputSchema[ReportV2](), WithOutputSchemaVersion("2"))
+
+	result := NewToolResultStructuredOnly(map[string]any{"total": map[string]any{"value": 3}})
+	result.SetMeta(MetaKeyOutputSchemaVersion, "2")
+
+	var v2 ReportV2
+	require.NoError(t, tool.UnmarshalStructuredResult(result, &v2))
+	assert.Equal(t, 3, v2.Total.Value)
+
+	// Pre-encoded structured content decodes the same way
+	var fromJSON ReportV2
+	require.NoError(t, UnmarshalStructuredResult(NewToolResultStructuredJSON(v2), &fromJSON))
+	assert.Equal(t, v2, fromJSON)
+
+	// A client built against the old shape gets a descriptive error
+	var v1 ReportV1
+	err := tool.UnmarshalStructuredResult(result, &v1)
+	var structuredErr *StructuredResultError
+	require.ErrorAs(t, err, &structuredErr)
+	assert.Equal(t, "report", structuredErr.Tool)
+	assert.Equal(t, "2", structuredErr.SchemaVersion)
+	assert.Equal(t, "2", structuredErr.ResultSchemaVersion)
+	assert.Contains(t, err.Error(), `tool "report"`)
+	assert.Contains(t, err.Error(), `result schema version "2"`)
+	assert.Contains(t, err.Error(), string(tool.RawOutputSchema))
+
+	err = UnmarshalStructuredResult(NewToolResultText("no structure"), &v1)
+	require.ErrorAs(t, err, &structuredErr)
+	assert.Contains(t, err.Error(), "no structured content")
+}
+
+func TestTool_ValidateStructuredResult_TopLevelArray(t *testing.T) {
+	type Asset struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Size int    `json:"size,omitempty"`
+	}
+	tool := NewTool("get_assets", WithOutputSchema[[]Asset]())
+
+	assets := []Asset{{ID: "a1", Name: "logo.png", Size: 12}, {ID: "a2", Name: "banner.png"}}
+	require.NoError(t, tool.ValidateStructuredResult(NewToolResultStructuredOnly(assets)))
+	require.NoError(t, tool.ValidateStructuredResult(NewToolResultStructuredOnly([]Asset{})))
+	require.NoError(t, tool.ValidateStructuredResult(NewToolResultStructuredJSON(assets)))
+
+	// As decoded by a client from the wire
+	var wire CallToolResult
+	data, err := json.Marshal(NewToolResultStructuredOnly(assets))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &wire))
+	require.IsType(t, []any{}, wire.StructuredContent)
+	require.NoError(t, tool.ValidateStructuredResult(&wire))
+
+	tests := []struct {
+		name    string
+		content any
+		errs    []string
+	}{
+		{
+			name:    "object instead of array",
+			content: assets[0],
+			errs:    []string{"structured content must be of type array"},
+		},
+		{
+			name:    "item of the wrong type",
+			content: []any{map[string]any{"id": "a1", "name": "logo.png"}, "a2"},
+			errs:    []string{"structured content[1] must be of type object"},
+		},
+		{
+			name:    "missing required property",
+			content: []any{map[string]any{"id": "a1", "name": "logo.png"}, map[string]any{"id": "a2"}},
+			errs:    []string{`structured content[1]: required property "name" not found`},
+		},
+		{
+			name:    "property of the wrong type",
+			content: []any{map[string]any{"id": 1, "name": "logo.png", "size": 1.5}},
+			errs: []string{
+				"structured content[0].id must be of type string",
+				"structured content[0].size must be of type integer",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tool.ValidateStructuredResult(NewToolResultStructuredOnly(tt.content))
+			require.Error(t, err)
+			for _, msg := range tt.errs {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+
+	// Error results and tools without an output schema are not checked
+	require.NoError(t, tool.ValidateStructuredResult(NewToolResultError("failed")))
+	require.NoError(t, NewTool("plain").ValidateStructuredResult(NewToolResultStructuredOnly("anything")))
+	assert.ErrorContains(t, tool.ValidateStructuredResult(NewToolResultText("no structure")), "no structured content")
+}
+
+func TestUnmarshalStructuredResult_TopLevelArray(t *testing.T) {
+	type Asset struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	tool := NewTool("get_assets", WithOutputSchema[[]Asset]())
+	assets := []Asset{{ID: "a1", Name: "logo.png"}, {ID: "a2", Name: "banner.png"}}
+
+	var wire CallToolResult
+	data, err := json.Marshal(NewToolResultStructuredOnly(assets))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &wire))
+
+	for name, result := range map[string]*CallToolResult{
+		"go value": NewToolResultStructuredOnly(assets),
+		"raw json": NewToolResultStructuredJSON(assets),
+		"wire":     &wire,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var decoded []Asset
+			require.NoError(t, tool.UnmarshalStructuredResult(result, &decoded))
+			assert.Equal(t, assets, decoded)
+		})
+	}
+
+	// Decoding an array into an object is reported with the schema
+	var single Asset
+	err = tool.UnmarshalStructuredResult(&wire, &single)
+	var structuredErr *StructuredResultError
+	require.ErrorAs(t, err, &structuredErr)
+	assert.Contains(t, err.Error(), `"type":"array"`)
+}
+
+func TestToolAnnotationHelpers(t *testing.T) {
+	plain := NewTool("plain")
+	assert.False(t, plain.IsReadOnly())
+	assert.True(t, plain.IsDestructive())
+	assert.False(t, plain.IsIdempotent())
+	assert.True(t, plain.IsOpenWorld())
+
+	reader := NewTool("reader",
+		WithReadOnlyHintAnnotation(true),
+		WithDestructiveHintAnnotation(true),
+		WithIdempotentHintAnnotation(true),
+		WithOpenWorldHintAnnotation(false),
+	)
+	assert.True(t, reader.IsReadOnly())
+	assert.False(t, reader.IsDestructive(), "read-only tools are never destructive")
+	assert.True(t, reader.IsIdempotent())
+	assert.False(t, reader.IsOpenWorld())
+
+	writer := NewTool("writer", WithDestructiveHintAnnotation(false))
+	assert.False(t, writer.IsReadOnly())
+	assert.False(t, writer.IsDestructive())
+}
+
+func TestToolTitleAndIcons(t *testing.T) {
+	icon := Icon{Src: "https://example.com/search.png", MIMEType: "image/png", Sizes: []string{"48x48"}}
+	tool := NewTool("search",
+		WithTitle("Search the Web"),
+		WithTitleAnnotation("Legacy Title"),
+		WithIcons(icon),
+		WithToolMeta(map[string]any{"vendor/category": "web"}),
+	)
+	assert.Equal(t, "Search the Web", tool.DisplayTitle())
+
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "Search the Web", raw["title"])
+	assert.Equal(t, map[string]any{"vendor/category": "web"}, raw["_meta"])
+	assert.Equal(t, "Legacy Title", raw["annotations"].(map[string]any)["title"])
+
+	var decoded Tool
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "Search the Web", decoded.Title)
+	assert.Equal(t, []Icon{icon}, decoded.Icons)
+	require.NotNil(t, decoded.Meta)
+	assert.Equal(t, "web", decoded.Meta.AdditionalFields["vendor/category"])
+
+	// Without a top-level title the annotation title, then the name, is used
+	assert.Equal(t, "Legacy Title", NewTool("search", WithTitleAnnotation("Legacy Title")).DisplayTitle())
+	assert.Equal(t, "search", NewTool("search").DisplayTitle())
+
+	data, err = json.Marshal(NewTool("plain"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"icons"`)
+	assert.NotContains(t, string(data), `"_meta"`)
+}
+
+// semver is a struct marshaled as a string, described by a schema override.
+type semver struct{ Major, Minor, Patch int }
+
+func (v semver) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch))
+}
+
+func TestSchemaOverrides(t *testing.T) {
+	type Release struct {
+		PublishedAt time.Time       `json:"publishedAt"`
+		YankedAt    *time.Time      `json:"yankedAt,omitempty"`
+		Manifest    json.RawMessage `json:"manifest" jsonschema_description:"The raw package manifest"`
+		Version     semver          `json:"version"`
+		Previous    *semver         `json:"previous,omitempty"`
+	}
+
+	RegisterSchemaOverride(reflect.TypeOf(semver{}), json.RawMessage(`{"type":"string","pattern":"^\\d+\\.\\d+\\.\\d+$","x-kind":"semver"}`))
+	t.Cleanup(func() { RegisterSchemaOverride(reflect.TypeOf(semver{}), nil) })
+
+	tool := NewTool("release", WithOutputSchema[Release]())
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+	}
+	require.NoError(t, json.Unmarshal(tool.RawOutputSchema, &schema))
+
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, schema.Properties["publishedAt"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, schema.Properties["yankedAt"])
+	assert.Equal(t, map[string]any{"description": "The raw package manifest"}, schema.Properties["manifest"])
+	wantVersion := map[string]any{"type": "string", "pattern": `^\d+\.\d+\.\d+$`, "x-kind": "semver"}
+	assert.Equal(t, wantVersion, schema.Properties["version"])
+	assert.Equal(t, wantVersion, schema.Properties["previous"], "pointers share the override")
+	assert.ElementsMatch(t, []string{"publishedAt", "manifest", "version"}, schema.Required)
+
+	// Input schemas use the same overrides
+	tool = NewTool("release", WithInputSchema[Release]())
+	require.NoError(t, json.Unmarshal(tool.RawInputSchema, &schema))
+	assert.Equal(t, wantVersion, schema.Properties["version"])
+	assert.Equal(t, map[string]any{"description": "The raw package manifest"}, schema.Properties["manifest"])
+
+	// Without overrides the type is reflected again
+	RegisterSchemaOverride(reflect.TypeOf(&semver{}), nil)
+	tool = NewTool("release", WithOutputSchema[Release]())
+	require.NoError(t, json.Unmarshal(tool.RawOutputSchema, &schema))
+	assert.Equal(t, "object", schema.Properties["version"]["type"])
+
+	assert.Panics(t, func() {
+		RegisterSchemaOverride(reflect.TypeOf(semver{}), json.RawMessage(`"string"`))
+	})
+}
+
+func TestTitlesForProtocolVersion(t *testing.T) {
+	assert.True(t, SupportsTitles(LATEST_PROTOCOL_VERSION))
+	assert.True(t, SupportsTitles(""))
+	assert.False(t, SupportsTitles("2025-03-26"))
+	assert.False(t, SupportsTitles("2024-11-05"))
+
+	tool := NewTool("search", WithTitle("Search the Web"))
+	assert.Equal(t, tool, tool.ForProtocolVersion(LATEST_PROTOCOL_VERSION))
+	legacy := tool.ForProtocolVersion("2025-03-26")
+	assert.Empty(t, legacy.Title)
+	assert.Equal(t, "Search the Web", legacy.Annotations.Title)
+	assert.Equal(t, "Search the Web", legacy.DisplayTitle())
+	assert.Equal(t, "Search the Web", tool.Title, "the original tool is unchanged")
+
+	// An explicit annotation title is kept
+	annotated := NewTool("search", WithTitle("Search the Web"), WithTitleAnnotation("Search")).ForProtocolVersion("2024-11-05")
+	assert.Empty(t, annotated.Title)
+	assert.Equal(t, "Search", annotated.Annotations.Title)
+
+	prompt := NewPrompt("greet", WithPromptTitle("Greeting"))
+	assert.Equal(t, "Greeting", prompt.ForProtocolVersion(LATEST_PROTOCOL_VERSION).Title)
+	assert.Empty(t, prompt.ForProtocolVersion("2025-03-26").Title)
+
+	resource := NewResource("file:///a", "a", WithResourceTitle("A"))
+	assert.Equal(t, "A", resource.ForProtocolVersion(LATEST_PROTOCOL_VERSION).Title)
+	assert.Empty(t, resource.ForProtocolVersion("2025-03-26").Title)
+
+	template := NewResourceTemplate("file:///{path}", "files", WithTemplateTitle("Files"))
+	assert.Equal(t, "Files", template.ForProtocolVersion(LATEST_PROTOCOL_VERSION).Title)
+	assert.Empty(t, template.ForProtocolVersion("2025-03-26").Title)
+}
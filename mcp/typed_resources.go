@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingTemplateVariables is returned, wrapped, by BindTemplateVariables
+// when required fields have no matching template variable.
+var ErrMissingTemplateVariables = errors.New("missing template variables")
+
+// TypedResourceHandlerFunc is a function that handles a resource read with
+// the URI template variables bound to a typed struct
+type TypedResourceHandlerFunc[T any] func(ctx context.Context, request ReadResourceRequest, vars T) ([]ResourceContents, error)
+
+// NewTypedResourceHandler creates a resource handler that binds the URI
+// template variables of the request to a struct with
+// BindTemplateVariables before calling handler. It can be used for
+// templates as well as exact resources, for which T is usually struct{}.
+func NewTypedResourceHandler[T any](handler TypedResourceHandlerFunc[T]) func(ctx context.Context, request ReadResourceRequest) ([]ResourceContents, error) {
+	return func(ctx context.Context, request ReadResourceRequest) ([]ResourceContents, error) {
+		var vars T
+		if err := request.BindTemplateVariables(&vars); err != nil {
+			return nil, err
+		}
+		return handler(ctx, request, vars)
+	}
+}
+
+// BindTemplateVariables stores the URI template variables matched for the
+// request in the struct pointed to by target.
+//
+// Each exported field is bound to the variable named by its uri tag, or else
+// to the variable with the field's name, compared case-insensitively. Fields
+// tagged uri:"-" are skipped. Strings, booleans and numbers take a single
+// value, parsed from its text; slices of them take all the values of a
+// multi-value variable such as {tags*}. Pointer fields are optional; all
+// other fields are required, and their absence is reported in an error
+// wrapping ErrMissingTemplateVariables that names every missing variable.
+func (r ReadResourceRequest) BindTemplateVariables(target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("template variables can only be bound to a pointer to a struct, got %T", target)
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	var missing []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("uri")
+		if name == "-" {
+			continue
+		}
+		values, found := r.templateVariable(name, field.Name)
+		if name == "" {
+			name = field.Name
+		}
+		if !found {
+			if field.Type.Kind() != reflect.Ptr {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		if err := setTemplateVariable(v.Field(i), name, values); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingTemplateVariables, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// templateVariable returns the values of the variable named tag, or, without
+// a tag, of the variable matching fieldName.
+func (r ReadResourceRequest) templateVariable(tag, fieldName string) ([]string, bool) {
+	if tag != "" {
+		value, ok := r.Params.Arguments[tag]
+		return templateVariableValues(value), ok && value != nil
+	}
+	if value, ok := r.Params.Arguments[fieldName]; ok && value != nil {
+		return templateVariableValues(value), true
+	}
+	for name, value := range r.Params.Arguments {
+		if strings.EqualFold(name, fieldName) && value != nil {
+			return templateVariableValues(value), true
+		}
+	}
+	return nil, false
+}
+
+// templateVariableValues returns the text of the values of a variable. The
+// server stores matched variables as []string; arguments sent by clients
+// may hold any JSON value.
+func templateVariableValues(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprint(item)
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func setTemplateVariable(field reflect.Value, name string, values []string) error {
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setTemplateScalar(slice.Index(i), name, value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("template variable %q: expected a single value, got %d", name, len(values))
+	}
+	return setTemplateScalar(field, name, values[0])
+}
+
+func setTemplateScalar(field reflect.Value, name, value string) error {
+	var err error
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+		return nil
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			field.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, field.Type().Bits()); err == nil {
+			field.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 10, field.Type().Bits()); err == nil {
+			field.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(value, field.Type().Bits()); err == nil {
+			field.SetFloat(f)
+			return nil
+		}
+	default:
+		return fmt.Errorf("template variable %q: unsupported field type %s", name, field.Type())
+	}
+	return fmt.Errorf("template variable %q: invalid %s value %q", name, field.Kind(), value)
+}
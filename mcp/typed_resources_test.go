@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readResourceRequest(uri string, arguments map[string]any) ReadResourceRequest {
+	var request ReadResourceRequest
+	request.Params.URI = uri
+	request.Params.Arguments = arguments
+	return request
+}
+
+func TestTypedResourceHandler(t *testing.T) {
+	type DocumentVars struct {
+		UserID  string   `uri:"userId"`
+		DocID   string   // matched case-insensitively as docid
+		Version *int     `uri:"version"`
+		Tags    []string `uri:"tags"`
+	}
+
+	var got DocumentVars
+	handler := NewTypedResourceHandler(func(ctx context.Context, request ReadResourceRequest, vars DocumentVars) ([]ResourceContents, error) {
+		got = vars
+		return []ResourceContents{TextResourceContents{URI: request.Params.URI, Text: vars.UserID + "/" + vars.DocID}}, nil
+	})
+
+	t.Run("binds variables", func(t *testing.T) {
+		contents, err := handler(context.Background(), readResourceRequest("docs://john/readme", map[string]any{
+			"userId":  []string{"john"},
+			"docid":   []string{"readme"},
+			"version": []string{"3"},
+			"tags":    []string{"a", "b"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "john/readme", contents[0].(TextResourceContents).Text)
+		assert.Equal(t, "john", got.UserID)
+		assert.Equal(t, "readme", got.DocID)
+		require.NotNil(t, got.Version)
+		assert.Equal(t, 3, *got.Version)
+		assert.Equal(t, []string{"a", "b"}, got.Tags)
+	})
+
+	t.Run("missing variables", func(t *testing.T) {
+		_, err := handler(context.Background(), readResourceRequest("docs://john", map[string]any{
+			"userId": []string{"john"},
+		}))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrMissingTemplateVariables))
+		assert.EqualError(t, err, "missing template variables: DocID, tags")
+	})
+
+	t.Run("multiple values for a scalar", func(t *testing.T) {
+		_, err := handler(context.Background(), readResourceRequest("docs://john/readme", map[string]any{
+			"userId": []string{"john", "jane"},
+			"docid":  []string{"readme"},
+			"tags":   []string{},
+		}))
+		assert.EqualError(t, err, `template variable "userId": expected a single value, got 2`)
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		_, err := handler(context.Background(), readResourceRequest("docs://john/readme", map[string]any{
+			"userId":  "john",
+			"docid":   "readme",
+			"tags":    []any{"a"},
+			"version": "latest",
+		}))
+		assert.EqualError(t, err, `template variable "version": invalid int value "latest"`)
+	})
+
+	t.Run("exact resource", func(t *testing.T) {
+		called := false
+		exact := NewTypedResourceHandler(func(ctx context.Context, request ReadResourceRequest, vars struct{}) ([]ResourceContents, error) {
+			called = true
+			return nil, nil
+		})
+		_, err := exact(context.Background(), readResourceRequest("docs://static", nil))
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+}
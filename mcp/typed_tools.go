@@ -1,8 +1,13 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 )
 
 // TypedToolHandlerFunc is a function that handles a tool call with typed arguments
@@ -16,27 +21,175 @@ func NewTypedToolHandler[T any](handler TypedToolHandlerFunc[T]) func(ctx contex
 	return func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
 		var args T
 		if err := request.BindArguments(&args); err != nil {
-			return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err)), nil
+			return NewToolResultErrorf("failed to bind arguments: %v", err), nil
 		}
 		return handler(ctx, request, args)
 	}
 }
 
+// StructuredToolHandlerOption configures NewStructuredToolHandler.
+type StructuredToolHandlerOption func(*structuredToolHandlerConfig)
+
+type structuredToolHandlerConfig struct {
+	omitZeroTimes bool
+}
+
+// WithOmitZeroTimes leaves time.Time and *time.Time fields tagged omitempty
+// out of the structured output when they hold the zero time, instead of
+// writing them as "0001-01-01T00:00:00Z". encoding/json only omits empty
+// structs for fields tagged omitzero, which needs Go 1.24.
+func WithOmitZeroTimes() StructuredToolHandlerOption {
+	return func(c *structuredToolHandlerConfig) {
+		c.omitZeroTimes = true
+	}
+}
+
 // NewStructuredToolHandler creates a ToolHandlerFunc that automatically binds arguments to a typed struct
 // and returns structured output. It automatically creates both structured and
 // text content (from the structured output) for backwards compatibility.
-func NewStructuredToolHandler[TArgs any, TResult any](handler StructuredToolHandlerFunc[TArgs, TResult]) func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
+func NewStructuredToolHandler[TArgs any, TResult any](handler StructuredToolHandlerFunc[TArgs, TResult], opts ...StructuredToolHandlerOption) func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
+	config := &structuredToolHandlerConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	return func(ctx context.Context, request CallToolRequest) (*CallToolResult, error) {
 		var args TArgs
 		if err := request.BindArguments(&args); err != nil {
-			return NewToolResultError(fmt.Sprintf("failed to bind arguments: %v", err)), nil
+			return NewToolResultErrorf("failed to bind arguments: %v", err), nil
 		}
 
 		result, err := handler(ctx, request, args)
 		if err != nil {
-			return NewToolResultError(fmt.Sprintf("tool execution failed: %v", err)), nil
+			return NewToolResultErrorf("tool execution failed: %v", err), nil
 		}
 
+		if config.omitZeroTimes {
+			structured, err := omitZeroTimes(result)
+			if err != nil {
+				return NewToolResultErrorf("failed to marshal structured output: %v", err), nil
+			}
+			return NewToolResultStructuredOnly(structured), nil
+		}
 		return NewToolResultStructuredOnly(result), nil
 	}
 }
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// omitZeroTimes returns the JSON form of v, decoded into maps and slices,
+// without the zero times held by fields tagged omitempty.
+func omitZeroTimes(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	pruneZeroTimes(reflect.ValueOf(v), decoded)
+	return decoded, nil
+}
+
+// pruneZeroTimes walks v along with its decoded JSON form, removing the zero
+// times of omitempty fields from the decoded objects.
+func pruneZeroTimes(v reflect.Value, decoded any) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+	// The JSON form of types marshaling themselves does not follow their fields
+	if v.Type().Implements(jsonMarshalerType) || reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if object, ok := decoded.(map[string]any); ok {
+			pruneStructZeroTimes(v, object)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := decoded.([]any); ok {
+			for i := 0; i < v.Len() && i < len(items); i++ {
+				pruneZeroTimes(v.Index(i), items[i])
+			}
+		}
+	case reflect.Map:
+		object, ok := decoded.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if item, ok := object[iter.Key().String()]; ok {
+				pruneZeroTimes(iter.Value(), item)
+			}
+		}
+	}
+}
+
+func pruneStructZeroTimes(v reflect.Value, object map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(jsonTag, ",")
+		value := v.Field(i)
+		if field.Anonymous && name == "" {
+			// Embedded struct fields are inlined into the parent object
+			for value.Kind() == reflect.Ptr && !value.IsNil() {
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				pruneStructZeroTimes(value, object)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if slices.Contains(strings.Split(options, ","), "omitempty") && isZeroTime(value) {
+			delete(object, name)
+			continue
+		}
+		if item, ok := object[name]; ok {
+			pruneZeroTimes(value, item)
+		}
+	}
+}
+
+// isZeroTime reports whether v is a time.Time, or a non-nil pointer to one,
+// holding the zero time.
+func isZeroTime(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if v.Type() != timeType {
+		return false
+	}
+	if v.CanInterface() {
+		return v.Interface().(time.Time).IsZero()
+	}
+	return v.IsZero()
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -302,3 +303,55 @@ func TestTypedToolHandlerWithComplexObjects(t *testing.T) {
 	assert.Contains(t, result.Content[0].(TextContent).Text, "Theme: system")
 	assert.Contains(t, result.Content[0].(TextContent).Text, "Subscribed to 1 newsletters")
 }
+
+func TestStructuredToolHandler_OmitZeroTimes(t *testing.T) {
+	type Audit struct {
+		By string    `json:"by"`
+		At time.Time `json:"at,omitempty"`
+	}
+	type Record struct {
+		Audit
+		CreatedAt time.Time       `json:"createdAt"`
+		UpdatedAt time.Time       `json:"updatedAt,omitempty"`
+		DeletedAt *time.Time      `json:"deletedAt,omitempty"`
+		Payload   json.RawMessage `json:"payload,omitempty"`
+		History   []Audit         `json:"history"`
+	}
+
+	zero := time.Time{}
+	when := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	record := Record{
+		Audit:     Audit{By: "alice"},
+		DeletedAt: &zero,
+		Payload:   json.RawMessage(`{"raw":true}`),
+		History:   []Audit{{By: "bob", At: when}, {By: "carol"}},
+	}
+	handler := func(ctx context.Context, request CallToolRequest, args struct{}) (Record, error) {
+		return record, nil
+	}
+
+	result, err := NewStructuredToolHandler(handler)(context.Background(), CallToolRequest{})
+	assert.NoError(t, err)
+	text := result.Content[0].(TextContent).Text
+	assert.Contains(t, text, `"updatedAt":"0001-01-01T00:00:00Z"`, "zero times are kept by default")
+
+	result, err = NewStructuredToolHandler(handler, WithOmitZeroTimes())(context.Background(), CallToolRequest{})
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(result.Content[0].(TextContent).Text), &got))
+	assert.Equal(t, map[string]any{
+		"by":        "alice",
+		"createdAt": "0001-01-01T00:00:00Z", // not omitempty
+		"payload":   map[string]any{"raw": true},
+		"history": []any{
+			map[string]any{"by": "bob", "at": "2025-06-18T12:00:00Z"},
+			map[string]any{"by": "carol"},
+		},
+	}, got)
+
+	structured, err := json.Marshal(result.StructuredContent)
+	assert.NoError(t, err)
+	assert.JSONEq(t, result.Content[0].(TextContent).Text, string(structured))
+}
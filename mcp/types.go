@@ -3,10 +3,15 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"maps"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"net/http"
 
@@ -69,6 +74,11 @@ const (
 	// MethodNotificationToolsListChanged notifies when the list of available tools changes.
 	// https://spec.modelcontextprotocol.io/specification/2024-11-05/server/tools/list_changed/
 	MethodNotificationToolsListChanged = "notifications/tools/list_changed"
+
+	// MethodNotificationCancelled notifies the receiver of a request that the
+	// sender no longer needs its result.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/cancellation
+	MethodNotificationCancelled = "notifications/cancelled"
 )
 
 type URITemplate struct {
@@ -107,6 +117,18 @@ var ValidProtocolVersions = []string{
 	"2024-11-05",
 }
 
+// titleProtocolVersion is the protocol version that added the title field
+// of tools, prompts, resources and resource templates.
+const titleProtocolVersion = "2025-06-18"
+
+// SupportsTitles reports whether clients that negotiated the protocol
+// version know the title field of tools, prompts, resources and resource
+// templates. Unknown and empty versions are assumed to be current.
+func SupportsTitles(version string) bool {
+	i := slices.Index(ValidProtocolVersions, version)
+	return i == -1 || i <= slices.Index(ValidProtocolVersions, titleProtocolVersion)
+}
+
 // JSONRPC_VERSION is the version of JSON-RPC used by MCP.
 const JSONRPC_VERSION = "2.0"
 
@@ -165,6 +187,43 @@ func NewMetaFromMap(m map[string]any) *Meta {
 	}
 }
 
+// withMetaFields returns m with fields merged into its additional fields,
+// allocating a Meta if m is nil.
+func withMetaFields(m *Meta, fields map[string]any) *Meta {
+	if m == nil {
+		m = &Meta{}
+	}
+	if m.AdditionalFields == nil {
+		m.AdditionalFields = make(map[string]any, len(fields))
+	}
+	maps.Copy(m.AdditionalFields, fields)
+	return m
+}
+
+// Get returns the value stored under key, including the well-known
+// "progressToken" key. It is safe to call on a nil Meta.
+func (m *Meta) Get(key string) any {
+	if m == nil {
+		return nil
+	}
+	if key == "progressToken" {
+		return m.ProgressToken
+	}
+	return m.AdditionalFields[key]
+}
+
+// Set stores value under key. Setting "progressToken" sets ProgressToken.
+func (m *Meta) Set(key string, value any) {
+	if key == "progressToken" {
+		m.ProgressToken = value
+		return
+	}
+	if m.AdditionalFields == nil {
+		m.AdditionalFields = make(map[string]any)
+	}
+	m.AdditionalFields[key] = value
+}
+
 type Request struct {
 	Method string        `json:"method"`
 	Params RequestParams `json:"params,omitempty"`
@@ -243,12 +302,39 @@ func (p *NotificationParams) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// GetMeta returns the _meta value stored under key, or nil if it is not set.
+func (n *Notification) GetMeta(key string) any {
+	return n.Params.Meta[key]
+}
+
+// SetMeta stores value under key in the notification's _meta, creating it if
+// needed.
+func (n *Notification) SetMeta(key string, value any) {
+	if n.Params.Meta == nil {
+		n.Params.Meta = make(map[string]any)
+	}
+	n.Params.Meta[key] = value
+}
+
 type Result struct {
 	// This result property is reserved by the protocol to allow clients and
 	// servers to attach additional metadata to their responses.
 	Meta *Meta `json:"_meta,omitempty"`
 }
 
+// GetMeta returns the _meta value stored under key, or nil if it is not set.
+func (r *Result) GetMeta(key string) any {
+	return r.Meta.Get(key)
+}
+
+// SetMeta stores value under key in the result's _meta, creating it if needed.
+func (r *Result) SetMeta(key string, value any) {
+	if r.Meta == nil {
+		r.Meta = &Meta{}
+	}
+	r.Meta.Set(key, value)
+}
+
 // RequestId is a uniquely identifying ID for a request in JSON-RPC.
 // It can be any JSON-serializable value, typically a number or string.
 type RequestId struct {
@@ -265,18 +351,43 @@ func (r RequestId) Value() any {
 	return r.value
 }
 
-// String returns a string representation of the RequestId
+// String returns a string representation of the RequestId. Equal IDs have
+// equal strings regardless of the Go type holding them, so an ID created
+// with NewRequestId(42) matches the same ID decoded from JSON, and integers
+// too large for a float64 keep all their digits.
 func (r RequestId) String() string {
 	switch v := r.value.(type) {
 	case string:
 		return "string:" + v
+	case int:
+		return "int64:" + strconv.FormatInt(int64(v), 10)
+	case int8:
+		return "int64:" + strconv.FormatInt(int64(v), 10)
+	case int16:
+		return "int64:" + strconv.FormatInt(int64(v), 10)
+	case int32:
+		return "int64:" + strconv.FormatInt(int64(v), 10)
 	case int64:
 		return "int64:" + strconv.FormatInt(v, 10)
+	case uint:
+		return uintRequestIdString(uint64(v))
+	case uint8:
+		return uintRequestIdString(uint64(v))
+	case uint16:
+		return uintRequestIdString(uint64(v))
+	case uint32:
+		return uintRequestIdString(uint64(v))
+	case uint64:
+		return uintRequestIdString(v)
+	case float32:
+		return floatRequestIdString(float64(v))
 	case float64:
-		if v == float64(int64(v)) {
-			return "int64:" + strconv.FormatInt(int64(v), 10)
+		return floatRequestIdString(v)
+	case json.Number:
+		if n, ok := parseRequestIdNumber(v).(json.Number); ok {
+			return "number:" + n.String()
 		}
-		return "float64:" + strconv.FormatFloat(v, 'f', -1, 64)
+		return NewRequestId(parseRequestIdNumber(v)).String()
 	case nil:
 		return "<nil>"
 	default:
@@ -284,6 +395,43 @@ func (r RequestId) String() string {
 	}
 }
 
+func uintRequestIdString(v uint64) string {
+	if v <= math.MaxInt64 {
+		return "int64:" + strconv.FormatInt(int64(v), 10)
+	}
+	return "uint64:" + strconv.FormatUint(v, 10)
+}
+
+func floatRequestIdString(v float64) string {
+	if v == float64(int64(v)) {
+		return "int64:" + strconv.FormatInt(int64(v), 10)
+	}
+	return "float64:" + strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseRequestIdNumber converts a JSON number ID to the narrowest Go type
+// that holds it: int64, then uint64, then float64 for fractions, with
+// integral floats such as 1.0 becoming int64.
+// Integers beyond uint64 stay a json.Number so that no digit is lost.
+func parseRequestIdNumber(n json.Number) any {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	if !strings.ContainsAny(n.String(), ".eE") {
+		return n
+	}
+	if f, err := n.Float64(); err == nil {
+		if f == float64(int64(f)) {
+			return int64(f)
+		}
+		return f
+	}
+	return n
+}
+
 // IsNil returns true if the RequestId is nil
 func (r RequestId) IsNil() bool {
 	return r.value == nil
@@ -293,6 +441,9 @@ func (r RequestId) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.value)
 }
 
+// UnmarshalJSON decodes a string or number ID. Integers are decoded as int64,
+// or uint64 if they do not fit, without going through float64, so large IDs
+// survive a round trip exactly.
 func (r *RequestId) UnmarshalJSON(data []byte) error {
 
 	if string(data) == "null" {
@@ -307,14 +458,10 @@ func (r *RequestId) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
-	// JSON numbers are unmarshaled as float64 in Go
-	var f float64
-	if err := json.Unmarshal(data, &f); err == nil {
-		if f == float64(int64(f)) {
-			r.value = int64(f)
-		} else {
-			r.value = f
-		}
+	// Keep the literal digits rather than decoding into a float64
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		r.value = parseRequestIdNumber(n)
 		return nil
 	}
 
@@ -372,6 +519,16 @@ const (
 	RESOURCE_NOT_FOUND = -32002
 )
 
+// Implementation-defined server error codes
+const (
+	// SERVER_BUSY is returned when the server refuses a request because the
+	// session already has too many requests in flight.
+	SERVER_BUSY = -32000
+	// PROMPT_NOT_FOUND is returned by prompts/get when no prompt with the
+	// requested name exists.
+	PROMPT_NOT_FOUND = -32001
+)
+
 /* Empty result */
 
 // EmptyResult represents a response that indicates success but carries no data.
@@ -443,6 +600,51 @@ type InitializeResult struct {
 	Instructions string `json:"instructions,omitempty"`
 }
 
+// TimeoutHintKeyForTool returns the key of the timeout hint for calls of the
+// named tool. Other hints are keyed by method name, such as "resources/read".
+func TimeoutHintKeyForTool(name string) string {
+	return string(MethodToolsCall) + ":" + name
+}
+
+// SetTimeoutHints publishes the timeouts the server suggests for requests
+// under MetaKeyTimeoutHints, in milliseconds.
+func (r *InitializeResult) SetTimeoutHints(hints map[string]time.Duration) {
+	if len(hints) == 0 {
+		return
+	}
+	millis := make(map[string]any, len(hints))
+	for key, timeout := range hints {
+		millis[key] = timeout.Milliseconds()
+	}
+	r.SetMeta(MetaKeyTimeoutHints, millis)
+}
+
+// TimeoutHints returns the timeouts suggested by the server under
+// MetaKeyTimeoutHints. Hints that are not a positive number of milliseconds
+// are ignored.
+func (r *InitializeResult) TimeoutHints() map[string]time.Duration {
+	raw, ok := r.GetMeta(MetaKeyTimeoutHints).(map[string]any)
+	if !ok {
+		return nil
+	}
+	hints := make(map[string]time.Duration, len(raw))
+	for key, value := range raw {
+		var millis float64
+		switch v := value.(type) {
+		case float64:
+			millis = v
+		case int64:
+			millis = float64(v)
+		case json.Number:
+			millis, _ = v.Float64()
+		}
+		if millis > 0 {
+			hints[key] = time.Duration(millis * float64(time.Millisecond))
+		}
+	}
+	return hints
+}
+
 // InitializedNotification is sent from the client to the server after
 // initialization has finished.
 type InitializedNotification struct {
@@ -495,9 +697,65 @@ type ServerCapabilities struct {
 }
 
 // Implementation describes the name and version of an MCP implementation.
+// It is comparable, so its optional fields of slice and map type are held by
+// pointer.
 type Implementation struct {
-	Name    string `json:"name"`
+	Name string `json:"name"`
+	// A display title, preferred over Name by clients that render it.
+	Title   string `json:"title,omitempty"`
 	Version string `json:"version"`
+	// The URL of the implementation's website.
+	WebsiteURL string `json:"websiteUrl,omitempty"`
+	// Icons clients can display for the implementation.
+	Icons *[]Icon `json:"icons,omitempty"`
+	// AdditionalFields holds the fields of the JSON object that are not
+	// defined above, so that fields added by newer protocol revisions survive
+	// a round trip.
+	AdditionalFields *map[string]any `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, writing AdditionalFields alongside
+// the defined fields.
+func (i Implementation) MarshalJSON() ([]byte, error) {
+	type implementationAlias Implementation
+	data, err := json.Marshal(implementationAlias(i))
+	if err != nil || i.AdditionalFields == nil || len(*i.AdditionalFields) == 0 {
+		return data, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range *i.AdditionalFields {
+		// Defined fields take precedence
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in
+// AdditionalFields.
+func (i *Implementation) UnmarshalJSON(data []byte) error {
+	type implementationAlias Implementation
+	var alias implementationAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, key := range []string{"name", "title", "version", "websiteUrl", "icons"} {
+		delete(fields, key)
+	}
+	*i = Implementation(alias)
+	if len(fields) > 0 {
+		i.AdditionalFields = &fields
+	}
+	return nil
 }
 
 /* Ping */
@@ -561,6 +819,59 @@ type PaginatedResult struct {
 type ListResourcesRequest struct {
 	PaginatedRequest
 	Header http.Header `json:"-"`
+	// Filter is sent as the uriPrefix and mimeType params.
+	Filter ListResourcesFilter `json:"-"`
+}
+
+// ListResourcesFilter narrows the results of resources/list and
+// resources/templates/list to the resources the client is interested in. It
+// is an extension to the protocol, sent as the uriPrefix and mimeType request
+// params, which servers that don't support it ignore.
+type ListResourcesFilter struct {
+	// URIPrefix keeps the resources whose URI starts with it, such as
+	// "file:///repo/docs/" or "db://".
+	URIPrefix string `json:"uriPrefix,omitempty"`
+	// MIMEType keeps the resources of this MIME type.
+	MIMEType string `json:"mimeType,omitempty"`
+}
+
+// IsZero reports whether the filter keeps every resource.
+func (f ListResourcesFilter) IsZero() bool {
+	return f == ListResourcesFilter{}
+}
+
+// MatchesResource reports whether the filter keeps resource.
+func (f ListResourcesFilter) MatchesResource(resource Resource) bool {
+	return strings.HasPrefix(resource.URI, f.URIPrefix) &&
+		(f.MIMEType == "" || resource.MIMEType == f.MIMEType)
+}
+
+// MatchesTemplate reports whether template could expand to a URI the filter
+// keeps: the literal part of the template before its first expression must
+// start with URIPrefix, or be the start of it.
+func (f ListResourcesFilter) MatchesTemplate(template ResourceTemplate) bool {
+	if f.MIMEType != "" && template.MIMEType != f.MIMEType {
+		return false
+	}
+	if template.URITemplate == nil || template.URITemplate.Template == nil {
+		return f.URIPrefix == ""
+	}
+	literal, _, _ := strings.Cut(template.URITemplate.Raw(), "{")
+	return strings.HasPrefix(literal, f.URIPrefix) || strings.HasPrefix(f.URIPrefix, literal)
+}
+
+func (r ListResourcesRequest) MarshalJSON() ([]byte, error) {
+	type plain ListResourcesRequest
+	return marshalWithFilter(plain(r), r.Filter)
+}
+
+func (r *ListResourcesRequest) UnmarshalJSON(data []byte) error {
+	type plain ListResourcesRequest
+	if err := unmarshalUseNumber(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.Filter = unmarshalFilter(data)
+	return nil
 }
 
 // ListResourcesResult is the server's response to a resources/list request
@@ -575,6 +886,72 @@ type ListResourcesResult struct {
 type ListResourceTemplatesRequest struct {
 	PaginatedRequest
 	Header http.Header `json:"-"`
+	// Filter is sent as the uriPrefix and mimeType params.
+	Filter ListResourcesFilter `json:"-"`
+}
+
+func (r ListResourceTemplatesRequest) MarshalJSON() ([]byte, error) {
+	type plain ListResourceTemplatesRequest
+	return marshalWithFilter(plain(r), r.Filter)
+}
+
+func (r *ListResourceTemplatesRequest) UnmarshalJSON(data []byte) error {
+	type plain ListResourceTemplatesRequest
+	if err := unmarshalUseNumber(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.Filter = unmarshalFilter(data)
+	return nil
+}
+
+// marshalWithFilter encodes a list request with the filter added to its
+// params.
+func marshalWithFilter(request any, filter ListResourcesFilter) ([]byte, error) {
+	data, err := json.Marshal(request)
+	if err != nil || filter.IsZero() {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	params := map[string]json.RawMessage{}
+	if raw, ok := fields["params"]; ok {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, err
+		}
+	}
+	if filter.URIPrefix != "" {
+		params["uriPrefix"], _ = json.Marshal(filter.URIPrefix)
+	}
+	if filter.MIMEType != "" {
+		params["mimeType"], _ = json.Marshal(filter.MIMEType)
+	}
+	if fields["params"], err = json.Marshal(params); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// unmarshalFilter decodes the filter params of a list request. Params of the
+// wrong type are ignored like unknown ones.
+func unmarshalFilter(data []byte) ListResourcesFilter {
+	var request struct {
+		Params struct {
+			URIPrefix any `json:"uriPrefix"`
+			MIMEType  any `json:"mimeType"`
+		} `json:"params"`
+	}
+	_ = json.Unmarshal(data, &request)
+	uriPrefix, _ := request.Params.URIPrefix.(string)
+	mimeType, _ := request.Params.MIMEType.(string)
+	return ListResourcesFilter{URIPrefix: uriPrefix, MIMEType: mimeType}
+}
+
+func unmarshalUseNumber(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
 }
 
 // ListResourceTemplatesResult is the server's response to a
@@ -654,6 +1031,21 @@ type ResourceUpdatedNotificationParams struct {
 	// The URI of the resource that has been updated. This might be a sub-
 	// resource of the one that the client actually subscribed to.
 	URI string `json:"uri"`
+	// Contents optionally carries the updated contents of the resource, so
+	// that clients do not need a follow-up resources/read. This is an
+	// extension; clients that don't know about it simply ignore the field.
+	Contents []ResourceContents `json:"contents,omitempty"`
+}
+
+// Icon is an image clients can display next to a tool, prompt or resource.
+type Icon struct {
+	// A URL pointing to the image, or a data: URI holding it inline.
+	Src string `json:"src"`
+	// The MIME type of the image, if it cannot be inferred from Src.
+	MIMEType string `json:"mimeType,omitempty"`
+	// The sizes the image is suitable for, such as "48x48", or "any" for
+	// scalable formats.
+	Sizes []string `json:"sizes,omitempty"`
 }
 
 // Resource represents a known resource that the server is capable of reading.
@@ -667,6 +1059,11 @@ type Resource struct {
 	//
 	// This can be used by clients to populate UI elements.
 	Name string `json:"name"`
+	// A display title for the resource, preferred over Name by clients that
+	// render it.
+	Title string `json:"title,omitempty"`
+	// Icons clients can display for the resource.
+	Icons []Icon `json:"icons,omitempty"`
 	// A description of what this resource represents.
 	//
 	// This can be used by clients to improve the LLM's understanding of
@@ -681,6 +1078,15 @@ func (r Resource) GetName() string {
 	return r.Name
 }
 
+// ForProtocolVersion returns the resource as it is sent to clients that
+// negotiated the protocol version, without a title if they don't know it.
+func (r Resource) ForProtocolVersion(version string) Resource {
+	if !SupportsTitles(version) {
+		r.Title = ""
+	}
+	return r
+}
+
 // ResourceTemplate represents a template description for resources available
 // on the server.
 type ResourceTemplate struct {
@@ -694,6 +1100,11 @@ type ResourceTemplate struct {
 	//
 	// This can be used by clients to populate UI elements.
 	Name string `json:"name"`
+	// A display title for the template, preferred over Name by clients that
+	// render it.
+	Title string `json:"title,omitempty"`
+	// Icons clients can display for the template.
+	Icons []Icon `json:"icons,omitempty"`
 	// A description of what this template is for.
 	//
 	// This can be used by clients to improve the LLM's understanding of
@@ -709,6 +1120,15 @@ func (rt ResourceTemplate) GetName() string {
 	return rt.Name
 }
 
+// ForProtocolVersion returns the template as it is sent to clients that
+// negotiated the protocol version, without a title if they don't know it.
+func (rt ResourceTemplate) ForProtocolVersion(version string) ResourceTemplate {
+	if !SupportsTitles(version) {
+		rt.Title = ""
+	}
+	return rt
+}
+
 // ResourceContents represents the contents of a specific resource or sub-
 // resource.
 type ResourceContents interface {
@@ -2,7 +2,9 @@ package mcp
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -138,3 +140,222 @@ func TestCallToolResultWithResourceLink(t *testing.T) {
 	assert.Equal(t, "A test document", resourceLink.Description)
 	assert.Equal(t, "application/pdf", resourceLink.MIMEType)
 }
+
+func TestRequestIdStringMatchesDecodedId(t *testing.T) {
+	tests := []struct {
+		name string
+		id   RequestId
+	}{
+		{name: "int", id: NewRequestId(42)},
+		{name: "int32", id: NewRequestId(int32(42))},
+		{name: "int64", id: NewRequestId(int64(42))},
+		{name: "string", id: NewRequestId("7f6c1e2a-uuid")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.id)
+			require.NoError(t, err)
+
+			var decoded RequestId
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tc.id.String(), decoded.String())
+		})
+	}
+}
+
+func TestRequestIdLargeNumbers(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		wantValue any
+	}{
+		{name: "max int64", json: "9223372036854775807", wantValue: int64(math.MaxInt64)},
+		{name: "beyond float64 precision", json: "9007199254740993", wantValue: int64(9007199254740993)},
+		{name: "negative", json: "-9007199254740993", wantValue: int64(-9007199254740993)},
+		{name: "max uint64", json: "18446744073709551615", wantValue: uint64(math.MaxUint64)},
+		{name: "beyond uint64", json: "123456789012345678901234567890", wantValue: json.Number("123456789012345678901234567890")},
+		{name: "fraction", json: "1.5", wantValue: 1.5},
+		{name: "integral float", json: "2.0", wantValue: int64(2)},
+		{name: "string", json: `"req:42:a"`, wantValue: "req:42:a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id RequestId
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &id))
+			assert.Equal(t, tt.wantValue, id.Value())
+
+			data, err := json.Marshal(id)
+			require.NoError(t, err)
+			if tt.json != "2.0" {
+				assert.Equal(t, tt.json, string(data), "the ID is sent back unchanged")
+			}
+
+			// The server decodes IDs as json.Number; both must match
+			if _, isString := tt.wantValue.(string); !isString {
+				assert.Equal(t, id.String(), NewRequestId(json.Number(tt.json)).String())
+			}
+		})
+	}
+
+	// A 64-bit ID survives a full message round trip
+	request := JSONRPCRequest{JSONRPC: JSONRPC_VERSION, ID: NewRequestId(int64(1<<62 + 1)), Request: Request{Method: "ping"}}
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	var decoded JSONRPCRequest
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, int64(1<<62+1), decoded.ID.Value())
+	assert.Equal(t, request.ID.String(), decoded.ID.String())
+
+	assert.Equal(t, NewRequestId(uint64(42)).String(), NewRequestId(42).String())
+	assert.Equal(t, NewRequestId(json.Number("42")).String(), NewRequestId(int64(42)).String())
+	assert.NotEqual(t, NewRequestId(int64(9007199254740992)).String(), NewRequestId(int64(9007199254740993)).String())
+}
+
+func TestStdioSessionTagging(t *testing.T) {
+	message := []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n")
+
+	tagged := TagStdioSession(message, "workspace-1")
+	assert.Equal(t, `{"mcpSession":"workspace-1","jsonrpc":"2.0","id":1,"method":"ping"}`+"\n", string(tagged))
+
+	key, untagged, err := UntagStdioSession(tagged)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-1", key)
+	assert.JSONEq(t, string(message), string(untagged))
+	assert.True(t, len(untagged) > 0 && untagged[len(untagged)-1] == '\n')
+
+	// Untagged messages belong to the default session and are left alone
+	key, untagged, err = UntagStdioSession(message)
+	require.NoError(t, err)
+	assert.Empty(t, key)
+	assert.Equal(t, message, untagged)
+	assert.Equal(t, message, TagStdioSession(message, ""))
+
+	assert.Equal(t, `{"mcpSession":"k"}`, string(TagStdioSession([]byte(`{}`), "k")))
+
+	_, _, err = UntagStdioSession([]byte(`{"mcpSession":1}`))
+	assert.Error(t, err)
+}
+
+func TestImplementationJSON(t *testing.T) {
+	t.Run("minimal", func(t *testing.T) {
+		data, err := json.Marshal(Implementation{Name: "server", Version: "1.0.0"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"server","version":"1.0.0"}`, string(data))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		input := `{
+			"name": "server",
+			"title": "My Server",
+			"version": "1.0.0",
+			"websiteUrl": "https://example.com",
+			"icons": [{"src": "https://example.com/icon.png"}],
+			"x-future": {"nested": [1, 2]}
+		}`
+		var info Implementation
+		require.NoError(t, json.Unmarshal([]byte(input), &info))
+		assert.Equal(t, "My Server", info.Title)
+		assert.Equal(t, "https://example.com", info.WebsiteURL)
+		assert.Equal(t, &[]Icon{{Src: "https://example.com/icon.png"}}, info.Icons)
+		assert.Equal(t, &map[string]any{"x-future": map[string]any{"nested": []any{1.0, 2.0}}}, info.AdditionalFields)
+
+		data, err := json.Marshal(info)
+		require.NoError(t, err)
+		assert.JSONEq(t, input, string(data))
+	})
+
+	t.Run("defined fields take precedence", func(t *testing.T) {
+		data, err := json.Marshal(Implementation{
+			Name:             "server",
+			Version:          "1.0.0",
+			AdditionalFields: &map[string]any{"name": "other", "x-build": "abc"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"server","version":"1.0.0","x-build":"abc"}`, string(data))
+	})
+
+	t.Run("comparable", func(t *testing.T) {
+		assert.True(t, Implementation{Name: "server", Version: "1.0.0"} == Implementation{Name: "server", Version: "1.0.0"})
+		assert.False(t, Implementation{Name: "server"} == Implementation{})
+	})
+}
+
+func TestListResourcesRequest_Filter(t *testing.T) {
+	request := ListResourcesRequest{Filter: ListResourcesFilter{URIPrefix: "file:///docs/", MIMEType: "text/markdown"}}
+	request.Method = string(MethodResourcesList)
+	request.Params.Cursor = "abc"
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"method": "resources/list",
+		"params": {"cursor": "abc", "uriPrefix": "file:///docs/", "mimeType": "text/markdown"}
+	}`, string(data))
+
+	var decoded ListResourcesRequest
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, request, decoded)
+
+	// Without a filter the request is encoded as before
+	data, err = json.Marshal(ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"method": "", "params": {}}`, string(data))
+
+	// Unknown params and filters of the wrong type are ignored
+	var lenient ListResourceTemplatesRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"method": "resources/templates/list",
+		"params": {"cursor": "abc", "uriPrefix": 5, "mimeType": "text/plain", "scheme": "file"}
+	}`), &lenient))
+	assert.Equal(t, Cursor("abc"), lenient.Params.Cursor)
+	assert.Equal(t, ListResourcesFilter{MIMEType: "text/plain"}, lenient.Filter)
+}
+
+func TestListResourcesFilter_Matches(t *testing.T) {
+	readme := Resource{URI: "file:///repo/README.md", MIMEType: "text/markdown"}
+	assert.True(t, ListResourcesFilter{}.MatchesResource(readme))
+	assert.True(t, ListResourcesFilter{URIPrefix: "file:///repo/"}.MatchesResource(readme))
+	assert.False(t, ListResourcesFilter{URIPrefix: "db://"}.MatchesResource(readme))
+	assert.False(t, ListResourcesFilter{URIPrefix: "file:///repo/", MIMEType: "text/plain"}.MatchesResource(readme))
+
+	files := NewResourceTemplate("file:///repo/{path}", "files", WithTemplateMIMEType("text/plain"))
+	tests := []struct {
+		filter ListResourcesFilter
+		want   bool
+	}{
+		{ListResourcesFilter{}, true},
+		{ListResourcesFilter{URIPrefix: "file://"}, true},
+		{ListResourcesFilter{URIPrefix: "file:///repo/docs/"}, true},
+		{ListResourcesFilter{URIPrefix: "db://"}, false},
+		{ListResourcesFilter{URIPrefix: "file:///other/"}, false},
+		{ListResourcesFilter{URIPrefix: "file://", MIMEType: "text/plain"}, true},
+		{ListResourcesFilter{MIMEType: "image/png"}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.filter.MatchesTemplate(files), "%+v", tt.filter)
+	}
+}
+
+func TestInitializeResult_TimeoutHints(t *testing.T) {
+	var result InitializeResult
+	assert.Nil(t, result.TimeoutHints())
+
+	result.SetTimeoutHints(map[string]time.Duration{
+		"resources/read":               2 * time.Second,
+		TimeoutHintKeyForTool("build"): 5 * time.Minute,
+	})
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"mcp-go/timeoutHints":{"resources/read":2000,"tools/call:build":300000}`)
+
+	var decoded InitializeResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]time.Duration{
+		"resources/read":   2 * time.Second,
+		"tools/call:build": 5 * time.Minute,
+	}, decoded.TimeoutHints())
+
+	// Malformed hints are ignored
+	require.NoError(t, json.Unmarshal([]byte(`{"_meta":{"mcp-go/timeoutHints":{"a":"slow","b":-1,"c":50}}}`), &decoded))
+	assert.Equal(t, map[string]time.Duration{"c": 50 * time.Millisecond}, decoded.TimeoutHints())
+}
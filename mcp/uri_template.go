@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"slices"
+
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// ParseURITemplate parses an RFC 6570 URI template, such as the URI
+// template of a ResourceTemplate, and returns the names of its variables in
+// order of first appearance.
+func ParseURITemplate(tmpl string) ([]string, error) {
+	template, err := uritemplate.New(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(template.Varnames()), nil
+}
+
+// ExpandURITemplate expands an RFC 6570 URI template with vars. Values are
+// percent-encoded as the expression requires, so that {name} with "a b"
+// gives "a%20b", and variables missing from vars expand to nothing.
+//
+//	uri, err := mcp.ExpandURITemplate("users://{id}/profile", map[string]string{"id": "42"})
+//	// uri == "users://42/profile"
+func ExpandURITemplate(tmpl string, vars map[string]string) (string, error) {
+	template, err := uritemplate.New(tmpl)
+	if err != nil {
+		return "", err
+	}
+	values := make(uritemplate.Values, len(vars))
+	for name, value := range vars {
+		values.Set(name, uritemplate.String(value))
+	}
+	return template.Expand(values)
+}
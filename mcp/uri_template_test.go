@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURITemplate(t *testing.T) {
+	names, err := ParseURITemplate("file://users/{userId}/documents/{docId}{?version,userId}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"userId", "docId", "version"}, names)
+
+	names, err = ParseURITemplate("test://static/resource")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = ParseURITemplate("users://{id")
+	assert.Error(t, err)
+}
+
+func TestExpandURITemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		vars     map[string]string
+		want     string
+	}{
+		{"simple", "users://{id}/profile", map[string]string{"id": "42"}, "users://42/profile"},
+		{"reserved characters are encoded", "search://{query}", map[string]string{"query": "a b/c?"}, "search://a%20b%2Fc%3F"},
+		{"missing variable", "users://{id}/{tab}", map[string]string{"id": "42"}, "users://42/"},
+		{"no variables", "test://static", nil, "test://static"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandURITemplate(tt.template, tt.vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ExpandURITemplate("users://{id", nil)
+	assert.Error(t, err)
+}
@@ -1,8 +1,12 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cast"
 )
@@ -131,6 +135,60 @@ func NewJSONRPCError(
 	}
 }
 
+// TagStdioSession adds the StdioSessionField for the given session key to an
+// encoded JSON-RPC message. The message is returned unchanged if key is empty
+// or it is not a JSON object.
+func TagStdioSession(message []byte, key string) []byte {
+	trimmed := bytes.TrimLeft(message, " \t\r\n")
+	if key == "" || len(trimmed) == 0 || trimmed[0] != '{' {
+		return message
+	}
+	quotedKey, _ := json.Marshal(key)
+
+	rest := trimmed[1:]
+	tagged := make([]byte, 0, len(message)+len(StdioSessionField)+len(quotedKey)+4)
+	tagged = append(tagged, '{', '"')
+	tagged = append(tagged, StdioSessionField...)
+	tagged = append(tagged, '"', ':')
+	tagged = append(tagged, quotedKey...)
+	if next := bytes.TrimLeft(rest, " \t\r\n"); len(next) > 0 && next[0] != '}' {
+		tagged = append(tagged, ',')
+	}
+	return append(tagged, rest...)
+}
+
+// UntagStdioSession removes the StdioSessionField from an encoded JSON-RPC
+// message, returning the session key it carried and the message without it.
+// Messages without the field are returned unchanged with an empty key.
+func UntagStdioSession(message []byte) (string, []byte, error) {
+	if !bytes.Contains(message, []byte(`"`+StdioSessionField+`"`)) {
+		return "", message, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return "", nil, err
+	}
+	raw, ok := fields[StdioSessionField]
+	if !ok {
+		return "", message, nil
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", nil, fmt.Errorf("invalid %s: %w", StdioSessionField, err)
+	}
+	delete(fields, StdioSessionField)
+
+	untagged, err := json.Marshal(fields)
+	if err != nil {
+		return "", nil, err
+	}
+	if bytes.HasSuffix(message, []byte("\n")) {
+		untagged = append(untagged, '\n')
+	}
+	return key, untagged, nil
+}
+
 // NewProgressNotification
 // Helper function for creating a progress notification
 func NewProgressNotification(
@@ -241,6 +299,15 @@ func NewEmbeddedResource(resource ResourceContents) EmbeddedResource {
 	}
 }
 
+// NewToolResultEmpty creates a new CallToolResult without any content, for
+// tools that perform an action and have nothing to return. The result is
+// successful and serializes with an empty content array.
+func NewToolResultEmpty() *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{},
+	}
+}
+
 // NewToolResultText creates a new CallToolResult with a text content
 func NewToolResultText(text string) *CallToolResult {
 	return &CallToolResult{
@@ -291,6 +358,85 @@ func NewToolResultStructuredOnly(structured any) *CallToolResult {
 	}
 }
 
+// NewToolResultStructuredJSON creates a new CallToolResult whose structured
+// content and text fallback share a single JSON encoding of structured. The
+// structured content is stored as json.RawMessage, so it is written out as is
+// instead of being marshalled a second time when the result is sent. This
+// matters for large outputs; for small ones NewToolResultStructuredOnly is
+// just as good and keeps the original value in StructuredContent.
+func NewToolResultStructuredJSON(structured any) *CallToolResult {
+	jsonBytes, err := json.Marshal(structured)
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{
+				TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error serializing structured content: %v", err),
+				},
+			},
+			StructuredContent: structured,
+		}
+	}
+
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+		StructuredContent: json.RawMessage(jsonBytes),
+	}
+}
+
+// NewToolResultStructuredAuto creates a new CallToolResult with structured
+// content and a text fallback generated by marshalling the structured value to
+// indented JSON, so the two representations can never drift apart. The text
+// content is marked as generated in its _meta.
+func NewToolResultStructuredAuto(structured any) *CallToolResult {
+	return NewToolResultStructuredAutoWithLimit(structured, 0)
+}
+
+// NewToolResultStructuredAutoWithLimit is like NewToolResultStructuredAuto but
+// truncates the generated text fallback to at most maxBytes bytes, appending a
+// note that the text was truncated. A maxBytes of zero or less means no limit.
+func NewToolResultStructuredAutoWithLimit(structured any, maxBytes int) *CallToolResult {
+	meta := map[string]any{MetaKeyGeneratedFallback: true}
+
+	var fallbackText string
+	jsonBytes, err := json.MarshalIndent(structured, "", "  ")
+	if err != nil {
+		fallbackText = fmt.Sprintf("Error serializing structured content: %v", err)
+	} else {
+		fallbackText = string(jsonBytes)
+	}
+
+	if maxBytes > 0 && len(fallbackText) > maxBytes {
+		total := len(fallbackText)
+		cut := maxBytes
+		// Don't split a multi-byte character
+		for cut > 0 && !utf8.RuneStart(fallbackText[cut]) {
+			cut--
+		}
+		fallbackText = fmt.Sprintf(
+			"%s\n... [truncated: showing %d of %d bytes, see structuredContent for the full result]",
+			fallbackText[:cut], cut, total,
+		)
+		meta[MetaKeyTruncated] = true
+	}
+
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Meta: NewMetaFromMap(meta),
+				Type: ContentTypeText,
+				Text: fallbackText,
+			},
+		},
+		StructuredContent: structured,
+	}
+}
+
 // NewToolResultImage creates a new CallToolResult with both text and image content
 func NewToolResultImage(text, imageData, mimeType string) *CallToolResult {
 	return &CallToolResult{
@@ -358,6 +504,25 @@ func NewToolResultError(text string) *CallToolResult {
 	}
 }
 
+// NewToolResultPartial creates an error CallToolResult for a call that only
+// partly succeeded, e.g. one that processed 3 of 5 items. The result starts
+// with errMsg as text, followed by content describing what did succeed.
+// IsError tells the client that the call did not fully succeed; it does not
+// mean the content is void, so clients should keep the content of partial
+// results rather than discard it.
+func NewToolResultPartial(content []Content, errMsg string) *CallToolResult {
+	blocks := make([]Content, 0, len(content)+1)
+	blocks = append(blocks, TextContent{
+		Type: ContentTypeText,
+		Text: errMsg,
+	})
+	blocks = append(blocks, content...)
+	return &CallToolResult{
+		Content: blocks,
+		IsError: true,
+	}
+}
+
 // NewToolResultErrorFromErr creates a new CallToolResult with an error message.
 // If an error is provided, its details will be appended to the text message.
 // Any errors that originate from the tool SHOULD be reported inside the result object.
@@ -391,6 +556,77 @@ func NewToolResultErrorf(format string, a ...any) *CallToolResult {
 	}
 }
 
+// NewToolResultErrorWithDetail creates an error CallToolResult that carries a
+// machine-readable code and optional detail in _meta.error, alongside message
+// as human-readable text. Using _meta keeps the payload independent of the
+// tool's output schema. See the ToolError* constants for recommended codes;
+// clients read the payload back with CallToolResult.GetErrorDetail.
+func NewToolResultErrorWithDetail(code string, message string, detail any) *CallToolResult {
+	result := NewToolResultError(message)
+	result.SetMeta(MetaKeyToolError, toolErrorDetail{
+		Code:    code,
+		Message: message,
+		Detail:  detail,
+	})
+	return result
+}
+
+// toolErrorDetail is the _meta.error payload of a tool result.
+type toolErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	Detail  any    `json:"detail,omitempty"`
+}
+
+// SamplingMeta is the _meta.sampling payload of a tool result that was
+// produced by sampling the client's LLM.
+type SamplingMeta struct {
+	Model      string `json:"model"`
+	StopReason string `json:"stopReason,omitempty"`
+}
+
+// NewToolResultFromSampling creates a tool result whose content is the message
+// returned by a sampling request, with the model and stop reason attached to
+// _meta (see AttachSamplingMeta). It returns an error if the message content
+// is not a supported content type.
+func NewToolResultFromSampling(sampling *CreateMessageResult) (*CallToolResult, error) {
+	if sampling == nil {
+		return nil, fmt.Errorf("sampling result is nil")
+	}
+
+	var content Content
+	switch c := sampling.Content.(type) {
+	case Content:
+		content = c
+	case map[string]any:
+		parsed, err := ParseContent(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sampling content: %w", err)
+		}
+		content = parsed
+	default:
+		return nil, fmt.Errorf("unsupported sampling content type: %T", sampling.Content)
+	}
+
+	result := &CallToolResult{Content: []Content{content}}
+	return AttachSamplingMeta(result, sampling), nil
+}
+
+// AttachSamplingMeta records the model and stop reason of a sampling result in
+// the tool result's _meta under MetaKeySampling and returns the tool result.
+// Use it when a tool combines its own output with sampled content; clients
+// read the payload back with CallToolResult.GetSamplingMeta.
+func AttachSamplingMeta(result *CallToolResult, sampling *CreateMessageResult) *CallToolResult {
+	if result == nil || sampling == nil {
+		return result
+	}
+	result.SetMeta(MetaKeySampling, SamplingMeta{
+		Model:      sampling.Model,
+		StopReason: sampling.StopReason,
+	})
+	return result
+}
+
 // NewListResourcesResult creates a new ListResourcesResult
 func NewListResourcesResult(
 	resources []Resource,
@@ -578,8 +814,9 @@ func ParseGetPromptResult(rawMessage *json.RawMessage) (*GetPromptResult, error)
 		}
 	}
 
+	// A server that built its result without messages sends null
 	messages, ok := jsonContent["messages"]
-	if ok {
+	if ok && messages != nil {
 		messagesArr, ok := messages.([]any)
 		if !ok {
 			return nil, fmt.Errorf("messages is not an array")
@@ -644,14 +881,14 @@ func ParseCallToolResult(rawMessage *json.RawMessage) (*CallToolResult, error) {
 		}
 	}
 
-	contents, ok := jsonContent["content"]
-	if !ok {
-		return nil, fmt.Errorf("content is missing")
-	}
-
-	contentArr, ok := contents.([]any)
-	if !ok {
-		return nil, fmt.Errorf("content is not an array")
+	// Tools with nothing to return may send an empty, null or (from lenient
+	// servers) missing content array
+	contentArr := []any{}
+	if contents, ok := jsonContent["content"]; ok && contents != nil {
+		contentArr, ok = contents.([]any)
+		if !ok {
+			return nil, fmt.Errorf("content is not an array")
+		}
 	}
 
 	for _, content := range contentArr {
@@ -706,6 +943,48 @@ func ParseResourceContents(contentMap map[string]any) (ResourceContents, error)
 	return nil, fmt.Errorf("unsupported resource type")
 }
 
+// ParseResourceUpdatedNotification extracts the URI and any inline contents
+// from a notifications/resources/updated notification. Contents is nil when
+// the server did not send the updated contents along.
+func ParseResourceUpdatedNotification(notification JSONRPCNotification) (*ResourceUpdatedNotificationParams, error) {
+	if notification.Method != MethodNotificationResourceUpdated {
+		return nil, fmt.Errorf("unexpected notification method: %s", notification.Method)
+	}
+
+	fields := notification.Params.AdditionalFields
+	uri, ok := fields["uri"].(string)
+	if !ok || uri == "" {
+		return nil, fmt.Errorf("resource uri is missing")
+	}
+	params := &ResourceUpdatedNotificationParams{URI: uri}
+
+	raw, ok := fields["contents"]
+	if !ok || raw == nil {
+		return params, nil
+	}
+	if contents, ok := raw.([]ResourceContents); ok {
+		params.Contents = contents
+		return params, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal contents: %w", err)
+	}
+	var contentMaps []map[string]any
+	if err := json.Unmarshal(data, &contentMaps); err != nil {
+		return nil, fmt.Errorf("contents is not an array of objects: %w", err)
+	}
+	for _, contentMap := range contentMaps {
+		contents, err := ParseResourceContents(contentMap)
+		if err != nil {
+			return nil, err
+		}
+		params.Contents = append(params.Contents, contents)
+	}
+	return params, nil
+}
+
 func ParseReadResourceResult(rawMessage *json.RawMessage) (*ReadResourceResult, error) {
 	if rawMessage == nil {
 		return nil, fmt.Errorf("response is nil")
@@ -763,98 +1042,302 @@ func ParseArgument(request CallToolRequest, key string, defaultVal any) any {
 	}
 }
 
+// parseArgumentE looks up key in the arguments of request and converts it
+// with convert. A missing key yields defaultValue; a value convert rejects
+// yields the zero value and an error naming the key and the value.
+func parseArgumentE[T any](request CallToolRequest, key string, defaultValue T, typeName string, convert func(any) (T, error)) (T, error) {
+	v, ok := request.GetArguments()[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	result, err := convert(v)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("argument %q: invalid %s value %#v", key, typeName, v)
+	}
+	return result, nil
+}
+
+// ParseBooleanE extracts and converts a boolean parameter from a CallToolRequest.
+// If the key is not found in the Arguments map, the defaultValue is returned
+// without error. Values that cannot be converted, such as "maybe", return an
+// error naming the key and the value.
+func ParseBooleanE(request CallToolRequest, key string, defaultValue bool) (bool, error) {
+	return parseArgumentE(request, key, defaultValue, "bool", cast.ToBoolE)
+}
+
 // ParseBoolean extracts and converts a boolean parameter from a CallToolRequest.
 // If the key is not found in the Arguments map, the defaultValue is returned.
 // The function uses cast.ToBool for conversion which handles various string representations
-// such as "true", "yes", "1", etc.
+// such as "true", "yes", "1", etc. Malformed values yield false; use
+// ParseBooleanE to detect them.
 func ParseBoolean(request CallToolRequest, key string, defaultValue bool) bool {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToBool(v)
+	v, _ := ParseBooleanE(request, key, defaultValue)
+	return v
+}
+
+// ParseInt64E extracts and converts an int64 parameter from a CallToolRequest.
+// If the key is not found in the Arguments map, the defaultValue is returned
+// without error. Malformed values return an error naming the key and the value.
+func ParseInt64E(request CallToolRequest, key string, defaultValue int64) (int64, error) {
+	return parseArgumentE(request, key, defaultValue, "int64", cast.ToInt64E)
 }
 
 // ParseInt64 extracts and converts an int64 parameter from a CallToolRequest.
 // If the key is not found in the Arguments map, the defaultValue is returned.
+// Malformed values yield 0; use ParseInt64E to detect them.
 func ParseInt64(request CallToolRequest, key string, defaultValue int64) int64 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToInt64(v)
+	v, _ := ParseInt64E(request, key, defaultValue)
+	return v
+}
+
+// ParseInt32E extracts and converts an int32 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseInt32E(request CallToolRequest, key string, defaultValue int32) (int32, error) {
+	return parseArgumentE(request, key, defaultValue, "int32", cast.ToInt32E)
 }
 
 // ParseInt32 extracts and converts an int32 parameter from a CallToolRequest.
 func ParseInt32(request CallToolRequest, key string, defaultValue int32) int32 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToInt32(v)
+	v, _ := ParseInt32E(request, key, defaultValue)
+	return v
+}
+
+// ParseInt16E extracts and converts an int16 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseInt16E(request CallToolRequest, key string, defaultValue int16) (int16, error) {
+	return parseArgumentE(request, key, defaultValue, "int16", cast.ToInt16E)
 }
 
 // ParseInt16 extracts and converts an int16 parameter from a CallToolRequest.
 func ParseInt16(request CallToolRequest, key string, defaultValue int16) int16 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToInt16(v)
+	v, _ := ParseInt16E(request, key, defaultValue)
+	return v
+}
+
+// ParseInt8E extracts and converts an int8 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseInt8E(request CallToolRequest, key string, defaultValue int8) (int8, error) {
+	return parseArgumentE(request, key, defaultValue, "int8", cast.ToInt8E)
 }
 
 // ParseInt8 extracts and converts an int8 parameter from a CallToolRequest.
 func ParseInt8(request CallToolRequest, key string, defaultValue int8) int8 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToInt8(v)
+	v, _ := ParseInt8E(request, key, defaultValue)
+	return v
+}
+
+// ParseIntE extracts and converts an int parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseIntE(request CallToolRequest, key string, defaultValue int) (int, error) {
+	return parseArgumentE(request, key, defaultValue, "int", cast.ToIntE)
 }
 
 // ParseInt extracts and converts an int parameter from a CallToolRequest.
 func ParseInt(request CallToolRequest, key string, defaultValue int) int {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToInt(v)
+	v, _ := ParseIntE(request, key, defaultValue)
+	return v
+}
+
+// ParseUIntE extracts and converts a uint parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseUIntE(request CallToolRequest, key string, defaultValue uint) (uint, error) {
+	return parseArgumentE(request, key, defaultValue, "uint", cast.ToUintE)
 }
 
-// ParseUInt extracts and converts an uint parameter from a CallToolRequest.
+// ParseUInt extracts and converts a uint parameter from a CallToolRequest.
 func ParseUInt(request CallToolRequest, key string, defaultValue uint) uint {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToUint(v)
+	v, _ := ParseUIntE(request, key, defaultValue)
+	return v
 }
 
-// ParseUInt64 extracts and converts an uint64 parameter from a CallToolRequest.
+// ParseUInt64E extracts and converts a uint64 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseUInt64E(request CallToolRequest, key string, defaultValue uint64) (uint64, error) {
+	return parseArgumentE(request, key, defaultValue, "uint64", cast.ToUint64E)
+}
+
+// ParseUInt64 extracts and converts a uint64 parameter from a CallToolRequest.
 func ParseUInt64(request CallToolRequest, key string, defaultValue uint64) uint64 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToUint64(v)
+	v, _ := ParseUInt64E(request, key, defaultValue)
+	return v
 }
 
-// ParseUInt32 extracts and converts an uint32 parameter from a CallToolRequest.
+// ParseUInt32E extracts and converts a uint32 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseUInt32E(request CallToolRequest, key string, defaultValue uint32) (uint32, error) {
+	return parseArgumentE(request, key, defaultValue, "uint32", cast.ToUint32E)
+}
+
+// ParseUInt32 extracts and converts a uint32 parameter from a CallToolRequest.
 func ParseUInt32(request CallToolRequest, key string, defaultValue uint32) uint32 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToUint32(v)
+	v, _ := ParseUInt32E(request, key, defaultValue)
+	return v
 }
 
-// ParseUInt16 extracts and converts an uint16 parameter from a CallToolRequest.
+// ParseUInt16E extracts and converts a uint16 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseUInt16E(request CallToolRequest, key string, defaultValue uint16) (uint16, error) {
+	return parseArgumentE(request, key, defaultValue, "uint16", cast.ToUint16E)
+}
+
+// ParseUInt16 extracts and converts a uint16 parameter from a CallToolRequest.
 func ParseUInt16(request CallToolRequest, key string, defaultValue uint16) uint16 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToUint16(v)
+	v, _ := ParseUInt16E(request, key, defaultValue)
+	return v
+}
+
+// ParseUInt8E extracts and converts a uint8 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseUInt8E(request CallToolRequest, key string, defaultValue uint8) (uint8, error) {
+	return parseArgumentE(request, key, defaultValue, "uint8", cast.ToUint8E)
 }
 
-// ParseUInt8 extracts and converts an uint8 parameter from a CallToolRequest.
+// ParseUInt8 extracts and converts a uint8 parameter from a CallToolRequest.
 func ParseUInt8(request CallToolRequest, key string, defaultValue uint8) uint8 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToUint8(v)
+	v, _ := ParseUInt8E(request, key, defaultValue)
+	return v
+}
+
+// ParseFloat32E extracts and converts a float32 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseFloat32E(request CallToolRequest, key string, defaultValue float32) (float32, error) {
+	return parseArgumentE(request, key, defaultValue, "float32", cast.ToFloat32E)
 }
 
 // ParseFloat32 extracts and converts a float32 parameter from a CallToolRequest.
 func ParseFloat32(request CallToolRequest, key string, defaultValue float32) float32 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToFloat32(v)
+	v, _ := ParseFloat32E(request, key, defaultValue)
+	return v
+}
+
+// ParseFloat64E extracts and converts a float64 parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseFloat64E(request CallToolRequest, key string, defaultValue float64) (float64, error) {
+	return parseArgumentE(request, key, defaultValue, "float64", cast.ToFloat64E)
 }
 
 // ParseFloat64 extracts and converts a float64 parameter from a CallToolRequest.
 func ParseFloat64(request CallToolRequest, key string, defaultValue float64) float64 {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToFloat64(v)
+	v, _ := ParseFloat64E(request, key, defaultValue)
+	return v
+}
+
+// ParseStringE extracts and converts a string parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseStringE(request CallToolRequest, key string, defaultValue string) (string, error) {
+	return parseArgumentE(request, key, defaultValue, "string", cast.ToStringE)
 }
 
 // ParseString extracts and converts a string parameter from a CallToolRequest.
 func ParseString(request CallToolRequest, key string, defaultValue string) string {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToString(v)
+	v, _ := ParseStringE(request, key, defaultValue)
+	return v
+}
+
+// ParseStringMapE extracts and converts a string map parameter from a CallToolRequest,
+// returning an error if the value cannot be converted.
+func ParseStringMapE(request CallToolRequest, key string, defaultValue map[string]any) (map[string]any, error) {
+	return parseArgumentE(request, key, defaultValue, "object", cast.ToStringMapE)
 }
 
 // ParseStringMap extracts and converts a string map parameter from a CallToolRequest.
+// Malformed values yield an empty map; use ParseStringMapE to detect them.
 func ParseStringMap(request CallToolRequest, key string, defaultValue map[string]any) map[string]any {
-	v := ParseArgument(request, key, defaultValue)
-	return cast.ToStringMap(v)
+	v, err := ParseStringMapE(request, key, defaultValue)
+	if err != nil {
+		return map[string]any{}
+	}
+	return v
+}
+
+// ParseTimeE extracts a timestamp parameter from a CallToolRequest. The value
+// must be a string in one of layouts, tried in order; without layouts it must
+// be in RFC 3339 format. If the key is not found in the Arguments map, the
+// defaultValue is returned without error.
+func ParseTimeE(request CallToolRequest, key string, defaultValue time.Time, layouts ...string) (time.Time, error) {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	return parseArgumentE(request, key, defaultValue, "time", func(v any) (time.Time, error) {
+		str, ok := v.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("not a string")
+		}
+		var err error
+		for _, layout := range layouts {
+			var t time.Time
+			if t, err = time.Parse(layout, str); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, err
+	})
+}
+
+// ParseTime extracts a timestamp parameter from a CallToolRequest, like
+// ParseTimeE, returning defaultValue if the value is missing or malformed.
+func ParseTime(request CallToolRequest, key string, defaultValue time.Time, layouts ...string) time.Time {
+	v, err := ParseTimeE(request, key, defaultValue, layouts...)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+// ParseDurationE extracts a duration parameter from a CallToolRequest. The
+// value must be a string accepted by time.ParseDuration, such as "1h30m". If
+// the key is not found in the Arguments map, the defaultValue is returned
+// without error.
+func ParseDurationE(request CallToolRequest, key string, defaultValue time.Duration) (time.Duration, error) {
+	return parseArgumentE(request, key, defaultValue, "duration", func(v any) (time.Duration, error) {
+		str, ok := v.(string)
+		if !ok {
+			return 0, fmt.Errorf("not a string")
+		}
+		return time.ParseDuration(str)
+	})
+}
+
+// ParseDuration extracts a duration parameter from a CallToolRequest, like
+// ParseDurationE, returning defaultValue if the value is missing or malformed.
+func ParseDuration(request CallToolRequest, key string, defaultValue time.Duration) time.Duration {
+	v, err := ParseDurationE(request, key, defaultValue)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+// ParseEnumE extracts a string parameter from a CallToolRequest that must be
+// one of allowed. If the key is not found in the Arguments map, the
+// defaultValue is returned without error.
+func ParseEnumE(request CallToolRequest, key string, allowed []string, defaultValue string) (string, error) {
+	v, err := parseArgumentE(request, key, defaultValue, "string", func(v any) (string, error) {
+		str, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("not a string")
+		}
+		return str, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if v != defaultValue && !slices.Contains(allowed, v) {
+		return "", fmt.Errorf("argument %q: %q is not one of %q", key, v, allowed)
+	}
+	return v, nil
+}
+
+// ParseEnum extracts a string parameter from a CallToolRequest that must be
+// one of allowed, like ParseEnumE, returning defaultValue if the value is
+// missing or not allowed.
+func ParseEnum(request CallToolRequest, key string, allowed []string, defaultValue string) string {
+	v, err := ParseEnumE(request, key, allowed, defaultValue)
+	if err != nil {
+		return defaultValue
+	}
+	return v
 }
 
 // ToBoolPtr returns a pointer to the given boolean value
@@ -4,6 +4,7 @@ package mcptest
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -34,6 +35,14 @@ type Server struct {
 
 	logBuffer bytes.Buffer
 
+	// logLevel is the level from which log notifications are captured;
+	// empty if CaptureLogs was not called.
+	logLevel mcp.LoggingLevel
+	logsMu   sync.Mutex
+	logs     []mcp.LoggingMessageNotification
+	// logsChanged is closed and replaced whenever a log is recorded.
+	logsChanged chan struct{}
+
 	transport transport.Interface
 	client    *client.Client
 
@@ -120,6 +129,72 @@ func (s *Server) AddResourceTemplates(templates ...server.ServerResourceTemplate
 	s.resourceTemplates = append(s.resourceTemplates, templates...)
 }
 
+// CaptureLogs makes an unstarted server declare the logging capability and
+// record the log notifications its handlers send at level or above, e.g.
+// with server.SendLogMessageToClient. Use Logs or WaitForLogs to inspect them.
+func (s *Server) CaptureLogs(level mcp.LoggingLevel) {
+	s.logLevel = level
+	s.logsChanged = make(chan struct{})
+}
+
+// Logs returns the log notifications the client received so far, in the
+// order they were sent. It returns nil if CaptureLogs was not called.
+//
+// The server writes notifications independently of responses, so a log sent
+// by a handler may arrive after the request returns. Use WaitForLogs to wait
+// for the logs a test expects.
+func (s *Server) Logs() []mcp.LoggingMessageNotification {
+	s.logsMu.Lock()
+	defer s.logsMu.Unlock()
+	return append([]mcp.LoggingMessageNotification(nil), s.logs...)
+}
+
+// WaitForLogs blocks until the client received at least n log notifications
+// and returns all of them, like Logs. It returns the logs received so far and
+// the context's error if ctx is done first.
+func (s *Server) WaitForLogs(ctx context.Context, n int) ([]mcp.LoggingMessageNotification, error) {
+	for {
+		s.logsMu.Lock()
+		logs := append([]mcp.LoggingMessageNotification(nil), s.logs...)
+		changed := s.logsChanged
+		s.logsMu.Unlock()
+
+		if len(logs) >= n {
+			return logs, nil
+		}
+		if changed == nil {
+			return logs, fmt.Errorf("CaptureLogs was not called")
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return logs, ctx.Err()
+		}
+	}
+}
+
+// recordLog stores notification if it is a log notification. It is
+// registered with the client's OnNotification.
+func (s *Server) recordLog(notification mcp.JSONRPCNotification) {
+	if notification.Method != "notifications/message" {
+		return
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+	var logNotification mcp.LoggingMessageNotification
+	if err := json.Unmarshal(data, &logNotification); err != nil {
+		return
+	}
+
+	s.logsMu.Lock()
+	defer s.logsMu.Unlock()
+	s.logs = append(s.logs, logNotification)
+	close(s.logsChanged)
+	s.logsChanged = make(chan struct{})
+}
+
 // Start starts the server in a goroutine. Make sure to defer Close() after Start().
 // When using NewServer(), the returned server is already started.
 func (s *Server) Start(ctx context.Context) error {
@@ -131,7 +206,11 @@ func (s *Server) Start(ctx context.Context) error {
 	go func() {
 		defer s.wg.Done()
 
-		mcpServer := server.NewMCPServer(s.name, "1.0.0")
+		var opts []server.ServerOption
+		if s.logLevel != "" {
+			opts = append(opts, server.WithLogging())
+		}
+		mcpServer := server.NewMCPServer(s.name, "1.0.0", opts...)
 
 		mcpServer.AddTools(s.tools...)
 		mcpServer.AddPrompts(s.prompts...)
@@ -149,11 +228,13 @@ func (s *Server) Start(ctx context.Context) error {
 	}()
 
 	s.transport = transport.NewIO(s.clientReader, s.clientWriter, io.NopCloser(&s.logBuffer))
-	if err := s.transport.Start(ctx); err != nil {
-		return fmt.Errorf("transport.Start(): %w", err)
-	}
-
 	s.client = client.NewClient(s.transport)
+	if s.logLevel != "" {
+		s.client.OnNotification(s.recordLog)
+	}
+	if err := s.client.Start(ctx); err != nil {
+		return fmt.Errorf("client.Start(): %w", err)
+	}
 
 	var initReq mcp.InitializeRequest
 	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
@@ -161,6 +242,14 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("client.Initialize(): %w", err)
 	}
 
+	if s.logLevel != "" {
+		var setLevelReq mcp.SetLevelRequest
+		setLevelReq.Params.Level = s.logLevel
+		if err := s.client.SetLevel(ctx, setLevelReq); err != nil {
+			return fmt.Errorf("client.SetLevel(): %w", err)
+		}
+	}
+
 	return nil
 }
 
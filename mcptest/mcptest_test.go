@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/mcptest"
@@ -352,3 +353,53 @@ func TestServerWithResourceTemplate(t *testing.T) {
 		t.Errorf("Got %q, want %q", textContent.Text, want)
 	}
 }
+
+func TestServerCaptureLogs(t *testing.T) {
+	ctx := context.Background()
+
+	srv := mcptest.NewUnstartedServer(t)
+	defer srv.Close()
+
+	srv.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s := server.ServerFromContext(ctx)
+		for _, level := range []mcp.LoggingLevel{mcp.LoggingLevelDebug, mcp.LoggingLevelInfo, mcp.LoggingLevelWarning} {
+			notification := mcp.NewLoggingMessageNotification(level, "work", fmt.Sprintf("%s message", level))
+			if err := s.SendLogMessageToClient(ctx, notification); err != nil {
+				return nil, err
+			}
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	srv.CaptureLogs(mcp.LoggingLevelInfo)
+
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "work"
+	if _, err := srv.Client().CallTool(ctx, req); err != nil {
+		t.Fatal("CallTool:", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	logs, err := srv.WaitForLogs(waitCtx, 2)
+	if err != nil {
+		t.Fatal("WaitForLogs:", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Got %d logs, want 2: %+v", len(logs), logs)
+	}
+	for i, level := range []mcp.LoggingLevel{mcp.LoggingLevelInfo, mcp.LoggingLevelWarning} {
+		if logs[i].Params.Level != level {
+			t.Errorf("Log %d: got level %q, want %q", i, logs[i].Params.Level, level)
+		}
+		if logs[i].Params.Logger != "work" {
+			t.Errorf("Log %d: got logger %q, want %q", i, logs[i].Params.Logger, "work")
+		}
+		if want := fmt.Sprintf("%s message", level); logs[i].Params.Data != want {
+			t.Errorf("Log %d: got data %v, want %q", i, logs[i].Params.Data, want)
+		}
+	}
+}
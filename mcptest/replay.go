@@ -0,0 +1,297 @@
+package mcptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReplayOption configures Replay.
+type ReplayOption func(*replayConfig)
+
+type replayConfig struct {
+	ignored [][]string
+}
+
+// WithIgnoredFields excludes volatile fields, such as timestamps or generated
+// IDs, from the comparison of replayed responses. A path is a dot-separated
+// list of object keys and array indices starting at the JSON-RPC message,
+// e.g. "result._meta.requestedAt"; a "*" segment matches any key or index,
+// e.g. "result.content.*.text".
+func WithIgnoredFields(paths ...string) ReplayOption {
+	return func(c *replayConfig) {
+		for _, path := range paths {
+			c.ignored = append(c.ignored, strings.Split(path, "."))
+		}
+	}
+}
+
+func (c *replayConfig) isIgnored(path []string) bool {
+	for _, pattern := range c.ignored {
+		if len(pattern) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay feeds the inbound messages of a recording made with
+// server.WithTrafficRecorder back through srv.HandleMessage, in order, and
+// compares every response with the recorded one. Each divergence is reported
+// with t.Errorf, listing the differing fields. Messages recorded within a
+// session are replayed within an in-process session with the same ID;
+// notifications sent by the server are not compared, and neither are the
+// errorId fields of error responses.
+func Replay(t testing.TB, r io.Reader, srv *server.MCPServer, opts ...ReplayOption) {
+	t.Helper()
+
+	// Error IDs are random, so they never match the recorded ones
+	config := &replayConfig{ignored: [][]string{{"error", "data", "errorId"}}}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	records, err := readTrafficRecords(r)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+		return
+	}
+
+	// Index the recorded responses so each request finds its own even when
+	// requests were handled concurrently
+	responses := make(map[string][]json.RawMessage)
+	for _, record := range records {
+		if record.Direction != server.TrafficOutbound {
+			continue
+		}
+		header, ok := parseMessageHeader(record.Message)
+		if ok && header.Method == "" && len(header.ID) > 0 {
+			key := responseKey(record.SessionID, header.ID)
+			responses[key] = append(responses[key], record.Message)
+		}
+	}
+
+	ctx := context.Background()
+	sessions := make(map[string]*server.InProcessSession)
+	defer func() {
+		for id := range sessions {
+			srv.UnregisterSession(ctx, id)
+		}
+	}()
+
+	for i, record := range records {
+		if record.Direction != server.TrafficInbound {
+			continue
+		}
+
+		requestCtx := ctx
+		if record.SessionID != "" {
+			session, ok := sessions[record.SessionID]
+			if !ok {
+				session = server.NewInProcessSession(record.SessionID, nil)
+				if err := srv.RegisterSession(ctx, session); err != nil {
+					t.Fatalf("replay: registering session %q: %v", record.SessionID, err)
+					return
+				}
+				sessions[record.SessionID] = session
+			}
+			requestCtx = srv.WithContext(ctx, session)
+		}
+
+		message := []byte(record.Message)
+		var malformed string
+		if json.Unmarshal(record.Message, &malformed) == nil {
+			// Invalid input is recorded as a JSON string of the raw bytes
+			message = []byte(malformed)
+		}
+		got := srv.HandleMessage(requestCtx, message)
+
+		header, ok := parseMessageHeader(record.Message)
+		if !ok || header.Method == "" || len(header.ID) == 0 {
+			continue
+		}
+		key := responseKey(record.SessionID, header.ID)
+		var want json.RawMessage
+		if recorded := responses[key]; len(recorded) > 0 {
+			want, responses[key] = recorded[0], recorded[1:]
+		}
+
+		desc := fmt.Sprintf("record %d: %s request %s", i+1, header.Method, header.ID)
+		switch {
+		case want == nil && got == nil:
+		case want == nil:
+			t.Errorf("replay: %s: got a response, but none was recorded", desc)
+		case got == nil:
+			t.Errorf("replay: %s: got no response, but one was recorded", desc)
+		default:
+			gotJSON, err := json.Marshal(got)
+			if err != nil {
+				t.Errorf("replay: %s: marshaling response: %v", desc, err)
+				continue
+			}
+			if diffs := config.diff(want, gotJSON); len(diffs) > 0 {
+				t.Errorf("replay: %s: response diverges from the recording:\n%s", desc, strings.Join(diffs, "\n"))
+			}
+		}
+	}
+}
+
+func readTrafficRecords(r io.Reader) ([]server.TrafficRecord, error) {
+	var records []server.TrafficRecord
+	decoder := json.NewDecoder(r)
+	for {
+		var record server.TrafficRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("reading record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+type messageHeader struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+func parseMessageHeader(message json.RawMessage) (messageHeader, bool) {
+	var header messageHeader
+	if err := json.Unmarshal(message, &header); err != nil {
+		return header, false
+	}
+	if bytes.Equal(header.ID, []byte("null")) {
+		header.ID = nil
+	}
+	return header, true
+}
+
+func responseKey(sessionID string, id json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, id); err != nil {
+		return sessionID + "\x00" + string(id)
+	}
+	return sessionID + "\x00" + compact.String()
+}
+
+// diff returns a line for every field that differs between the recorded and
+// the replayed message, skipping ignored fields.
+func (c *replayConfig) diff(want, got json.RawMessage) []string {
+	wantValue, err := decodeJSON(want)
+	if err != nil {
+		return []string{fmt.Sprintf("  recorded response is not valid JSON: %v", err)}
+	}
+	gotValue, err := decodeJSON(got)
+	if err != nil {
+		return []string{fmt.Sprintf("  replayed response is not valid JSON: %v", err)}
+	}
+	var diffs []string
+	c.diffValues(nil, wantValue, gotValue, &diffs)
+	return diffs
+}
+
+func (c *replayConfig) diffValues(path []string, want, got any, diffs *[]string) {
+	if c.isIgnored(path) {
+		return
+	}
+
+	switch want := want.(type) {
+	case map[string]any:
+		if got, ok := got.(map[string]any); ok {
+			keys := make([]string, 0, len(want)+len(got))
+			for key := range want {
+				keys = append(keys, key)
+			}
+			for key := range got {
+				if _, ok := want[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				wantItem, ok := want[key]
+				if !ok {
+					wantItem = missing{}
+				}
+				gotItem, ok := got[key]
+				if !ok {
+					gotItem = missing{}
+				}
+				c.diffValues(appendPath(path, key), wantItem, gotItem, diffs)
+			}
+			return
+		}
+	case []any:
+		if got, ok := got.([]any); ok {
+			for i := 0; i < len(want) || i < len(got); i++ {
+				var wantItem, gotItem any = missing{}, missing{}
+				if i < len(want) {
+					wantItem = want[i]
+				}
+				if i < len(got) {
+					gotItem = got[i]
+				}
+				c.diffValues(appendPath(path, strconv.Itoa(i)), wantItem, gotItem, diffs)
+			}
+			return
+		}
+	}
+
+	wantText, gotText := formatJSONValue(want), formatJSONValue(got)
+	if wantText != gotText {
+		name := strings.Join(path, ".")
+		if name == "" {
+			name = "(message)"
+		}
+		*diffs = append(*diffs, fmt.Sprintf("  %s: recorded %s, replayed %s", name, wantText, gotText))
+	}
+}
+
+// missing stands for a field or array item present on only one side of a
+// diff.
+type missing struct{}
+
+func formatJSONValue(v any) string {
+	if _, ok := v.(missing); ok {
+		return "<missing>"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func decodeJSON(data json.RawMessage) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func appendPath(path []string, segment string) []string {
+	return append(append([]string(nil), path...), segment)
+}
@@ -0,0 +1,165 @@
+package mcptest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newReplayServer(greeting string, opts ...server.ServerOption) *server.MCPServer {
+	srv := server.NewMCPServer("replay-server", "1.0.0", opts...)
+	srv.AddTool(
+		mcp.NewTool("greet", mcp.WithString("name", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(fmt.Sprintf("%s, %s!", greeting, request.GetString("name", ""))), nil
+		},
+	)
+	srv.AddTool(
+		mcp.NewTool("now"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(time.Now().Format(time.RFC3339Nano)), nil
+		},
+	)
+	return srv
+}
+
+func recordSession(t *testing.T, srv *server.MCPServer) {
+	t.Helper()
+	ctx := context.Background()
+
+	c, err := client.NewInProcessClient(srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var initRequest mcp.InitializeRequest
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "replay-client", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatal("Initialize:", err)
+	}
+	if _, err := c.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+		t.Fatal("ListTools:", err)
+	}
+
+	var greet mcp.CallToolRequest
+	greet.Params.Name = "greet"
+	greet.Params.Arguments = map[string]any{"name": "Ada"}
+	if _, err := c.CallTool(ctx, greet); err != nil {
+		t.Fatal("CallTool:", err)
+	}
+
+	var now mcp.CallToolRequest
+	now.Params.Name = "now"
+	if _, err := c.CallTool(ctx, now); err != nil {
+		t.Fatal("CallTool:", err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var recording bytes.Buffer
+	srv := newReplayServer("Hello", server.WithTrafficRecorder(&recording))
+	recordSession(t, srv)
+
+	if got := strings.Count(recording.String(), "\n"); got < 8 {
+		t.Fatalf("got %d recorded messages, want at least 8:\n%s", got, recording.String())
+	}
+
+	// The time returned by "now" differs on every call
+	mcptest.Replay(t, bytes.NewReader(recording.Bytes()), srv,
+		mcptest.WithIgnoredFields("result.content.*.text"),
+	)
+	mcptest.Replay(t, bytes.NewReader(recording.Bytes()), newReplayServer("Hello"),
+		mcptest.WithIgnoredFields("result.content.*.text"),
+	)
+}
+
+// divergenceRecorder collects the failures reported by Replay instead of
+// failing the test.
+type divergenceRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *divergenceRecorder) Helper() {}
+
+func (r *divergenceRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *divergenceRecorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestReplay_Divergence(t *testing.T) {
+	var recording bytes.Buffer
+	recordSession(t, newReplayServer("Hello", server.WithTrafficRecorder(&recording)))
+
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.Replay(recorder, bytes.NewReader(recording.Bytes()), newReplayServer("Howdy"),
+		mcptest.WithIgnoredFields("result.content.0.text.ignored"),
+	)
+
+	var divergences []string
+	for _, err := range recorder.errors {
+		if strings.Contains(err, "diverges") {
+			divergences = append(divergences, err)
+		}
+	}
+	// Only the greeting changed; "now" differs too since its text is not ignored
+	if len(divergences) != 2 {
+		t.Fatalf("got %d divergences, want 2: %q", len(divergences), recorder.errors)
+	}
+	want := `result.content.0.text: recorded "Hello, Ada!", replayed "Howdy, Ada!"`
+	if !strings.Contains(divergences[0], want) {
+		t.Errorf("divergence %q does not contain %q", divergences[0], want)
+	}
+}
+
+func TestTrafficRecorder_Redaction(t *testing.T) {
+	var recording bytes.Buffer
+	srv := newReplayServer("Hello", server.WithTrafficRecorder(&recording,
+		server.WithTrafficRedactor(func(direction server.TrafficDirection, message json.RawMessage) json.RawMessage {
+			return bytes.ReplaceAll(message, []byte("Ada"), []byte("[redacted]"))
+		}),
+	))
+	recordSession(t, srv)
+
+	if strings.Contains(recording.String(), "Ada") {
+		t.Errorf("recording contains the redacted name:\n%s", recording.String())
+	}
+
+	var inbound, outbound int
+	for _, line := range strings.Split(strings.TrimSpace(recording.String()), "\n") {
+		var record server.TrafficRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		if record.Time.IsZero() {
+			t.Errorf("record %q has no timestamp", line)
+		}
+		switch record.Direction {
+		case server.TrafficInbound:
+			inbound++
+		case server.TrafficOutbound:
+			outbound++
+		}
+	}
+	// initialize, notifications/initialized, tools/list and two tools/call
+	if inbound != 5 || outbound != 4 {
+		t.Errorf("got %d inbound and %d outbound records, want 5 and 4", inbound, outbound)
+	}
+}
@@ -0,0 +1,308 @@
+package mcptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// updateEnv is the environment variable that, set to a true value like 1,
+// makes the snapshot assertions rewrite the golden files with the actual
+// output instead of comparing against them.
+const updateEnv = "MCPTEST_UPDATE"
+
+// updateGoldenFiles reports whether the golden files should be rewritten:
+// if updateEnv is true, or if the test binary defines an -update flag, as
+// packages with golden files of their own often do, and it is set. mcptest
+// declares no flag itself, so it does not conflict with those.
+func updateGoldenFiles() bool {
+	if update, err := strconv.ParseBool(os.Getenv(updateEnv)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, err := strconv.ParseBool(f.Value.String())
+		return err == nil && update
+	}
+	return false
+}
+
+// Normalizer replaces volatile values, such as timestamps or generated IDs,
+// before a snapshot is compared. It is called for every value of the JSON
+// document, innermost first, with its dot-separated path of object keys and
+// array indices, e.g. "content.0.text", and returns the value to keep.
+type Normalizer func(path string, value any) any
+
+// SnapshotOption configures the snapshot assertions.
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	normalizers []Normalizer
+}
+
+// WithNormalizer adds a normalizer that is applied before the snapshot is
+// compared or written. Normalizers run in the order they were added.
+func WithNormalizer(normalizer Normalizer) SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.normalizers = append(c.normalizers, normalizer)
+	}
+}
+
+// AssertToolResult compares result with the golden file at goldenPath. The
+// result is serialized as indented JSON with sorted keys, so the file is
+// stable and readable; a mismatch is reported with t.Errorf as a unified diff
+// of the golden file and the actual output. Run the tests with
+// MCPTEST_UPDATE=1, or with -update if the test package defines that flag, to
+// write the golden file instead.
+func AssertToolResult(t testing.TB, result *mcp.CallToolResult, goldenPath string, opts ...SnapshotOption) {
+	t.Helper()
+	assertSnapshot(t, result, goldenPath, opts)
+}
+
+// CallAndSnapshot calls the tool described by request and compares the result
+// with the golden file at goldenPath like AssertToolResult. It fails the test
+// if the call fails, and returns the result otherwise.
+func CallAndSnapshot(t testing.TB, c client.MCPClient, request mcp.CallToolRequest, goldenPath string, opts ...SnapshotOption) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := c.CallTool(context.Background(), request)
+	if err != nil {
+		t.Fatalf("mcptest: calling tool %q: %v", request.Params.Name, err)
+		return nil
+	}
+	assertSnapshot(t, result, goldenPath, opts)
+	return result
+}
+
+// AssertTools compares tools, sorted by name, with the golden file at
+// goldenPath like AssertToolResult. Snapshotting a server's tools locks its
+// public surface: changing a name, description or schema fails the test until
+// the golden file is updated.
+func AssertTools(t testing.TB, tools []mcp.Tool, goldenPath string, opts ...SnapshotOption) {
+	t.Helper()
+
+	sorted := append([]mcp.Tool(nil), tools...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	assertSnapshot(t, sorted, goldenPath, opts)
+}
+
+// ListToolsAndSnapshot lists all tools of the server and compares them with
+// the golden file at goldenPath like AssertTools. It fails the test if the
+// tools cannot be listed, and returns them otherwise.
+func ListToolsAndSnapshot(t testing.TB, c client.MCPClient, goldenPath string, opts ...SnapshotOption) []mcp.Tool {
+	t.Helper()
+
+	result, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("mcptest: listing tools: %v", err)
+		return nil
+	}
+	AssertTools(t, result.Tools, goldenPath, opts...)
+	return result.Tools
+}
+
+func assertSnapshot(t testing.TB, v any, goldenPath string, opts []SnapshotOption) {
+	t.Helper()
+
+	config := &snapshotConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	got, err := config.serialize(v)
+	if err != nil {
+		t.Fatalf("mcptest: serializing snapshot: %v", err)
+		return
+	}
+
+	if updateGoldenFiles() {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("mcptest: creating golden file directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatalf("mcptest: writing golden file: %v", err)
+			return
+		}
+		t.Logf("mcptest: updated golden file %s", goldenPath)
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("mcptest: golden file %s does not exist; run the tests with MCPTEST_UPDATE=1 to create it", goldenPath)
+		return
+	}
+	if err != nil {
+		t.Fatalf("mcptest: reading golden file: %v", err)
+		return
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("mcptest: snapshot does not match %s (run the tests with MCPTEST_UPDATE=1 to rewrite it):\n%s",
+			goldenPath, unifiedDiff(goldenPath, "actual", string(want), string(got)))
+	}
+}
+
+// serialize encodes v as indented JSON with sorted keys after applying the
+// normalizers.
+func (c *snapshotConfig) serialize(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.normalizers) > 0 {
+		value = c.normalize(nil, value)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *snapshotConfig) normalize(path []string, value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = c.normalize(appendPath(path, key), item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = c.normalize(appendPath(path, strconv.Itoa(i)), item)
+		}
+	}
+	name := strings.Join(path, ".")
+	for _, normalizer := range c.normalizers {
+		value = normalizer(name, value)
+	}
+	return value
+}
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+	// a and b are the indices of the line in the old and the new text, or
+	// of the next line for lines missing from that text.
+	a, b int
+}
+
+// unifiedDiff returns the differences between the old and new text in
+// unified diff format.
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(lines); {
+		// Find the next change and extend the hunk while the following
+		// change is close enough to share its context
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(lines) && i <= last+2*diffContext+1; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+		from, to := max(first-diffContext, start), min(last+diffContext+1, len(lines))
+
+		var oldCount, newCount int
+		for _, line := range lines[from:to] {
+			if line.op != '+' {
+				oldCount++
+			}
+			if line.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(lines[from].a, oldCount), hunkRange(lines[from].b, newCount))
+		for _, line := range lines[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", line.op, line.text)
+		}
+		start = to
+	}
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range names the line before it
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffLines computes a line diff of a and b from their longest common
+// subsequence. Snapshots are small, so the quadratic table is fine.
+func diffLines(a, b []string) []diffLine {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{op: ' ', text: a[i], a: i, b: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{op: '-', text: a[i], a: i, b: j})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,200 @@
+package mcptest_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// withUpdate runs f as if the tests had been started with MCPTEST_UPDATE=1.
+func withUpdate(t *testing.T, f func()) {
+	t.Helper()
+	t.Setenv("MCPTEST_UPDATE", "1")
+	f()
+	t.Setenv("MCPTEST_UPDATE", "")
+}
+
+func TestAssertToolResult_UpdateAndMatch(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "testdata", "greet.golden")
+	result := mcp.NewToolResultText("Hello, Ada!")
+
+	withUpdate(t, func() {
+		mcptest.AssertToolResult(t, result, golden)
+	})
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal("golden file was not written:", err)
+	}
+	want := `{
+  "content": [
+    {
+      "text": "Hello, Ada!",
+      "type": "text"
+    }
+  ]
+}
+`
+	if string(data) != want {
+		t.Errorf("golden file:\n%s\nwant:\n%s", data, want)
+	}
+
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.AssertToolResult(recorder, mcp.NewToolResultText("Hello, Ada!"), golden)
+	if len(recorder.errors) > 0 {
+		t.Errorf("unexpected failures: %q", recorder.errors)
+	}
+}
+
+func TestAssertToolResult_Mismatch(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "greet.golden")
+	withUpdate(t, func() {
+		mcptest.AssertToolResult(t, mcp.NewToolResultText("Hello, Ada!"), golden)
+	})
+
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.AssertToolResult(recorder, mcp.NewToolResultText("Howdy, Ada!"), golden)
+	if len(recorder.errors) != 1 {
+		t.Fatalf("got %d failures, want 1: %q", len(recorder.errors), recorder.errors)
+	}
+
+	wantDiff := "--- " + golden + `
++++ actual
+@@ -1,7 +1,7 @@
+ {
+   "content": [
+     {
+-      "text": "Hello, Ada!",
++      "text": "Howdy, Ada!",
+       "type": "text"
+     }
+   ]
+`
+	if !strings.HasSuffix(recorder.errors[0], wantDiff) {
+		t.Errorf("failure:\n%s\nwant it to end with the diff:\n%s", recorder.errors[0], wantDiff)
+	}
+	if !strings.Contains(recorder.errors[0], "MCPTEST_UPDATE=1") {
+		t.Errorf("failure %q does not mention MCPTEST_UPDATE=1", recorder.errors[0])
+	}
+}
+
+func TestAssertToolResult_MissingGoldenFile(t *testing.T) {
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.AssertToolResult(recorder, mcp.NewToolResultText("Hello"), filepath.Join(t.TempDir(), "missing.golden"))
+	if len(recorder.errors) != 1 || !strings.Contains(recorder.errors[0], "does not exist") {
+		t.Errorf("got failures %q, want one about the missing golden file", recorder.errors)
+	}
+}
+
+// update stands in for the -update flag test packages with golden files of
+// their own often declare, which mcptest honors too.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestAssertToolResult_UpdateFlag(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "greet.golden")
+
+	*update = true
+	mcptest.AssertToolResult(t, mcp.NewToolResultText("Hello, Ada!"), golden)
+	*update = false
+
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("golden file was not written: %v", err)
+	}
+}
+
+func TestAssertToolResult_Normalizer(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "now.golden")
+	redactTime := mcptest.WithNormalizer(func(path string, value any) any {
+		if path == "structuredContent.time" {
+			return "<time>"
+		}
+		return value
+	})
+	result := func(now string) *mcp.CallToolResult {
+		return mcp.NewToolResultStructured(map[string]any{"time": now, "zone": "UTC"}, "the time")
+	}
+
+	withUpdate(t, func() {
+		mcptest.AssertToolResult(t, result("2025-01-01T10:00:00Z"), golden, redactTime)
+	})
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"time": "<time>"`) {
+		t.Errorf("golden file does not contain the normalized time:\n%s", data)
+	}
+
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.AssertToolResult(recorder, result("2025-06-30T23:59:59Z"), golden, redactTime)
+	if len(recorder.errors) > 0 {
+		t.Errorf("unexpected failures: %q", recorder.errors)
+	}
+}
+
+func TestCallAndListToolsSnapshot(t *testing.T) {
+	srv, err := mcptest.NewServer(t,
+		server.ServerTool{
+			Tool: mcp.NewTool("greet",
+				mcp.WithDescription("Greets someone."),
+				mcp.WithString("name", mcp.Required()),
+			),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("Hello, " + request.GetString("name", "") + "!"), nil
+			},
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("add", mcp.WithDescription("Adds two numbers.")),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("0"), nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	dir := t.TempDir()
+	var greet mcp.CallToolRequest
+	greet.Params.Name = "greet"
+	greet.Params.Arguments = map[string]any{"name": "Ada"}
+
+	withUpdate(t, func() {
+		mcptest.CallAndSnapshot(t, srv.Client(), greet, filepath.Join(dir, "greet.golden"))
+		mcptest.ListToolsAndSnapshot(t, srv.Client(), filepath.Join(dir, "tools.golden"))
+	})
+
+	result := mcptest.CallAndSnapshot(t, srv.Client(), greet, filepath.Join(dir, "greet.golden"))
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Hello, Ada!" {
+		t.Errorf("got result %q, want %q", text, "Hello, Ada!")
+	}
+	tools := mcptest.ListToolsAndSnapshot(t, srv.Client(), filepath.Join(dir, "tools.golden"))
+	if len(tools) != 2 {
+		t.Errorf("got %d tools, want 2", len(tools))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tools.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if add, greet := strings.Index(string(data), `"add"`), strings.Index(string(data), `"greet"`); add < 0 || add > greet {
+		t.Errorf("tools are not sorted by name:\n%s", data)
+	}
+
+	// Changing the public surface breaks the snapshot
+	recorder := &divergenceRecorder{TB: t}
+	mcptest.AssertTools(recorder, []mcp.Tool{
+		mcp.NewTool("add", mcp.WithDescription("Adds numbers.")),
+		mcp.NewTool("greet", mcp.WithDescription("Greets someone."), mcp.WithString("name", mcp.Required())),
+	}, filepath.Join(dir, "tools.golden"))
+	if len(recorder.errors) != 1 || !strings.Contains(recorder.errors[0], `+    "description": "Adds numbers.",`) {
+		t.Errorf("got failures %q, want a diff of the changed description", recorder.errors)
+	}
+}
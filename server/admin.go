@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// adminRequestKey marks the contexts of ListToolsForSession and
+// CallToolForSession.
+type adminRequestKey struct{}
+
+// IsAdminRequest reports whether ctx belongs to a call made by the server
+// application through ListToolsForSession or CallToolForSession rather than
+// by the client, so that hooks, filters and handlers can tell them apart.
+func IsAdminRequest(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminRequestKey{}).(bool)
+	return admin
+}
+
+// adminContext returns ctx with the registered session sessionID attached,
+// as it would be for a request received from that session, and marked as an
+// admin request.
+func (s *MCPServer) adminContext(ctx context.Context, sessionID string) (context.Context, error) {
+	value, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	session, ok := value.(ClientSession)
+	if !ok || !session.Initialized() {
+		return nil, ErrSessionNotInitialized
+	}
+	if s.capabilities.tools == nil {
+		return nil, fmt.Errorf("tools %w", ErrUnsupported)
+	}
+
+	ctx = context.WithValue(ctx, serverKey{}, s)
+	ctx = s.WithContext(ctx, session)
+	ctx = withErrorID(ctx)
+	ctx = s.withRequestID(ctx)
+	return context.WithValue(ctx, adminRequestKey{}, true), nil
+}
+
+// ListToolsForSession returns the tools the session sessionID currently sees
+// in response to tools/list: the global tools merged with the session's
+// tools, with the tool filters applied, unpaginated. Filters run with a
+// context holding the session, for which IsAdminRequest is true; values that
+// only exist while handling a client request, such as HTTP headers, are
+// missing. It returns ErrSessionNotFound for an unknown session.
+func (s *MCPServer) ListToolsForSession(ctx context.Context, sessionID string) ([]mcp.Tool, error) {
+	ctx, err := s.adminContext(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listTools(ctx), nil
+}
+
+// CallToolForSession calls a tool as if the session sessionID had sent
+// request, for example to debug a session from an admin endpoint. The tool
+// is looked up, and its handler run with middlewares and hooks, exactly like
+// for a tools/call from the session; IsAdminRequest is true for the handler's
+// context, and hooks see a nil request ID. A panicking handler is recovered
+// and reported as a *PanicError. It returns ErrSessionNotFound for an
+// unknown session and an error matching ErrToolNotFound for an unknown tool.
+func (s *MCPServer) CallToolForSession(
+	ctx context.Context,
+	sessionID string,
+	request mcp.CallToolRequest,
+) (result *mcp.CallToolResult, err error) {
+	ctx, err = s.adminContext(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	request.Method = string(mcp.MethodToolsCall)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panicErr := &PanicError{Method: mcp.MethodToolsCall, Value: recovered, Stack: debug.Stack()}
+			s.reportPanic(ctx, nil, mcp.MethodToolsCall, &request, panicErr)
+			s.hooks.onError(ctx, nil, mcp.MethodToolsCall, &request, panicErr)
+			result, err = nil, panicErr
+		}
+	}()
+
+	s.hooks.beforeCallTool(ctx, nil, &request)
+	result, reqErr := s.handleToolCall(ctx, nil, request)
+	if reqErr != nil {
+		s.hooks.onError(ctx, nil, mcp.MethodToolsCall, &request, reqErr)
+		return nil, reqErr.err
+	}
+	s.hooks.afterCallTool(ctx, nil, &request, result)
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newAdminTestServer(t *testing.T, hooks *Hooks) (*MCPServer, *sessionTestClientWithTools) {
+	t.Helper()
+	// Hide tools starting with "internal_" from everyone
+	filter := func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		visible := tools[:0:0]
+		for _, tool := range tools {
+			if !strings.HasPrefix(tool.Name, "internal_") {
+				visible = append(visible, tool)
+			}
+		}
+		return visible
+	}
+	srv := NewMCPServer("test", "1.0.0", WithToolFilter(filter), WithHooks(hooks))
+	srv.AddTool(mcp.NewTool("global"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("global"), nil
+	})
+	srv.AddTool(mcp.NewTool("shadowed", mcp.WithDescription("global version")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("global shadowed"), nil
+	})
+	srv.AddTool(mcp.NewTool("internal_reset"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("reset"), nil
+	})
+
+	session := &sessionTestClientWithTools{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	require.NoError(t, srv.AddSessionTools(session.SessionID(),
+		ServerTool{
+			Tool: mcp.NewTool("shadowed", mcp.WithDescription("session version")),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("session shadowed " + ClientSessionFromContext(ctx).SessionID()), nil
+			},
+		},
+		ServerTool{
+			Tool: mcp.NewTool("session_only"),
+			Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("session only"), nil
+			},
+		},
+	))
+	return srv, session
+}
+
+func TestListToolsForSession_MatchesClientView(t *testing.T) {
+	srv, session := newAdminTestServer(t, nil)
+
+	ctx := srv.WithContext(context.Background(), session)
+	response := srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %#v", response)
+	clientTools := resp.Result.(mcp.ListToolsResult).Tools
+
+	adminTools, err := srv.ListToolsForSession(context.Background(), session.SessionID())
+	require.NoError(t, err)
+	assert.Equal(t, clientTools, adminTools)
+
+	names := make([]string, 0, len(adminTools))
+	for _, tool := range adminTools {
+		names = append(names, tool.Name)
+		if tool.Name == "shadowed" {
+			assert.Equal(t, "session version", tool.Description)
+		}
+	}
+	assert.Equal(t, []string{"global", "session_only", "shadowed"}, names)
+}
+
+func TestCallToolForSession(t *testing.T) {
+	var adminCalls []bool
+	var errs []error
+	hooks := &Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		adminCalls = append(adminCalls, IsAdminRequest(ctx))
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		errs = append(errs, err)
+	})
+	srv, session := newAdminTestServer(t, hooks)
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "shadowed"
+	result, err := srv.CallToolForSession(context.Background(), session.SessionID(), req)
+	require.NoError(t, err)
+	assert.Equal(t, mcp.NewTextContent("session shadowed session-1"), result.Content[0])
+
+	// A regular call from the client is not an admin call
+	ctx := srv.WithContext(context.Background(), session)
+	message, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": map[string]any{"name": "shadowed"},
+	})
+	srv.HandleMessage(ctx, message)
+	assert.Equal(t, []bool{true, false}, adminCalls)
+
+	req.Params.Name = "missing"
+	_, err = srv.CallToolForSession(context.Background(), session.SessionID(), req)
+	assert.ErrorIs(t, err, ErrToolNotFound)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrToolNotFound)
+
+	_, err = srv.CallToolForSession(context.Background(), "unknown", req)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = srv.ListToolsForSession(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestCallToolForSession_RecoversPanic(t *testing.T) {
+	srv, session := newAdminTestServer(t, nil)
+	srv.AddTool(mcp.NewTool("explode"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	})
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "explode"
+	_, err := srv.CallToolForSession(context.Background(), session.SessionID(), req)
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ArgumentDecoderFunc decodes tool call arguments from data into v, which
+// is a *map[string]any.
+type ArgumentDecoderFunc func(data []byte, v any) error
+
+// WithArgumentDecoder registers decoder for tools/call arguments sent in a
+// non-JSON representation. A client selects it by setting
+// mcp.MetaKeyArgumentsContentType in the request's _meta to contentType and
+// passing the encoded arguments as a string; binary formats such as
+// protobuf have to be text-encoded, e.g. in base64, and decoded by decoder.
+// Media type parameters are ignored when matching contentType.
+//
+// The decoded arguments replace the string before schema defaults, dry runs
+// and the tool handler see the request. Calls with a content type that has no
+// decoder, or whose arguments fail to decode, are rejected with an
+// INVALID_PARAMS error.
+func WithArgumentDecoder(contentType string, decoder ArgumentDecoderFunc) ServerOption {
+	return func(s *MCPServer) {
+		if s.argumentDecoders == nil {
+			s.argumentDecoders = make(map[string]ArgumentDecoderFunc)
+		}
+		s.argumentDecoders[normalizeContentType(contentType)] = decoder
+	}
+}
+
+// DecodeFormArguments is an ArgumentDecoderFunc for
+// application/x-www-form-urlencoded arguments. Fields with a single value
+// decode to a string and repeated fields to a []string.
+func DecodeFormArguments(data []byte, v any) error {
+	target, ok := v.(*map[string]any)
+	if !ok {
+		return fmt.Errorf("cannot decode form arguments into %T", v)
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	args := make(map[string]any, len(values))
+	for key, value := range values {
+		if len(value) == 1 {
+			args[key] = value[0]
+		} else {
+			args[key] = value
+		}
+	}
+	*target = args
+	return nil
+}
+
+// decodeArguments replaces the arguments of a request that declares a
+// non-JSON content type with their decoded form.
+func (s *MCPServer) decodeArguments(request *mcp.CallToolRequest) error {
+	contentType, _ := request.GetMeta(mcp.MetaKeyArgumentsContentType).(string)
+	if contentType == "" {
+		return nil
+	}
+	contentType = normalizeContentType(contentType)
+	if contentType == "application/json" {
+		return nil
+	}
+
+	decoder, ok := s.argumentDecoders[contentType]
+	if !ok {
+		return fmt.Errorf("unsupported arguments content type %q: %w", contentType, ErrUnsupported)
+	}
+	data, ok := request.Params.Arguments.(string)
+	if !ok {
+		return fmt.Errorf("arguments with content type %q must be a string, got %T", contentType, request.Params.Arguments)
+	}
+	var args map[string]any
+	if err := decoder([]byte(data), &args); err != nil {
+		return fmt.Errorf("failed to decode %s arguments: %w", contentType, err)
+	}
+	request.Params.Arguments = args
+	return nil
+}
+
+// normalizeContentType strips the parameters of a media type and lowercases
+// it.
+func normalizeContentType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
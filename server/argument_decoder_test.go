@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newArgumentDecoderServer(opts ...ServerOption) (*MCPServer, *map[string]any) {
+	var received map[string]any
+	srv := NewMCPServer("test", "1.0.0", append([]ServerOption{WithToolCapabilities(false)}, opts...)...)
+	srv.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received = request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	})
+	return srv, &received
+}
+
+func callWithArgumentsContentType(srv *MCPServer, contentType string, arguments any) mcp.JSONRPCMessage {
+	params, _ := json.Marshal(map[string]any{
+		"name":      "echo",
+		"arguments": arguments,
+		"_meta":     map[string]any{mcp.MetaKeyArgumentsContentType: contentType},
+	})
+	message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":` + string(params) + `}`
+	return srv.HandleMessage(context.Background(), []byte(message))
+}
+
+func TestWithArgumentDecoder(t *testing.T) {
+	t.Run("form arguments", func(t *testing.T) {
+		srv, received := newArgumentDecoderServer(
+			WithArgumentDecoder("application/x-www-form-urlencoded", DecodeFormArguments))
+
+		response := callWithArgumentsContentType(srv, "application/x-www-form-urlencoded; charset=utf-8", "name=gopher&tag=a&tag=b")
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected a response, got %#v", response)
+		assert.Equal(t, map[string]any{"name": "gopher", "tag": []string{"a", "b"}}, *received)
+	})
+
+	t.Run("custom binary decoder", func(t *testing.T) {
+		srv, received := newArgumentDecoderServer(
+			WithArgumentDecoder("application/x-protobuf", func(data []byte, v any) error {
+				raw, err := base64.StdEncoding.DecodeString(string(data))
+				if err != nil {
+					return err
+				}
+				*v.(*map[string]any) = map[string]any{"payload": string(raw)}
+				return nil
+			}))
+
+		response := callWithArgumentsContentType(srv, "Application/X-Protobuf", base64.StdEncoding.EncodeToString([]byte("\x08\x01")))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected a response, got %#v", response)
+		assert.Equal(t, map[string]any{"payload": "\x08\x01"}, *received)
+	})
+
+	t.Run("JSON arguments are left alone", func(t *testing.T) {
+		srv, received := newArgumentDecoderServer()
+
+		response := callWithArgumentsContentType(srv, "application/json", map[string]any{"name": "gopher"})
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected a response, got %#v", response)
+		assert.Equal(t, "gopher", (*received)["name"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		srv, _ := newArgumentDecoderServer(
+			WithArgumentDecoder("text/plain", func(data []byte, v any) error {
+				return errors.New("boom")
+			}))
+
+		tests := []struct {
+			name        string
+			contentType string
+			arguments   any
+			message     string
+		}{
+			{"unknown content type", "application/x-protobuf", "CAE=", "unsupported arguments content type"},
+			{"arguments not a string", "text/plain", map[string]any{"a": 1}, "must be a string"},
+			{"decoder failure", "text/plain", "whatever", "boom"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				response := callWithArgumentsContentType(srv, tt.contentType, tt.arguments)
+				errorResponse, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok, "expected an error, got %#v", response)
+				assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+				assert.Contains(t, errorResponse.Error.Message, tt.message)
+			})
+		}
+	})
+}
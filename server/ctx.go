@@ -1,8 +1,164 @@
 package server
 
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/mark3labs/mcp-go/util"
+)
+
 type contextKey int
 
 const (
 	// This const is used as key for context value lookup
 	requestHeader contextKey = iota
+	// inlineResponse marks requests whose response is returned in the HTTP
+	// response body rather than over a stream
+	inlineResponse
+	// transportType holds the TransportType serving the request
+	transportType
+	// rawMessage holds the captured raw JSON-RPC message
+	rawMessage
+	// dependencies holds the server dependencies as of the request start
+	dependencies
+	// errorID holds the correlation ID reported with error responses
+	errorID
+	// requestID holds the ID set by WithRequestIDContext
+	requestID
 )
+
+// TransportType identifies the transport a request was received on.
+type TransportType string
+
+const (
+	TransportUnknown        TransportType = ""
+	TransportStdio          TransportType = "stdio"
+	TransportSSE            TransportType = "sse"
+	TransportStreamableHTTP TransportType = "streamable-http"
+	TransportInProcess      TransportType = "inprocess"
+)
+
+// WithTransportType returns a context tagged with the transport serving the
+// request. The built-in transports set it automatically; custom transports can
+// use it to identify themselves to hooks and handlers.
+func WithTransportType(ctx context.Context, transport TransportType) context.Context {
+	return context.WithValue(ctx, transportType, transport)
+}
+
+// TransportFromContext returns the transport the current request was received
+// on, or TransportUnknown if it was not tagged.
+func TransportFromContext(ctx context.Context) TransportType {
+	if transport, ok := ctx.Value(transportType).(TransportType); ok {
+		return transport
+	}
+	return TransportUnknown
+}
+
+// RawMessageFromContext returns the raw JSON-RPC message being handled, as
+// captured when the server was created with WithRawMessageCapture. Messages
+// larger than the capture limit are truncated, so the result is not
+// necessarily valid JSON. If the server has a Redactor, the message is
+// redacted before it is truncated. It returns nil if capture is disabled.
+func RawMessageFromContext(ctx context.Context) []byte {
+	if raw, ok := ctx.Value(rawMessage).([]byte); ok {
+		return raw
+	}
+	return nil
+}
+
+// withRawMessage stores a copy of the message in the context if raw message
+// capture is enabled.
+func (s *MCPServer) withRawMessage(ctx context.Context, message []byte) context.Context {
+	if s.rawMessageCaptureLimit <= 0 {
+		return ctx
+	}
+	if s.redactor != nil {
+		message = s.redactor.RedactJSON(message)
+	}
+	if len(message) > s.rawMessageCaptureLimit {
+		message = message[:s.rawMessageCaptureLimit]
+	}
+	raw := make([]byte, len(message))
+	copy(raw, message)
+	return context.WithValue(ctx, rawMessage, raw)
+}
+
+// ErrorIDFromContext returns the correlation ID of the message being handled.
+// The server includes it as "errorId" in the data of every error response to
+// the message, so handlers and hooks can log it to tie a client-side error to
+// the server logs. It returns "" outside of HandleMessage.
+func ErrorIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(errorID).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// withErrorID returns a context holding a new error correlation ID. The ID
+// is random and carries no information about the request.
+func withErrorID(ctx context.Context) context.Context {
+	return context.WithValue(ctx, errorID, fmt.Sprintf("%016x", rand.Uint64()))
+}
+
+// WithRequestIDContext gives every message the server handles an ID that
+// handlers can read with RequestIDFromContext and that prefixes every line
+// written through LoggerFromContext, so that the logs of a single tool call
+// can be traced through its lifecycle. The ID is the error correlation ID of
+// the message, so an error a client reports can be found in the logs as well.
+func WithRequestIDContext() ServerOption {
+	return func(s *MCPServer) {
+		s.requestIDContext = true
+	}
+}
+
+// RequestIDFromContext returns the ID of the message being handled. It
+// returns "" if the server was not created with WithRequestIDContext, or
+// outside of HandleMessage.
+func RequestIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestID).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// withRequestID stores the error ID of ctx as the request ID if the server
+// was created with WithRequestIDContext.
+func (s *MCPServer) withRequestID(ctx context.Context) context.Context {
+	if !s.requestIDContext {
+		return ctx
+	}
+	return context.WithValue(ctx, requestID, ErrorIDFromContext(ctx))
+}
+
+// LoggerFromContext returns the logger of the server handling the current
+// request, as set with WithServerLogger. If the context holds a request ID,
+// every line is prefixed with it. Outside of a request it returns
+// util.DefaultLogger().
+func LoggerFromContext(ctx context.Context) util.Logger {
+	return ServerFromContext(ctx).contextLogger(ctx)
+}
+
+// contextLogger returns the server's logger, prefixed with the request ID of
+// ctx if there is one.
+func (s *MCPServer) contextLogger(ctx context.Context) util.Logger {
+	logger := s.errorLogger()
+	if id := RequestIDFromContext(ctx); id != "" {
+		return &requestLogger{logger: logger, prefix: "[request_id=" + id + "] "}
+	}
+	return logger
+}
+
+// requestLogger prefixes the lines of a logger with a request ID.
+type requestLogger struct {
+	logger util.Logger
+	prefix string
+}
+
+func (l *requestLogger) Infof(format string, v ...any) {
+	l.logger.Infof(l.prefix+format, v...)
+}
+
+func (l *requestLogger) Errorf(format string, v ...any) {
+	l.logger.Errorf(l.prefix+format, v...)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWithRequestIDContext(t *testing.T) {
+	logger := &recordingLogger{}
+	srv := NewMCPServer("test", "1.0.0", WithRequestIDContext(), WithServerLogger(logger))
+
+	var requestIDs []string
+	srv.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestIDs = append(requestIDs, RequestIDFromContext(ctx))
+		LoggerFromContext(ctx).Infof("starting %s", request.Params.Name)
+		if request.GetBool("fail", false) {
+			LoggerFromContext(ctx).Errorf("giving up")
+			return nil, errors.New("failed")
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"work"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	response = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"work","arguments":{"fail":true}}}`))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected an error response, got %T", response)
+
+	// Every call gets its own ID, which is also its error ID
+	require.Len(t, requestIDs, 2)
+	assert.NotEmpty(t, requestIDs[0])
+	assert.NotEqual(t, requestIDs[0], requestIDs[1])
+	assert.Equal(t, map[string]any{"errorId": requestIDs[1]}, errResponse.Error.Data)
+
+	// Every line the handler logs carries the ID of its call
+	assert.Equal(t, []string{
+		"[request_id=" + requestIDs[0] + "] starting work",
+		"[request_id=" + requestIDs[1] + "] starting work",
+	}, logger.infos)
+	assert.Equal(t, []string{"[request_id=" + requestIDs[1] + "] giving up"}, logger.errors)
+}
+
+func TestRequestIDFromContext_Disabled(t *testing.T) {
+	logger := &recordingLogger{}
+	srv := NewMCPServer("test", "1.0.0", WithServerLogger(logger))
+
+	var requestID string
+	srv.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestID = RequestIDFromContext(ctx)
+		LoggerFromContext(ctx).Infof("starting")
+		return mcp.NewToolResultText("done"), nil
+	})
+	srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"work"}}`))
+
+	assert.Empty(t, requestID)
+	assert.Equal(t, []string{"starting"}, logger.infos)
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+	assert.NotNil(t, LoggerFromContext(context.Background()))
+}
+
+func TestWithRequestIDContext_PanicLog(t *testing.T) {
+	srv, logger, _, _ := newPanickingServer(t, WithRequestIDContext())
+	var requestID string
+	srv.AddTool(mcp.NewTool("boom"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		requestID = RequestIDFromContext(ctx)
+		panic("boom")
+	})
+	srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"boom"}}`))
+
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "[request_id="+requestID+"] ")
+}
@@ -0,0 +1,91 @@
+package server
+
+import "context"
+
+// SetDependency makes value available to every handler and hook under key,
+// through DependencyFromContext. It is meant for shared dependencies such as
+// database pools or API clients, so that tools registered dynamically do not
+// need them captured in closures. A nil value removes the dependency.
+//
+// Each request sees the dependencies as they were when it started, so
+// concurrent calls never change what a running handler reads. SetDependency
+// is safe for concurrent use.
+func (s *MCPServer) SetDependency(key string, value any) {
+	s.dependenciesMu.Lock()
+	defer s.dependenciesMu.Unlock()
+
+	var current map[string]any
+	if p := s.dependencies.Load(); p != nil {
+		current = *p
+	}
+	updated := make(map[string]any, len(current)+1)
+	for k, v := range current {
+		updated[k] = v
+	}
+	if value == nil {
+		delete(updated, key)
+	} else {
+		updated[key] = value
+	}
+	s.dependencies.Store(&updated)
+}
+
+// withDependencies stores the current dependencies in the context of a
+// request. The map is never modified after it is published, so it can be
+// shared without copying.
+func (s *MCPServer) withDependencies(ctx context.Context) context.Context {
+	p := s.dependencies.Load()
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, dependencies, *p)
+}
+
+// DependencyFromContext returns the dependency registered under key with
+// SetDependency, as of the start of the current request. It returns false if
+// there is no such dependency or it is not of type T.
+func DependencyFromContext[T any](ctx context.Context, key string) (T, bool) {
+	var zero T
+	values, ok := ctx.Value(dependencies).(map[string]any)
+	if !ok {
+		return zero, false
+	}
+	value, ok := values[key].(T)
+	if !ok {
+		return zero, false
+	}
+	return value, true
+}
+
+// DependencyKey is a typed key for a dependency. Declaring keys as
+// package-level variables lets the compiler check both the key and the type
+// of the value:
+//
+//	var ClockKey = server.NewDependencyKey[Clock]("clock")
+//
+//	server.SetTypedDependency(s, ClockKey, realClock{})
+//	clock, ok := ClockKey.FromContext(ctx)
+type DependencyKey[T any] struct {
+	name string
+}
+
+// NewDependencyKey creates a typed key for the dependency with the given
+// name. The name is shared with SetDependency and DependencyFromContext.
+func NewDependencyKey[T any](name string) DependencyKey[T] {
+	return DependencyKey[T]{name: name}
+}
+
+// Name returns the name of the dependency.
+func (k DependencyKey[T]) Name() string {
+	return k.name
+}
+
+// FromContext returns the dependency as of the start of the current request.
+func (k DependencyKey[T]) FromContext(ctx context.Context) (T, bool) {
+	return DependencyFromContext[T](ctx, k.name)
+}
+
+// SetTypedDependency is SetDependency for a typed key.
+func SetTypedDependency[T any](s *MCPServer, key DependencyKey[T], value T) {
+	s.SetDependency(key.name, value)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type clock interface {
+	Now() time.Time
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+var clockKey = NewDependencyKey[clock]("clock")
+
+func TestMCPServer_Dependencies(t *testing.T) {
+	frozen := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var hookSawClock bool
+	hooks := &Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		_, hookSawClock = clockKey.FromContext(ctx)
+	})
+
+	server := NewMCPServer("test-server", "1.0.0", WithHooks(hooks), WithResourceCapabilities(false, false))
+	SetTypedDependency[clock](server, clockKey, fakeClock{now: frozen})
+	server.SetDependency("greeting", "hello")
+
+	server.AddTool(mcp.NewTool("now"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		c, ok := clockKey.FromContext(ctx)
+		if !ok {
+			return mcp.NewToolResultError("no clock"), nil
+		}
+		greeting, _ := DependencyFromContext[string](ctx, "greeting")
+		return mcp.NewToolResultText(greeting + " " + c.Now().Format(time.RFC3339)), nil
+	})
+	server.AddResource(mcp.NewResource("time://now", "now"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		c, ok := DependencyFromContext[clock](ctx, "clock")
+		if !ok {
+			return nil, assert.AnError
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: c.Now().Format(time.RFC3339)}}, nil
+	})
+
+	response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"now"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	assert.Equal(t, "hello 2025-01-02T03:04:05Z", result.Content[0].(mcp.TextContent).Text)
+	assert.True(t, hookSawClock)
+
+	response = server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"time://now"}}`))
+	resp, ok = response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+	read := resp.Result.(mcp.ReadResourceResult)
+	assert.Equal(t, "2025-01-02T03:04:05Z", read.Contents[0].(mcp.TextResourceContents).Text)
+
+	// Wrong types and unknown keys are reported as missing
+	ctx := server.withDependencies(context.Background())
+	_, ok = DependencyFromContext[int](ctx, "greeting")
+	assert.False(t, ok)
+	_, ok = DependencyFromContext[string](ctx, "missing")
+	assert.False(t, ok)
+
+	server.SetDependency("greeting", nil)
+	_, ok = DependencyFromContext[string](server.withDependencies(context.Background()), "greeting")
+	assert.False(t, ok)
+}
+
+func TestMCPServer_DependenciesFixedAtRequestStart(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.SetDependency("version", "v1")
+
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	server.AddTool(mcp.NewTool("version"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-proceed
+		version, _ := DependencyFromContext[string](ctx, "version")
+		return mcp.NewToolResultText(version), nil
+	})
+
+	done := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		done <- server.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"version"}}`))
+	}()
+
+	<-started
+	server.SetDependency("version", "v2")
+	close(proceed)
+
+	resp, ok := (<-done).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	assert.Equal(t, "v1", resp.Result.(mcp.CallToolResult).Content[0].(mcp.TextContent).Text)
+
+	version, _ := DependencyFromContext[string](server.withDependencies(context.Background()), "version")
+	assert.Equal(t, "v2", version)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithLenientParsing disables the strict validation of JSON-RPC envelopes, for
+// clients that send quirky but otherwise usable messages. By default the
+// server rejects, with an INVALID_REQUEST error, messages that repeat a
+// top-level key, carry an id that is not a string, a number or null, use a
+// null id on a request, or mix a method with a result or error.
+func WithLenientParsing() ServerOption {
+	return func(s *MCPServer) {
+		s.lenientParsing = true
+	}
+}
+
+// validateEnvelope checks the top-level members of a JSON-RPC message that
+// encoding/json silently accepts: duplicate keys, where the last one wins,
+// malformed ids and messages that are both a request and a response. It
+// returns nil for valid messages and for messages that are not JSON objects,
+// which fail to parse later on.
+func validateEnvelope(message []byte) *requestError {
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+
+	members := make(map[string]json.RawMessage)
+	var duplicate string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil
+		}
+		if _, seen := members[key]; seen && duplicate == "" {
+			duplicate = key
+		}
+		members[key] = value
+	}
+
+	rawID, hasID := members["id"]
+	var id any
+	validID := !hasID
+	if hasID {
+		switch trimmed := bytes.TrimSpace(rawID); {
+		case len(trimmed) > 0 && trimmed[0] == '"':
+			var s string
+			validID = json.Unmarshal(trimmed, &s) == nil
+			id = s
+		case bytes.Equal(trimmed, []byte("null")):
+			validID = true
+		default:
+			var n json.Number
+			validID = json.Unmarshal(trimmed, &n) == nil
+			id = n
+		}
+	}
+	if !validID {
+		id = nil
+	}
+
+	invalid := func(format string, args ...any) *requestError {
+		return &requestError{id: id, code: mcp.INVALID_REQUEST, err: fmt.Errorf(format, args...)}
+	}
+
+	if duplicate != "" {
+		if duplicate == "id" {
+			// The ids disagree, so neither can be trusted for correlation
+			id = nil
+		}
+		return invalid("duplicate key %q in JSON-RPC message", duplicate)
+	}
+	if !validID {
+		return invalid("invalid id: must be a string, a number or null")
+	}
+
+	_, hasMethod := members["method"]
+	_, hasResult := members["result"]
+	_, hasError := members["error"]
+	if hasMethod && (hasResult || hasError) {
+		return invalid("message must not have both a method and a result or error")
+	}
+	if hasMethod && hasID && id == nil {
+		return invalid("request id must not be null; omit the id for notifications")
+	}
+	return nil
+}
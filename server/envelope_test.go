@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_EnvelopeValidation(t *testing.T) {
+	const (
+		accepted     = 0
+		notification = 1
+	)
+
+	tests := []struct {
+		name    string
+		message string
+		// code is the expected JSON-RPC error code, or accepted for a
+		// successful response, or notification for no response at all.
+		code int
+		// id is the expected JSON id of the error response; empty means
+		// null.
+		id string
+	}{
+		{
+			name:    "valid request",
+			message: `{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+			code:    accepted,
+		},
+		{
+			name:    "valid request with string id",
+			message: `{"jsonrpc":"2.0","id":"abc","method":"ping"}`,
+			code:    accepted,
+		},
+		{
+			name:    "valid notification",
+			message: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			code:    notification,
+		},
+		{
+			name:    "response to a server request",
+			message: `{"jsonrpc":"2.0","id":7,"result":{}}`,
+			code:    notification,
+		},
+		{
+			name:    "missing jsonrpc",
+			message: `{"id":1,"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+		{
+			name:    "jsonrpc 1.0",
+			message: `{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+		{
+			name:    "jsonrpc is not a string",
+			message: `{"jsonrpc":2.0,"id":1,"method":"ping"}`,
+			code:    mcp.PARSE_ERROR,
+		},
+		{
+			name:    "not JSON",
+			message: `{"jsonrpc":"2.0","id":1,`,
+			code:    mcp.PARSE_ERROR,
+		},
+		{
+			name:    "duplicate id",
+			message: `{"jsonrpc":"2.0","id":1,"method":"ping","id":2}`,
+			code:    mcp.INVALID_REQUEST,
+		},
+		{
+			name:    "duplicate method",
+			message: `{"jsonrpc":"2.0","id":1,"method":"ping","method":"tools/list"}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+		{
+			name:    "duplicate jsonrpc",
+			message: `{"jsonrpc":"1.0","jsonrpc":"2.0","id":1,"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+		{
+			name:    "object id",
+			message: `{"jsonrpc":"2.0","id":{"n":1},"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+		},
+		{
+			name:    "boolean id",
+			message: `{"jsonrpc":"2.0","id":true,"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+		},
+		{
+			name:    "null id on a request",
+			message: `{"jsonrpc":"2.0","id":null,"method":"ping"}`,
+			code:    mcp.INVALID_REQUEST,
+		},
+		{
+			name:    "method with result",
+			message: `{"jsonrpc":"2.0","id":1,"method":"ping","result":{}}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+		{
+			name:    "method with error",
+			message: `{"jsonrpc":"2.0","id":1,"method":"ping","error":{"code":1,"message":"x"}}`,
+			code:    mcp.INVALID_REQUEST,
+			id:      "1",
+		},
+	}
+
+	server := NewMCPServer("test-server", "1.0.0")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := server.HandleMessage(context.Background(), []byte(tt.message))
+			switch tt.code {
+			case notification:
+				assert.Nil(t, response)
+			case accepted:
+				_, ok := response.(mcp.JSONRPCResponse)
+				assert.True(t, ok, "expected a response, got %#v", response)
+			default:
+				errorResponse, ok := response.(mcp.JSONRPCError)
+				require.True(t, ok, "expected an error, got %#v", response)
+				assert.Equal(t, tt.code, errorResponse.Error.Code)
+				id, err := json.Marshal(errorResponse.ID)
+				require.NoError(t, err)
+				if tt.id == "" {
+					assert.Equal(t, "null", string(id))
+				} else {
+					assert.Equal(t, tt.id, string(id))
+				}
+			}
+		})
+	}
+}
+
+func TestMCPServer_LenientParsing(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithLenientParsing())
+
+	// The last id wins, as with encoding/json
+	response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping","id":2}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %#v", response)
+	id, err := json.Marshal(resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(id))
+
+	// The version is still checked
+	response = server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"1.0","id":1,"method":"ping"}`))
+	errorResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected an error, got %#v", response)
+	assert.Equal(t, mcp.INVALID_REQUEST, errorResponse.Error.Code)
+}
@@ -1,8 +1,12 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 var (
@@ -12,16 +16,39 @@ var (
 	ErrPromptNotFound   = errors.New("prompt not found")
 	ErrToolNotFound     = errors.New("tool not found")
 
+	// Registration errors returned under strict registration
+	ErrToolAlreadyExists     = errors.New("tool already exists")
+	ErrPromptAlreadyExists   = errors.New("prompt already exists")
+	ErrResourceAlreadyExists = errors.New("resource already exists")
+
+	// ErrMissingPromptArguments is returned by prompts/get when arguments the
+	// prompt marks as required are missing.
+	ErrMissingPromptArguments = errors.New("missing required prompt arguments")
+
 	// Session-related errors
 	ErrSessionNotFound              = errors.New("session not found")
 	ErrSessionExists                = errors.New("session already exists")
 	ErrSessionNotInitialized        = errors.New("session not properly initialized")
+	ErrSessionAlreadyInitialized    = errors.New("session already initialized")
 	ErrSessionDoesNotSupportTools   = errors.New("session does not support per-session tools")
+	ErrSessionDoesNotSupportPrompts = errors.New("session does not support per-session prompts")
 	ErrSessionDoesNotSupportLogging = errors.New("session does not support setting logging level")
+	ErrTooManyConcurrentRequests    = errors.New("too many concurrent requests for session")
+	ErrToolCallQueueFull            = errors.New("tool call queue is full")
+	ErrSamplingUnavailableInline    = errors.New("sampling is not available for requests answered inline")
+
+	// ErrSessionCancelled is the cancellation cause of handler contexts aborted
+	// by MCPServer.CancelSession.
+	ErrSessionCancelled = errors.New("session cancelled by server")
 
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
 	ErrNotificationChannelBlocked = errors.New("notification channel queue is full - client may not be processing notifications fast enough")
+
+	// ErrNotificationAfterResponse is reported for notifications a handler
+	// sends on the streamable HTTP transport after its request was answered,
+	// when the session has no standalone GET stream to take them.
+	ErrNotificationAfterResponse = errors.New("notification sent after the response to its request")
 )
 
 // ErrDynamicPathConfig is returned when attempting to use static path methods with dynamic path configuration
@@ -32,3 +59,47 @@ type ErrDynamicPathConfig struct {
 func (e *ErrDynamicPathConfig) Error() string {
 	return fmt.Sprintf("%s cannot be used with WithDynamicBasePath. Use dynamic path logic in your router.", e.Method)
 }
+
+// withErrorIDData adds the error ID of ctx to the data of an error response.
+// Data that is neither empty nor an object is left as is.
+func withErrorIDData(ctx context.Context, response mcp.JSONRPCMessage) mcp.JSONRPCMessage {
+	errResponse, ok := response.(mcp.JSONRPCError)
+	if !ok {
+		return response
+	}
+	id := ErrorIDFromContext(ctx)
+	if id == "" {
+		return response
+	}
+	switch data := errResponse.Error.Data.(type) {
+	case nil:
+		errResponse.Error.Data = map[string]any{"errorId": id}
+	case map[string]any:
+		data = maps.Clone(data)
+		data["errorId"] = id
+		errResponse.Error.Data = data
+	}
+	return errResponse
+}
+
+// newErrorInfo collects the details of a failed request for OnErrorInfo
+// hooks.
+func newErrorInfo(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) ErrorInfo {
+	info := ErrorInfo{
+		ID:        id,
+		ErrorID:   ErrorIDFromContext(ctx),
+		Method:    method,
+		SessionID: sessionIDFromContext(ctx),
+		Message:   message,
+		Err:       err,
+	}
+	switch request := message.(type) {
+	case *mcp.CallToolRequest:
+		info.Name = request.Params.Name
+	case *mcp.GetPromptRequest:
+		info.Name = request.Params.Name
+	case *mcp.ReadResourceRequest:
+		info.Name = request.Params.URI
+	}
+	return info
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestErrorID_MatchesOnErrorInfoHook(t *testing.T) {
+	var infos []ErrorInfo
+	var handlerErrorID string
+	hooks := &Hooks{}
+	hooks.AddOnErrorInfo(func(ctx context.Context, info ErrorInfo) {
+		assert.Equal(t, info.ErrorID, ErrorIDFromContext(ctx))
+		infos = append(infos, info)
+	})
+
+	srv := NewMCPServer("test", "1.0.0", WithHooks(hooks))
+	srv.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		handlerErrorID = ErrorIDFromContext(ctx)
+		return nil, errors.New("database password rejected")
+	})
+
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	ctx := srv.WithContext(context.Background(), session)
+
+	response := srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected an error response, got %T", response)
+
+	// Only the opaque ID is added to the error data
+	data, err := json.Marshal(errResponse.Error.Data)
+	require.NoError(t, err)
+	var wire map[string]string
+	require.NoError(t, json.Unmarshal(data, &wire))
+	require.Len(t, wire, 1)
+	errorID := wire["errorId"]
+	require.NotEmpty(t, errorID)
+
+	require.Len(t, infos, 1)
+	info := infos[0]
+	assert.Equal(t, errorID, info.ErrorID)
+	assert.Equal(t, errorID, handlerErrorID)
+	assert.Equal(t, int64(1), mustInt64(t, info.ID))
+	assert.Equal(t, mcp.MethodToolsCall, info.Method)
+	assert.Equal(t, "fail", info.Name)
+	assert.Equal(t, "session-1", info.SessionID)
+	assert.ErrorContains(t, info.Err, "database password rejected")
+
+	// Every message gets its own ID
+	response = srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fail"}}`))
+	require.Len(t, infos, 2)
+	assert.NotEqual(t, errorID, infos[1].ErrorID)
+	assert.Equal(t, map[string]any{"errorId": infos[1].ErrorID}, response.(mcp.JSONRPCError).Error.Data)
+}
+
+func TestAddOnError_RunsAsOnErrorInfoHook(t *testing.T) {
+	var calls []string
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		assert.Equal(t, int64(1), mustInt64(t, id))
+		assert.Equal(t, mcp.MethodToolsCall, method)
+		assert.IsType(t, &mcp.CallToolRequest{}, message)
+		assert.ErrorContains(t, err, "failed")
+		calls = append(calls, "OnError")
+	})
+	hooks.AddOnErrorInfo(func(ctx context.Context, info ErrorInfo) {
+		calls = append(calls, "OnErrorInfo")
+	})
+	require.Len(t, hooks.OnErrorInfo, 2)
+
+	srv := NewMCPServer("test", "1.0.0", WithHooks(hooks))
+	srv.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("failed")
+	})
+
+	srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`))
+	assert.Equal(t, []string{"OnError", "OnErrorInfo"}, calls)
+}
+
+func TestErrorID_AddedToProtocolErrors(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0")
+
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"unknown/method"}`))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected an error response, got %T", response)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, errResponse.Error.Code)
+	data, ok := errResponse.Error.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Len(t, data["errorId"], 16)
+
+	response = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+	_, isError := response.(mcp.JSONRPCError)
+	assert.False(t, isError)
+}
+
+func TestErrorIDFromContext_OutsideRequest(t *testing.T) {
+	assert.Empty(t, ErrorIDFromContext(context.Background()))
+}
+
+func mustInt64(t *testing.T, id any) int64 {
+	t.Helper()
+	n, err := id.(json.Number).Int64()
+	require.NoError(t, err)
+	return n
+}
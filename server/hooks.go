@@ -14,6 +14,17 @@ type OnRegisterSessionHookFunc func(ctx context.Context, session ClientSession)
 // OnUnregisterSessionHookFunc is a hook that will be called when a session is being unregistered.
 type OnUnregisterSessionHookFunc func(ctx context.Context, session ClientSession)
 
+// OnToolOverwrittenHookFunc is a hook that will be called when a tool is
+// added under the name of an already registered global tool. previous is the
+// existing registration and replacement the one being added.
+type OnToolOverwrittenHookFunc func(previous, replacement ServerTool)
+
+// OnReinitializeHookFunc is a hook that will be called when a client sends
+// initialize on a session that is already initialized. request is the new
+// request and original the result of the first initialize, which the session
+// keeps.
+type OnReinitializeHookFunc func(ctx context.Context, id any, request *mcp.InitializeRequest, original *mcp.InitializeResult)
+
 // BeforeAnyHookFunc is a function that is called after the request is
 // parsed but before the method is called.
 type BeforeAnyHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any)
@@ -57,6 +68,33 @@ type OnSuccessHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, m
 //	})
 type OnErrorHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error)
 
+// ErrorInfo describes a failed request to OnErrorInfo hooks.
+type ErrorInfo struct {
+	// ID is the JSON-RPC ID of the request, or nil for notifications.
+	ID any
+	// ErrorID is the correlation ID sent to the client as "errorId" in the
+	// data of the error response, see ErrorIDFromContext.
+	ErrorID string
+	Method  mcp.MCPMethod
+	// Name is the name of the tool or prompt, or the URI of the resource,
+	// the request targets, if any.
+	Name string
+	// SessionID is the ID of the client session, if any.
+	SessionID string
+	// Message is the request, as passed to OnErrorHookFunc.
+	Message any
+	Err     error
+}
+
+// OnErrorInfoHookFunc is a hook that will be called when an error occurs,
+// like OnErrorHookFunc, with the details of the failed request in one struct.
+type OnErrorInfoHookFunc func(ctx context.Context, info ErrorInfo)
+
+// OnPanicHookFunc is a hook that will be called when a request handler
+// panics. message is the parsed request for tool calls and the raw JSON-RPC
+// message otherwise; stack is the stack trace of the panicking goroutine.
+type OnPanicHookFunc func(ctx context.Context, id any, method mcp.MCPMethod, message any, recovered any, stack []byte)
+
 // OnRequestInitializationFunc is a function that called before handle diff request method
 // Should any errors arise during func execution, the service will promptly return the corresponding error message.
 type OnRequestInitializationFunc func(ctx context.Context, id any, message any) error
@@ -94,9 +132,12 @@ type OnAfterCallToolFunc func(ctx context.Context, id any, message *mcp.CallTool
 type Hooks struct {
 	OnRegisterSession             []OnRegisterSessionHookFunc
 	OnUnregisterSession           []OnUnregisterSessionHookFunc
+	OnToolOverwritten             []OnToolOverwrittenHookFunc
+	OnReinitialize                []OnReinitializeHookFunc
 	OnBeforeAny                   []BeforeAnyHookFunc
 	OnSuccess                     []OnSuccessHookFunc
-	OnError                       []OnErrorHookFunc
+	OnErrorInfo                   []OnErrorInfoHookFunc
+	OnPanic                       []OnPanicHookFunc
 	OnRequestInitialization       []OnRequestInitializationFunc
 	OnBeforeInitialize            []OnBeforeInitializeFunc
 	OnAfterInitialize             []OnAfterInitializeFunc
@@ -174,7 +215,22 @@ func (c *Hooks) AddOnSuccess(hook OnSuccessHookFunc) {
 // server := NewMCPServer("test-server", "1.0.0", WithHooks(hooks))
 // ```
 func (c *Hooks) AddOnError(hook OnErrorHookFunc) {
-	c.OnError = append(c.OnError, hook)
+	c.AddOnErrorInfo(func(ctx context.Context, info ErrorInfo) {
+		hook(ctx, info.ID, info.Method, info.Message, info.Err)
+	})
+}
+
+// AddOnErrorInfo registers a hook function that will be called when an error
+// occurs. AddOnError hooks are registered as OnErrorInfo hooks too, so both
+// kinds run in the order they were added.
+func (c *Hooks) AddOnErrorInfo(hook OnErrorInfoHookFunc) {
+	c.OnErrorInfo = append(c.OnErrorInfo, hook)
+}
+
+// AddOnPanic registers a hook function that will be called when a request
+// handler panics, before the panic is turned into a response.
+func (c *Hooks) AddOnPanic(hook OnPanicHookFunc) {
+	c.OnPanic = append(c.OnPanic, hook)
 }
 
 func (c *Hooks) beforeAny(ctx context.Context, id any, method mcp.MCPMethod, message any) {
@@ -209,12 +265,28 @@ func (c *Hooks) onSuccess(ctx context.Context, id any, method mcp.MCPMethod, mes
 // - ErrResourceNotFound: When a resource is not found
 // - ErrPromptNotFound: When a prompt is not found
 // - ErrToolNotFound: When a tool is not found
+//
+// If the server has a Redactor, the hooks receive a redacted copy of message.
 func (c *Hooks) onError(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
 	if c == nil {
 		return
 	}
-	for _, hook := range c.OnError {
-		hook(ctx, id, method, message, err)
+	if len(c.OnErrorInfo) == 0 {
+		return
+	}
+	message = ServerFromContext(ctx).redactMessage(string(method), message)
+	info := newErrorInfo(ctx, id, method, message, err)
+	for _, hook := range c.OnErrorInfo {
+		hook(ctx, info)
+	}
+}
+
+func (c *Hooks) onPanic(ctx context.Context, id any, method mcp.MCPMethod, message any, recovered any, stack []byte) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnPanic {
+		hook(ctx, id, method, message, recovered, stack)
 	}
 }
 
@@ -244,6 +316,32 @@ func (c *Hooks) UnregisterSession(ctx context.Context, session ClientSession) {
 	}
 }
 
+func (c *Hooks) AddOnToolOverwritten(hook OnToolOverwrittenHookFunc) {
+	c.OnToolOverwritten = append(c.OnToolOverwritten, hook)
+}
+
+func (c *Hooks) toolOverwritten(previous, replacement ServerTool) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnToolOverwritten {
+		hook(previous, replacement)
+	}
+}
+
+func (c *Hooks) AddOnReinitialize(hook OnReinitializeHookFunc) {
+	c.OnReinitialize = append(c.OnReinitialize, hook)
+}
+
+func (c *Hooks) reinitialize(ctx context.Context, id any, request *mcp.InitializeRequest, original *mcp.InitializeResult) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnReinitialize {
+		hook(ctx, id, request, original)
+	}
+}
+
 func (c *Hooks) AddOnRequestInitialization(hook OnRequestInitializationFunc) {
 	c.OnRequestInitialization = append(c.OnRequestInitialization, hook)
 }
@@ -22,6 +22,7 @@ type InProcessSession struct {
 	loggingLevel       atomic.Value
 	clientInfo         atomic.Value
 	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value
 	samplingHandler    SamplingHandler
 	mu                 sync.RWMutex
 }
@@ -42,6 +43,12 @@ func (s *InProcessSession) NotificationChannel() chan<- mcp.JSONRPCNotification
 	return s.notifications
 }
 
+// Notifications returns the channel the notifications sent to the session
+// are delivered on, for the transport to forward them to the client.
+func (s *InProcessSession) Notifications() <-chan mcp.JSONRPCNotification {
+	return s.notifications
+}
+
 func (s *InProcessSession) Initialize() {
 	s.loggingLevel.Store(mcp.LoggingLevelError)
 	s.initialized.Store(true)
@@ -77,6 +84,17 @@ func (s *InProcessSession) SetClientCapabilities(clientCapabilities mcp.ClientCa
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *InProcessSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *InProcessSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *InProcessSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -98,18 +116,38 @@ func (s *InProcessSession) RequestSampling(ctx context.Context, request mcp.Crea
 		return nil, fmt.Errorf("no sampling handler available")
 	}
 
-	return handler.CreateMessage(ctx, request)
+	// Stop waiting as soon as ctx is done, even if the handler ignores it
+	type outcome struct {
+		result *mcp.CreateMessageResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := handler.CreateMessage(ctx, request)
+		done <- outcome{result, err}
+	}()
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // GenerateInProcessSessionID generates a unique session ID for inprocess clients
 func GenerateInProcessSessionID() string {
-	return fmt.Sprintf("inprocess-%d", time.Now().UnixNano())
+	return fmt.Sprintf("inprocess-%d-%d", time.Now().UnixNano(), inProcessSessionCounter.Add(1))
 }
 
+// inProcessSessionCounter keeps IDs generated within the same nanosecond
+// unique.
+var inProcessSessionCounter atomic.Uint64
+
 // Ensure interface compliance
 var (
-	_ ClientSession         = (*InProcessSession)(nil)
-	_ SessionWithLogging    = (*InProcessSession)(nil)
-	_ SessionWithClientInfo = (*InProcessSession)(nil)
-	_ SessionWithSampling   = (*InProcessSession)(nil)
+	_ ClientSession              = (*InProcessSession)(nil)
+	_ SessionWithLogging         = (*InProcessSession)(nil)
+	_ SessionWithClientInfo      = (*InProcessSession)(nil)
+	_ SessionWithProtocolVersion = (*InProcessSession)(nil)
+	_ SessionWithSampling        = (*InProcessSession)(nil)
 )
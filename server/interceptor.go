@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResultInterceptorFunc rewrites the result of a request before it is sent to
+// the client. result is the value the handler produced, such as an
+// mcp.CallToolResult for tools/call; the returned value is marshaled in its
+// place. Results share slices and maps with the handler's result, so return a
+// modified copy rather than changing them in place. Returning an error aborts
+// the response with an internal error.
+type ResultInterceptorFunc func(ctx context.Context, method mcp.MCPMethod, result any) (any, error)
+
+// WithResultInterceptor adds an interceptor that rewrites the results of all
+// requests, for example to watermark content or strip internal fields. It
+// runs after the handler and the OnSuccess and OnAfter hooks, with the
+// request context, so session-specific behavior is possible. Interceptors run
+// in the order they were added, each receiving the result of the previous
+// one. They are not applied to notifications or error responses.
+func WithResultInterceptor(interceptor ResultInterceptorFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.resultInterceptors = append(s.resultInterceptors, interceptor)
+	}
+}
+
+// createResultResponse runs the result interceptors on result and builds the
+// response to the request.
+func (s *MCPServer) createResultResponse(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	message any,
+	result any,
+) mcp.JSONRPCMessage {
+	for _, interceptor := range s.resultInterceptors {
+		intercepted, err := interceptor(ctx, method, result)
+		if err != nil {
+			reqErr := &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  fmt.Errorf("result interceptor: %w", err),
+			}
+			s.hooks.onError(ctx, id, method, message, reqErr.err)
+			return s.sanitizeRequestError(reqErr).ToJSONRPCError()
+		}
+		result = intercepted
+	}
+	return createResponse(id, result)
+}
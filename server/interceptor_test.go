@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// watermark appends suffix to every text content of tool results.
+func watermark(suffix string) ResultInterceptorFunc {
+	return func(ctx context.Context, method mcp.MCPMethod, result any) (any, error) {
+		toolResult, ok := result.(mcp.CallToolResult)
+		if !ok {
+			return result, nil
+		}
+		content := make([]mcp.Content, len(toolResult.Content))
+		for i, c := range toolResult.Content {
+			if text, ok := c.(mcp.TextContent); ok {
+				text.Text += suffix
+				c = text
+			}
+			content[i] = c
+		}
+		toolResult.Content = content
+		return toolResult, nil
+	}
+}
+
+func newInterceptedServer(opts ...ServerOption) *MCPServer {
+	srv := NewMCPServer("test", "1.0.0", append([]ServerOption{WithToolCapabilities(false)}, opts...)...)
+	srv.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("hello"), nil
+	})
+	return srv
+}
+
+const echoCallRequest = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo"}}`
+
+func TestResultInterceptor(t *testing.T) {
+	var methods []mcp.MCPMethod
+	var sessions []string
+	srv := newInterceptedServer(
+		WithResultInterceptor(func(ctx context.Context, method mcp.MCPMethod, result any) (any, error) {
+			methods = append(methods, method)
+			sessions = append(sessions, sessionIDFromContext(ctx))
+			return result, nil
+		}),
+		WithResultInterceptor(watermark(" [a]")),
+		WithResultInterceptor(watermark(" [b]")),
+	)
+	srv.AddNotificationHandler("notifications/test", func(ctx context.Context, notification mcp.JSONRPCNotification) {})
+
+	session := NewInProcessSession("tenant-1", nil)
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	ctx := srv.WithContext(context.Background(), session)
+
+	response := srv.HandleMessage(ctx, []byte(echoCallRequest))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %T", response)
+	result := resp.Result.(mcp.CallToolResult)
+	assert.Equal(t, "hello [a] [b]", result.Content[0].(mcp.TextContent).Text, "interceptors compose in order")
+
+	srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"ping"}`))
+	srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/test"}`))
+	assert.Equal(t, []mcp.MCPMethod{mcp.MethodToolsCall, mcp.MethodPing}, methods, "notifications are not intercepted")
+	assert.Equal(t, []string{"tenant-1", "tenant-1"}, sessions)
+}
+
+func TestResultInterceptor_Error(t *testing.T) {
+	var hookErr error
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		hookErr = err
+	})
+	errWatermark := errors.New("no tenant")
+	srv := newInterceptedServer(
+		WithHooks(hooks),
+		WithResultInterceptor(func(ctx context.Context, method mcp.MCPMethod, result any) (any, error) {
+			return nil, errWatermark
+		}),
+	)
+
+	response := srv.HandleMessage(context.Background(), []byte(echoCallRequest))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected a JSON-RPC error, got %T", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResponse.Error.Code)
+	assert.ErrorIs(t, hookErr, errWatermark)
+}
+
+func TestResultInterceptor_Stdio(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	stdioServer := NewStdioServer(newInterceptedServer(WithResultInterceptor(watermark(" [tenant]"))))
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer stdinWriter.Close()
+
+	initBytes, err := json.Marshal(initRequest)
+	require.NoError(t, err)
+	scanner := bufio.NewScanner(stdoutReader)
+	for _, message := range [][]byte{initBytes, []byte(echoCallRequest)} {
+		_, err := stdinWriter.Write(append(message, '\n'))
+		require.NoError(t, err)
+		require.True(t, scanner.Scan(), "failed to read response")
+	}
+
+	var response struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &response))
+	require.Len(t, response.Result.Content, 1)
+	assert.Equal(t, "hello [tenant]", response.Result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestResultInterceptor_StreamableHTTP(t *testing.T) {
+	server := NewTestStreamableHTTPServer(newInterceptedServer(WithResultInterceptor(watermark(" [tenant]"))))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(echoCallRequest))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderKeySessionID, sessionID)
+	resp, err = server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Result mcp.CallToolResult `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Len(t, response.Result.Content, 1)
+	assert.Equal(t, "hello [tenant]", response.Result.Content[0].(mcp.TextContent).Text)
+}
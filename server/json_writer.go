@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// sseWriteBufferSize is the size of the buffer SSE events are written
+// through, so that the "data: " prefixes don't each cost a write.
+const sseWriteBufferSize = 4096
+
+// sseWriteError is returned by encodeSSEEvent when the event could not be
+// written to the stream, as opposed to its data not being marshaled.
+type sseWriteError struct {
+	err error
+}
+
+func (e *sseWriteError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sseWriteError) Unwrap() error {
+	return e.err
+}
+
+// encodeSSEEvent writes data as a "message" SSE event to w. json.Encoder
+// still marshals the whole message into its own buffer, but it is written
+// from there without the copies that formatting the event would take.
+// Nothing is written if data cannot be marshaled; an error writing to w is
+// returned as an *sseWriteError.
+//
+// The output is byte for byte the same as
+//
+//	fmt.Fprintf(w, "event: message\ndata: %s\n\n", json.Marshal(data))
+func encodeSSEEvent(w io.Writer, data any) error {
+	bw := bufio.NewWriterSize(w, sseWriteBufferSize)
+	_, _ = bw.WriteString("event: message\n")
+	dw := &sseDataWriter{w: bw, lineStart: true}
+	if err := json.NewEncoder(dw).Encode(data); err != nil {
+		if dw.err != nil {
+			return &sseWriteError{err: err}
+		}
+		return err
+	}
+	_ = bw.WriteByte('\n')
+	if err := bw.Flush(); err != nil {
+		return &sseWriteError{err: err}
+	}
+	return nil
+}
+
+// sseDataWriter prefixes every line written through it with "data: ", so
+// that a message spanning several lines stays a single SSE event. Compact
+// JSON has no line breaks besides the one json.Encoder appends.
+type sseDataWriter struct {
+	w         io.Writer
+	lineStart bool
+	// err is the first error writing to w.
+	err error
+}
+
+func (d *sseDataWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if d.lineStart {
+			if _, err := io.WriteString(d.w, "data: "); err != nil {
+				d.err = err
+				return written, err
+			}
+			d.lineStart = false
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			d.lineStart = true
+		}
+		n, err := d.w.Write(line)
+		written += n
+		if err != nil {
+			d.err = err
+			return written, err
+		}
+		p = p[len(line):]
+	}
+	return written, nil
+}
+
+// jsonResponseWriter writes a JSON response body with status 200. When
+// compress is set, it holds back the first minCompressedResponseSize bytes
+// to decide whether the body is worth gzipping, then writes the rest through.
+type jsonResponseWriter struct {
+	w        http.ResponseWriter
+	compress bool
+	buf      []byte
+	started  bool
+	zw       *gzip.Writer
+}
+
+func (j *jsonResponseWriter) Write(p []byte) (int, error) {
+	if !j.started && j.compress && len(j.buf)+len(p) < minCompressedResponseSize {
+		j.buf = append(j.buf, p...)
+		return len(p), nil
+	}
+	if !j.started {
+		if err := j.start(j.compress); err != nil {
+			return 0, err
+		}
+	}
+	if j.zw != nil {
+		return j.zw.Write(p)
+	}
+	return j.w.Write(p)
+}
+
+// start writes the headers and the held back bytes.
+func (j *jsonResponseWriter) start(gzipped bool) error {
+	j.started = true
+	if gzipped {
+		j.w.Header().Set("Content-Encoding", "gzip")
+		j.w.Header().Add("Vary", "Accept-Encoding")
+		j.w.WriteHeader(http.StatusOK)
+		j.zw = gzip.NewWriter(j.w)
+		_, err := j.zw.Write(j.buf)
+		return err
+	}
+	j.w.WriteHeader(http.StatusOK)
+	_, err := j.w.Write(j.buf)
+	return err
+}
+
+// Close writes a body that was too small to compress, or ends the gzip
+// stream.
+func (j *jsonResponseWriter) Close() error {
+	if !j.started {
+		return j.start(false)
+	}
+	if j.zw != nil {
+		return j.zw.Close()
+	}
+	return nil
+}
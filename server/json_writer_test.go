@@ -0,0 +1,196 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// legacySSEEvent is how SSE events were written before they were encoded
+// into the stream.
+func legacySSEEvent(w io.Writer, data any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", jsonData)
+	return err
+}
+
+// largeToolResponse returns a tools/call response carrying about size bytes
+// of text.
+func largeToolResponse(size int) mcp.JSONRPCResponse {
+	return mcp.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(1),
+		Result:  mcp.NewToolResultText(strings.Repeat("lorem ipsum <dolor> sit amet\n", size/29)),
+	}
+}
+
+var wireOutputCases = []struct {
+	name string
+	data any
+}{
+	{"notification", mcp.NewLoggingMessageNotification(mcp.LoggingLevelInfo, "test", "hello")},
+	{"html and unicode", map[string]any{"text": "<b>&</b>   héllo\n\t\"quoted\""}},
+	{"indented raw message", json.RawMessage("{\n  \"a\": [1,\n 2]\n}")},
+	{"tool result", largeToolResponse(10_000)},
+}
+
+func TestWriteSSEEvent_WireOutput(t *testing.T) {
+	var golden bytes.Buffer
+	require.NoError(t, writeSSEEvent(&golden, map[string]any{"id": 1, "text": "a<b"}))
+	assert.Equal(t, "event: message\ndata: {\"id\":1,\"text\":\"a\\u003cb\"}\n\n", golden.String())
+
+	for _, tc := range wireOutputCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var want, got bytes.Buffer
+			require.NoError(t, legacySSEEvent(&want, tc.data))
+			require.NoError(t, writeSSEEvent(&got, tc.data))
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+
+	t.Run("marshal error writes nothing", func(t *testing.T) {
+		var got bytes.Buffer
+		err := writeSSEEvent(&got, math.Inf(1))
+		assert.Error(t, err)
+		var writeErr *sseWriteError
+		assert.False(t, errors.As(err, &writeErr), "marshal errors are not write errors")
+		assert.Zero(t, got.Len())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		for _, tc := range wireOutputCases {
+			err := writeSSEEvent(failingWriter{}, tc.data)
+			var writeErr *sseWriteError
+			assert.True(t, errors.As(err, &writeErr), "expected a write error for %s, got %v", tc.name, err)
+			assert.ErrorIs(t, err, io.ErrClosedPipe)
+		}
+	})
+}
+
+// failingWriter fails every write, like a stream whose client went away.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestSSEDataWriter_MultilineData(t *testing.T) {
+	var got bytes.Buffer
+	writer := &sseDataWriter{w: &got, lineStart: true}
+	for _, chunk := range []string{"first", " line\nsec", "ond line\n", "\nlast\n"} {
+		_, err := writer.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "data: first line\ndata: second line\ndata: \ndata: last\n", got.String())
+}
+
+func TestWriteJSONResponse_WireOutput(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		for _, tc := range wireOutputCases {
+			t.Run(fmt.Sprintf("%s compression=%v", tc.name, compression), func(t *testing.T) {
+				want, err := json.Marshal(tc.data)
+				require.NoError(t, err)
+				want = append(want, '\n')
+
+				s := &StreamableHTTPServer{compression: compression}
+				r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+				r.Header.Set("Accept-Encoding", "gzip")
+				w := httptest.NewRecorder()
+				require.NoError(t, s.writeJSONResponse(w, r, tc.data))
+				assert.Equal(t, http.StatusOK, w.Code)
+
+				body := w.Body.Bytes()
+				gzipped := compression && len(want) >= minCompressedResponseSize
+				if gzipped {
+					assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+					zr, err := gzip.NewReader(w.Body)
+					require.NoError(t, err)
+					body, err = io.ReadAll(zr)
+					require.NoError(t, err)
+				} else {
+					assert.Empty(t, w.Header().Get("Content-Encoding"))
+				}
+				assert.Equal(t, string(want), string(body))
+			})
+		}
+	}
+
+	t.Run("marshal error", func(t *testing.T) {
+		s := &StreamableHTTPServer{}
+		w := httptest.NewRecorder()
+		assert.Error(t, s.writeJSONResponse(w, httptest.NewRequest(http.MethodPost, "/mcp", nil), math.Inf(1)))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Zero(t, w.Body.Len())
+	})
+}
+
+// BenchmarkWriteSSEEvent compares writing a 20MB tool result as an SSE
+// event by marshaling and formatting it with encoding it into the stream. Run
+// with -benchmem: marshaling allocates several copies of the message per
+// event, while the encoder reuses its pooled buffer, which still holds the
+// whole message, and only allocates the small write buffer.
+func BenchmarkWriteSSEEvent(b *testing.B) {
+	response := largeToolResponse(20 << 20)
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := legacySSEEvent(io.Discard, response); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := writeSSEEvent(io.Discard, response); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkWriteJSONResponse does the same for plain JSON responses.
+func BenchmarkWriteJSONResponse(b *testing.B) {
+	response := largeToolResponse(20 << 20)
+	s := &StreamableHTTPServer{}
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			data, err := json.Marshal(response)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_, _ = io.Discard.Write(append(data, '\n'))
+		}
+	})
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := s.writeJSONResponse(discardResponseWriter{}, r, response); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// discardResponseWriter is an http.ResponseWriter that drops the body.
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return http.Header{} }
+func (discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithSortedToolList makes tools/list return tools sorted by name, after the
+// tool filters have run. Tools are collected in name order by default, but a
+// filter may reorder them, which also breaks the name-based pagination
+// cursors.
+func WithSortedToolList() ServerOption {
+	return func(s *MCPServer) {
+		s.sortedTools = true
+	}
+}
+
+// WithSortedResourceList makes resources/list and resources/templates/list
+// return entries sorted by name, breaking ties between entries with the same
+// name by URI so that the order is fully deterministic.
+func WithSortedResourceList() ServerOption {
+	return func(s *MCPServer) {
+		s.sortedResources = true
+	}
+}
+
+// sortByName returns a copy of items sorted by name, breaking ties with
+// tiebreak if it is not nil.
+func sortByName[T mcp.Named](items []T, tiebreak func(T) string) []T {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		if c := cmp.Compare(a.GetName(), b.GetName()); c != 0 || tiebreak == nil {
+			return c
+		}
+		return cmp.Compare(tiebreak(a), tiebreak(b))
+	})
+	return sorted
+}
+
+func resourceURI(resource mcp.Resource) string {
+	return resource.URI
+}
+
+func resourceTemplateURI(template mcp.ResourceTemplate) string {
+	if template.URITemplate == nil || template.URITemplate.Template == nil {
+		return ""
+	}
+	return template.URITemplate.Raw()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func reverseTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	reversed := slices.Clone(tools)
+	slices.Reverse(reversed)
+	return reversed
+}
+
+func listResult[T any](t *testing.T, srv *MCPServer, method string) T {
+	t.Helper()
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"`+method+`"}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %#v", response)
+	result, ok := resp.Result.(T)
+	require.True(t, ok, "unexpected result %T", resp.Result)
+	return result
+}
+
+func TestWithSortedToolList(t *testing.T) {
+	toolNames := func(srv *MCPServer) []string {
+		var names []string
+		for _, tool := range listResult[mcp.ListToolsResult](t, srv, "tools/list").Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	addTools := func(srv *MCPServer) {
+		for _, name := range []string{"charlie", "alpha", "delta", "bravo"} {
+			srv.AddTool(mcp.NewTool(name), nil)
+		}
+	}
+
+	unsorted := NewMCPServer("test", "1.0.0", WithToolFilter(reverseTools))
+	addTools(unsorted)
+	assert.Equal(t, []string{"delta", "charlie", "bravo", "alpha"}, toolNames(unsorted), "filters may reorder tools")
+
+	sorted := NewMCPServer("test", "1.0.0", WithToolFilter(reverseTools), WithSortedToolList())
+	addTools(sorted)
+	assert.Equal(t, []string{"alpha", "bravo", "charlie", "delta"}, toolNames(sorted))
+}
+
+func TestWithSortedResourceList(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0", WithSortedResourceList())
+	for _, uri := range []string{"test://c", "test://a", "test://d", "test://b"} {
+		srv.AddResource(mcp.NewResource(uri, "same"), nil)
+	}
+	srv.AddResource(mcp.NewResource("test://z", "first"), nil)
+	for _, uri := range []string{"test://{b}", "test://{a}"} {
+		srv.AddResourceTemplate(mcp.NewResourceTemplate(uri, "same"), nil)
+	}
+
+	for range 5 {
+		var uris []string
+		for _, resource := range listResult[mcp.ListResourcesResult](t, srv, "resources/list").Resources {
+			uris = append(uris, resource.URI)
+		}
+		assert.Equal(t, []string{"test://z", "test://a", "test://b", "test://c", "test://d"}, uris)
+
+		var templates []string
+		for _, template := range listResult[mcp.ListResourceTemplatesResult](t, srv, "resources/templates/list").ResourceTemplates {
+			templates = append(templates, template.URITemplate.Raw())
+		}
+		assert.Equal(t, []string{"test://{a}", "test://{b}"}, templates)
+	}
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxPendingNotifications bounds the notifications a session may have waiting
+// for their coalescing window to pass.
+const maxPendingNotifications = 1000
+
+// WithNotificationCoalescing collapses identical notifications sent to the
+// same session within window into one. Two notifications are identical if
+// they have the same method and params. Every notification is held back for
+// window before it is delivered. An identical notification emitted while it
+// is waiting replaces it, so the client sees the last occurrence in its
+// place in the order: A, B, A is delivered as B, A. The replacement is
+// delivered no later than the notification it replaced would have been. This
+// spares clients repeated refreshes when, for example, several tools are
+// added in a row.
+//
+// Notifications tied to a request, such as progress notifications or
+// notifications carrying a progressToken or requestId, are never coalesced;
+// they are still held back for window to keep the order.
+//
+// Coalescing only applies to registered sessions. Notifications keep the
+// order in which they were emitted for each session; there is no ordering
+// across sessions.
+func WithNotificationCoalescing(window time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.notificationCoalescing = window
+	}
+}
+
+// pendingNotification is a notification waiting in a notificationQueue.
+type pendingNotification struct {
+	notification mcp.JSONRPCNotification
+	key          string
+	emitted      time.Time
+}
+
+// notificationQueue delivers the notifications of a registered session in
+// the order they were emitted. Without coalescing, notifications are handed to
+// the session's channel right away, serialized by the queue's lock. With
+// coalescing, a single writer goroutine drains the pending notifications
+// once their window has passed.
+type notificationQueue struct {
+	server  *MCPServer
+	session ClientSession
+	window  time.Duration
+
+	mu      sync.Mutex
+	pending []pendingNotification
+	running bool
+	done    chan struct{}
+	closed  bool
+}
+
+// queueNotification delivers the notification to the session, through the
+// session's queue if it is registered.
+func (s *MCPServer) queueNotification(
+	ctx context.Context,
+	session ClientSession,
+	notification mcp.JSONRPCNotification,
+) error {
+	if queue := s.notificationQueueFor(session); queue != nil {
+		return queue.send(ctx, notification)
+	}
+	return s.trySendNotification(ctx, session, notification)
+}
+
+// notificationQueueFor returns the queue of session, or nil if session is not
+// registered. Sessions that merely share the ID of a registered session, like
+// the per-request sessions of the streamable HTTP transport, have no queue.
+func (s *MCPServer) notificationQueueFor(session ClientSession) *notificationQueue {
+	sessionID := session.SessionID()
+	value, ok := s.notificationQueues.Load(sessionID)
+	if !ok {
+		// UnregisterSession removes the session and its queue under the
+		// same lock, so a notification in flight can't create a queue for a
+		// session that is already gone
+		s.notificationQueuesMu.Lock()
+		if registered, ok := s.sessions.Load(sessionID); !ok || registered != session {
+			s.notificationQueuesMu.Unlock()
+			return nil
+		}
+		value, _ = s.notificationQueues.LoadOrStore(sessionID, &notificationQueue{
+			server:  s,
+			session: session,
+			window:  s.notificationCoalescing,
+			done:    make(chan struct{}),
+		})
+		s.notificationQueuesMu.Unlock()
+	}
+	queue := value.(*notificationQueue)
+	if queue.session != session {
+		return nil
+	}
+	return queue
+}
+
+// send delivers the notification or, with coalescing, schedules it in place of
+// an identical notification that is already pending.
+func (q *notificationQueue) send(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.window <= 0 {
+		return q.server.trySendNotification(ctx, q.session, notification)
+	}
+	if q.closed {
+		return ErrSessionNotFound
+	}
+
+	key := notificationKey(notification)
+	emitted := time.Now()
+	if key != "" {
+		for i, pending := range q.pending {
+			if pending.key == key {
+				// Keep the deadline of the replaced notification, so that
+				// one that is re-emitted continuously is still delivered
+				emitted = pending.emitted
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	if len(q.pending) >= maxPendingNotifications {
+		return ErrNotificationChannelBlocked
+	}
+	q.pending = append(q.pending, pendingNotification{
+		notification: notification,
+		key:          key,
+		emitted:      emitted,
+	})
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+	return nil
+}
+
+// run delivers pending notifications in order once their window has passed.
+// It returns when no notifications are left.
+func (q *notificationQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 || q.closed {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		// A replacement keeps the deadline of the notification it replaced,
+		// so the first pending notification is the one due last
+		next := q.pending[0]
+		if wait := q.window - time.Since(next.emitted); wait > 0 {
+			q.mu.Unlock()
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-q.done:
+				timer.Stop()
+			}
+			continue
+		}
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		// Failures are reported through the error hooks
+		_ = q.server.trySendNotification(context.Background(), q.session, next.notification)
+	}
+}
+
+// close drops pending notifications and stops the writer goroutine.
+func (q *notificationQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.pending = nil
+		close(q.done)
+	}
+}
+
+// notificationKey identifies notifications that may be coalesced. It returns
+// "" for notifications that must each be delivered.
+func notificationKey(notification mcp.JSONRPCNotification) string {
+	if isRequestNotification(notification) {
+		return ""
+	}
+	params, err := json.Marshal(notification.Params)
+	if err != nil {
+		// Never coalesce notifications whose params can't be compared
+		return ""
+	}
+	return notification.Method + "\x00" + string(params)
+}
+
+// isRequestNotification reports whether the notification is about a
+// particular request, that is whether it carries a progressToken, in its
+// params or their _meta, or a requestId. Progress and cancellation
+// notifications always do.
+func isRequestNotification(notification mcp.JSONRPCNotification) bool {
+	params := notification.Params
+	if _, ok := params.Meta["progressToken"]; ok {
+		return true
+	}
+	for _, field := range []string{"progressToken", "requestId"} {
+		if _, ok := params.AdditionalFields[field]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// PanicError is the error reported for a request whose handler panicked.
+type PanicError struct {
+	// Method is the method of the request being handled.
+	Method mcp.MCPMethod
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic handling %s: %v", e.Method, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// PanicHandlerFunc converts a panic in a tool handler into the result of the
+// tool call. stack is the stack trace of the panicking goroutine.
+type PanicHandlerFunc func(recovered any, stack []byte) *mcp.CallToolResult
+
+// WithPanicHandler sets the function that turns a panic in a tool handler
+// into a tool result, for example one with IsError set that the model can
+// react to. Without it, or if it returns nil, the panic is reported to the
+// client as a JSON-RPC internal error.
+//
+// The server recovers from panics in all request handlers regardless of this
+// option: each panic is logged with its stack trace, reported to the OnPanic
+// and OnError hooks, and answered with an internal error.
+func WithPanicHandler(handler PanicHandlerFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.panicHandler = handler
+	}
+}
+
+// WithServerLogger sets the logger the server writes errors to, such as the
+// stack traces of recovered panics, and that handlers get from
+// LoggerFromContext. It defaults to util.DefaultLogger().
+func WithServerLogger(logger util.Logger) ServerOption {
+	return func(s *MCPServer) {
+		s.logger = logger
+	}
+}
+
+func (s *MCPServer) errorLogger() util.Logger {
+	if s == nil || s.logger == nil {
+		return util.DefaultLogger()
+	}
+	return s.logger
+}
+
+// reportPanic logs a recovered panic and runs the OnPanic hooks.
+func (s *MCPServer) reportPanic(ctx context.Context, id any, method mcp.MCPMethod, message any, panicErr *PanicError) {
+	s.contextLogger(ctx).Errorf("%v (request %v)\n%s", panicErr, id, panicErr.Stack)
+	s.hooks.onPanic(ctx, id, method, message, panicErr.Value, panicErr.Stack)
+}
+
+// recoverRequestPanic builds the response to a request whose handling
+// panicked with recovered. Notifications get no response.
+func (s *MCPServer) recoverRequestPanic(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	message json.RawMessage,
+	recovered any,
+) mcp.JSONRPCMessage {
+	panicErr := &PanicError{Method: method, Value: recovered, Stack: debug.Stack()}
+	s.reportPanic(ctx, id, method, message, panicErr)
+	if id == nil {
+		return nil
+	}
+	s.hooks.onError(ctx, id, method, message, panicErr)
+	return s.sanitizeRequestError(&requestError{
+		id:   id,
+		code: mcp.INTERNAL_ERROR,
+		err:  panicErr,
+	}).ToJSONRPCError()
+}
+
+// callToolHandler runs a tool handler, converting a panic into a tool result
+// with the panic handler if one is set. Other panics propagate to
+// HandleMessage, which answers them with an internal error.
+func (s *MCPServer) callToolHandler(
+	ctx context.Context,
+	id any,
+	handler ToolHandlerFunc,
+	request mcp.CallToolRequest,
+) (result *mcp.CallToolResult, err error) {
+	if s.panicHandler == nil {
+		return handler(ctx, request)
+	}
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		panicErr := &PanicError{Method: mcp.MethodToolsCall, Value: recovered, Stack: debug.Stack()}
+		s.reportPanic(ctx, id, mcp.MethodToolsCall, &request, panicErr)
+		if result = s.panicHandler(recovered, panicErr.Stack); result == nil {
+			err = panicErr
+		}
+	}()
+	return handler(ctx, request)
+}
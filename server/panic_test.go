@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// recordingLogger keeps the lines logged by the server.
+type recordingLogger struct {
+	mu     sync.Mutex
+	infos  []string
+	errors []string
+}
+
+func (l *recordingLogger) Infof(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.infos = append(l.infos, fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Errorf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, v...))
+}
+
+type panicRecord struct {
+	method    mcp.MCPMethod
+	recovered any
+	stack     []byte
+}
+
+func newPanickingServer(t *testing.T, opts ...ServerOption) (*MCPServer, *recordingLogger, *[]panicRecord, *[]error) {
+	t.Helper()
+	logger := &recordingLogger{}
+	var panics []panicRecord
+	var errs []error
+	hooks := &Hooks{}
+	hooks.AddOnPanic(func(ctx context.Context, id any, method mcp.MCPMethod, message any, recovered any, stack []byte) {
+		panics = append(panics, panicRecord{method: method, recovered: recovered, stack: stack})
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		errs = append(errs, err)
+	})
+
+	opts = append([]ServerOption{
+		WithToolCapabilities(false),
+		WithResourceCapabilities(false, false),
+		WithHooks(hooks),
+		WithServerLogger(logger),
+	}, opts...)
+	srv := NewMCPServer("test", "1.0.0", opts...)
+	srv.AddTool(mcp.NewTool("explode"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	})
+	srv.AddResource(mcp.NewResource("test://explode", "explode"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		panic(errors.New("resource boom"))
+	})
+	srv.AddNotificationHandler("notifications/explode", func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		panic("notification boom")
+	})
+	return srv, logger, &panics, &errs
+}
+
+func TestMCPServer_RecoversHandlerPanics(t *testing.T) {
+	srv, logger, panics, errs := newPanickingServer(t)
+
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"explode"}}`))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected a JSON-RPC error, got %T", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResponse.Error.Code)
+	assert.Contains(t, errResponse.Error.Message, "panic handling tools/call: boom")
+
+	require.Len(t, *panics, 1)
+	assert.Equal(t, mcp.MethodToolsCall, (*panics)[0].method)
+	assert.Equal(t, "boom", (*panics)[0].recovered)
+	assert.Contains(t, string((*panics)[0].stack), "panic_test.go")
+
+	require.Len(t, *errs, 1)
+	var panicErr *PanicError
+	require.ErrorAs(t, (*errs)[0], &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "panic handling tools/call: boom")
+	assert.Contains(t, logger.errors[0], "goroutine")
+
+	// Other handlers are covered too
+	response = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"test://explode"}}`))
+	errResponse, ok = response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected a JSON-RPC error, got %T", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResponse.Error.Code)
+	require.Len(t, *errs, 2)
+	assert.EqualError(t, errors.Unwrap((*errs)[1]), "resource boom")
+
+	// Notifications have no response, but the panic is still reported
+	response = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/explode"}`))
+	assert.Nil(t, response)
+	require.Len(t, *panics, 3)
+	assert.Equal(t, "notification boom", (*panics)[2].recovered)
+}
+
+func TestMCPServer_WithPanicHandler(t *testing.T) {
+	srv, logger, panics, errs := newPanickingServer(t, WithPanicHandler(func(recovered any, stack []byte) *mcp.CallToolResult {
+		return mcp.NewToolResultErrorf("the tool crashed: %v", recovered)
+	}))
+
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"explode"}}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %T", response)
+	result, ok := resp.Result.(mcp.CallToolResult)
+	require.True(t, ok, "expected a tool result, got %T", resp.Result)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "the tool crashed: boom", result.Content[0].(mcp.TextContent).Text)
+
+	assert.Len(t, *panics, 1, "the panic is still reported")
+	assert.Len(t, logger.errors, 1)
+	assert.Empty(t, *errs)
+
+	// A nil result falls back to the internal error
+	srv, _, _, _ = newPanickingServer(t, WithPanicHandler(func(recovered any, stack []byte) *mcp.CallToolResult {
+		return nil
+	}))
+	response = srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"explode"}}`))
+	errResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected a JSON-RPC error, got %T", response)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResponse.Error.Code)
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ServerCatalog is the complete set of tools, prompts and resources a server
+// should expose, as passed to MCPServer.Reconcile.
+type ServerCatalog struct {
+	Tools     []ServerTool
+	Prompts   []ServerPrompt
+	Resources []ServerResource
+}
+
+// CatalogDiff lists the changes Reconcile applies to bring the server in line
+// with a catalog. Tools and prompts are identified by name and resources by
+// URI. Each list is sorted.
+type CatalogDiff struct {
+	AddedTools     []string
+	UpdatedTools   []string
+	RemovedTools   []string
+	AddedPrompts   []string
+	UpdatedPrompts []string
+	RemovedPrompts []string
+
+	AddedResources   []string
+	UpdatedResources []string
+	RemovedResources []string
+}
+
+// ToolsChanged reports whether the diff adds, updates or removes any tool.
+func (d CatalogDiff) ToolsChanged() bool {
+	return len(d.AddedTools)+len(d.UpdatedTools)+len(d.RemovedTools) > 0
+}
+
+// PromptsChanged reports whether the diff adds, updates or removes any prompt.
+func (d CatalogDiff) PromptsChanged() bool {
+	return len(d.AddedPrompts)+len(d.UpdatedPrompts)+len(d.RemovedPrompts) > 0
+}
+
+// ResourcesChanged reports whether the diff adds, updates or removes any resource.
+func (d CatalogDiff) ResourcesChanged() bool {
+	return len(d.AddedResources)+len(d.UpdatedResources)+len(d.RemovedResources) > 0
+}
+
+// Empty reports whether the diff contains no changes.
+func (d CatalogDiff) Empty() bool {
+	return !d.ToolsChanged() && !d.PromptsChanged() && !d.ResourcesChanged()
+}
+
+// DiffCatalog computes the changes Reconcile would apply for catalog without
+// modifying the server. It is useful for logging or dry runs.
+func (s *MCPServer) DiffCatalog(catalog ServerCatalog) CatalogDiff {
+	s.toolsMu.RLock()
+	s.promptsMu.RLock()
+	s.resourcesMu.RLock()
+	defer s.toolsMu.RUnlock()
+	defer s.promptsMu.RUnlock()
+	defer s.resourcesMu.RUnlock()
+
+	return s.diffCatalogLocked(catalog)
+}
+
+// Reconcile replaces the server's tools, prompts and resources with those in
+// catalog and returns the applied changes. All changes are applied under the
+// registry locks, so requests observe either the old or the new catalog.
+//
+// Entries are compared by their JSON definition. Entries whose definition is
+// unchanged keep their current handler, so in-flight and future calls are not
+// affected; handlers are only replaced together with a changed definition.
+// One list_changed notification is sent per category that actually changed,
+// if the corresponding listChanged capability is enabled.
+func (s *MCPServer) Reconcile(catalog ServerCatalog) CatalogDiff {
+	if len(catalog.Tools) > 0 {
+		s.implicitlyRegisterToolCapabilities()
+	}
+	if len(catalog.Prompts) > 0 {
+		s.implicitlyRegisterPromptCapabilities()
+	}
+	if len(catalog.Resources) > 0 {
+		s.implicitlyRegisterResourceCapabilities()
+	}
+
+	s.toolsMu.Lock()
+	s.promptsMu.Lock()
+	s.resourcesMu.Lock()
+	diff := s.diffCatalogLocked(catalog)
+	s.applyCatalogLocked(catalog, diff)
+	s.resourcesMu.Unlock()
+	s.promptsMu.Unlock()
+	s.toolsMu.Unlock()
+
+	s.capabilitiesMu.RLock()
+	notifyTools := diff.ToolsChanged() && s.capabilities.tools != nil && s.capabilities.tools.listChanged
+	notifyPrompts := diff.PromptsChanged() && s.capabilities.prompts != nil && s.capabilities.prompts.listChanged
+	notifyResources := diff.ResourcesChanged() && s.capabilities.resources != nil && s.capabilities.resources.listChanged
+	s.capabilitiesMu.RUnlock()
+
+	if notifyTools {
+		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+	if notifyPrompts {
+		s.SendNotificationToAllClients(mcp.MethodNotificationPromptsListChanged, nil)
+	}
+	if notifyResources {
+		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+	}
+
+	return diff
+}
+
+// diffCatalogLocked computes the diff for catalog. The caller must hold the
+// tools, prompts and resources locks.
+func (s *MCPServer) diffCatalogLocked(catalog ServerCatalog) CatalogDiff {
+	var diff CatalogDiff
+
+	currentTools := make(map[string]any, len(s.tools))
+	for name, tool := range s.tools {
+		currentTools[name] = tool.Tool
+	}
+	desiredTools := make(map[string]any, len(catalog.Tools))
+	for _, tool := range catalog.Tools {
+		desiredTools[tool.Tool.Name] = tool.Tool
+	}
+	diff.AddedTools, diff.UpdatedTools, diff.RemovedTools = diffDefinitions(currentTools, desiredTools)
+
+	currentPrompts := make(map[string]any, len(s.prompts))
+	for name, prompt := range s.prompts {
+		currentPrompts[name] = prompt
+	}
+	desiredPrompts := make(map[string]any, len(catalog.Prompts))
+	for _, prompt := range catalog.Prompts {
+		desiredPrompts[prompt.Prompt.Name] = prompt.Prompt
+	}
+	diff.AddedPrompts, diff.UpdatedPrompts, diff.RemovedPrompts = diffDefinitions(currentPrompts, desiredPrompts)
+
+	currentResources := make(map[string]any, len(s.resources))
+	for uri, entry := range s.resources {
+		currentResources[uri] = entry.resource
+	}
+	desiredResources := make(map[string]any, len(catalog.Resources))
+	for _, resource := range catalog.Resources {
+		desiredResources[resource.Resource.URI] = resource.Resource
+	}
+	diff.AddedResources, diff.UpdatedResources, diff.RemovedResources = diffDefinitions(currentResources, desiredResources)
+
+	return diff
+}
+
+// applyCatalogLocked applies diff using the entries of catalog. The caller
+// must hold the tools, prompts and resources locks for writing.
+func (s *MCPServer) applyCatalogLocked(catalog ServerCatalog, diff CatalogDiff) {
+	for _, name := range diff.RemovedTools {
+		delete(s.tools, name)
+	}
+	for _, tool := range catalog.Tools {
+		if changed(diff.AddedTools, diff.UpdatedTools, tool.Tool.Name) {
+			s.tools[tool.Tool.Name] = tool
+		}
+	}
+
+	for _, name := range diff.RemovedPrompts {
+		delete(s.prompts, name)
+		delete(s.promptHandlers, name)
+	}
+	for _, prompt := range catalog.Prompts {
+		if changed(diff.AddedPrompts, diff.UpdatedPrompts, prompt.Prompt.Name) {
+			s.prompts[prompt.Prompt.Name] = prompt.Prompt
+			s.promptHandlers[prompt.Prompt.Name] = prompt.Handler
+		}
+	}
+
+	for _, uri := range diff.RemovedResources {
+		delete(s.resources, uri)
+	}
+	for _, resource := range catalog.Resources {
+		if changed(diff.AddedResources, diff.UpdatedResources, resource.Resource.URI) {
+			s.resources[resource.Resource.URI] = resourceEntry{
+				resource: resource.Resource,
+				handler:  resource.Handler,
+			}
+		}
+	}
+}
+
+// changed reports whether key is in added or updated.
+func changed(added, updated []string, key string) bool {
+	return slices.Contains(added, key) || slices.Contains(updated, key)
+}
+
+// diffDefinitions compares two sets of definitions by their JSON encoding and
+// returns the sorted keys that were added, updated and removed.
+func diffDefinitions(current, desired map[string]any) (added, updated, removed []string) {
+	for key, definition := range desired {
+		existing, ok := current[key]
+		switch {
+		case !ok:
+			added = append(added, key)
+		case !sameDefinition(existing, definition):
+			updated = append(updated, key)
+		}
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(updated)
+	slices.Sort(removed)
+	return added, updated, removed
+}
+
+// sameDefinition reports whether a and b encode to the same JSON. Values that
+// can't be encoded are never considered equal.
+func sameDefinition(a, b any) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
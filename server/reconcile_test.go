@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPServer_Reconcile(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(true),
+		WithPromptCapabilities(true),
+		WithResourceCapabilities(false, true),
+	)
+
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	drainNotifications := func() map[string]int {
+		counts := make(map[string]int)
+		for {
+			select {
+			case notification := <-session.notificationChannel:
+				counts[notification.Method]++
+			default:
+				return counts
+			}
+		}
+	}
+
+	toolHandler := func(text string) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(text), nil
+		}
+	}
+	promptHandler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("", nil), nil
+	}
+	resourceHandler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+
+	callTool := func(name string) string {
+		response := server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "`+name+`"}
+		}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	initial := ServerCatalog{
+		Tools: []ServerTool{
+			{Tool: mcp.NewTool("search", mcp.WithDescription("Search the catalog")), Handler: toolHandler("search v1")},
+			{Tool: mcp.NewTool("fetch"), Handler: toolHandler("fetch v1")},
+		},
+		Prompts: []ServerPrompt{
+			{Prompt: mcp.NewPrompt("summarize"), Handler: promptHandler},
+		},
+		Resources: []ServerResource{
+			{Resource: mcp.NewResource("catalog://services", "services"), Handler: resourceHandler},
+		},
+	}
+
+	t.Run("initial reconcile adds everything", func(t *testing.T) {
+		diff := server.Reconcile(initial)
+		assert.Equal(t, []string{"fetch", "search"}, diff.AddedTools)
+		assert.Equal(t, []string{"summarize"}, diff.AddedPrompts)
+		assert.Equal(t, []string{"catalog://services"}, diff.AddedResources)
+		assert.Equal(t, map[string]int{
+			mcp.MethodNotificationToolsListChanged:     1,
+			mcp.MethodNotificationPromptsListChanged:   1,
+			mcp.MethodNotificationResourcesListChanged: 1,
+		}, drainNotifications())
+	})
+
+	t.Run("no-op reconcile keeps handlers and sends nothing", func(t *testing.T) {
+		unchanged := ServerCatalog{
+			Tools: []ServerTool{
+				{Tool: mcp.NewTool("search", mcp.WithDescription("Search the catalog")), Handler: toolHandler("search v2")},
+				{Tool: mcp.NewTool("fetch"), Handler: toolHandler("fetch v2")},
+			},
+			Prompts:   initial.Prompts,
+			Resources: initial.Resources,
+		}
+		diff := server.Reconcile(unchanged)
+		assert.True(t, diff.Empty(), "expected empty diff, got %+v", diff)
+		assert.Empty(t, drainNotifications())
+
+		// Handlers of unchanged definitions are not swapped
+		assert.Equal(t, "search v1", callTool("search"))
+		assert.Equal(t, "fetch v1", callTool("fetch"))
+	})
+
+	t.Run("dry run computes diff without applying it", func(t *testing.T) {
+		next := ServerCatalog{
+			Tools: []ServerTool{
+				{Tool: mcp.NewTool("search", mcp.WithDescription("Search the service catalog")), Handler: toolHandler("search v3")},
+				{Tool: mcp.NewTool("deploy"), Handler: toolHandler("deploy v1")},
+			},
+			Prompts:   initial.Prompts,
+			Resources: initial.Resources,
+		}
+		diff := server.DiffCatalog(next)
+		assert.Equal(t, []string{"deploy"}, diff.AddedTools)
+		assert.Equal(t, []string{"search"}, diff.UpdatedTools)
+		assert.Equal(t, []string{"fetch"}, diff.RemovedTools)
+		assert.False(t, diff.PromptsChanged())
+		assert.False(t, diff.ResourcesChanged())
+
+		assert.Empty(t, drainNotifications())
+		assert.Equal(t, "search v1", callTool("search"))
+		assert.Equal(t, "fetch v1", callTool("fetch"))
+	})
+
+	t.Run("changes only notify affected categories", func(t *testing.T) {
+		next := ServerCatalog{
+			Tools: []ServerTool{
+				{Tool: mcp.NewTool("search", mcp.WithDescription("Search the service catalog")), Handler: toolHandler("search v3")},
+				{Tool: mcp.NewTool("deploy"), Handler: toolHandler("deploy v1")},
+			},
+			Prompts:   initial.Prompts,
+			Resources: nil,
+		}
+		diff := server.Reconcile(next)
+		assert.Equal(t, []string{"deploy"}, diff.AddedTools)
+		assert.Equal(t, []string{"search"}, diff.UpdatedTools)
+		assert.Equal(t, []string{"fetch"}, diff.RemovedTools)
+		assert.Equal(t, []string{"catalog://services"}, diff.RemovedResources)
+		assert.False(t, diff.PromptsChanged())
+
+		assert.Equal(t, map[string]int{
+			mcp.MethodNotificationToolsListChanged:     1,
+			mcp.MethodNotificationResourcesListChanged: 1,
+		}, drainNotifications())
+
+		// Updated definitions get the new handler
+		assert.Equal(t, "search v3", callTool("search"))
+		assert.Equal(t, "deploy v1", callTool("deploy"))
+
+		server.toolsMu.RLock()
+		assert.Len(t, server.tools, 2)
+		assert.Contains(t, server.tools, "search")
+		assert.Contains(t, server.tools, "deploy")
+		server.toolsMu.RUnlock()
+
+		server.resourcesMu.RLock()
+		assert.Empty(t, server.resources)
+		server.resourcesMu.RUnlock()
+
+		server.promptsMu.RLock()
+		assert.Contains(t, server.prompts, "summarize")
+		server.promptsMu.RUnlock()
+	})
+}
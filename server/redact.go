@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the values removed by RedactKeys.
+const RedactedValue = "[REDACTED]"
+
+// Redactor rewrites a JSON-RPC message before it leaves the request
+// pipeline for diagnostics. method is the method of the message, or empty
+// for responses. payload is a decoded copy of the message that may be
+// modified in place; returning nil removes the message entirely.
+type Redactor func(method string, payload map[string]any) map[string]any
+
+// WithRedactor sets the redactor applied to every message the server exposes
+// for diagnostics: the messages written by WithTrafficRecorder (before any
+// TrafficRedactor), the raw messages returned by RawMessageFromContext, and
+// the message passed to OnError and OnErrorInfo hooks. Request handlers
+// always receive the unredacted message.
+//
+// Redacted messages are re-encoded, so their formatting and key order may
+// differ from the original. Messages that are not JSON objects or arrays of
+// objects are passed through unchanged.
+func WithRedactor(redactor Redactor) ServerOption {
+	return func(s *MCPServer) {
+		s.redactor = redactor
+	}
+}
+
+// RedactKeys returns a Redactor that replaces the value of every object key
+// matching one of keys, compared case-insensitively, with RedactedValue at
+// any depth of the message. Strings are not inspected, so secrets embedded in
+// text content, such as the JSON text fallback of a structured tool result,
+// are not redacted.
+func RedactKeys(keys ...string) Redactor {
+	redacted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = struct{}{}
+	}
+	var walk func(value any)
+	walk = func(value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, child := range value {
+				if _, ok := redacted[strings.ToLower(key)]; ok {
+					value[key] = RedactedValue
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	return func(_ string, payload map[string]any) map[string]any {
+		walk(payload)
+		return payload
+	}
+}
+
+// RedactJSON applies the redactor to a raw JSON-RPC message or batch, for
+// use in custom loggers. It returns nil if the redactor removed the message.
+// Messages that are not JSON objects or arrays are returned unchanged.
+func (r Redactor) RedactJSON(message []byte) []byte {
+	trimmed := bytes.TrimSpace(message)
+	if len(trimmed) == 0 {
+		return message
+	}
+	switch trimmed[0] {
+	case '{':
+		var payload map[string]any
+		if err := decodeNumbers(trimmed, &payload); err != nil {
+			return message
+		}
+		method, _ := payload["method"].(string)
+		if payload = r(method, payload); payload == nil {
+			return nil
+		}
+		redacted, err := json.Marshal(payload)
+		if err != nil {
+			return nil
+		}
+		return redacted
+	case '[':
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return message
+		}
+		kept := make([]json.RawMessage, 0, len(batch))
+		for _, element := range batch {
+			if element = r.RedactJSON(element); element != nil {
+				kept = append(kept, element)
+			}
+		}
+		redacted, err := json.Marshal(kept)
+		if err != nil {
+			return nil
+		}
+		return redacted
+	}
+	return message
+}
+
+// redactMessage returns a redacted copy of a message passed to hooks,
+// keeping its Go type when the redacted message still decodes into it.
+func (s *MCPServer) redactMessage(method string, message any) any {
+	if s == nil || s.redactor == nil || message == nil {
+		return message
+	}
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return nil
+	}
+	var payload map[string]any
+	if err := decodeNumbers(raw, &payload); err != nil {
+		// Not an object, there are no keys to redact
+		return message
+	}
+	if payload = s.redactor(method, payload); payload == nil {
+		return nil
+	}
+	if raw, err = json.Marshal(payload); err != nil {
+		return nil
+	}
+
+	typ := reflect.TypeOf(message)
+	if typ.Kind() == reflect.Pointer {
+		copied := reflect.New(typ.Elem())
+		if err := json.Unmarshal(raw, copied.Interface()); err == nil {
+			return copied.Interface()
+		}
+		return payload
+	}
+	copied := reflect.New(typ)
+	if err := json.Unmarshal(raw, copied.Interface()); err == nil {
+		return copied.Elem().Interface()
+	}
+	return payload
+}
+
+func decodeNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactKeys(t *testing.T) {
+	redact := RedactKeys("password", "Token", "authorization")
+	payload := map[string]any{
+		"method": "tools/call",
+		"params": map[string]any{
+			"arguments": map[string]any{
+				"user":     "alice",
+				"PASSWORD": "hunter2",
+				"nested": map[string]any{
+					"token": map[string]any{"value": "abc"},
+				},
+				"headers": []any{
+					map[string]any{"Authorization": "Bearer xyz"},
+					"password",
+				},
+			},
+		},
+	}
+
+	redacted := redact("tools/call", payload)
+	assert.Equal(t, map[string]any{
+		"method": "tools/call",
+		"params": map[string]any{
+			"arguments": map[string]any{
+				"user":     "alice",
+				"PASSWORD": RedactedValue,
+				"nested": map[string]any{
+					"token": RedactedValue,
+				},
+				"headers": []any{
+					map[string]any{"Authorization": RedactedValue},
+					"password",
+				},
+			},
+		},
+	}, redacted)
+}
+
+func TestRedactor_RedactJSON(t *testing.T) {
+	redact := RedactKeys("token")
+
+	t.Run("message", func(t *testing.T) {
+		redacted := redact.RedactJSON([]byte(`{"jsonrpc":"2.0","id":1,"method":"m","params":{"token":"abc","n":12345678901234567890}}`))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"m","params":{"token":"[REDACTED]","n":12345678901234567890}}`, string(redacted))
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		redacted := redact.RedactJSON([]byte(`[{"id":1,"params":{"token":"a"}},{"id":2,"params":{"token":"b"}}]`))
+		assert.JSONEq(t, `[{"id":1,"params":{"token":"[REDACTED]"}},{"id":2,"params":{"token":"[REDACTED]"}}]`, string(redacted))
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		assert.Equal(t, `"token"`, string(redact.RedactJSON([]byte(`"token"`))))
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		drop := Redactor(func(string, map[string]any) map[string]any { return nil })
+		assert.Nil(t, drop.RedactJSON([]byte(`{"method":"m"}`)))
+	})
+}
+
+func TestMCPServer_WithRedactor(t *testing.T) {
+	var recording bytes.Buffer
+	var hookMessage any
+	var hookInfo ErrorInfo
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		hookMessage = message
+	})
+	hooks.AddOnErrorInfo(func(ctx context.Context, info ErrorInfo) {
+		hookInfo = info
+	})
+
+	s := NewMCPServer("test", "1.0.0",
+		WithToolCapabilities(false),
+		WithHooks(hooks),
+		WithRawMessageCapture(0),
+		WithTrafficRecorder(&recording),
+		WithRedactor(RedactKeys("password", "token")),
+	)
+
+	var handlerArgs map[string]any
+	var handlerRaw []byte
+	s.AddTool(mcp.NewTool("login"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		handlerArgs = request.GetArguments()
+		handlerRaw = RawMessageFromContext(ctx)
+		return mcp.NewToolResultStructured(map[string]any{"token": "session-token"}, "logged in"), nil
+	})
+
+	response := s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {
+			"name": "login",
+			"arguments": {"user": "alice", "credentials": {"Password": "hunter2"}}
+		}
+	}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	// The handler sees the real values, diagnostics don't
+	assert.Equal(t, map[string]any{"user": "alice", "credentials": map[string]any{"Password": "hunter2"}}, handlerArgs)
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {
+			"name": "login",
+			"arguments": {"user": "alice", "credentials": {"Password": "[REDACTED]"}}
+		}
+	}`, string(handlerRaw))
+	result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	assert.Equal(t, map[string]any{"token": "session-token"}, result.StructuredContent)
+
+	// A failing call reaches the error hooks redacted, with its type kept
+	s.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tools/call",
+		"params": {"name": "missing", "arguments": {"token": "abc"}}
+	}`))
+	require.IsType(t, &mcp.CallToolRequest{}, hookMessage)
+	assert.Equal(t, map[string]any{"token": RedactedValue}, hookMessage.(*mcp.CallToolRequest).GetArguments())
+	assert.Equal(t, "missing", hookInfo.Name)
+
+	recorded := recording.String()
+	assert.NotContains(t, recorded, "hunter2")
+	assert.NotContains(t, recorded, "session-token")
+	assert.NotContains(t, recorded, `"abc"`)
+
+	var records []TrafficRecord
+	scanner := bufio.NewScanner(strings.NewReader(recorded))
+	for scanner.Scan() {
+		var record TrafficRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 4)
+
+	var inbound struct {
+		Params struct {
+			Arguments map[string]any `json:"arguments"`
+		} `json:"params"`
+	}
+	require.NoError(t, json.Unmarshal(records[0].Message, &inbound))
+	assert.Equal(t, map[string]any{"user": "alice", "credentials": map[string]any{"Password": RedactedValue}}, inbound.Params.Arguments)
+
+	var outbound struct {
+		Result struct {
+			StructuredContent map[string]any `json:"structuredContent"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(records[1].Message, &outbound))
+	assert.Equal(t, map[string]any{"token": RedactedValue}, outbound.Result.StructuredContent)
+}
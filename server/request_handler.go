@@ -20,11 +20,32 @@ var JsonUseNumber = jsoniter.Config{
 func (s *MCPServer) HandleMessage(
 	ctx context.Context,
 	message json.RawMessage,
-) mcp.JSONRPCMessage {
+) (response mcp.JSONRPCMessage) {
 	// Add server to context
 	ctx = context.WithValue(ctx, serverKey{}, s)
+	ctx = s.withRawMessage(ctx, message)
+	ctx = s.withDependencies(ctx)
+	ctx = withErrorID(ctx)
+	ctx = s.withRequestID(ctx)
+	if recorder := s.trafficRecorder; recorder != nil {
+		recorder.recordInbound(ctx, message)
+		defer func() {
+			recorder.recordOutbound(sessionIDFromContext(ctx), response)
+		}()
+	}
+	// Tag error responses with the error ID, before they are recorded
+	defer func() {
+		response = withErrorIDData(ctx, response)
+	}()
 	var err *requestError
 
+	// Let CancelSession abort the handling of this message
+	if session := ClientSessionFromContext(ctx); session != nil {
+		var untrack func()
+		ctx, untrack = s.trackSessionRequest(ctx, session.SessionID())
+		defer untrack()
+	}
+
 	var baseMessage struct {
 		JSONRPC string        `json:"jsonrpc"`
 		Method  mcp.MCPMethod `json:"method"`
@@ -40,6 +61,13 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
+	// Answer requests whose handler panics with an internal error
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response = s.recoverRequestPanic(ctx, baseMessage.ID, baseMessage.Method, message, recovered)
+		}
+	}()
+
 	// Check for valid JSONRPC version
 	if baseMessage.JSONRPC != mcp.JSONRPC_VERSION {
 		return createErrorResponse(
@@ -49,6 +77,12 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 
+	if !s.lenientParsing {
+		if envelopeErr := validateEnvelope(message); envelopeErr != nil {
+			return envelopeErr.ToJSONRPCError()
+		}
+	}
+
 	if baseMessage.ID == nil {
 		var notification mcp.JSONRPCNotification
 		if err := JsonUseNumber.Unmarshal(message, &notification); err != nil {
@@ -102,10 +136,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterInitialize(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodPing:
 		var request mcp.PingRequest
 		var result *mcp.EmptyResult
@@ -122,10 +156,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterPing(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodSetLogLevel:
 		var request mcp.SetLevelRequest
 		var result *mcp.EmptyResult
@@ -148,10 +182,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterSetLevel(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodResourcesList:
 		var request mcp.ListResourcesRequest
 		var result *mcp.ListResourcesResult
@@ -174,10 +208,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterListResources(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodResourcesTemplatesList:
 		var request mcp.ListResourceTemplatesRequest
 		var result *mcp.ListResourceTemplatesResult
@@ -200,10 +234,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterListResourceTemplates(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodResourcesRead:
 		var request mcp.ReadResourceRequest
 		var result *mcp.ReadResourceResult
@@ -226,10 +260,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterReadResource(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodPromptsList:
 		var request mcp.ListPromptsRequest
 		var result *mcp.ListPromptsResult
@@ -252,10 +286,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterListPrompts(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodPromptsGet:
 		var request mcp.GetPromptRequest
 		var result *mcp.GetPromptResult
@@ -278,10 +312,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterGetPrompt(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodToolsList:
 		var request mcp.ListToolsRequest
 		var result *mcp.ListToolsResult
@@ -304,10 +338,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterListTools(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	case mcp.MethodToolsCall:
 		var request mcp.CallToolRequest
 		var result *mcp.CallToolResult
@@ -330,10 +364,10 @@ func (s *MCPServer) HandleMessage(
 		}
 		if err != nil {
 			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
-			return err.ToJSONRPCError()
+			return s.sanitizeRequestError(err).ToJSONRPCError()
 		}
 		s.hooks.afterCallTool(ctx, baseMessage.ID, &request, result)
-		return createResponse(baseMessage.ID, *result)
+		return s.createResultResponse(ctx, baseMessage.ID, baseMessage.Method, &request, *result)
 	default:
 		return createErrorResponse(
 			baseMessage.ID,
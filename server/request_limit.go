@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionRequestLimiter tracks the number of in-flight requests per session.
+type sessionRequestLimiter struct {
+	limit    int
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func newSessionRequestLimiter(limit int) *sessionRequestLimiter {
+	return &sessionRequestLimiter{
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// acquire reserves a request slot for the session. It returns false if the
+// session has already reached the limit.
+func (l *sessionRequestLimiter) acquire(sessionID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[sessionID] >= l.limit {
+		return false
+	}
+	l.inFlight[sessionID]++
+	return true
+}
+
+// release frees a slot previously reserved with acquire.
+func (l *sessionRequestLimiter) release(sessionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[sessionID] <= 1 {
+		delete(l.inFlight, sessionID)
+		return
+	}
+	l.inFlight[sessionID]--
+}
+
+// acquireRequestSlot reserves a slot for a JSON-RPC request received on the
+// given session. Notifications, responses and messages without a session are
+// never limited. When the limit is reached, the returned error response should
+// be sent to the client instead of handling the message; otherwise the caller
+// must invoke release once the request has been handled.
+func (s *MCPServer) acquireRequestSlot(
+	sessionID string,
+	message json.RawMessage,
+) (release func(), errResponse mcp.JSONRPCMessage) {
+	noop := func() {}
+	limiter := s.requestLimiter
+	if limiter == nil || sessionID == "" {
+		return noop, nil
+	}
+
+	var request struct {
+		ID     *mcp.RequestId `json:"id"`
+		Method string         `json:"method"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil ||
+		request.ID == nil || request.ID.IsNil() || request.Method == "" {
+		return noop, nil
+	}
+
+	if !limiter.acquire(sessionID) {
+		return noop, createErrorResponse(
+			request.ID.Value(),
+			mcp.SERVER_BUSY,
+			ErrTooManyConcurrentRequests.Error(),
+		)
+	}
+	return func() { limiter.release(sessionID) }, nil
+}
@@ -2,6 +2,9 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
 	"testing"
 	"time"
 
@@ -251,3 +254,127 @@ func TestMCPServer_RemoveResource(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_ListResourcesFilter(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithResourceCapabilities(false, false),
+		WithPaginationLimit(2),
+	)
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	for _, resource := range []mcp.Resource{
+		mcp.NewResource("file:///repo/README.md", "a-readme", mcp.WithMIMEType("text/markdown")),
+		mcp.NewResource("file:///repo/docs/guide.md", "b-guide", mcp.WithMIMEType("text/markdown")),
+		mcp.NewResource("file:///repo/docs/api.md", "c-api", mcp.WithMIMEType("text/markdown")),
+		mcp.NewResource("file:///repo/main.go", "d-main", mcp.WithMIMEType("text/x-go")),
+		mcp.NewResource("file:///repo/docs/diagram.png", "e-diagram", mcp.WithMIMEType("image/png")),
+		mcp.NewResource("db://users/1", "f-user", mcp.WithMIMEType("application/json")),
+		mcp.NewResource("db://users/2", "g-user", mcp.WithMIMEType("application/json")),
+	} {
+		server.AddResource(resource, handler)
+	}
+	templateHandler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///repo/{path}", "files", mcp.WithTemplateMIMEType("text/plain")), templateHandler)
+	server.AddResourceTemplate(mcp.NewResourceTemplate("db://users/{id}", "users", mcp.WithTemplateMIMEType("application/json")), templateHandler)
+
+	// list follows the cursors of method with params, returning the URIs of
+	// every page and the number of pages
+	list := func(method string, params map[string]any) ([]string, int) {
+		t.Helper()
+		var uris []string
+		pages := 0
+		for {
+			message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": pages, "method": method, "params": params})
+			require.NoError(t, err)
+			response, ok := server.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			pages++
+
+			var cursor mcp.Cursor
+			switch result := response.Result.(type) {
+			case mcp.ListResourcesResult:
+				for _, resource := range result.Resources {
+					uris = append(uris, resource.URI)
+				}
+				cursor = result.NextCursor
+			case mcp.ListResourceTemplatesResult:
+				for _, template := range result.ResourceTemplates {
+					uris = append(uris, template.URITemplate.Raw())
+				}
+				cursor = result.NextCursor
+			default:
+				t.Fatalf("unexpected result %T", response.Result)
+			}
+			if cursor == "" {
+				return uris, pages
+			}
+			params = maps.Clone(params)
+			params["cursor"] = cursor
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   []string
+		pages  int
+	}{
+		{
+			name:   "scheme prefix",
+			params: map[string]any{"uriPrefix": "db://"},
+			want:   []string{"db://users/1", "db://users/2"},
+			pages:  2,
+		},
+		{
+			name:   "path prefix",
+			params: map[string]any{"uriPrefix": "file:///repo/docs/"},
+			want:   []string{"file:///repo/docs/guide.md", "file:///repo/docs/api.md", "file:///repo/docs/diagram.png"},
+			pages:  2,
+		},
+		{
+			name:   "MIME type",
+			params: map[string]any{"mimeType": "text/markdown"},
+			want:   []string{"file:///repo/README.md", "file:///repo/docs/guide.md", "file:///repo/docs/api.md"},
+			pages:  2,
+		},
+		{
+			name:   "prefix and MIME type",
+			params: map[string]any{"uriPrefix": "file:///repo/docs/", "mimeType": "text/markdown"},
+			want:   []string{"file:///repo/docs/guide.md", "file:///repo/docs/api.md"},
+			pages:  2,
+		},
+		{
+			name:   "no match",
+			params: map[string]any{"uriPrefix": "s3://"},
+			pages:  1,
+		},
+		{
+			name:   "unknown params are ignored",
+			params: map[string]any{"scheme": "db", "uriPrefix": 42, "tags": []string{"x"}},
+			want: []string{
+				"file:///repo/README.md", "file:///repo/docs/guide.md", "file:///repo/docs/api.md", "file:///repo/main.go",
+				"file:///repo/docs/diagram.png", "db://users/1", "db://users/2",
+			},
+			pages: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uris, pages := list("resources/list", tt.params)
+			assert.Equal(t, tt.want, uris)
+			assert.Equal(t, tt.pages, pages, fmt.Sprintf("pages for %v", uris))
+		})
+	}
+
+	t.Run("templates", func(t *testing.T) {
+		uris, _ := list("resources/templates/list", map[string]any{"uriPrefix": "file:///repo/docs/"})
+		assert.Equal(t, []string{"file:///repo/{path}"}, uris)
+		uris, _ = list("resources/templates/list", map[string]any{"uriPrefix": "db://", "mimeType": "application/json"})
+		assert.Equal(t, []string{"db://users/{id}"}, uris)
+		uris, _ = list("resources/templates/list", map[string]any{"mimeType": "image/png"})
+		assert.Empty(t, uris)
+	})
+}
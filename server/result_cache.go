@@ -0,0 +1,155 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ResultCache stores tool results for WithToolResultCache. Implementations
+// must be safe for concurrent use.
+type ResultCache interface {
+	// Get returns the result cached under key, if any.
+	Get(key string) (*mcp.CallToolResult, bool)
+	// Set caches result under key.
+	Set(key string, result *mcp.CallToolResult)
+}
+
+// ToolCacheKeyFunc derives the cache key of a tool call from its request.
+// Calls with equal keys must produce equal results. Returning an empty string
+// bypasses the cache for the call.
+type ToolCacheKeyFunc func(request mcp.CallToolRequest) string
+
+// DefaultToolCacheKey hashes the arguments of the call. Object keys are
+// sorted when encoding, so argument order does not matter.
+func DefaultToolCacheKey(request mcp.CallToolRequest) string {
+	data, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// WithToolResultCache caches the results of tools annotated as both
+// read-only and idempotent, keyed by the tool name and keyFn. Cached calls
+// skip the tool handler, but still go through the tool handler middlewares,
+// so authorization and logging middlewares see every call. Results with
+// IsError set, handler errors, dry runs and session-specific tools are never
+// cached. A nil keyFn uses DefaultToolCacheKey.
+func WithToolResultCache(cache ResultCache, keyFn ToolCacheKeyFunc) ServerOption {
+	return func(s *MCPServer) {
+		if keyFn == nil {
+			keyFn = DefaultToolCacheKey
+		}
+		s.toolResultCache = cache
+		s.toolCacheKey = keyFn
+	}
+}
+
+// isCacheableTool reports whether the results of tool may be cached.
+func isCacheableTool(tool mcp.Tool) bool {
+	return tool.IsReadOnly() && tool.IsIdempotent()
+}
+
+// withResultCache wraps the handler of a cacheable tool so that repeated
+// calls are answered from the cache.
+func (s *MCPServer) withResultCache(name string, next ToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		argsKey := s.toolCacheKey(request)
+		if argsKey == "" {
+			return next(ctx, request)
+		}
+		key := name + "\x00" + argsKey
+		if cached, ok := s.toolResultCache.Get(key); ok {
+			return cloneToolResult(cached), nil
+		}
+
+		result, err := next(ctx, request)
+		if err == nil && result != nil && !result.IsError {
+			s.toolResultCache.Set(key, cloneToolResult(result))
+		}
+		return result, err
+	}
+}
+
+// cloneToolResult copies a result deeply enough that setting _meta on the
+// copy does not affect the cached original.
+func cloneToolResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	clone := *result
+	if result.Meta != nil {
+		meta := *result.Meta
+		meta.AdditionalFields = maps.Clone(result.Meta.AdditionalFields)
+		clone.Meta = &meta
+	}
+	return &clone
+}
+
+// LRUResultCache is an in-memory ResultCache that holds a fixed number of
+// results, evicting the least recently used one when full.
+type LRUResultCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is the most recently used
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	result *mcp.CallToolResult
+}
+
+// NewLRUResultCache creates a cache holding up to capacity results. A
+// capacity of zero or less is treated as one.
+func NewLRUResultCache(capacity int) *LRUResultCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUResultCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements ResultCache.
+func (c *LRUResultCache) Get(key string) (*mcp.CallToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).result, true
+}
+
+// Set implements ResultCache.
+func (c *LRUResultCache) Set(key string, result *mcp.CallToolResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, result: result})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of cached results.
+func (c *LRUResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
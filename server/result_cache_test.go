@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_ToolResultCache(t *testing.T) {
+	calls := map[string]int{}
+	middlewareCalls := 0
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolResultCache(NewLRUResultCache(10), nil),
+		WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				middlewareCalls++
+				return next(ctx, request)
+			}
+		}),
+	)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls[request.Params.Name]++
+		if request.GetString("text", "") == "fail" {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s #%d", request.GetString("text", ""), calls[request.Params.Name])), nil
+	}
+	server.AddTool(mcp.NewTool("embed",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+	), handler)
+	server.AddTool(mcp.NewTool("clock", mcp.WithReadOnlyHintAnnotation(true)), handler)
+
+	callTool := func(name, args string) string {
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, name, args)
+		response := server.HandleMessage(context.Background(), []byte(message))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result := resp.Result.(mcp.CallToolResult)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "a #1", callTool("embed", `{"text":"a","n":1}`))
+	assert.Equal(t, "a #1", callTool("embed", `{"n":1,"text":"a"}`), "argument order must not matter")
+	assert.Equal(t, "b #2", callTool("embed", `{"text":"b","n":1}`))
+	assert.Equal(t, 2, calls["embed"])
+	assert.Equal(t, 3, middlewareCalls, "middlewares must see cached calls")
+
+	// Error results are not cached
+	callTool("embed", `{"text":"fail"}`)
+	callTool("embed", `{"text":"fail"}`)
+	assert.Equal(t, 4, calls["embed"])
+
+	// Tools that are not idempotent are never cached
+	assert.Equal(t, "a #1", callTool("clock", `{"text":"a"}`))
+	assert.Equal(t, "a #2", callTool("clock", `{"text":"a"}`))
+}
+
+func TestMCPServer_ToolResultCacheKeyFunc(t *testing.T) {
+	calls := 0
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolResultCache(NewLRUResultCache(10), func(request mcp.CallToolRequest) string {
+			// Only the query matters; an empty key bypasses the cache
+			return request.GetString("query", "")
+		}),
+	)
+	server.AddTool(mcp.NewTool("search",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("results"), nil
+	})
+
+	call := func(args string) {
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":%s}}`, args)
+		_, ok := server.HandleMessage(context.Background(), []byte(message)).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+	}
+
+	call(`{"query":"go","traceId":"1"}`)
+	call(`{"query":"go","traceId":"2"}`)
+	assert.Equal(t, 1, calls)
+	call(`{}`)
+	call(`{}`)
+	assert.Equal(t, 3, calls)
+}
+
+func TestLRUResultCache(t *testing.T) {
+	cache := NewLRUResultCache(2)
+	cache.Set("a", mcp.NewToolResultText("a"))
+	cache.Set("b", mcp.NewToolResultText("b"))
+
+	// Touch "a" so that "b" is the least recently used
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+	cache.Set("c", mcp.NewToolResultText("c"))
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "b should have been evicted")
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+
+	cache.Set("c", mcp.NewToolResultText("updated"))
+	result, _ := cache.Get("c")
+	assert.Equal(t, "updated", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, 2, cache.Len())
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -17,14 +18,38 @@ func (s *MCPServer) EnableSampling() {
 	s.capabilities.sampling = &enabled
 }
 
+// WithDefaultModelPreferences sets the model preferences of sampling requests
+// that do not set their own. A request with ModelPreferences replaces the
+// defaults as a whole; they are not merged.
+func WithDefaultModelPreferences(prefs *mcp.ModelPreferences) ServerOption {
+	return func(s *MCPServer) {
+		s.modelPreferences = prefs
+	}
+}
+
 // RequestSampling sends a sampling request to the client.
 // The client must have declared sampling capability during initialization.
+// Requests without ModelPreferences get the server's defaults, see
+// WithDefaultModelPreferences.
 func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
 	session := ClientSessionFromContext(ctx)
 	if session == nil {
 		return nil, fmt.Errorf("no active session")
 	}
 
+	if request.ModelPreferences == nil && s.modelPreferences != nil {
+		// Copy, so that handlers can't modify the defaults
+		prefs := *s.modelPreferences
+		prefs.Hints = slices.Clone(prefs.Hints)
+		request.ModelPreferences = &prefs
+	}
+
+	// Server-initiated requests can't be delivered while the client waits for
+	// an inline response
+	if inline, _ := ctx.Value(inlineResponse).(bool); inline {
+		return nil, ErrSamplingUnavailableInline
+	}
+
 	// Check if the session supports sampling requests
 	if samplingSession, ok := session.(SessionWithSampling); ok {
 		return samplingSession.RequestSampling(ctx, request)
@@ -38,6 +63,39 @@ func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessa
 	return nil, fmt.Errorf("session does not support sampling")
 }
 
+// SampleToolResult sends a sampling request to the client and returns the
+// sampled message as a tool result, with the model and stop reason attached to
+// its _meta. It lets a tool handler delegate its answer to the client's LLM:
+//
+//	return s.SampleToolResult(ctx, samplingRequest)
+//
+// To combine sampled content with the tool's own output, call RequestSampling
+// and use mcp.AttachSamplingMeta instead.
+func (s *MCPServer) SampleToolResult(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CallToolResult, error) {
+	sampling, err := s.RequestSampling(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultFromSampling(sampling)
+}
+
+// newCancelledNotification builds the notification telling the client that
+// the server abandoned the request with the given ID.
+func newCancelledNotification(requestID mcp.RequestId, reason string) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": requestID,
+					"reason":    reason,
+				},
+			},
+		},
+	}
+}
+
 // SessionWithSampling extends ClientSession to support sampling requests.
 type SessionWithSampling interface {
 	ClientSession
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -152,3 +154,57 @@ func TestMCPServer_EnableSampling_SetsCapability(t *testing.T) {
 		t.Error("sampling capability should be set after EnableSampling() is called")
 	}
 }
+
+func TestMCPServer_SampleToolResult(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0")
+	server.EnableSampling()
+
+	// Sampling results decoded from the wire carry content as a map
+	mockSession := &mockSamplingSession{
+		mockSession: mockSession{sessionID: "test-session"},
+		result: &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{
+				Role:    mcp.RoleAssistant,
+				Content: map[string]any{"type": "text", "text": "Sampled answer"},
+			},
+			Model:      "test-model",
+			StopReason: "endTurn",
+		},
+	}
+	ctx := server.WithContext(context.Background(), mockSession)
+
+	result, err := server.SampleToolResult(ctx, mcp.CreateMessageRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("expected 1 content item, got %d", len(result.Content))
+	}
+	if text, ok := result.Content[0].(mcp.TextContent); !ok || text.Text != "Sampled answer" {
+		t.Errorf("expected sampled text content, got %#v", result.Content[0])
+	}
+
+	// The metadata survives a round trip to the client
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	raw := json.RawMessage(data)
+	decoded, err := mcp.ParseCallToolResult(&raw)
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	meta, ok := decoded.GetSamplingMeta()
+	if !ok {
+		t.Fatal("expected sampling metadata")
+	}
+	if meta.Model != "test-model" || meta.StopReason != "endTurn" {
+		t.Errorf("unexpected sampling metadata: %+v", meta)
+	}
+
+	// Errors from the sampling request are returned as is
+	mockSession.err = errors.New("user rejected sampling")
+	if _, err := server.SampleToolResult(ctx, mcp.CreateMessageRequest{}); err == nil {
+		t.Error("expected sampling error")
+	}
+}
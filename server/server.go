@@ -6,11 +6,17 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"reflect"
 	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // resourceEntry holds both a resource and its handler
@@ -50,6 +56,9 @@ type ToolFilterFunc func(ctx context.Context, tools []mcp.Tool) []mcp.Tool
 type ServerTool struct {
 	Tool    mcp.Tool
 	Handler ToolHandlerFunc
+	// DryRun, if set, is called instead of Handler when the request asks for a
+	// dry run. It should describe what the call would do without side effects.
+	DryRun ToolHandlerFunc
 }
 
 // ServerPrompt combines a Prompt with its handler function.
@@ -111,6 +120,7 @@ type requestError struct {
 	id   any
 	code int
 	err  error
+	data any
 }
 
 func (e *requestError) Error() string {
@@ -128,6 +138,7 @@ func (e *requestError) ToJSONRPCError() mcp.JSONRPCError {
 		}{
 			Code:    e.code,
 			Message: e.err.Error(),
+			Data:    e.data,
 		},
 	}
 }
@@ -136,6 +147,43 @@ func (e *requestError) Unwrap() error {
 	return e.err
 }
 
+// runShutdownHooks runs the hooks registered with WithShutdownHook. Only the
+// first call has an effect.
+func (s *MCPServer) runShutdownHooks(ctx context.Context) {
+	s.shutdownOnce.Do(func() {
+		for _, hook := range s.shutdownHooks {
+			hook(ctx)
+		}
+	})
+}
+
+// sanitizeRequestError applies the configured error mapper and sanitizer to
+// err and returns the error that should be sent to the client.
+func (s *MCPServer) sanitizeRequestError(err *requestError) *requestError {
+	err = s.mapRequestError(err)
+	if s.errorSanitizer == nil {
+		return err
+	}
+	sanitized := s.errorSanitizer(err.err)
+	if sanitized == nil {
+		return err
+	}
+	return &requestError{id: err.id, code: err.code, err: sanitized, data: err.data}
+}
+
+// mapRequestError gives an internal error the code and data chosen by the
+// configured error mapper, if any.
+func (s *MCPServer) mapRequestError(err *requestError) *requestError {
+	if s.errorMapper == nil || err.code != mcp.INTERNAL_ERROR {
+		return err
+	}
+	code, data := s.errorMapper(err.err)
+	if code == 0 {
+		return err
+	}
+	return &requestError{id: err.id, code: code, err: err.err, data: data}
+}
+
 // NotificationHandlerFunc handles incoming notifications.
 type NotificationHandlerFunc func(ctx context.Context, notification mcp.JSONRPCNotification)
 
@@ -166,6 +214,40 @@ type MCPServer struct {
 	paginationLimit        *int
 	sessions               sync.Map
 	hooks                  *Hooks
+	requestLimiter         *sessionRequestLimiter
+	sessionRequests        sessionRequestTracker
+	notificationQueues     sync.Map // sessionID -> *notificationQueue
+	notificationQueuesMu   sync.Mutex
+	notificationCoalescing time.Duration
+	rawMessageCaptureLimit int
+	errorSanitizer         func(error) error
+	errorMapper            func(error) (int, any)
+	timeoutHints           map[string]time.Duration
+	shutdownHooks          []func(ctx context.Context)
+	shutdownOnce           sync.Once
+	applySchemaDefaults    bool
+	requestIDContext       bool
+	modelPreferences       *mcp.ModelPreferences
+	validateOutput         bool
+	argumentDecoders       map[string]ArgumentDecoderFunc
+	strictTools            bool
+	strictPrompts          bool
+	strictResources        bool
+	strictReinitialize     bool
+	initializations        sync.Map // sessionID -> *sessionInitialization
+	trafficRecorder        *trafficRecorder
+	redactor               Redactor
+	lenientParsing         bool
+	toolResultCache        ResultCache
+	toolCacheKey           ToolCacheKeyFunc
+	dependenciesMu         sync.Mutex
+	dependencies           atomic.Pointer[map[string]any]
+	panicHandler           PanicHandlerFunc
+	logger                 util.Logger
+	resultInterceptors     []ResultInterceptorFunc
+	serverInfo             mcp.Implementation
+	sortedTools            bool
+	sortedResources        bool
 }
 
 // WithPaginationLimit sets the pagination limit for the server.
@@ -175,6 +257,154 @@ func WithPaginationLimit(limit int) ServerOption {
 	}
 }
 
+// WithMaxConcurrentRequestsPerSession limits the number of requests a single
+// session may have in flight on the HTTP transports. Requests beyond the limit
+// are rejected with a [mcp.SERVER_BUSY] JSON-RPC error. A limit of zero or less
+// disables the check.
+func WithMaxConcurrentRequestsPerSession(n int) ServerOption {
+	return func(s *MCPServer) {
+		if n <= 0 {
+			s.requestLimiter = nil
+			return
+		}
+		s.requestLimiter = newSessionRequestLimiter(n)
+	}
+}
+
+// DefaultRawMessageCaptureLimit is the number of bytes retained by
+// WithRawMessageCapture when no explicit limit is given.
+const DefaultRawMessageCaptureLimit = 64 * 1024
+
+// WithRawMessageCapture retains the raw bytes of each incoming JSON-RPC
+// message so hooks and handlers can read them with RawMessageFromContext.
+// At most maxBytes bytes are kept per message; zero or less uses
+// DefaultRawMessageCaptureLimit. Capture is disabled unless this option is set.
+func WithRawMessageCapture(maxBytes int) ServerOption {
+	return func(s *MCPServer) {
+		if maxBytes <= 0 {
+			maxBytes = DefaultRawMessageCaptureLimit
+		}
+		s.rawMessageCaptureLimit = maxBytes
+	}
+}
+
+// WithErrorSanitizer sets a function that rewrites handler errors before they
+// are serialized into JSON-RPC error responses, for example to hide internal
+// details from clients. OnError hooks still receive the original error, so it
+// can be logged server-side. If the sanitizer returns nil, the original error
+// is sent unchanged.
+func WithErrorSanitizer(sanitizer func(err error) error) ServerOption {
+	return func(s *MCPServer) {
+		s.errorSanitizer = sanitizer
+	}
+}
+
+// WithErrorMapper sets a function that chooses the JSON-RPC error code and
+// data for errors that would otherwise be reported as internal errors, such
+// as those returned by tool, prompt and resource handlers. This lets clients
+// tell, say, a missing record (mcp.RESOURCE_NOT_FOUND) from a server fault
+// without each handler building JSON-RPC errors by hand:
+//
+//	server.WithErrorMapper(func(err error) (int, any) {
+//		var notFound *NotFoundError
+//		if errors.As(err, &notFound) {
+//			return mcp.RESOURCE_NOT_FOUND, map[string]any{"id": notFound.ID}
+//		}
+//		return 0, nil
+//	})
+//
+// A code of zero keeps the internal error. The mapper sees the original error
+// and runs before the sanitizer set with WithErrorSanitizer, which may still
+// rewrite the message.
+func WithErrorMapper(mapper func(err error) (code int, data any)) ServerOption {
+	return func(s *MCPServer) {
+		s.errorMapper = mapper
+	}
+}
+
+// WithTimeoutHints publishes the timeouts the server suggests for slow
+// requests in the _meta of its initialize result, under
+// mcp.MetaKeyTimeoutHints. Keys are method names, such as "resources/read",
+// or mcp.TimeoutHintKeyForTool for the calls of a single tool. Clients
+// created with client.WithHonorServerTimeoutHints use them as the timeout of
+// matching requests made without a deadline; other clients ignore them.
+func WithTimeoutHints(hints map[string]time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.timeoutHints = maps.Clone(hints)
+	}
+}
+
+// WithApplySchemaDefaults fills tool arguments omitted by the client with the
+// defaults declared in the tool's input schema before the handler is called.
+// Properties of object arguments are filled one level deep. Explicitly
+// provided values, including nulls, are never overwritten.
+func WithApplySchemaDefaults() ServerOption {
+	return func(s *MCPServer) {
+		s.applySchemaDefaults = true
+	}
+}
+
+// WithOutputValidation checks the structured content of every successful
+// tool result against the tool's output schema, as described by
+// mcp.Tool.ValidateStructuredResult. A result that does not conform is
+// reported to the client as an internal error instead of being sent.
+func WithOutputValidation() ServerOption {
+	return func(s *MCPServer) {
+		s.validateOutput = true
+	}
+}
+
+// WithStrictToolRegistration rejects tools whose name is already registered
+// instead of replacing the existing tool. AddToolE and AddToolsE return
+// ErrToolAlreadyExists, while AddTool and AddTools panic. Session tools may
+// still shadow global tools of the same name.
+func WithStrictToolRegistration() ServerOption {
+	return func(s *MCPServer) {
+		s.strictTools = true
+	}
+}
+
+// WithStrictPromptRegistration rejects prompts whose name is already
+// registered. AddPromptE and AddPromptsE return ErrPromptAlreadyExists, while
+// AddPrompt and AddPrompts panic.
+func WithStrictPromptRegistration() ServerOption {
+	return func(s *MCPServer) {
+		s.strictPrompts = true
+	}
+}
+
+// WithStrictResourceRegistration rejects resources whose URI is already
+// registered. AddResourceE and AddResourcesE return ErrResourceAlreadyExists,
+// while AddResource and AddResources panic.
+func WithStrictResourceRegistration() ServerOption {
+	return func(s *MCPServer) {
+		s.strictResources = true
+	}
+}
+
+// WithStrictReinitialization rejects an initialize request on a session that
+// is already initialized with an INVALID_REQUEST error if it asks for another
+// protocol version or declares other capabilities than the first one. By
+// default, and for an identical request, the server answers with the result of
+// the first initialize. Either way the session keeps what was negotiated
+// first.
+func WithStrictReinitialization() ServerOption {
+	return func(s *MCPServer) {
+		s.strictReinitialize = true
+	}
+}
+
+// WithShutdownHook registers a function to run when the server shuts down,
+// e.g. to flush metrics or close database pools. Hooks run once, in the order
+// they were registered, when a transport shuts down gracefully: on Shutdown of
+// the SSE and streamable HTTP servers, or when a stdio server stops
+// listening. The context carries the shutdown deadline.
+func WithShutdownHook(hook func(ctx context.Context)) ServerOption {
+	return func(s *MCPServer) {
+		s.shutdownHooks = append(s.shutdownHooks, hook)
+	}
+}
+
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
 	tools     *toolCapabilities
@@ -295,6 +525,21 @@ func WithInstructions(instructions string) ServerOption {
 	}
 }
 
+// WithServerInfo sets the implementation information the server reports to
+// clients in the initialize response, such as its title, website and icons.
+// A non-empty Name or Version replaces the one passed to NewMCPServer.
+func WithServerInfo(info mcp.Implementation) ServerOption {
+	return func(s *MCPServer) {
+		if info.Name != "" {
+			s.name = info.Name
+		}
+		if info.Version != "" {
+			s.version = info.Version
+		}
+		s.serverInfo = info
+	}
+}
+
 // NewMCPServer creates a new MCP server instance with the given name, version and options
 func NewMCPServer(
 	name, version string,
@@ -320,6 +565,9 @@ func NewMCPServer(
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.trafficRecorder != nil {
+		s.trafficRecorder.redactor = s.redactor
+	}
 
 	return s
 }
@@ -331,9 +579,28 @@ func (s *MCPServer) GenerateInProcessSessionID() string {
 
 // AddResources registers multiple resources at once
 func (s *MCPServer) AddResources(resources ...ServerResource) {
+	if err := s.AddResourcesE(resources...); err != nil {
+		panic(err)
+	}
+}
+
+// AddResourcesE registers multiple resources at once. With
+// WithStrictResourceRegistration it returns ErrResourceAlreadyExists and
+// registers none of them if any URI is already registered.
+func (s *MCPServer) AddResourcesE(resources ...ServerResource) error {
 	s.implicitlyRegisterResourceCapabilities()
 
 	s.resourcesMu.Lock()
+	if s.strictResources {
+		seen := make(map[string]bool, len(resources))
+		for _, entry := range resources {
+			if _, ok := s.resources[entry.Resource.URI]; ok || seen[entry.Resource.URI] {
+				s.resourcesMu.Unlock()
+				return fmt.Errorf("%w: %s", ErrResourceAlreadyExists, entry.Resource.URI)
+			}
+			seen[entry.Resource.URI] = true
+		}
+	}
 	for _, entry := range resources {
 		s.resources[entry.Resource.URI] = resourceEntry{
 			resource: entry.Resource,
@@ -347,6 +614,7 @@ func (s *MCPServer) AddResources(resources ...ServerResource) {
 		// Send notification to all initialized sessions
 		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
 	}
+	return nil
 }
 
 // SetResources replaces all existing resources with the provided list
@@ -365,6 +633,15 @@ func (s *MCPServer) AddResource(
 	s.AddResources(ServerResource{Resource: resource, Handler: handler})
 }
 
+// AddResourceE registers a new resource and its handler, returning
+// ErrResourceAlreadyExists under strict registration if the URI is taken.
+func (s *MCPServer) AddResourceE(
+	resource mcp.Resource,
+	handler ResourceHandlerFunc,
+) error {
+	return s.AddResourcesE(ServerResource{Resource: resource, Handler: handler})
+}
+
 // DeleteResources removes resources from the server
 func (s *MCPServer) DeleteResources(uris ...string) {
 	s.resourcesMu.Lock()
@@ -383,6 +660,13 @@ func (s *MCPServer) DeleteResources(uris ...string) {
 	}
 }
 
+// DeleteResource removes the resource with the given URI from the server,
+// notifying clients if the resource existed and the listChanged capability is
+// enabled.
+func (s *MCPServer) DeleteResource(uri string) {
+	s.DeleteResources(uri)
+}
+
 // RemoveResource removes a resource from the server
 func (s *MCPServer) RemoveResource(uri string) {
 	s.resourcesMu.Lock()
@@ -398,6 +682,24 @@ func (s *MCPServer) RemoveResource(uri string) {
 	}
 }
 
+// NotifyResourceUpdated sends a notifications/resources/updated notification
+// for uri to all initialized sessions.
+func (s *MCPServer) NotifyResourceUpdated(uri string) {
+	s.NotifyResourceUpdatedWithContents(uri, nil)
+}
+
+// NotifyResourceUpdatedWithContents is like NotifyResourceUpdated, but also
+// carries the updated contents inline so clients can apply the change without
+// reading the resource again. With no contents it behaves exactly like
+// NotifyResourceUpdated.
+func (s *MCPServer) NotifyResourceUpdatedWithContents(uri string, contents []mcp.ResourceContents) {
+	params := map[string]any{"uri": uri}
+	if len(contents) > 0 {
+		params["contents"] = contents
+	}
+	s.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, params)
+}
+
 // AddResourceTemplates registers multiple resource templates at once
 func (s *MCPServer) AddResourceTemplates(resourceTemplates ...ServerResourceTemplate) {
 	s.implicitlyRegisterResourceCapabilities()
@@ -436,9 +738,28 @@ func (s *MCPServer) AddResourceTemplate(
 
 // AddPrompts registers multiple prompts at once
 func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
+	if err := s.AddPromptsE(prompts...); err != nil {
+		panic(err)
+	}
+}
+
+// AddPromptsE registers multiple prompts at once. With
+// WithStrictPromptRegistration it returns ErrPromptAlreadyExists and registers
+// none of them if any name is already registered.
+func (s *MCPServer) AddPromptsE(prompts ...ServerPrompt) error {
 	s.implicitlyRegisterPromptCapabilities()
 
 	s.promptsMu.Lock()
+	if s.strictPrompts {
+		seen := make(map[string]bool, len(prompts))
+		for _, entry := range prompts {
+			if _, ok := s.prompts[entry.Prompt.Name]; ok || seen[entry.Prompt.Name] {
+				s.promptsMu.Unlock()
+				return fmt.Errorf("%w: %s", ErrPromptAlreadyExists, entry.Prompt.Name)
+			}
+			seen[entry.Prompt.Name] = true
+		}
+	}
 	for _, entry := range prompts {
 		s.prompts[entry.Prompt.Name] = entry.Prompt
 		s.promptHandlers[entry.Prompt.Name] = entry.Handler
@@ -450,6 +771,7 @@ func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 		// Send notification to all initialized sessions
 		s.SendNotificationToAllClients(mcp.MethodNotificationPromptsListChanged, nil)
 	}
+	return nil
 }
 
 // AddPrompt registers a new prompt handler with the given name
@@ -457,6 +779,12 @@ func (s *MCPServer) AddPrompt(prompt mcp.Prompt, handler PromptHandlerFunc) {
 	s.AddPrompts(ServerPrompt{Prompt: prompt, Handler: handler})
 }
 
+// AddPromptE registers a new prompt handler with the given name, returning
+// ErrPromptAlreadyExists under strict registration if the name is taken.
+func (s *MCPServer) AddPromptE(prompt mcp.Prompt, handler PromptHandlerFunc) error {
+	return s.AddPromptsE(ServerPrompt{Prompt: prompt, Handler: handler})
+}
+
 // SetPrompts replaces all existing prompts with the provided list
 func (s *MCPServer) SetPrompts(prompts ...ServerPrompt) {
 	s.promptsMu.Lock()
@@ -486,11 +814,53 @@ func (s *MCPServer) DeletePrompts(names ...string) {
 	}
 }
 
+// DeletePrompt removes the prompt with the given name from the server,
+// notifying clients if the prompt existed and the listChanged capability is
+// enabled.
+func (s *MCPServer) DeletePrompt(name string) {
+	s.DeletePrompts(name)
+}
+
 // AddTool registers a new tool and its handler
 func (s *MCPServer) AddTool(tool mcp.Tool, handler ToolHandlerFunc) {
 	s.AddTools(ServerTool{Tool: tool, Handler: handler})
 }
 
+// AddToolE registers a new tool and its handler, returning
+// ErrToolAlreadyExists under strict registration if the name is taken.
+func (s *MCPServer) AddToolE(tool mcp.Tool, handler ToolHandlerFunc) error {
+	return s.AddToolsE(ServerTool{Tool: tool, Handler: handler})
+}
+
+// AddTypedTool registers a tool whose input and output are described by Go
+// types. The input schema is generated from TIn and the output schema from
+// TOut, honoring json and jsonschema struct tags; fields without omitempty are
+// marked as required. The handler receives the bound arguments and its result
+// is returned as structured content. Additional tool options, such as
+// annotations, are applied after the generated schemas.
+func AddTypedTool[TIn, TOut any](
+	s *MCPServer,
+	name, description string,
+	handler mcp.StructuredToolHandlerFunc[TIn, TOut],
+	opts ...mcp.ToolOption,
+) {
+	toolOpts := []mcp.ToolOption{
+		mcp.WithDescription(description),
+		mcp.WithInputSchema[TIn](),
+		mcp.WithOutputSchema[TOut](),
+	}
+	toolOpts = append(toolOpts, opts...)
+	s.AddTool(mcp.NewTool(name, toolOpts...), mcp.NewStructuredToolHandler(handler))
+}
+
+// AddToolWithDryRun registers a new tool together with a function that is
+// called instead of the handler when a tools/call request sets _meta.dryRun,
+// once the arguments passed validation. It can preview what the call would do
+// in more detail than the default validation report.
+func (s *MCPServer) AddToolWithDryRun(tool mcp.Tool, handler ToolHandlerFunc, dryRun ToolHandlerFunc) {
+	s.AddTools(ServerTool{Tool: tool, Handler: handler, DryRun: dryRun})
+}
+
 // Register tool capabilities due to a tool being added.  Default to
 // listChanged: true, but don't change the value if we've already explicitly
 // registered tools.listChanged false.
@@ -532,19 +902,51 @@ func (s *MCPServer) implicitlyRegisterCapabilities(check func() bool, register f
 
 // AddTools registers multiple tools at once
 func (s *MCPServer) AddTools(tools ...ServerTool) {
+	if err := s.AddToolsE(tools...); err != nil {
+		panic(err)
+	}
+}
+
+// AddToolsE registers multiple tools at once. Every tool added under the name
+// of an already registered tool fires the OnToolOverwritten hooks. With
+// WithStrictToolRegistration it then returns ErrToolAlreadyExists and
+// registers none of the tools.
+func (s *MCPServer) AddToolsE(tools ...ServerTool) error {
 	s.implicitlyRegisterToolCapabilities()
 
 	s.toolsMu.Lock()
+	var collisions [][2]ServerTool
+	pending := make(map[string]ServerTool, len(tools))
 	for _, entry := range tools {
-		s.tools[entry.Tool.Name] = entry
+		previous, ok := pending[entry.Tool.Name]
+		if !ok {
+			previous, ok = s.tools[entry.Tool.Name]
+		}
+		if ok {
+			collisions = append(collisions, [2]ServerTool{previous, entry})
+		}
+		pending[entry.Tool.Name] = entry
+	}
+	if !s.strictTools || len(collisions) == 0 {
+		for _, entry := range tools {
+			s.tools[entry.Tool.Name] = entry
+		}
 	}
 	s.toolsMu.Unlock()
 
+	for _, collision := range collisions {
+		s.hooks.toolOverwritten(collision[0], collision[1])
+	}
+	if s.strictTools && len(collisions) > 0 {
+		return fmt.Errorf("%w: %s", ErrToolAlreadyExists, collisions[0][1].Tool.Name)
+	}
+
 	// When the list of available tools changes, servers that declared the listChanged capability SHOULD send a notification.
 	if s.capabilities.tools.listChanged {
 		// Send notification to all initialized sessions
 		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
 	}
+	return nil
 }
 
 // SetTools replaces all existing tools with the provided list
@@ -574,6 +976,12 @@ func (s *MCPServer) DeleteTools(names ...string) {
 	}
 }
 
+// DeleteTool removes the tool with the given name from the server, notifying
+// clients if the tool existed and the listChanged capability is enabled.
+func (s *MCPServer) DeleteTool(name string) {
+	s.DeleteTools(name)
+}
+
 // AddNotificationHandler registers a new handler for incoming notifications
 func (s *MCPServer) AddNotificationHandler(
 	method string,
@@ -586,9 +994,14 @@ func (s *MCPServer) AddNotificationHandler(
 
 func (s *MCPServer) handleInitialize(
 	ctx context.Context,
-	_ any,
+	id any,
 	request mcp.InitializeRequest,
 ) (*mcp.InitializeResult, *requestError) {
+	session := ClientSessionFromContext(ctx)
+	if initialization := s.firstInitialization(session); initialization != nil {
+		return s.handleReinitialize(ctx, id, request, initialization)
+	}
+
 	capabilities := mcp.ServerCapabilities{}
 
 	// Only add resource capabilities if they're configured
@@ -628,17 +1041,32 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Sampling = &struct{}{}
 	}
 
+	serverInfo := s.serverInfo
+	serverInfo.Name = s.name
+	serverInfo.Version = s.version
+
 	result := mcp.InitializeResult{
 		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
-		ServerInfo: mcp.Implementation{
-			Name:    s.name,
-			Version: s.version,
-		},
-		Capabilities: capabilities,
-		Instructions: s.instructions,
+		ServerInfo:      serverInfo,
+		Capabilities:    capabilities,
+		Instructions:    s.instructions,
+	}
+	if s.capabilities.tools != nil {
+		// Let clients know that dry runs are not executed
+		result.SetMeta(mcp.MetaKeyDryRunSupported, true)
 	}
+	result.SetTimeoutHints(s.timeoutHints)
 
-	if session := ClientSessionFromContext(ctx); session != nil {
+	if session != nil {
+		// Sessions without an ID, like those of stateless servers, may be
+		// initialized again
+		if sessionID := session.SessionID(); sessionID != "" {
+			initialization := &sessionInitialization{request: request.Params, result: result}
+			if first, loaded := s.initializations.LoadOrStore(sessionID, initialization); loaded {
+				// A concurrent initialize of the same session came first
+				return s.handleReinitialize(ctx, id, request, first.(*sessionInitialization))
+			}
+		}
 		session.Initialize()
 
 		// Store client info if the session supports it
@@ -646,11 +1074,101 @@ func (s *MCPServer) handleInitialize(
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 			sessionWithClientInfo.SetClientCapabilities(request.Params.Capabilities)
 		}
+		if sessionWithProtocolVersion, ok := session.(SessionWithProtocolVersion); ok {
+			sessionWithProtocolVersion.SetProtocolVersion(result.ProtocolVersion)
+		}
 	}
 
 	return &result, nil
 }
 
+// sessionInitialization is what the first initialize of a session requested
+// and negotiated.
+type sessionInitialization struct {
+	request mcp.InitializeParams
+	result  mcp.InitializeResult
+}
+
+// firstInitialization returns the first initialize of session, or nil if the
+// session has not been initialized or has no ID.
+func (s *MCPServer) firstInitialization(session ClientSession) *sessionInitialization {
+	if session == nil || session.SessionID() == "" {
+		return nil
+	}
+	if initialization, ok := s.initializations.Load(session.SessionID()); ok {
+		return initialization.(*sessionInitialization)
+	}
+	return nil
+}
+
+// sessionInitialized reports whether the session with the given ID has been
+// initialized.
+func (s *MCPServer) sessionInitialized(sessionID string) bool {
+	_, ok := s.initializations.Load(sessionID)
+	return ok
+}
+
+// forgetInitialization lets the session ID be initialized anew once the
+// session has ended.
+func (s *MCPServer) forgetInitialization(sessionID string) {
+	s.initializations.Delete(sessionID)
+}
+
+// handleReinitialize answers an initialize request on an initialized session
+// with the result of the first one, leaving the negotiated state untouched.
+func (s *MCPServer) handleReinitialize(
+	ctx context.Context,
+	id any,
+	request mcp.InitializeRequest,
+	first *sessionInitialization,
+) (*mcp.InitializeResult, *requestError) {
+	result := first.result
+	s.hooks.reinitialize(ctx, id, &request, &result)
+
+	if s.strictReinitialize {
+		switch {
+		case request.Params.ProtocolVersion != first.request.ProtocolVersion:
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err: fmt.Errorf("%w: protocol version %q was negotiated, but %q was requested",
+					ErrSessionAlreadyInitialized, result.ProtocolVersion, request.Params.ProtocolVersion),
+			}
+		case !sameCapabilities(request.Params.Capabilities, first.request.Capabilities):
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_REQUEST,
+				err:  fmt.Errorf("%w with different client capabilities", ErrSessionAlreadyInitialized),
+			}
+		}
+	}
+	return &result, nil
+}
+
+// sameCapabilities reports whether a and b declare the same capabilities.
+// They are compared as JSON, the way the client sent them, so that for
+// example an empty experimental object matches a missing one, and numbers
+// match regardless of the Go type they were decoded into.
+func sameCapabilities(a, b mcp.ClientCapabilities) bool {
+	normalize := func(capabilities mcp.ClientCapabilities) (any, error) {
+		data, err := json.Marshal(capabilities)
+		if err != nil {
+			return nil, err
+		}
+		var normalized any
+		if err := json.Unmarshal(data, &normalized); err != nil {
+			return nil, err
+		}
+		return normalized, nil
+	}
+	normalizedA, errA := normalize(a)
+	normalizedB, errB := normalize(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return reflect.DeepEqual(normalizedA, normalizedB)
+}
+
 func (s *MCPServer) protocolVersion(clientVersion string) string {
 	// For backwards compatibility, if the server does not receive an MCP-Protocol-Version header,
 	// and has no other way to identify the version - for example, by relying on the protocol version negotiated
@@ -754,6 +1272,20 @@ func listByPagination[T mcp.Named](
 	return elementsToReturn, nextCursor, nil
 }
 
+// forProtocolVersion adapts the listed items, which must not be shared, to
+// the protocol version negotiated with the client of ctx, for example by
+// leaving out titles older clients don't know.
+func forProtocolVersion[T interface{ ForProtocolVersion(string) T }](ctx context.Context, items []T) []T {
+	version := ProtocolVersionFromContext(ctx)
+	if version == "" {
+		return items
+	}
+	for i, item := range items {
+		items[i] = item.ForProtocolVersion(version)
+	}
+	return items
+}
+
 func (s *MCPServer) handleListResources(
 	ctx context.Context,
 	id any,
@@ -762,7 +1294,9 @@ func (s *MCPServer) handleListResources(
 	s.resourcesMu.RLock()
 	resources := make([]mcp.Resource, 0, len(s.resources))
 	for _, entry := range s.resources {
-		resources = append(resources, entry.resource)
+		if request.Filter.MatchesResource(entry.resource) {
+			resources = append(resources, entry.resource)
+		}
 	}
 	s.resourcesMu.RUnlock()
 
@@ -770,6 +1304,9 @@ func (s *MCPServer) handleListResources(
 	sort.Slice(resources, func(i, j int) bool {
 		return resources[i].Name < resources[j].Name
 	})
+	if s.sortedResources {
+		resources = sortByName(resources, resourceURI)
+	}
 	resourcesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
@@ -784,7 +1321,7 @@ func (s *MCPServer) handleListResources(
 		}
 	}
 	result := mcp.ListResourcesResult{
-		Resources: resourcesToReturn,
+		Resources: forProtocolVersion(ctx, resourcesToReturn),
 		PaginatedResult: mcp.PaginatedResult{
 			NextCursor: nextCursor,
 		},
@@ -800,12 +1337,17 @@ func (s *MCPServer) handleListResourceTemplates(
 	s.resourcesMu.RLock()
 	templates := make([]mcp.ResourceTemplate, 0, len(s.resourceTemplates))
 	for _, entry := range s.resourceTemplates {
-		templates = append(templates, entry.template)
+		if request.Filter.MatchesTemplate(entry.template) {
+			templates = append(templates, entry.template)
+		}
 	}
 	s.resourcesMu.RUnlock()
 	sort.Slice(templates, func(i, j int) bool {
 		return templates[i].Name < templates[j].Name
 	})
+	if s.sortedResources {
+		templates = sortByName(templates, resourceTemplateURI)
+	}
 	templatesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
@@ -820,7 +1362,7 @@ func (s *MCPServer) handleListResourceTemplates(
 		}
 	}
 	result := mcp.ListResourceTemplatesResult{
-		ResourceTemplates: templatesToReturn,
+		ResourceTemplates: forProtocolVersion(ctx, templatesToReturn),
 		PaginatedResult: mcp.PaginatedResult{
 			NextCursor: nextCursor,
 		},
@@ -902,12 +1444,26 @@ func (s *MCPServer) handleListPrompts(
 	request mcp.ListPromptsRequest,
 ) (*mcp.ListPromptsResult, *requestError) {
 	s.promptsMu.RLock()
-	prompts := make([]mcp.Prompt, 0, len(s.prompts))
-	for _, prompt := range s.prompts {
-		prompts = append(prompts, prompt)
+	promptMap := make(map[string]mcp.Prompt, len(s.prompts))
+	for name, prompt := range s.prompts {
+		promptMap[name] = prompt
 	}
 	s.promptsMu.RUnlock()
 
+	// Session prompts override global prompts with the same name
+	if session := ClientSessionFromContext(ctx); session != nil {
+		if sessionWithPrompts, ok := session.(SessionWithPrompts); ok {
+			for name, serverPrompt := range sessionWithPrompts.GetSessionPrompts() {
+				promptMap[name] = serverPrompt.Prompt
+			}
+		}
+	}
+
+	prompts := make([]mcp.Prompt, 0, len(promptMap))
+	for _, prompt := range promptMap {
+		prompts = append(prompts, prompt)
+	}
+
 	// sort prompts by name
 	sort.Slice(prompts, func(i, j int) bool {
 		return prompts[i].Name < prompts[j].Name
@@ -926,7 +1482,7 @@ func (s *MCPServer) handleListPrompts(
 		}
 	}
 	result := mcp.ListPromptsResult{
-		Prompts: promptsToReturn,
+		Prompts: forProtocolVersion(ctx, promptsToReturn),
 		PaginatedResult: mcp.PaginatedResult{
 			NextCursor: nextCursor,
 		},
@@ -939,18 +1495,40 @@ func (s *MCPServer) handleGetPrompt(
 	id any,
 	request mcp.GetPromptRequest,
 ) (*mcp.GetPromptResult, *requestError) {
-	s.promptsMu.RLock()
-	handler, ok := s.promptHandlers[request.Params.Name]
-	s.promptsMu.RUnlock()
+	// First check session-specific prompts
+	var prompt ServerPrompt
+	var ok bool
+	if session := ClientSessionFromContext(ctx); session != nil {
+		if sessionWithPrompts, typeAssertOk := session.(SessionWithPrompts); typeAssertOk {
+			prompt, ok = sessionWithPrompts.GetSessionPrompts()[request.Params.Name]
+		}
+	}
 
+	// If not found in session prompts, check global prompts
 	if !ok {
+		s.promptsMu.RLock()
+		prompt.Prompt, ok = s.prompts[request.Params.Name]
+		prompt.Handler = s.promptHandlers[request.Params.Name]
+		s.promptsMu.RUnlock()
+	}
+
+	if !ok || prompt.Handler == nil {
 		return nil, &requestError{
 			id:   id,
-			code: mcp.INVALID_PARAMS,
+			code: mcp.PROMPT_NOT_FOUND,
 			err:  fmt.Errorf("prompt '%s' not found: %w", request.Params.Name, ErrPromptNotFound),
 		}
 	}
 
+	if err := validatePromptArguments(prompt.Prompt, request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  err,
+		}
+	}
+
+	handler := prompt.Handler
 	result, err := handler(ctx, request)
 	if err != nil {
 		return nil, &requestError{
@@ -968,6 +1546,35 @@ func (s *MCPServer) handleListTools(
 	id any,
 	request mcp.ListToolsRequest,
 ) (*mcp.ListToolsResult, *requestError) {
+	tools := s.listTools(ctx)
+
+	// Apply pagination
+	toolsToReturn, nextCursor, err := listByPagination(
+		ctx,
+		s,
+		request.Params.Cursor,
+		tools,
+	)
+	if err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  err,
+		}
+	}
+
+	result := mcp.ListToolsResult{
+		Tools: forProtocolVersion(ctx, toolsToReturn),
+		PaginatedResult: mcp.PaginatedResult{
+			NextCursor: nextCursor,
+		},
+	}
+	return &result, nil
+}
+
+// listTools returns the tools visible to the session of ctx: the global tools
+// merged with the session's tools, with the tool filters applied.
+func (s *MCPServer) listTools(ctx context.Context) []mcp.Tool {
 	// Get the base tools from the server
 	s.toolsMu.RLock()
 	tools := make([]mcp.Tool, 0, len(s.tools))
@@ -1028,29 +1635,11 @@ func (s *MCPServer) handleListTools(
 		}
 	}
 	s.toolFiltersMu.RUnlock()
-
-	// Apply pagination
-	toolsToReturn, nextCursor, err := listByPagination(
-		ctx,
-		s,
-		request.Params.Cursor,
-		tools,
-	)
-	if err != nil {
-		return nil, &requestError{
-			id:   id,
-			code: mcp.INVALID_PARAMS,
-			err:  err,
-		}
+	if s.sortedTools {
+		tools = sortByName(tools, nil)
 	}
 
-	result := mcp.ListToolsResult{
-		Tools: toolsToReturn,
-		PaginatedResult: mcp.PaginatedResult{
-			NextCursor: nextCursor,
-		},
-	}
-	return &result, nil
+	return tools
 }
 
 func (s *MCPServer) handleToolCall(
@@ -1061,6 +1650,7 @@ func (s *MCPServer) handleToolCall(
 	// First check session-specific tools
 	var tool ServerTool
 	var ok bool
+	var sessionTool bool
 
 	session := ClientSessionFromContext(ctx)
 	if session != nil {
@@ -1070,6 +1660,7 @@ func (s *MCPServer) handleToolCall(
 				tool, sessionOk = sessionTools[request.Params.Name]
 				if sessionOk {
 					ok = true
+					sessionTool = true
 				}
 			}
 		}
@@ -1090,7 +1681,37 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	if err := s.decodeArguments(&request); err != nil {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  err,
+		}
+	}
+
+	if s.applySchemaDefaults {
+		if args, ok := request.Params.Arguments.(map[string]any); ok || request.Params.Arguments == nil {
+			if filled := tool.Tool.ApplyArgumentDefaults(args); filled != nil {
+				request.Params.Arguments = filled
+			}
+		}
+	}
+
 	finalHandler := tool.Handler
+	if s.toolResultCache != nil && !sessionTool && !isDryRun(request) && isCacheableTool(tool.Tool) {
+		finalHandler = s.withResultCache(tool.Tool.Name, finalHandler)
+	}
+	if isDryRun(request) {
+		// Never run the real handler for a dry run. The middlewares still
+		// run, so that a call they would refuse is refused as a dry run too.
+		if err := tool.Tool.ValidateArguments(request.GetArguments()); err != nil || tool.DryRun == nil {
+			finalHandler = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return dryRunResult(tool.Tool, err), nil
+			}
+		} else {
+			finalHandler = tool.DryRun
+		}
+	}
 
 	s.middlewareMu.RLock()
 	mw := s.toolHandlerMiddlewares
@@ -1101,7 +1722,7 @@ func (s *MCPServer) handleToolCall(
 	}
 	s.middlewareMu.RUnlock()
 
-	result, err := finalHandler(ctx, request)
+	result, err := s.callToolHandler(ctx, id, finalHandler, request)
 	if err != nil {
 		return nil, &requestError{
 			id:   id,
@@ -1110,9 +1731,70 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	if s.validateOutput && !isDryRun(request) {
+		if err := tool.Tool.ValidateStructuredResult(result); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  fmt.Errorf("tool '%s' returned invalid structured content: %w", tool.Tool.Name, err),
+			}
+		}
+	}
+
+	// Tell the client which version of the output schema the result follows
+	if result != nil && result.StructuredContent != nil && result.OutputSchemaVersion() == "" {
+		if version := tool.Tool.OutputSchemaVersion(); version != "" {
+			result.SetMeta(mcp.MetaKeyOutputSchemaVersion, version)
+		}
+	}
+
 	return result, nil
 }
 
+// isDryRun reports whether the request asks for a dry run via _meta.dryRun.
+func isDryRun(request mcp.CallToolRequest) bool {
+	dryRun, _ := request.GetMeta(mcp.MetaKeyDryRun).(bool)
+	return dryRun
+}
+
+// dryRunResult reports the outcome of validating a dry run of tool, with a
+// DryRunReport as structured content.
+func dryRunResult(tool mcp.Tool, validationErr error) *mcp.CallToolResult {
+	report := mcp.DryRunReport{
+		Tool:        tool.Name,
+		Valid:       validationErr == nil,
+		Annotations: tool.Annotations,
+	}
+	if validationErr == nil {
+		return mcp.NewToolResultStructured(report, fmt.Sprintf("dry run: arguments for tool '%s' are valid", tool.Name))
+	}
+	if joined, ok := validationErr.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	} else {
+		report.Errors = []string{validationErr.Error()}
+	}
+	result := mcp.NewToolResultStructured(report, validationErr.Error())
+	result.IsError = true
+	return result
+}
+
+// validatePromptArguments checks that every argument the prompt marks as
+// required is present in the request.
+func validatePromptArguments(prompt mcp.Prompt, request mcp.GetPromptRequest) error {
+	var missing []string
+	for _, argument := range prompt.Arguments {
+		if _, ok := request.Params.Arguments[argument.Name]; argument.Required && !ok {
+			missing = append(missing, argument.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w for prompt '%s': %s", ErrMissingPromptArguments, prompt.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
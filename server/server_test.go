@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"slices"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -1409,7 +1411,7 @@ func TestMCPServer_HandleUndefinedHandlers(t *testing.T) {
                         "arguments": {}
                     }
                 }`,
-			expectedErr: mcp.INVALID_PARAMS,
+			expectedErr: mcp.PROMPT_NOT_FOUND,
 			validateCallbacks: func(t *testing.T, err error, beforeResults beforeResult) {
 				assert.Equal(t, mcp.MethodPromptsGet, beforeResults.method)
 				assert.True(t, errors.Is(err, ErrPromptNotFound))
@@ -2124,7 +2126,596 @@ func TestMCPServer_WithRecover(t *testing.T) {
 		"panic recovered in panic-tool tool handler: test panic",
 		errorResponse.Error.Message,
 	)
-	assert.Nil(t, errorResponse.Error.Data)
+	// Only the error ID is sent along, not the stack
+	data, ok := errorResponse.Error.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Len(t, data, 1)
+	assert.NotEmpty(t, data["errorId"])
+}
+
+func TestMCPServer_WithErrorSanitizer(t *testing.T) {
+	internalErr := errors.New("pq: password authentication failed for user \"admin\"")
+
+	var hookErr error
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		hookErr = err
+	})
+
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithHooks(hooks),
+		WithErrorSanitizer(func(err error) error {
+			if errors.Is(err, ErrToolNotFound) {
+				return nil
+			}
+			return errors.New("internal server error")
+		}),
+	)
+
+	server.AddTool(
+		mcp.NewTool("db-tool"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, internalErr
+		},
+	)
+
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {
+			"name": "db-tool"
+		}
+	}`))
+
+	errorResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errorResponse.Error.Code)
+	assert.Equal(t, "internal server error", errorResponse.Error.Message)
+
+	// Hooks still see the full error for server-side logging
+	assert.ErrorIs(t, hookErr, internalErr)
+
+	// Returning nil from the sanitizer keeps the original message
+	response = server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tools/call",
+		"params": {
+			"name": "missing-tool"
+		}
+	}`))
+
+	errorResponse, ok = response.(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+	assert.Contains(t, errorResponse.Error.Message, "missing-tool")
+}
+
+type testNotFoundError struct {
+	id string
+}
+
+func (e *testNotFoundError) Error() string {
+	return fmt.Sprintf("record %s not found", e.id)
+}
+
+func TestMCPServer_WithErrorMapper(t *testing.T) {
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithErrorMapper(func(err error) (int, any) {
+			var notFound *testNotFoundError
+			if errors.As(err, &notFound) {
+				return mcp.RESOURCE_NOT_FOUND, map[string]any{"id": notFound.id}
+			}
+			return 0, nil
+		}),
+		WithErrorSanitizer(func(err error) error {
+			var notFound *testNotFoundError
+			if errors.As(err, &notFound) {
+				return nil
+			}
+			return errors.New("internal server error")
+		}),
+	)
+
+	server.AddTool(
+		mcp.NewTool("lookup", mcp.WithString("id")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			id := request.GetString("id", "")
+			if id == "broken" {
+				return nil, errors.New("connection reset")
+			}
+			return nil, fmt.Errorf("lookup: %w", &testNotFoundError{id: id})
+		},
+	)
+
+	call := func(id string) mcp.JSONRPCError {
+		t.Helper()
+		response := server.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "lookup", "arguments": {"id": %q}}
+		}`, id)))
+		errorResponse, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+		return errorResponse
+	}
+
+	errorResponse := call("42")
+	assert.Equal(t, mcp.RESOURCE_NOT_FOUND, errorResponse.Error.Code)
+	assert.Equal(t, "lookup: record 42 not found", errorResponse.Error.Message)
+	data, ok := errorResponse.Error.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "42", data["id"])
+	assert.NotEmpty(t, data["errorId"], "mapped data keeps the error ID")
+
+	// Unmapped errors stay internal errors and are still sanitized
+	errorResponse = call("broken")
+	assert.Equal(t, mcp.INTERNAL_ERROR, errorResponse.Error.Code)
+	assert.Equal(t, "internal server error", errorResponse.Error.Message)
+	assert.NotContains(t, errorResponse.Error.Data, "id")
+
+	// Protocol errors keep their own codes
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 2,
+		"method": "tools/call",
+		"params": {"name": "missing-tool"}
+	}`))
+	errorResponse, ok = response.(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+}
+
+func TestMCPServer_ShutdownHook(t *testing.T) {
+	newServer := func(calls *[]time.Time) *MCPServer {
+		return NewMCPServer("test-server", "1.0.0",
+			WithShutdownHook(func(ctx context.Context) {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok, "shutdown context should carry a deadline")
+				*calls = append(*calls, deadline)
+			}),
+		)
+	}
+
+	t.Run("streamable HTTP", func(t *testing.T) {
+		var calls []time.Time
+		httpServer := NewStreamableHTTPServer(newServer(&calls))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		require.NoError(t, httpServer.Shutdown(ctx))
+		require.NoError(t, httpServer.Shutdown(ctx))
+
+		require.Len(t, calls, 1, "hook should run exactly once")
+		expected, _ := ctx.Deadline()
+		assert.Equal(t, expected, calls[0])
+	})
+
+	t.Run("SSE", func(t *testing.T) {
+		var calls []time.Time
+		sseServer := NewSSEServer(newServer(&calls))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		require.NoError(t, sseServer.Shutdown(ctx))
+		require.Len(t, calls, 1)
+	})
+
+	t.Run("stdio", func(t *testing.T) {
+		var calls []time.Time
+		stdioServer := NewStdioServer(newServer(&calls))
+
+		// Listen returns once stdin is exhausted
+		err := stdioServer.Listen(context.Background(), strings.NewReader(""), io.Discard)
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+	})
+}
+
+func TestMCPServer_ApplySchemaDefaults(t *testing.T) {
+	tool := mcp.NewTool("search",
+		mcp.WithString("query"),
+		mcp.WithString("sort", mcp.DefaultString("relevance")),
+		mcp.WithNumber("limit", mcp.DefaultNumber(10)),
+		mcp.WithNumber("offset", mcp.DefaultNumber(0)),
+		mcp.WithObject("filter", mcp.Properties(map[string]any{
+			"lang": map[string]any{"type": "string", "default": "en"},
+		})),
+	)
+
+	callWith := func(server *MCPServer, arguments string) map[string]any {
+		var received map[string]any
+		server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			received = request.GetArguments()
+			return mcp.NewToolResultText("ok"), nil
+		})
+		response := server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "search", "arguments": `+arguments+`}
+		}`))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "unexpected response %#v", response)
+		return received
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0")
+		args := callWith(server, `{"query": "go"}`)
+		assert.Equal(t, map[string]any{"query": "go"}, args)
+	})
+
+	t.Run("fills omitted arguments", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithApplySchemaDefaults())
+		args := callWith(server, `{"query": "go", "filter": {}}`)
+		assert.Equal(t, map[string]any{
+			"query":  "go",
+			"sort":   "relevance",
+			"limit":  json.Number("10"),
+			"offset": json.Number("0"),
+			"filter": map[string]any{"lang": "en"},
+		}, args)
+	})
+
+	t.Run("explicit values and nulls win", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithApplySchemaDefaults())
+		args := callWith(server, `{"sort": null, "limit": 3, "filter": {"lang": "de"}}`)
+		assert.Equal(t, map[string]any{
+			"sort":   nil,
+			"limit":  json.Number("3"),
+			"offset": json.Number("0"),
+			"filter": map[string]any{"lang": "de"},
+		}, args)
+	})
+}
+
+func TestMCPServer_PromptValidationAndPagination(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPaginationLimit(2))
+	handler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("ok", nil), nil
+	}
+	server.AddPrompt(mcp.NewPrompt("translate",
+		mcp.WithArgument("text", mcp.RequiredArgument()),
+		mcp.WithArgument("language", mcp.RequiredArgument()),
+		mcp.WithArgument("tone"),
+	), handler)
+	server.AddPrompt(mcp.NewPrompt("greet"), handler)
+	server.AddPrompt(mcp.NewPrompt("summarize"), handler)
+
+	tests := []struct {
+		name            string
+		message         string
+		expectedCode    int
+		expectedErr     error
+		expectedMessage string
+	}{
+		{
+			name:         "missing prompt",
+			message:      `{"jsonrpc": "2.0", "id": 1, "method": "prompts/get", "params": {"name": "unknown"}}`,
+			expectedCode: mcp.PROMPT_NOT_FOUND,
+			expectedErr:  ErrPromptNotFound,
+		},
+		{
+			name:            "missing required arguments",
+			message:         `{"jsonrpc": "2.0", "id": 1, "method": "prompts/get", "params": {"name": "translate", "arguments": {"tone": "formal"}}}`,
+			expectedCode:    mcp.INVALID_PARAMS,
+			expectedErr:     ErrMissingPromptArguments,
+			expectedMessage: "text, language",
+		},
+		{
+			name:    "required arguments present",
+			message: `{"jsonrpc": "2.0", "id": 1, "method": "prompts/get", "params": {"name": "translate", "arguments": {"text": "hi", "language": "de"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hookErr error
+			hooks := &Hooks{}
+			hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+				hookErr = err
+			})
+			server.hooks = hooks
+
+			response := server.HandleMessage(context.Background(), []byte(tt.message))
+			if tt.expectedCode == 0 {
+				_, ok := response.(mcp.JSONRPCResponse)
+				assert.True(t, ok, "unexpected response %#v", response)
+				return
+			}
+
+			errorResponse, ok := response.(mcp.JSONRPCError)
+			require.True(t, ok, "unexpected response %#v", response)
+			assert.Equal(t, tt.expectedCode, errorResponse.Error.Code)
+			assert.Contains(t, errorResponse.Error.Message, tt.expectedMessage)
+			assert.ErrorIs(t, hookErr, tt.expectedErr)
+		})
+	}
+
+	t.Run("multi-page listing", func(t *testing.T) {
+		var names []string
+		cursor := ""
+		pages := 0
+		for {
+			params := `{}`
+			if cursor != "" {
+				params = `{"cursor": "` + cursor + `"}`
+			}
+			response := server.HandleMessage(context.Background(), []byte(
+				`{"jsonrpc": "2.0", "id": 1, "method": "prompts/list", "params": `+params+`}`,
+			))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "unexpected response %#v", response)
+			result, ok := resp.Result.(mcp.ListPromptsResult)
+			require.True(t, ok)
+			pages++
+			for _, prompt := range result.Prompts {
+				names = append(names, prompt.Name)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = string(result.NextCursor)
+		}
+		assert.Equal(t, 2, pages)
+		assert.Equal(t, []string{"greet", "summarize", "translate"}, names)
+	})
+}
+
+func TestAddTypedTool(t *testing.T) {
+	type weatherInput struct {
+		City string  `json:"city" jsonschema:"description=City name"`
+		Days float64 `json:"days,omitempty"`
+	}
+	type weatherOutput struct {
+		City        string  `json:"city"`
+		Temperature float64 `json:"temperature"`
+	}
+
+	server := NewMCPServer("test-server", "1.0.0")
+	AddTypedTool(server, "weather", "Get the weather",
+		func(ctx context.Context, request mcp.CallToolRequest, args weatherInput) (weatherOutput, error) {
+			return weatherOutput{City: args.City, Temperature: 21.5}, nil
+		},
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+
+	tool, ok := server.tools["weather"]
+	require.True(t, ok)
+	assert.Equal(t, "Get the weather", tool.Tool.Description)
+	require.NotNil(t, tool.Tool.Annotations.ReadOnlyHint)
+	assert.True(t, *tool.Tool.Annotations.ReadOnlyHint)
+	assert.NotEmpty(t, tool.Tool.RawOutputSchema)
+
+	handBuilt := mcp.NewTool("weather",
+		mcp.WithString("city", mcp.Required(), mcp.Description("City name")),
+		mcp.WithNumber("days"),
+	)
+	expected, err := json.Marshal(handBuilt.InputSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(tool.Tool.RawInputSchema))
+
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "tools/call",
+		"params": {
+			"name": "weather",
+			"arguments": {"city": "Paris"}
+		}
+	}`))
+
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := resp.Result.(mcp.CallToolResult)
+	require.True(t, ok)
+	assert.False(t, result.IsError)
+	assert.Equal(t, weatherOutput{City: "Paris", Temperature: 21.5}, result.StructuredContent)
+}
+
+func TestMCPServer_ToolDryRun(t *testing.T) {
+	deleted := false
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddToolWithDryRun(
+		mcp.NewTool("delete-file",
+			mcp.WithString("path", mcp.Required()),
+			mcp.WithDestructiveHintAnnotation(true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			deleted = true
+			return mcp.NewToolResultText("deleted"), nil
+		},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("would delete " + request.GetString("path", "")), nil
+		},
+	)
+	server.AddTool(
+		mcp.NewTool("plain", mcp.WithString("name", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			t.Error("handler must not run during a dry run")
+			return mcp.NewToolResultText("ran"), nil
+		},
+	)
+
+	callTool := func(name string, args string, dryRun bool) mcp.CallToolResult {
+		message := fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {
+				"name": %q,
+				"arguments": %s,
+				"_meta": {"dryRun": %t}
+			}
+		}`, name, args, dryRun)
+		response := server.HandleMessage(context.Background(), []byte(message))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		return result
+	}
+
+	textOf := func(result mcp.CallToolResult) string {
+		require.Len(t, result.Content, 1)
+		text, ok := result.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		return text.Text
+	}
+
+	t.Run("uses the registered dry run function", func(t *testing.T) {
+		result := callTool("delete-file", `{"path": "/tmp/a"}`, true)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "would delete /tmp/a", textOf(result))
+		assert.False(t, deleted)
+	})
+
+	t.Run("validates required arguments", func(t *testing.T) {
+		result := callTool("delete-file", `{}`, true)
+		assert.True(t, result.IsError)
+		assert.Contains(t, textOf(result), `"path"`)
+		assert.False(t, deleted)
+	})
+
+	t.Run("validates tools without dry run function", func(t *testing.T) {
+		result := callTool("plain", `{"name": "x"}`, true)
+		assert.False(t, result.IsError)
+		assert.Contains(t, textOf(result), "valid")
+
+		result = callTool("plain", `{}`, true)
+		assert.True(t, result.IsError)
+	})
+
+	t.Run("runs the handler without dry run", func(t *testing.T) {
+		result := callTool("delete-file", `{"path": "/tmp/a"}`, false)
+		assert.Equal(t, "deleted", textOf(result))
+		assert.True(t, deleted)
+	})
+}
+
+func TestMCPServer_ToolDryRunReport(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false))
+	server.AddTool(
+		mcp.NewTool("resize",
+			mcp.WithNumber("width", mcp.Required()),
+			mcp.WithString("unit", mcp.Enum("px", "em")),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			t.Error("handler must not run during a dry run")
+			return nil, nil
+		},
+	)
+	server.AddToolWithDryRun(
+		mcp.NewTool("preview", mcp.WithString("name", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			t.Error("handler must not run during a dry run")
+			return nil, nil
+		},
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("would greet " + request.GetString("name", "")), nil
+		},
+	)
+
+	dryRun := func(name string, args string) mcp.CallToolResult {
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":%s,"_meta":{"dryRun":true}}}`, name, args)
+		response := server.HandleMessage(context.Background(), []byte(message))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		wantErrors []string
+	}{
+		{name: "valid", args: `{"width": 10, "unit": "px"}`},
+		{name: "missing required", args: `{}`, wantErrors: []string{`required argument "width" not found`}},
+		{
+			name: "wrong type and value",
+			args: `{"width": "wide", "unit": "pt"}`,
+			wantErrors: []string{
+				`argument "unit" must be one of [px em]`,
+				`argument "width" must be of type number`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := dryRun("resize", tt.args)
+			assert.Equal(t, len(tt.wantErrors) > 0, result.IsError)
+			report, ok := result.StructuredContent.(mcp.DryRunReport)
+			require.True(t, ok, "unexpected structured content %T", result.StructuredContent)
+			assert.Equal(t, "resize", report.Tool)
+			assert.Equal(t, len(tt.wantErrors) == 0, report.Valid)
+			assert.Equal(t, tt.wantErrors, report.Errors)
+			require.NotNil(t, report.Annotations.ReadOnlyHint)
+			assert.True(t, *report.Annotations.ReadOnlyHint)
+		})
+	}
+
+	t.Run("custom dry run handler", func(t *testing.T) {
+		result := dryRun("preview", `{"name": "Ada"}`)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "would greet Ada", result.Content[0].(mcp.TextContent).Text)
+
+		result = dryRun("preview", `{}`)
+		assert.True(t, result.IsError, "arguments are validated before the dry run handler")
+	})
+
+	t.Run("middlewares run for dry runs", func(t *testing.T) {
+		var calls []string
+		guarded := NewMCPServer("test-server", "1.0.0", WithToolHandlerMiddleware(func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, request.Params.Name)
+				if request.Params.Name == "resize" {
+					return mcp.NewToolResultError("forbidden"), nil
+				}
+				return next(ctx, request)
+			}
+		}))
+		guarded.AddTool(mcp.NewTool("resize", mcp.WithNumber("width")), nil)
+		guarded.AddToolWithDryRun(mcp.NewTool("preview"), nil,
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("preview"), nil
+			},
+		)
+
+		for _, name := range []string{"resize", "preview"} {
+			message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":{},"_meta":{"dryRun":true}}}`, name)
+			response := guarded.HandleMessage(context.Background(), []byte(message))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok, "expected response, got %#v", response)
+			result, ok := resp.Result.(mcp.CallToolResult)
+			require.True(t, ok)
+			if name == "resize" {
+				assert.True(t, result.IsError, "the middleware refuses the validation report")
+				assert.Equal(t, "forbidden", result.Content[0].(mcp.TextContent).Text)
+			} else {
+				assert.Equal(t, "preview", result.Content[0].(mcp.TextContent).Text)
+			}
+		}
+		assert.Equal(t, []string{"resize", "preview"}, calls)
+	})
+
+	t.Run("initialize advertises dry runs", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result, ok := resp.Result.(mcp.InitializeResult)
+		require.True(t, ok)
+		assert.Equal(t, true, result.GetMeta(mcp.MetaKeyDryRunSupported))
+	})
 }
 
 func getTools(length int) []mcp.Tool {
@@ -2320,3 +2911,232 @@ func TestMCPServer_ProtocolNegotiation(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_StrictRegistration(t *testing.T) {
+	first := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("first"), nil
+	}
+	second := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("second"), nil
+	}
+
+	callSearch := func(t *testing.T, server *MCPServer, ctx context.Context) string {
+		t.Helper()
+		response := server.HandleMessage(ctx, []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "search"}
+		}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected JSONRPCResponse, got %T", response)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("lenient mode overwrites and fires hook", func(t *testing.T) {
+		hooks := &Hooks{}
+		var previous, replacement []ServerTool
+		hooks.AddOnToolOverwritten(func(p, r ServerTool) {
+			previous = append(previous, p)
+			replacement = append(replacement, r)
+		})
+		server := NewMCPServer("test-server", "1.0.0", WithHooks(hooks))
+
+		server.AddTool(mcp.NewTool("search", mcp.WithDescription("first")), first)
+		assert.Empty(t, previous)
+		require.NoError(t, server.AddToolE(mcp.NewTool("search", mcp.WithDescription("second")), second))
+
+		require.Len(t, previous, 1)
+		assert.Equal(t, "first", previous[0].Tool.Description)
+		assert.Equal(t, "second", replacement[0].Tool.Description)
+		assert.Equal(t, "second", callSearch(t, server, context.Background()))
+	})
+
+	t.Run("strict mode rejects duplicates and fires hook", func(t *testing.T) {
+		hooks := &Hooks{}
+		var collisions int
+		hooks.AddOnToolOverwritten(func(p, r ServerTool) {
+			collisions++
+		})
+		server := NewMCPServer("test-server", "1.0.0", WithHooks(hooks), WithStrictToolRegistration())
+
+		require.NoError(t, server.AddToolE(mcp.NewTool("search"), first))
+		err := server.AddToolE(mcp.NewTool("search"), second)
+		assert.ErrorIs(t, err, ErrToolAlreadyExists)
+		assert.Equal(t, 1, collisions)
+		assert.Equal(t, "first", callSearch(t, server, context.Background()))
+
+		// Duplicates within one batch are rejected as a whole
+		err = server.AddToolsE(
+			ServerTool{Tool: mcp.NewTool("lookup"), Handler: first},
+			ServerTool{Tool: mcp.NewTool("lookup"), Handler: second},
+		)
+		assert.ErrorIs(t, err, ErrToolAlreadyExists)
+		_, ok := server.tools["lookup"]
+		assert.False(t, ok)
+
+		assert.Panics(t, func() {
+			server.AddTool(mcp.NewTool("search"), second)
+		})
+	})
+
+	t.Run("strict mode allows session tools to shadow global tools", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithStrictToolRegistration())
+		require.NoError(t, server.AddToolE(mcp.NewTool("search"), first))
+
+		session := &sessionTestClientWithTools{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+			initialized:         true,
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		require.NoError(t, server.AddSessionTool(session.SessionID(), mcp.NewTool("search"), second))
+
+		assert.Equal(t, "second", callSearch(t, server, server.WithContext(context.Background(), session)))
+	})
+
+	t.Run("strict prompts and resources", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0",
+			WithStrictPromptRegistration(),
+			WithStrictResourceRegistration(),
+		)
+		promptHandler := func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("", nil), nil
+		}
+		resourceHandler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, nil
+		}
+
+		require.NoError(t, server.AddPromptE(mcp.NewPrompt("greet"), promptHandler))
+		assert.ErrorIs(t, server.AddPromptE(mcp.NewPrompt("greet"), promptHandler), ErrPromptAlreadyExists)
+
+		require.NoError(t, server.AddResourceE(mcp.NewResource("file:///a", "a"), resourceHandler))
+		assert.ErrorIs(t, server.AddResourceE(mcp.NewResource("file:///a", "a"), resourceHandler), ErrResourceAlreadyExists)
+	})
+}
+
+func TestMCPServer_OutputSchemaVersionMeta(t *testing.T) {
+	type Report struct {
+		Total int `json:"total"`
+	}
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddTool(
+		mcp.NewTool("report", mcp.WithOutputSchema[Report](), mcp.WithOutputSchemaVersion("2")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultStructuredOnly(Report{Total: 3}), nil
+		},
+	)
+	server.AddTool(
+		mcp.NewTool("text"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("plain"), nil
+		},
+	)
+
+	callTool := func(name string) mcp.CallToolResult {
+		message := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": %q}}`, name)
+		response := server.HandleMessage(context.Background(), []byte(message))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		return result
+	}
+
+	report := callTool("report")
+	assert.Equal(t, "2", report.OutputSchemaVersion())
+	text := callTool("text")
+	assert.Empty(t, text.OutputSchemaVersion())
+}
+
+func TestMCPServer_WithOutputValidation(t *testing.T) {
+	type Asset struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	var assets any
+	newServer := func(opts ...ServerOption) *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0", opts...)
+		server.AddTool(
+			mcp.NewTool("get_assets", mcp.WithOutputSchema[[]Asset]()),
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultStructuredOnly(assets), nil
+			},
+		)
+		return server
+	}
+	callTool := func(server *MCPServer) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "get_assets"}}`))
+	}
+
+	server := newServer(WithOutputValidation())
+	assets = []Asset{{ID: "a1", Name: "logo.png"}}
+	response, ok := callTool(server).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result := response.Result.(mcp.CallToolResult)
+	assert.Equal(t, assets, result.StructuredContent)
+
+	// An object where the schema declares an array is rejected
+	assets = Asset{ID: "a1", Name: "logo.png"}
+	errResponse, ok := callTool(server).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INTERNAL_ERROR, errResponse.Error.Code)
+	assert.Contains(t, errResponse.Error.Message, "structured content must be of type array")
+
+	assets = []any{map[string]any{"id": "a1"}}
+	errResponse, ok = callTool(server).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Contains(t, errResponse.Error.Message, `structured content[0]: required property "name" not found`)
+
+	// Without the option results are sent unchecked
+	_, ok = callTool(newServer()).(mcp.JSONRPCResponse)
+	assert.True(t, ok)
+}
+
+func TestMCPServer_TitlesForProtocolVersion(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0")
+	srv.AddTool(mcp.NewTool("search", mcp.WithTitle("Search the Web")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, nil
+	})
+	srv.AddPrompt(mcp.NewPrompt("greet", mcp.WithPromptTitle("Greeting")), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return nil, nil
+	})
+	srv.AddResource(mcp.NewResource("file:///a", "a", mcp.WithResourceTitle("A")), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	})
+
+	// list returns the titles of the tool, prompt and resource a client of
+	// the protocol version sees, and the tool's annotation title
+	list := func(version string) (tool, prompt, resource, annotation string) {
+		session := NewInProcessSession("session-"+version, nil)
+		require.NoError(t, srv.RegisterSession(context.Background(), session))
+		defer srv.UnregisterSession(context.Background(), session.SessionID())
+		ctx := srv.WithContext(context.Background(), session)
+
+		initialize := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"clientInfo":{"name":"c","version":"1"}}}`, version)
+		require.IsType(t, mcp.JSONRPCResponse{}, srv.HandleMessage(ctx, []byte(initialize)))
+
+		result := func(method string) any {
+			response, ok := srv.HandleMessage(ctx, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":%q}`, method))).(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			return response.Result
+		}
+		tools := result("tools/list").(mcp.ListToolsResult).Tools[0]
+		prompts := result("prompts/list").(mcp.ListPromptsResult).Prompts[0]
+		resources := result("resources/list").(mcp.ListResourcesResult).Resources[0]
+		return tools.Title, prompts.Title, resources.Title, tools.Annotations.Title
+	}
+
+	tool, prompt, resource, annotation := list(mcp.LATEST_PROTOCOL_VERSION)
+	assert.Equal(t, []string{"Search the Web", "Greeting", "A", ""}, []string{tool, prompt, resource, annotation})
+
+	// Older clients get the tool's title as its annotation title instead
+	tool, prompt, resource, annotation = list("2025-03-26")
+	assert.Equal(t, []string{"", "", "", "Search the Web"}, []string{tool, prompt, resource, annotation})
+
+	// The registered tool is unchanged
+	tool, _, _, _ = list(mcp.LATEST_PROTOCOL_VERSION)
+	assert.Equal(t, "Search the Web", tool)
+}
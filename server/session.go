@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -39,6 +41,17 @@ type SessionWithTools interface {
 	SetSessionTools(tools map[string]ServerTool)
 }
 
+// SessionWithPrompts is an extension of ClientSession that can store session-specific prompt data
+type SessionWithPrompts interface {
+	ClientSession
+	// GetSessionPrompts returns the prompts specific to this session, if any
+	// This method must be thread-safe for concurrent access
+	GetSessionPrompts() map[string]ServerPrompt
+	// SetSessionPrompts sets prompts specific to this session
+	// This method must be thread-safe for concurrent access
+	SetSessionPrompts(prompts map[string]ServerPrompt)
+}
+
 // SessionWithClientInfo is an extension of ClientSession that can store client info
 type SessionWithClientInfo interface {
 	ClientSession
@@ -52,6 +65,17 @@ type SessionWithClientInfo interface {
 	SetClientCapabilities(clientCapabilities mcp.ClientCapabilities)
 }
 
+// SessionWithProtocolVersion is an extension of ClientSession that can store
+// the protocol version negotiated during initialization
+type SessionWithProtocolVersion interface {
+	ClientSession
+	// GetProtocolVersion returns the negotiated protocol version, or an empty
+	// string if the session has not been initialized
+	GetProtocolVersion() string
+	// SetProtocolVersion sets the negotiated protocol version
+	SetProtocolVersion(version string)
+}
+
 // SessionWithStreamableHTTPConfig extends ClientSession to support streamable HTTP transport configurations
 type SessionWithStreamableHTTPConfig interface {
 	ClientSession
@@ -79,6 +103,44 @@ func ClientSessionFromContext(ctx context.Context) ClientSession {
 	return nil
 }
 
+// defaultHTTPProtocolVersion is the protocol version assumed for HTTP
+// requests without the Mcp-Protocol-Version header.
+const defaultHTTPProtocolVersion = "2025-03-26"
+
+// ProtocolVersionFromContext returns the protocol version negotiated with the
+// client of the current request. It is read from the session when the
+// session stores it, and otherwise from the Mcp-Protocol-Version header the
+// streamable HTTP transport sends with every request. HTTP requests without
+// a valid header are assumed to use 2025-03-26, as the specification says,
+// because older clients don't send it. Otherwise it returns an empty string
+// if the version is not known.
+func ProtocolVersionFromContext(ctx context.Context) string {
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithProtocolVersion); ok {
+		if version := session.GetProtocolVersion(); version != "" {
+			return version
+		}
+	}
+	if headers, ok := ctx.Value(requestHeader).(http.Header); ok {
+		if version := headers.Get(HeaderKeyProtocolVersion); slices.Contains(mcp.ValidProtocolVersions, version) {
+			return version
+		}
+		return defaultHTTPProtocolVersion
+	}
+	return ""
+}
+
+// ClientInfoFromContext returns the name and version the client of the
+// current request reported in its initialize request, so handlers can, for
+// example, work around a known bug of a specific client version. It returns
+// an empty Implementation if the session does not store client info or the
+// client has not initialized it.
+func ClientInfoFromContext(ctx context.Context) mcp.Implementation {
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithClientInfo); ok {
+		return session.GetClientInfo()
+	}
+	return mcp.Implementation{}
+}
+
 // WithContext sets the current client session and returns the provided context
 func (s *MCPServer) WithContext(
 	ctx context.Context,
@@ -134,25 +196,8 @@ func (s *MCPServer) SendLogMessageToClient(ctx context.Context, notification mcp
 func (s *MCPServer) sendNotificationToAllClients(notification mcp.JSONRPCNotification) {
 	s.sessions.Range(func(k, v any) bool {
 		if session, ok := v.(ClientSession); ok && session.Initialized() {
-			select {
-			case session.NotificationChannel() <- notification:
-				// Successfully sent notification
-			default:
-				// Channel is blocked, if there's an error hook, use it
-				if s.hooks != nil && len(s.hooks.OnError) > 0 {
-					err := ErrNotificationChannelBlocked
-					// Copy hooks pointer to local variable to avoid race condition
-					hooks := s.hooks
-					go func(sessionID string, hooks *Hooks) {
-						ctx := context.Background()
-						// Use the error hook to report the blocked channel
-						hooks.onError(ctx, nil, "notification", map[string]any{
-							"method":    notification.Method,
-							"sessionID": sessionID,
-						}, fmt.Errorf("notification channel blocked for session %s: %w", sessionID, err))
-					}(session.SessionID(), hooks)
-				}
-			}
+			// Failures are reported through the error hooks
+			_ = s.queueNotification(context.Background(), session, notification)
 		}
 		return true
 	})
@@ -163,26 +208,54 @@ func (s *MCPServer) sendNotificationToSpecificClient(session ClientSession, noti
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
-	select {
-	case session.NotificationChannel() <- notification:
-		return nil
-	default:
-		// Channel is blocked, if there's an error hook, use it
-		if s.hooks != nil && len(s.hooks.OnError) > 0 {
-			err := ErrNotificationChannelBlocked
-			ctx := context.Background()
-			// Copy hooks pointer to local variable to avoid race condition
-			hooks := s.hooks
-			go func(sID string, hooks *Hooks) {
-				// Use the error hook to report the blocked channel
-				hooks.onError(ctx, nil, "notification", map[string]any{
-					"method":    notification.Method,
-					"sessionID": sID,
-				}, fmt.Errorf("notification channel blocked for session %s: %w", sID, err))
-			}(session.SessionID(), hooks)
+	return s.queueNotification(context.Background(), session, notification)
+}
+
+// notificationWriter is implemented by sessions that deliver notifications
+// themselves rather than through their notification channel, so that they
+// can order them with the responses written to the same stream.
+type notificationWriter interface {
+	writeNotification(notification mcp.JSONRPCNotification) error
+}
+
+// trySendNotification hands the notification to the session without
+// blocking. If it can't be delivered, for example because the session's
+// channel is full, the error hooks are called and the error is returned.
+func (s *MCPServer) trySendNotification(
+	ctx context.Context,
+	session ClientSession,
+	notification mcp.JSONRPCNotification,
+) error {
+	var err error
+	if writer, ok := session.(notificationWriter); ok {
+		err = writer.writeNotification(notification)
+	} else {
+		select {
+		case session.NotificationChannel() <- notification:
+		default:
+			err = ErrNotificationChannelBlocked
 		}
-		return ErrNotificationChannelBlocked
 	}
+	if err == nil {
+		if s.trafficRecorder != nil {
+			s.trafficRecorder.recordOutbound(session.SessionID(), notification)
+		}
+		return nil
+	}
+
+	// If there's an error hook, use it
+	if s.hooks != nil && len(s.hooks.OnErrorInfo) > 0 {
+		method := notification.Method
+		// Copy hooks pointer to local variable to avoid race condition
+		hooks := s.hooks
+		go func(sessionID string, hooks *Hooks) {
+			hooks.onError(ctx, nil, "notification", map[string]any{
+				"method":    method,
+				"sessionID": sessionID,
+			}, fmt.Errorf("notification not delivered to session %s: %w", sessionID, err))
+		}(session.SessionID(), hooks)
+	}
+	return err
 }
 
 func (s *MCPServer) SendLogMessageToSpecificClient(sessionID string, notification mcp.LoggingMessageNotification) error {
@@ -209,10 +282,21 @@ func (s *MCPServer) UnregisterSession(
 	ctx context.Context,
 	sessionID string,
 ) {
+	s.notificationQueuesMu.Lock()
 	sessionValue, ok := s.sessions.LoadAndDelete(sessionID)
+	queue, hasQueue := s.notificationQueues.LoadAndDelete(sessionID)
+	s.notificationQueuesMu.Unlock()
+	if hasQueue {
+		queue.(*notificationQueue).close()
+	}
 	if !ok {
 		return
 	}
+	// The streamable HTTP session outlives its GET stream and is forgotten
+	// when the client terminates it
+	if _, ok := sessionValue.(*streamableHttpSession); !ok {
+		s.forgetInitialization(sessionID)
+	}
 	if session, ok := sessionValue.(ClientSession); ok {
 		s.hooks.UnregisterSession(ctx, session)
 	}
@@ -245,26 +329,7 @@ func (s *MCPServer) sendNotificationCore(
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
-	select {
-	case session.NotificationChannel() <- notification:
-		return nil
-	default:
-		// Channel is blocked, if there's an error hook, use it
-		if s.hooks != nil && len(s.hooks.OnError) > 0 {
-			method := notification.Method
-			err := ErrNotificationChannelBlocked
-			// Copy hooks pointer to local variable to avoid race condition
-			hooks := s.hooks
-			go func(sessionID string, hooks *Hooks) {
-				// Use the error hook to report the blocked channel
-				hooks.onError(ctx, nil, "notification", map[string]any{
-					"method":    method,
-					"sessionID": sessionID,
-				}, fmt.Errorf("notification channel blocked for session %s: %w", sessionID, err))
-			}(session.SessionID(), hooks)
-		}
-		return ErrNotificationChannelBlocked
-	}
+	return s.queueNotification(ctx, session, notification)
 }
 
 // SendNotificationToClient sends a notification to the current client
@@ -365,7 +430,7 @@ func (s *MCPServer) AddSessionTools(sessionID string, tools ...ServerTool) error
 		if err := s.SendNotificationToSpecificClient(sessionID, "notifications/tools/list_changed", nil); err != nil {
 			// Log the error but don't fail the operation
 			// The tools were successfully added, but notification failed
-			if s.hooks != nil && len(s.hooks.OnError) > 0 {
+			if s.hooks != nil && len(s.hooks.OnErrorInfo) > 0 {
 				hooks := s.hooks
 				go func(sID string, hooks *Hooks) {
 					ctx := context.Background()
@@ -427,7 +492,7 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 		if err := s.SendNotificationToSpecificClient(sessionID, "notifications/tools/list_changed", nil); err != nil {
 			// Log the error but don't fail the operation
 			// The tools were successfully deleted, but notification failed
-			if s.hooks != nil && len(s.hooks.OnError) > 0 {
+			if s.hooks != nil && len(s.hooks.OnErrorInfo) > 0 {
 				hooks := s.hooks
 				go func(sID string, hooks *Hooks) {
 					ctx := context.Background()
@@ -442,3 +507,101 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 
 	return nil
 }
+
+// AddSessionPrompt adds a prompt for a specific session
+func (s *MCPServer) AddSessionPrompt(sessionID string, prompt mcp.Prompt, handler PromptHandlerFunc) error {
+	return s.AddSessionPrompts(sessionID, ServerPrompt{Prompt: prompt, Handler: handler})
+}
+
+// AddSessionPrompts adds prompts for a specific session. A session prompt
+// overrides a global prompt with the same name for that session only.
+func (s *MCPServer) AddSessionPrompts(sessionID string, prompts ...ServerPrompt) error {
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	session, ok := sessionValue.(SessionWithPrompts)
+	if !ok {
+		return ErrSessionDoesNotSupportPrompts
+	}
+
+	s.implicitlyRegisterPromptCapabilities()
+
+	// Get existing prompts (this should return a thread-safe copy)
+	sessionPrompts := session.GetSessionPrompts()
+
+	// Create a new map to avoid concurrent modification issues
+	newSessionPrompts := make(map[string]ServerPrompt, len(sessionPrompts)+len(prompts))
+	for k, v := range sessionPrompts {
+		newSessionPrompts[k] = v
+	}
+	for _, prompt := range prompts {
+		newSessionPrompts[prompt.Prompt.Name] = prompt
+	}
+
+	// Set the prompts (this should be thread-safe)
+	session.SetSessionPrompts(newSessionPrompts)
+
+	s.notifySessionPromptsChanged(session, "adding")
+	return nil
+}
+
+// DeleteSessionPrompts removes prompts from a specific session
+func (s *MCPServer) DeleteSessionPrompts(sessionID string, names ...string) error {
+	sessionValue, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	session, ok := sessionValue.(SessionWithPrompts)
+	if !ok {
+		return ErrSessionDoesNotSupportPrompts
+	}
+
+	// Get existing prompts (this should return a thread-safe copy)
+	sessionPrompts := session.GetSessionPrompts()
+	if sessionPrompts == nil {
+		return nil
+	}
+
+	// Create a new map to avoid concurrent modification issues
+	newSessionPrompts := make(map[string]ServerPrompt, len(sessionPrompts))
+	for k, v := range sessionPrompts {
+		newSessionPrompts[k] = v
+	}
+	for _, name := range names {
+		delete(newSessionPrompts, name)
+	}
+
+	// Set the prompts (this should be thread-safe)
+	session.SetSessionPrompts(newSessionPrompts)
+
+	s.notifySessionPromptsChanged(session, "deleting")
+	return nil
+}
+
+// notifySessionPromptsChanged sends notifications/prompts/list_changed to an
+// initialized session if the server declared prompts.listChanged.
+func (s *MCPServer) notifySessionPromptsChanged(session ClientSession, action string) {
+	s.capabilitiesMu.RLock()
+	listChanged := s.capabilities.prompts != nil && s.capabilities.prompts.listChanged
+	s.capabilitiesMu.RUnlock()
+	if !session.Initialized() || !listChanged {
+		return
+	}
+
+	sessionID := session.SessionID()
+	err := s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationPromptsListChanged, nil)
+	if err != nil && s.hooks != nil && len(s.hooks.OnErrorInfo) > 0 {
+		// Log the error but don't fail the operation
+		hooks := s.hooks
+		go func(sID string, hooks *Hooks) {
+			ctx := context.Background()
+			hooks.onError(ctx, nil, "notification", map[string]any{
+				"method":    mcp.MethodNotificationPromptsListChanged,
+				"sessionID": sID,
+			}, fmt.Errorf("failed to send notification after %s prompts: %w", action, err))
+		}(sessionID, hooks)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// sessionRequestTracker keeps the cancel functions of the requests each
+// session currently has in flight.
+type sessionRequestTracker struct {
+	mu       sync.Mutex
+	next     uint64
+	inFlight map[string]map[uint64]context.CancelCauseFunc
+}
+
+// track derives a cancellable context for a request of the session. The
+// returned function must be called once the request has been handled.
+func (t *sessionRequestTracker) track(ctx context.Context, sessionID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	t.mu.Lock()
+	if t.inFlight == nil {
+		t.inFlight = make(map[string]map[uint64]context.CancelCauseFunc)
+	}
+	requests, ok := t.inFlight[sessionID]
+	if !ok {
+		requests = make(map[uint64]context.CancelCauseFunc)
+		t.inFlight[sessionID] = requests
+	}
+	t.next++
+	id := t.next
+	requests[id] = cancel
+	t.mu.Unlock()
+
+	return ctx, func() {
+		t.mu.Lock()
+		if requests, ok := t.inFlight[sessionID]; ok {
+			delete(requests, id)
+			if len(requests) == 0 {
+				delete(t.inFlight, sessionID)
+			}
+		}
+		t.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel cancels every request of the session with the given cause and
+// reports whether there were any.
+func (t *sessionRequestTracker) cancel(sessionID string, cause error) bool {
+	t.mu.Lock()
+	requests, ok := t.inFlight[sessionID]
+	delete(t.inFlight, sessionID)
+	t.mu.Unlock()
+
+	for _, cancel := range requests {
+		cancel(cause)
+	}
+	return ok
+}
+
+// trackSessionRequest registers the request being handled on ctx so that
+// CancelSession can abort it.
+func (s *MCPServer) trackSessionRequest(ctx context.Context, sessionID string) (context.Context, func()) {
+	return s.sessionRequests.track(ctx, sessionID)
+}
+
+// sessionCloser is implemented by sessions whose transport keeps a stream
+// open to the client that the server can end.
+type sessionCloser interface {
+	closeSession()
+}
+
+// CancelSession cancels the contexts of all requests the session currently
+// has in flight and closes the session's stream to the client, if the
+// transport keeps one open. Cancelled contexts report ErrSessionCancelled as
+// their cause.
+//
+// The session stays registered, so a client may reconnect with the same
+// session ID. To keep it out, unregister the session or revoke its
+// credentials as well. ErrSessionNotFound is returned if the session is not
+// registered and has no requests in flight.
+func (s *MCPServer) CancelSession(sessionID string) error {
+	cancelled := s.sessionRequests.cancel(sessionID, ErrSessionCancelled)
+
+	value, ok := s.sessions.Load(sessionID)
+	if !ok {
+		if cancelled {
+			return nil
+		}
+		return ErrSessionNotFound
+	}
+	if closer, ok := value.(sessionCloser); ok {
+		closer.closeSession()
+	}
+	return nil
+}
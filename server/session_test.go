@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -100,6 +102,53 @@ func (f *sessionTestClientWithTools) SetSessionTools(tools map[string]ServerTool
 	f.sessionTools = toolsCopy
 }
 
+// sessionTestClientWithPrompts implements the SessionWithPrompts interface for testing
+type sessionTestClientWithPrompts struct {
+	sessionID           string
+	notificationChannel chan mcp.JSONRPCNotification
+	initialized         bool
+	sessionPrompts      map[string]ServerPrompt
+	mu                  sync.RWMutex // Mutex to protect concurrent access to sessionPrompts
+}
+
+func (f *sessionTestClientWithPrompts) SessionID() string {
+	return f.sessionID
+}
+
+func (f *sessionTestClientWithPrompts) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return f.notificationChannel
+}
+
+func (f *sessionTestClientWithPrompts) Initialize() {
+	f.initialized = true
+}
+
+func (f *sessionTestClientWithPrompts) Initialized() bool {
+	return f.initialized
+}
+
+func (f *sessionTestClientWithPrompts) GetSessionPrompts() map[string]ServerPrompt {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	promptsCopy := make(map[string]ServerPrompt, len(f.sessionPrompts))
+	for k, v := range f.sessionPrompts {
+		promptsCopy[k] = v
+	}
+	return promptsCopy
+}
+
+func (f *sessionTestClientWithPrompts) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	promptsCopy := make(map[string]ServerPrompt, len(prompts))
+	for k, v := range prompts {
+		promptsCopy[k] = v
+	}
+	f.sessionPrompts = promptsCopy
+}
+
 // sessionTestClientWithClientInfo implements the SessionWithClientInfo interface for testing
 type sessionTestClientWithClientInfo struct {
 	sessionID           string
@@ -805,6 +854,51 @@ func TestMCPServer_SendNotificationToSpecificClient(t *testing.T) {
 	assert.Contains(t, err.Error(), "not properly initialized")
 }
 
+func TestMCPServer_NotifyResourceUpdatedWithContents(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false))
+
+	sessionChan := make(chan mcp.JSONRPCNotification, 10)
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: sessionChan,
+	}
+	session.Initialize()
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	receive := func() mcp.JSONRPCNotification {
+		select {
+		case notification := <-sessionChan:
+			return notification
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("Expected resource updated notification")
+			return mcp.JSONRPCNotification{}
+		}
+	}
+
+	server.NotifyResourceUpdated("test://plain")
+	plain := receive()
+	assert.Equal(t, mcp.MethodNotificationResourceUpdated, plain.Method)
+	assert.Equal(t, "test://plain", plain.Params.AdditionalFields["uri"])
+	assert.NotContains(t, plain.Params.AdditionalFields, "contents")
+
+	server.NotifyResourceUpdatedWithContents("test://inline", []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: "test://inline", MIMEType: "text/plain", Text: "updated"},
+	})
+	inline := receive()
+
+	// The contents must survive the trip over the wire
+	data, err := json.Marshal(inline)
+	require.NoError(t, err)
+	var decoded mcp.JSONRPCNotification
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	params, err := mcp.ParseResourceUpdatedNotification(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, "test://inline", params.URI)
+	require.Len(t, params.Contents, 1)
+	assert.Equal(t, mcp.TextResourceContents{URI: "test://inline", MIMEType: "text/plain", Text: "updated"}, params.Contents[0])
+}
+
 func TestMCPServer_NotificationChannelBlocked(t *testing.T) {
 	// Set up a hooks object to capture error notifications
 	var mu sync.Mutex
@@ -1529,3 +1623,432 @@ func TestMCPServer_LoggingNotificationFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_SessionPrompts(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPromptCapabilities(true))
+	ctx := context.Background()
+
+	staticPrompt := func(text string) PromptHandlerFunc {
+		return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("", []mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+			}), nil
+		}
+	}
+
+	server.AddPrompt(mcp.NewPrompt("greeting", mcp.WithPromptDescription("global")), staticPrompt("hello from global"))
+	server.AddPrompt(mcp.NewPrompt("shared"), staticPrompt("shared"))
+
+	tenantA := &sessionTestClientWithPrompts{
+		sessionID:           "tenant-a",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	tenantB := &sessionTestClientWithPrompts{
+		sessionID:           "tenant-b",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(ctx, tenantA))
+	require.NoError(t, server.RegisterSession(ctx, tenantB))
+
+	require.NoError(t, server.AddSessionPrompt(tenantA.SessionID(),
+		mcp.NewPrompt("greeting", mcp.WithPromptDescription("tenant a")), staticPrompt("hello from tenant a")))
+	require.NoError(t, server.AddSessionPrompt(tenantB.SessionID(),
+		mcp.NewPrompt("greeting", mcp.WithPromptDescription("tenant b")), staticPrompt("hello from tenant b")))
+
+	// Each session is notified about its own override only
+	for _, session := range []*sessionTestClientWithPrompts{tenantA, tenantB} {
+		select {
+		case notification := <-session.notificationChannel:
+			assert.Equal(t, mcp.MethodNotificationPromptsListChanged, notification.Method)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("Expected notification for %s not received", session.SessionID())
+		}
+		select {
+		case notification := <-session.notificationChannel:
+			t.Errorf("Unexpected notification for %s: %v", session.SessionID(), notification.Method)
+		default:
+		}
+	}
+
+	getPromptText := func(session ClientSession) string {
+		response := server.HandleMessage(server.WithContext(ctx, session), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "prompts/get",
+			"params": {"name": "greeting"}
+		}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.GetPromptResult)
+		require.True(t, ok)
+		require.Len(t, result.Messages, 1)
+		return result.Messages[0].Content.(mcp.TextContent).Text
+	}
+
+	listPrompts := func(session ClientSession) map[string]string {
+		response := server.HandleMessage(server.WithContext(ctx, session), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 2,
+			"method": "prompts/list"
+		}`))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.ListPromptsResult)
+		require.True(t, ok)
+		descriptions := make(map[string]string, len(result.Prompts))
+		for _, prompt := range result.Prompts {
+			descriptions[prompt.Name] = prompt.Description
+		}
+		return descriptions
+	}
+
+	assert.Equal(t, "hello from tenant a", getPromptText(tenantA))
+	assert.Equal(t, "hello from tenant b", getPromptText(tenantB))
+
+	assert.Equal(t, map[string]string{"greeting": "tenant a", "shared": ""}, listPrompts(tenantA))
+	assert.Equal(t, map[string]string{"greeting": "tenant b", "shared": ""}, listPrompts(tenantB))
+
+	// Removing the override falls back to the global prompt
+	require.NoError(t, server.DeleteSessionPrompts(tenantA.SessionID(), "greeting"))
+	select {
+	case notification := <-tenantA.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationPromptsListChanged, notification.Method)
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Expected notification not received")
+	}
+	assert.Equal(t, "hello from global", getPromptText(tenantA))
+	assert.Equal(t, "hello from tenant b", getPromptText(tenantB))
+}
+
+func TestMCPServer_SessionPromptsUnsupported(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	err := server.AddSessionPrompt(session.SessionID(), mcp.NewPrompt("greeting"), nil)
+	assert.ErrorIs(t, err, ErrSessionDoesNotSupportPrompts)
+
+	err = server.AddSessionPrompt("missing", mcp.NewPrompt("greeting"), nil)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestMCPServer_CancelSession(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false))
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	server.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	sessionCtx := server.WithContext(context.Background(), session)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.HandleMessage(sessionCtx, []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "block"}
+		}`))
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("tool handler did not start")
+	}
+
+	require.NoError(t, server.CancelSession(session.SessionID()))
+
+	select {
+	case cause := <-causes:
+		assert.ErrorIs(t, cause, ErrSessionCancelled)
+	case <-time.After(time.Second):
+		t.Fatal("tool handler was not cancelled")
+	}
+	<-done
+
+	// The session stays registered after being cancelled
+	assert.NoError(t, server.CancelSession(session.SessionID()))
+	assert.ErrorIs(t, server.CancelSession("unknown"), ErrSessionNotFound)
+}
+
+func TestMCPServer_CancelSessionClosesStream(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &sseSession{
+		done:                make(chan struct{}),
+		eventQueue:          make(chan sseEvent, 10),
+		sessionID:           "sse-session",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	require.NoError(t, server.CancelSession(session.SessionID()))
+	select {
+	case <-session.done:
+	default:
+		t.Fatal("expected session stream to be closed")
+	}
+
+	// Closing twice must not panic
+	require.NoError(t, server.CancelSession(session.SessionID()))
+}
+
+func TestMCPServer_NotificationOrdering(t *testing.T) {
+	for _, window := range []time.Duration{0, 10 * time.Millisecond} {
+		t.Run(fmt.Sprintf("window %s", window), func(t *testing.T) {
+			server := NewMCPServer("test-server", "1.0.0", WithNotificationCoalescing(window))
+
+			const count = 50
+			sessions := make([]*sessionTestClient, 2)
+			for i := range sessions {
+				sessions[i] = &sessionTestClient{
+					sessionID:           fmt.Sprintf("session-%d", i),
+					notificationChannel: make(chan mcp.JSONRPCNotification, 4*count),
+					initialized:         true,
+				}
+				require.NoError(t, server.RegisterSession(context.Background(), sessions[i]))
+			}
+
+			// Interleave broadcasts with notifications for the first session only
+			for i := 0; i < count; i++ {
+				server.SendNotificationToAllClients("broadcast", map[string]any{"seq": i})
+				require.NoError(t, server.SendNotificationToSpecificClient(sessions[0].SessionID(), "direct", map[string]any{"seq": i}))
+			}
+
+			receive := func(session *sessionTestClient, n int) []string {
+				var got []string
+				for len(got) < n {
+					select {
+					case notification := <-session.notificationChannel:
+						got = append(got, fmt.Sprintf("%s:%v", notification.Method, notification.Params.AdditionalFields["seq"]))
+					case <-time.After(time.Second):
+						t.Fatalf("session %s received %d of %d notifications", session.SessionID(), len(got), n)
+					}
+				}
+				return got
+			}
+
+			var wantFirst, wantSecond []string
+			for i := 0; i < count; i++ {
+				wantFirst = append(wantFirst, fmt.Sprintf("broadcast:%d", i), fmt.Sprintf("direct:%d", i))
+				wantSecond = append(wantSecond, fmt.Sprintf("broadcast:%d", i))
+			}
+			assert.Equal(t, wantFirst, receive(sessions[0], 2*count))
+			assert.Equal(t, wantSecond, receive(sessions[1], count))
+		})
+	}
+}
+
+func TestMCPServer_NotificationCoalescing(t *testing.T) {
+	const window = 50 * time.Millisecond
+	server := NewMCPServer("test-server", "1.0.0", WithNotificationCoalescing(window))
+
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 20),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	for i := 0; i < 5; i++ {
+		server.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+	server.SendNotificationToAllClients("progress", map[string]any{"value": 1})
+	server.SendNotificationToAllClients("progress", map[string]any{"value": 2})
+	server.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	server.SendNotificationToAllClients("progress", map[string]any{"value": 1})
+
+	collect := func() []string {
+		var got []string
+		for {
+			select {
+			case notification := <-session.notificationChannel:
+				got = append(got, fmt.Sprintf("%s%v", notification.Method, notification.Params.AdditionalFields["value"]))
+			case <-time.After(2 * window):
+				return got
+			}
+		}
+	}
+
+	// Identical notifications are collapsed into the last one, and
+	// notifications with different params are kept
+	assert.Equal(t, []string{
+		"progress2",
+		mcp.MethodNotificationToolsListChanged + "<nil>",
+		"progress1",
+	}, collect())
+
+	// Once delivered, the same notification is sent again
+	server.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	assert.Equal(t, []string{mcp.MethodNotificationToolsListChanged + "<nil>"}, collect())
+}
+
+func TestMCPServer_NotificationCoalescingRequestNotifications(t *testing.T) {
+	const window = 20 * time.Millisecond
+	server := NewMCPServer("test-server", "1.0.0", WithNotificationCoalescing(window))
+
+	session := &sessionTestClient{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 20),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	progress := map[string]any{"progressToken": "token", "progress": 1}
+	cancelled := map[string]any{"requestId": 1}
+	for i := 0; i < 2; i++ {
+		server.SendNotificationToAllClients("notifications/progress", progress)
+		server.SendNotificationToAllClients(mcp.MethodNotificationCancelled, cancelled)
+	}
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/message",
+			Params: mcp.NotificationParams{
+				Meta:             map[string]any{"progressToken": "token"},
+				AdditionalFields: map[string]any{"data": "step"},
+			},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, server.queueNotification(context.Background(), session, notification))
+	}
+
+	var got []string
+	for len(got) < 6 {
+		select {
+		case notification := <-session.notificationChannel:
+			got = append(got, notification.Method)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of 6 notifications: %v", len(got), got)
+		}
+	}
+	assert.Equal(t, []string{
+		"notifications/progress",
+		mcp.MethodNotificationCancelled,
+		"notifications/progress",
+		mcp.MethodNotificationCancelled,
+		"notifications/message",
+		"notifications/message",
+	}, got)
+}
+
+func TestMCPServer_NotificationQueueUnregister(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithNotificationCoalescing(time.Millisecond))
+	notification := mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: "test"},
+	}
+
+	for i := 0; i < 25; i++ {
+		session := &sessionTestClient{
+			sessionID:           fmt.Sprintf("session-%d", i),
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+			initialized:         true,
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+
+		// Notifications in flight while the session goes away must not
+		// leave a queue behind
+		stop, sent := make(chan struct{}), make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = server.queueNotification(context.Background(), session, notification)
+			close(sent)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_ = server.queueNotification(context.Background(), session, notification)
+				}
+			}
+		}()
+		<-sent
+		server.UnregisterSession(context.Background(), session.SessionID())
+		close(stop)
+		wg.Wait()
+	}
+
+	server.notificationQueues.Range(func(key, _ any) bool {
+		t.Errorf("queue left behind for unregistered session %v", key)
+		return true
+	})
+}
+
+func TestProtocolVersionFromContext(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0")
+	var got string
+	srv.AddTool(mcp.NewTool("version"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = ProtocolVersionFromContext(ctx)
+		return mcp.NewToolResultText(got), nil
+	})
+
+	session := NewInProcessSession("protocol-version", nil)
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	ctx := srv.WithContext(context.Background(), session)
+
+	assert.Empty(t, ProtocolVersionFromContext(ctx), "no version before initialize")
+
+	initialize := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"clientInfo":{"name":"c","version":"1"}}}`, "2025-03-26")
+	require.IsType(t, mcp.JSONRPCResponse{}, srv.HandleMessage(ctx, []byte(initialize)))
+	assert.Equal(t, "2025-03-26", session.GetProtocolVersion())
+
+	response := srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"version"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	assert.Equal(t, "2025-03-26", got)
+
+	// Without a session storing it, the version comes from the request header
+	headers := http.Header{}
+	headers.Set(HeaderKeyProtocolVersion, mcp.LATEST_PROTOCOL_VERSION)
+	headerCtx := context.WithValue(context.Background(), requestHeader, headers)
+	assert.Equal(t, mcp.LATEST_PROTOCOL_VERSION, ProtocolVersionFromContext(headerCtx))
+
+	headers.Set(HeaderKeyProtocolVersion, "1999-01-01")
+	assert.Equal(t, "2025-03-26", ProtocolVersionFromContext(headerCtx), "unsupported versions are ignored")
+
+	headers.Del(HeaderKeyProtocolVersion)
+	assert.Equal(t, "2025-03-26", ProtocolVersionFromContext(headerCtx), "HTTP requests without a header use 2025-03-26")
+}
+
+func TestClientInfoFromContext(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0")
+	var got mcp.Implementation
+	srv.AddTool(mcp.NewTool("client"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = ClientInfoFromContext(ctx)
+		return mcp.NewToolResultText(got.Name), nil
+	})
+
+	session := NewInProcessSession("client-info", nil)
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+	ctx := srv.WithContext(context.Background(), session)
+
+	assert.Empty(t, ClientInfoFromContext(ctx), "no client info before initialize")
+	assert.Empty(t, ClientInfoFromContext(context.Background()), "no client info without a session")
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"buggy-client","version":"0.9.1"}}}`
+	require.IsType(t, mcp.JSONRPCResponse{}, srv.HandleMessage(ctx, []byte(initialize)))
+
+	response := srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"client"}}`))
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	assert.Equal(t, mcp.Implementation{Name: "buggy-client", Version: "0.9.1"}, got)
+}
@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -22,15 +24,36 @@ import (
 // sseSession represents an active SSE connection.
 type sseSession struct {
 	done                chan struct{}
-	eventQueue          chan string // Channel for queuing events
+	closeOnce           sync.Once
+	eventQueue          chan sseEvent // Channel for queuing events
 	sessionID           string
 	requestID           atomic.Int64
 	notificationChannel chan mcp.JSONRPCNotification
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
 	tools               sync.Map     // stores session-specific tools
+	prompts             sync.Map     // stores session-specific prompts
 	clientInfo          atomic.Value // stores session-specific client info
 	clientCapabilities  atomic.Value // stores session-specific client capabilities
+	protocolVersion     atomic.Value // stores the negotiated protocol version
+	// deferNotificationFlush lets notifications be batched, see
+	// WithSSEBatchFlush.
+	deferNotificationFlush bool
+}
+
+// sseInternalErrorEvent is sent in place of a response that cannot be
+// marshaled.
+const sseInternalErrorEvent = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
+
+// sseEvent is a formatted event waiting to be written to the SSE stream.
+type sseEvent struct {
+	data string
+	// message, if set, is encoded as a "message" event when the event is
+	// written, instead of data.
+	message any
+	// deferFlush allows the event to be batched with following events
+	// instead of being flushed right away.
+	deferFlush bool
 }
 
 // SSEContextFunc is a function that takes an existing context and the current
@@ -53,6 +76,32 @@ func (s *sseSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
 	return s.notificationChannel
 }
 
+// writeNotification queues the notification on the event stream, in the
+// same queue as responses, so that a response follows the notifications its
+// handler sent before returning.
+func (s *sseSession) writeNotification(notification mcp.JSONRPCNotification) error {
+	select {
+	case <-s.done:
+		return ErrSessionNotFound
+	default:
+	}
+	select {
+	case s.eventQueue <- sseEvent{message: notification, deferFlush: s.deferNotificationFlush}:
+		return nil
+	default:
+		return ErrNotificationChannelBlocked
+	}
+}
+
+var _ notificationWriter = (*sseSession)(nil)
+
+// closeSession ends the event stream of the session.
+func (s *sseSession) closeSession() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
 func (s *sseSession) Initialize() {
 	// set default logging level
 	s.loggingLevel.Store(mcp.LoggingLevelError)
@@ -96,6 +145,27 @@ func (s *sseSession) SetSessionTools(tools map[string]ServerTool) {
 	}
 }
 
+func (s *sseSession) GetSessionPrompts() map[string]ServerPrompt {
+	prompts := make(map[string]ServerPrompt)
+	s.prompts.Range(func(key, value any) bool {
+		if prompt, ok := value.(ServerPrompt); ok {
+			prompts[key.(string)] = prompt
+		}
+		return true
+	})
+	return prompts
+}
+
+func (s *sseSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	// Clear existing prompts
+	s.prompts.Clear()
+
+	// Set new prompts
+	for name, prompt := range prompts {
+		s.prompts.Store(name, prompt)
+	}
+}
+
 func (s *sseSession) GetClientInfo() mcp.Implementation {
 	if value := s.clientInfo.Load(); value != nil {
 		if clientInfo, ok := value.(mcp.Implementation); ok {
@@ -122,11 +192,24 @@ func (s *sseSession) GetClientCapabilities() mcp.ClientCapabilities {
 	return mcp.ClientCapabilities{}
 }
 
+func (s *sseSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *sseSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 var (
-	_ ClientSession         = (*sseSession)(nil)
-	_ SessionWithTools      = (*sseSession)(nil)
-	_ SessionWithLogging    = (*sseSession)(nil)
-	_ SessionWithClientInfo = (*sseSession)(nil)
+	_ ClientSession              = (*sseSession)(nil)
+	_ SessionWithTools           = (*sseSession)(nil)
+	_ SessionWithPrompts         = (*sseSession)(nil)
+	_ SessionWithLogging         = (*sseSession)(nil)
+	_ SessionWithClientInfo      = (*sseSession)(nil)
+	_ SessionWithProtocolVersion = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -146,6 +229,13 @@ type SSEServer struct {
 
 	keepAlive         bool
 	keepAliveInterval time.Duration
+	inlineResponses   bool
+
+	publicBaseURL     string
+	trustProxyHeaders bool
+
+	batchFlushCount    int
+	batchFlushInterval time.Duration
 
 	mu sync.RWMutex
 }
@@ -176,6 +266,52 @@ func WithBaseURL(baseURL string) SSEOption {
 	}
 }
 
+// WithPublicBaseURL sets the externally visible base URL advertised to clients
+// in the endpoint event, e.g. "https://api.example.com/tools". Unlike
+// WithBaseURL it does not affect routing, so it can be used when the server is
+// reachable under a different scheme, host or path prefix behind a reverse
+// proxy. It takes precedence over WithTrustProxyHeaders and WithBaseURL.
+//
+// The URL must be an absolute http or https URL without a query. An invalid
+// URL is logged when the server is created and otherwise ignored.
+func WithPublicBaseURL(publicBaseURL string) SSEOption {
+	return func(s *SSEServer) {
+		if err := validatePublicBaseURL(publicBaseURL); err != nil {
+			log.Printf("ignoring public base URL %q: %v", publicBaseURL, err)
+			return
+		}
+		s.publicBaseURL = strings.TrimSuffix(publicBaseURL, "/")
+	}
+}
+
+func validatePublicBaseURL(publicBaseURL string) error {
+	u, err := url.Parse(publicBaseURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" || strings.HasPrefix(u.Host, ":") {
+		return fmt.Errorf("missing host")
+	}
+	if u.RawQuery != "" {
+		return fmt.Errorf("must not have a query")
+	}
+	return nil
+}
+
+// WithTrustProxyHeaders makes the SSE server build the advertised message
+// endpoint from the Forwarded, X-Forwarded-Proto and X-Forwarded-Host request
+// headers when present. Only enable this when the server is reachable solely
+// through a proxy that sets or strips these headers, as clients can otherwise
+// spoof them.
+func WithTrustProxyHeaders() SSEOption {
+	return func(s *SSEServer) {
+		s.trustProxyHeaders = true
+	}
+}
+
 // WithStaticBasePath adds a new option for setting a static base path
 func WithStaticBasePath(basePath string) SSEOption {
 	return func(s *SSEServer) {
@@ -263,6 +399,43 @@ func WithKeepAlive(keepAlive bool) SSEOption {
 	}
 }
 
+// defaultSSEBatchFlushInterval bounds how long batched notifications wait
+// when WithSSEBatchFlush is given only a count.
+const defaultSSEBatchFlushInterval = 50 * time.Millisecond
+
+// WithSSEBatchFlush batches notifications written to the event stream instead
+// of flushing after every event. Pending notifications are flushed once count
+// of them have accumulated or interval has passed since the first one,
+// whichever comes first. Responses and pings are always flushed immediately,
+// together with any pending notifications. A non-positive count flushes by
+// interval only; a non-positive interval defaults to 50ms. Batching is
+// disabled if both are non-positive.
+func WithSSEBatchFlush(count int, interval time.Duration) SSEOption {
+	return func(s *SSEServer) {
+		if count <= 0 && interval <= 0 {
+			s.batchFlushCount = 0
+			s.batchFlushInterval = 0
+			return
+		}
+		if interval <= 0 {
+			interval = defaultSSEBatchFlushInterval
+		}
+		s.batchFlushCount = count
+		s.batchFlushInterval = interval
+	}
+}
+
+// WithInlineResponses allows clients that can't consume the event stream to
+// receive responses in the body of their message POST. When enabled, a POST
+// whose Accept header includes application/json is handled synchronously and
+// answered with the JSON-RPC response, while notifications keep flowing over
+// the SSE stream. Requests answered inline can't trigger sampling.
+func WithInlineResponses() SSEOption {
+	return func(s *SSEServer) {
+		s.inlineResponses = true
+	}
+}
+
 // WithSSEContextFunc sets a function that will be called to customise the context
 // to the server using the incoming request.
 func WithSSEContextFunc(fn SSEContextFunc) SSEOption {
@@ -327,11 +500,12 @@ func (s *SSEServer) Shutdown(ctx context.Context) error {
 	s.mu.RLock()
 	srv := s.srv
 	s.mu.RUnlock()
+	defer s.server.runShutdownHooks(ctx)
 
 	if srv != nil {
 		s.sessions.Range(func(key, value any) bool {
 			if session, ok := value.(*sseSession); ok {
-				close(session.done)
+				session.closeSession()
 			}
 			s.sessions.Delete(key)
 			return true
@@ -363,10 +537,11 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := uuid.New().String()
 	session := &sseSession{
-		done:                make(chan struct{}),
-		eventQueue:          make(chan string, 100), // Buffer for events
-		sessionID:           sessionID,
-		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		done:                   make(chan struct{}),
+		eventQueue:             make(chan sseEvent, 100), // Buffer for events
+		sessionID:              sessionID,
+		notificationChannel:    make(chan mcp.JSONRPCNotification, 100),
+		deferNotificationFlush: s.batchFlushInterval > 0,
 	}
 
 	s.sessions.Store(sessionID, session)
@@ -382,7 +557,8 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 	defer s.server.UnregisterSession(r.Context(), sessionID)
 
-	// Start notification handler for this session
+	// Notifications are normally queued by writeNotification; this forwards
+	// those written to the notification channel directly
 	go func() {
 		for {
 			select {
@@ -390,7 +566,10 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 				eventData, err := json.Marshal(notification)
 				if err == nil {
 					select {
-					case session.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
+					case session.eventQueue <- sseEvent{
+						data:       fmt.Sprintf("event: message\ndata: %s\n\n", eventData),
+						deferFlush: s.batchFlushInterval > 0,
+					}:
 						// Event queued successfully
 					case <-session.done:
 						return
@@ -422,7 +601,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 					messageBytes, _ := json.Marshal(message)
 					pingMsg := fmt.Sprintf("event: message\ndata:%s\n\n", messageBytes)
 					select {
-					case session.eventQueue <- pingMsg:
+					case session.eventQueue <- sseEvent{data: pingMsg}:
 						// Message sent successfully
 					case <-session.done:
 						return
@@ -444,15 +623,66 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "event: endpoint\ndata: %s\r\n\r\n", endpoint)
 	flusher.Flush()
 
+	// Pending batched notifications are flushed when the timer fires
+	pending := 0
+	var flushTimer *time.Timer
+	var flushTimeout <-chan time.Time
+	flush := func() {
+		flusher.Flush()
+		pending = 0
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer = nil
+			flushTimeout = nil
+		}
+	}
+	defer func() {
+		if flushTimer != nil {
+			flushTimer.Stop()
+		}
+	}()
+
 	// Main event loop - this runs in the HTTP handler goroutine
 	for {
 		select {
 		case event := <-session.eventQueue:
 			// Write the event to the response
-			fmt.Fprint(w, event)
-			flusher.Flush()
+			var err error
+			if event.message == nil {
+				_, err = fmt.Fprint(w, event.data)
+			} else if err = encodeSSEEvent(w, event.message); err != nil {
+				var writeErr *sseWriteError
+				if !errors.As(err, &writeErr) {
+					// If there is an error marshalling the response, send a generic error response
+					log.Printf("failed to marshal response: %v", err)
+					_, err = fmt.Fprint(w, sseInternalErrorEvent)
+				}
+			}
+			if err != nil {
+				// The client is gone, nothing more can be sent to it
+				log.Printf("failed to write event: %v", err)
+				session.closeSession()
+				return
+			}
+			if !event.deferFlush {
+				flush()
+				continue
+			}
+			pending++
+			if s.batchFlushCount > 0 && pending >= s.batchFlushCount {
+				flush()
+				continue
+			}
+			if flushTimer == nil {
+				flushTimer = time.NewTimer(s.batchFlushInterval)
+				flushTimeout = flushTimer.C
+			}
+		case <-flushTimeout:
+			flushTimer = nil
+			flushTimeout = nil
+			flush()
 		case <-r.Context().Done():
-			close(session.done)
+			session.closeSession()
 			return
 		case <-session.done:
 			return
@@ -470,13 +700,92 @@ func (s *SSEServer) GetMessageEndpointForClient(r *http.Request, sessionID strin
 	}
 
 	endpointPath := normalizeURLPath(basePath, s.messageEndpoint)
-	if s.useFullURLForMessageEndpoint && s.baseURL != "" {
-		endpointPath = s.baseURL + endpointPath
+	if s.useFullURLForMessageEndpoint {
+		endpointPath = s.advertisedBaseURL(r) + endpointPath
 	}
 
 	return fmt.Sprintf("%s?sessionId=%s", endpointPath, sessionID)
 }
 
+// advertisedBaseURL returns the scheme, host and optional path prefix the
+// message endpoint is advertised under for the given request.
+func (s *SSEServer) advertisedBaseURL(r *http.Request) string {
+	if s.publicBaseURL != "" {
+		return s.publicBaseURL
+	}
+	if s.trustProxyHeaders && r != nil {
+		if forwarded := forwardedBaseURL(r); forwarded != "" {
+			return forwarded
+		}
+	}
+	return s.baseURL
+}
+
+// forwardedBaseURL builds "scheme://host" from the proxy headers of r. The
+// standard Forwarded header takes precedence over the X-Forwarded-* headers.
+// It returns an empty string if no proxy headers are set or they are invalid.
+func forwardedBaseURL(r *http.Request) string {
+	proto, host := parseForwardedHeader(r.Header.Get("Forwarded"))
+	if proto == "" {
+		proto = firstHeaderValue(r.Header.Get("X-Forwarded-Proto"))
+	}
+	if host == "" {
+		host = firstHeaderValue(r.Header.Get("X-Forwarded-Host"))
+	}
+	if proto == "" && host == "" {
+		return ""
+	}
+
+	if proto == "" {
+		proto = "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+	}
+	proto = strings.ToLower(proto)
+	if proto != "http" && proto != "https" {
+		return ""
+	}
+	if host == "" {
+		host = r.Host
+	}
+
+	u, err := url.Parse(proto + "://" + host)
+	if err != nil || u.Host != host || u.Path != "" || u.User != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// parseForwardedHeader extracts the proto and host parameters from the first
+// element of an RFC 7239 Forwarded header.
+func parseForwardedHeader(header string) (proto, host string) {
+	if header == "" {
+		return "", ""
+	}
+	first, _, _ := strings.Cut(header, ",")
+	for _, pair := range strings.Split(first, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(key) {
+		case "proto":
+			proto = value
+		case "host":
+			host = value
+		}
+	}
+	return proto, host
+}
+
+// firstHeaderValue returns the first entry of a comma-separated header value.
+func firstHeaderValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
+
 // handleMessage processes incoming JSON-RPC messages from clients and sends responses
 // back through the SSE connection and 202 code to HTTP response.
 func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
@@ -499,6 +808,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
+	ctx = WithTransportType(ctx, TransportSSE)
 	if s.contextFunc != nil {
 		ctx = s.contextFunc(ctx, r)
 	}
@@ -510,6 +820,29 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	release, errResponse := s.server.acquireRequestSlot(sessionID, rawMessage)
+
+	if s.inlineResponses && acceptsJSON(r) {
+		defer release()
+		response := errResponse
+		if response == nil {
+			ctx = context.WithValue(ctx, requestHeader, r.Header)
+			ctx = context.WithValue(ctx, inlineResponse, true)
+			response = s.server.HandleMessage(ctx, rawMessage)
+		}
+		if response == nil {
+			// Notifications and responses have no reply
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("failed to write inline response: %v", err)
+		}
+		return
+	}
+
 	// Create a context that preserves all values from parent ctx but won't be canceled when the parent is canceled.
 	// this is required because the http ctx will be canceled when the client disconnects
 	detachedCtx := context.WithoutCancel(ctx)
@@ -523,34 +856,43 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 
 	go func(ctx context.Context) {
 		defer cancel()
+		defer release()
 		// Use the context that will be canceled when session is done
-		// Process message through MCPServer
-		response := s.server.HandleMessage(ctx, rawMessage)
+		// Process message through MCPServer, unless the session is over its
+		// concurrent request limit
+		response := errResponse
+		if response == nil {
+			response = s.server.HandleMessage(ctx, rawMessage)
+		}
 		// Only send response if there is one (not for notifications)
 		if response != nil {
-			var message string
-			if eventData, err := json.Marshal(response); err != nil {
-				// If there is an error marshalling the response, send a generic error response
-				log.Printf("failed to marshal response: %v", err)
-				message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
-			} else {
-				message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
-			}
-
-			// Queue the event for sending via SSE
+			// Queue the event for sending via SSE, after the notifications
+			// the handler sent. The response is encoded when the event is
+			// written. Wait for room rather than drop it, as the client is
+			// waiting for it.
 			select {
-			case session.eventQueue <- message:
+			case session.eventQueue <- sseEvent{message: response}:
 				// Event queued successfully
 			case <-session.done:
 				// Session is closed, don't try to queue
-			default:
-				// Queue is full, log this situation
-				log.Printf("Event queue full for session %s", sessionID)
 			}
 		}
 	}(messageCtx)
 }
 
+// acceptsJSON reports whether the request's Accept header allows a plain JSON response.
+func acceptsJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == "application/json" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // writeJSONRPCError writes a JSON-RPC error response with the given error details.
 func (s *SSEServer) writeJSONRPCError(
 	w http.ResponseWriter,
@@ -590,7 +932,7 @@ func (s *SSEServer) SendEventToSession(
 
 	// Queue the event for sending via SSE
 	select {
-	case session.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
+	case session.eventQueue <- sseEvent{data: fmt.Sprintf("event: message\ndata: %s\n\n", eventData)}:
 		return nil
 	case <-session.done:
 		return fmt.Errorf("session closed")
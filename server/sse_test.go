@@ -7,9 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -1606,6 +1610,249 @@ func TestSSEServer(t *testing.T) {
 			t.Error("Headers check hook was not called within timeout")
 		}
 	})
+	t.Run("Inline responses for clients accepting JSON", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0.0")
+		mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("echoed"), nil
+		})
+		mcpServer.AddTool(mcp.NewTool("sample"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, err := ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{})
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText("sampled"), nil
+		})
+
+		testServer := NewTestServer(mcpServer, WithInlineResponses())
+		defer testServer.Close()
+
+		sseResp, err := http.Get(fmt.Sprintf("%s/sse", testServer.URL))
+		require.NoError(t, err, "Failed to connect to SSE endpoint")
+		defer sseResp.Body.Close()
+
+		endpointEvent, err := readSSEEvent(sseResp)
+		require.NoError(t, err, "Failed to read SSE response")
+		messageURL := strings.TrimSpace(
+			strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0],
+		)
+
+		post := func(message map[string]any, accept string) *http.Response {
+			body, err := json.Marshal(message)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, messageURL, bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if accept != "" {
+				req.Header.Set("Accept", accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			return resp
+		}
+
+		postInline := func(message map[string]any) map[string]any {
+			resp := post(message, "application/json")
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			var response map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			return response
+		}
+
+		initResponse := postInline(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "initialize",
+			"params": map[string]any{
+				"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+				"clientInfo": map[string]any{
+					"name":    "test-client",
+					"version": "1.0.0",
+				},
+			},
+		})
+		require.Equal(t, float64(1), initResponse["id"])
+		require.NotNil(t, initResponse["result"])
+
+		callResponse := postInline(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      2,
+			"method":  "tools/call",
+			"params": map[string]any{
+				"name": "echo",
+			},
+		})
+		require.Equal(t, float64(2), callResponse["id"])
+		result := callResponse["result"].(map[string]any)
+		content := result["content"].([]any)[0].(map[string]any)
+		require.Equal(t, "echoed", content["text"])
+
+		// Sampling can't be delivered to a client waiting for an inline response
+		sampleResponse := postInline(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      3,
+			"method":  "tools/call",
+			"params": map[string]any{
+				"name": "sample",
+			},
+		})
+		result = sampleResponse["result"].(map[string]any)
+		require.Equal(t, true, result["isError"])
+		content = result["content"].([]any)[0].(map[string]any)
+		require.Equal(t, ErrSamplingUnavailableInline.Error(), content["text"])
+
+		// Without the JSON accept header the response is delivered over SSE.
+		// It must be the first message event, proving the inline requests
+		// were not echoed on the stream.
+		resp := post(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      4,
+			"method":  "ping",
+		}, "")
+		resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		event, err := readSSEEvent(sseResp)
+		require.NoError(t, err)
+		require.Contains(t, event, "event: message")
+		data := strings.TrimSpace(strings.Split(strings.Split(event, "data: ")[1], "\n")[0])
+		var pingResponse map[string]any
+		require.NoError(t, json.Unmarshal([]byte(data), &pingResponse))
+		require.Equal(t, float64(4), pingResponse["id"])
+	})
+}
+
+func TestSSEServer_AdvertisedEndpointBehindProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []SSEOption
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "proxy headers ignored unless trusted",
+			opts:     []SSEOption{WithBaseURL("http://internal:8080")},
+			headers:  map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com"},
+			expected: "http://internal:8080/message?sessionId=abc",
+		},
+		{
+			name:     "X-Forwarded headers",
+			opts:     []SSEOption{WithBaseURL("http://internal:8080"), WithTrustProxyHeaders()},
+			headers:  map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com, proxy.local"},
+			expected: "https://api.example.com/message?sessionId=abc",
+		},
+		{
+			name:     "Forwarded header takes precedence",
+			opts:     []SSEOption{WithTrustProxyHeaders()},
+			headers:  map[string]string{"Forwarded": `for=192.0.2.60;proto=https;host="mcp.example.com:8443", for=10.0.0.1`, "X-Forwarded-Host": "other.example.com"},
+			expected: "https://mcp.example.com:8443/message?sessionId=abc",
+		},
+		{
+			name:     "forwarded proto only keeps request host",
+			opts:     []SSEOption{WithTrustProxyHeaders()},
+			headers:  map[string]string{"X-Forwarded-Proto": "https"},
+			expected: "https://internal:8080/message?sessionId=abc",
+		},
+		{
+			name:     "invalid forwarded host falls back to base URL",
+			opts:     []SSEOption{WithBaseURL("http://internal:8080"), WithTrustProxyHeaders()},
+			headers:  map[string]string{"X-Forwarded-Host": "evil.example.com/path"},
+			expected: "http://internal:8080/message?sessionId=abc",
+		},
+		{
+			name:     "public base URL overrides everything",
+			opts:     []SSEOption{WithBaseURL("http://internal:8080"), WithTrustProxyHeaders(), WithPublicBaseURL("https://gateway.example.com/tools/")},
+			headers:  map[string]string{"X-Forwarded-Host": "api.example.com"},
+			expected: "https://gateway.example.com/tools/message?sessionId=abc",
+		},
+		{
+			name:     "custom endpoints and base path",
+			opts:     []SSEOption{WithPublicBaseURL("https://gateway.example.com"), WithStaticBasePath("/mcp"), WithSSEEndpoint("/events"), WithMessageEndpoint("/rpc")},
+			expected: "https://gateway.example.com/mcp/rpc?sessionId=abc",
+		},
+		{
+			name:     "invalid public base URL is ignored",
+			opts:     []SSEOption{WithBaseURL("http://internal:8080"), WithPublicBaseURL("gateway.example.com/tools")},
+			expected: "http://internal:8080/message?sessionId=abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sseServer := NewSSEServer(NewMCPServer("test", "1.0.0"), tt.opts...)
+			req := httptest.NewRequest(http.MethodGet, "http://internal:8080/sse", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			require.Equal(t, tt.expected, sseServer.GetMessageEndpointForClient(req, "abc"))
+		})
+	}
+
+	t.Run("invalid public base URL is logged", func(t *testing.T) {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		NewSSEServer(NewMCPServer("test", "1.0.0"), WithPublicBaseURL("ftp://gateway.example.com"))
+		require.Contains(t, logs.String(), `ignoring public base URL "ftp://gateway.example.com"`)
+	})
+
+	t.Run("round trip through reverse proxy", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0.0")
+		sseServer := NewSSEServer(mcpServer,
+			WithBaseURL("http://internal:8080"),
+			WithTrustProxyHeaders(),
+			WithSSEEndpoint("/events"),
+			WithMessageEndpoint("/rpc"),
+		)
+		backend := httptest.NewServer(sseServer)
+		defer backend.Close()
+
+		backendURL, err := url.Parse(backend.URL)
+		require.NoError(t, err)
+		proxy := httptest.NewServer(&httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.SetURL(backendURL)
+				pr.SetXForwarded()
+			},
+			FlushInterval: -1,
+		})
+		defer proxy.Close()
+
+		sseResp, err := http.Get(proxy.URL + "/events")
+		require.NoError(t, err)
+		defer sseResp.Body.Close()
+
+		endpointEvent, err := readSSEEvent(sseResp)
+		require.NoError(t, err)
+		messageURL := strings.TrimSpace(
+			strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0],
+		)
+		require.True(t, strings.HasPrefix(messageURL, proxy.URL+"/rpc?sessionId="),
+			"expected endpoint advertised through the proxy, got %s", messageURL)
+
+		parsed, err := url.Parse(messageURL)
+		require.NoError(t, err)
+		sessionID := parsed.Query().Get("sessionId")
+		_, ok := sseServer.sessions.Load(sessionID)
+		require.True(t, ok, "session ID in the advertised URL should match a live session")
+
+		body, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "ping",
+		})
+		require.NoError(t, err)
+		resp, err := http.Post(messageURL, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		responseEvent, err := readSSEEvent(sseResp)
+		require.NoError(t, err)
+		require.Contains(t, responseEvent, `"id":1`)
+	})
 }
 
 func readSSEEvent(sseResp *http.Response) (string, error) {
@@ -1629,3 +1876,215 @@ func addHeaderVerificationTool(mcpServer *MCPServer) {
 		},
 	)
 }
+
+// flushRecorder is a streaming http.ResponseWriter that records what has been
+// flushed to the client.
+type flushRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	buf     bytes.Buffer
+	flushed string
+	flushes int
+}
+
+func (f *flushRecorder) Header() http.Header { return f.header }
+
+func (f *flushRecorder) WriteHeader(int) {}
+
+func (f *flushRecorder) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buf.Write(p)
+}
+
+func (f *flushRecorder) Flush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushed = f.buf.String()
+	f.flushes++
+}
+
+func (f *flushRecorder) state() (string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushed, f.flushes
+}
+
+func TestSSEServer_BatchFlush(t *testing.T) {
+	// serve runs the SSE handler and returns the recorder and the session
+	serve := func(t *testing.T, opts ...SSEOption) (*SSEServer, *flushRecorder, *sseSession) {
+		t.Helper()
+		sseServer := NewSSEServer(NewMCPServer("test", "1.0.0"), opts...)
+		recorder := &flushRecorder{header: make(http.Header)}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req := httptest.NewRequest(http.MethodGet, "/sse", nil).WithContext(ctx)
+			sseServer.handleSSE(recorder, req)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+
+		var session *sseSession
+		require.Eventually(t, func() bool {
+			sseServer.sessions.Range(func(_, value any) bool {
+				session = value.(*sseSession)
+				return false
+			})
+			_, flushes := recorder.state()
+			return session != nil && flushes == 1
+		}, time.Second, 5*time.Millisecond, "endpoint event was not flushed")
+		return sseServer, recorder, session
+	}
+
+	notify := func(session *sseSession, method string) {
+		session.notificationChannel <- mcp.JSONRPCNotification{
+			JSONRPC:      mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{Method: method},
+		}
+	}
+
+	t.Run("flushes when the count is reached", func(t *testing.T) {
+		_, recorder, session := serve(t, WithSSEBatchFlush(3, time.Hour))
+
+		notify(session, "notifications/one")
+		notify(session, "notifications/two")
+		time.Sleep(50 * time.Millisecond)
+		flushed, flushes := recorder.state()
+		require.Equal(t, 1, flushes)
+		require.NotContains(t, flushed, "notifications/one")
+
+		notify(session, "notifications/three")
+		require.Eventually(t, func() bool {
+			flushed, flushes := recorder.state()
+			return flushes == 2 && strings.Contains(flushed, "notifications/three")
+		}, time.Second, 5*time.Millisecond)
+		flushed, _ = recorder.state()
+		require.Contains(t, flushed, "notifications/one")
+	})
+
+	t.Run("flushes when the interval passes", func(t *testing.T) {
+		_, recorder, session := serve(t, WithSSEBatchFlush(100, 20*time.Millisecond))
+
+		notify(session, "notifications/one")
+		require.Eventually(t, func() bool {
+			flushed, _ := recorder.state()
+			return strings.Contains(flushed, "notifications/one")
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("responses flush immediately", func(t *testing.T) {
+		sseServer, recorder, session := serve(t, WithSSEBatchFlush(100, time.Hour))
+
+		notify(session, "notifications/pending")
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, sseServer.SendEventToSession(session.sessionID, mcp.JSONRPCResponse{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(1),
+			Result:  map[string]any{},
+		}))
+		require.Eventually(t, func() bool {
+			flushed, _ := recorder.state()
+			return strings.Contains(flushed, `"id":1`) && strings.Contains(flushed, "notifications/pending")
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("flushes every event by default", func(t *testing.T) {
+		_, recorder, session := serve(t)
+
+		notify(session, "notifications/one")
+		require.Eventually(t, func() bool {
+			_, flushes := recorder.state()
+			return flushes == 2
+		}, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestSSEServer_ResponseFollowsNotifications(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	var stragglers sync.WaitGroup
+	mcpServer.AddTool(mcp.NewTool("racy"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		server := ServerFromContext(ctx)
+		id := request.GetArguments()["call"]
+		for i := 0; i < 5; i++ {
+			_ = server.SendNotificationToClient(ctx, "test/before", map[string]any{"call": id})
+		}
+		// Notifications racing with the return may come before or after the
+		// response, but must never corrupt the stream
+		stragglers.Add(1)
+		go func() {
+			defer stragglers.Done()
+			_ = server.SendNotificationToClient(ctx, "test/racing", map[string]any{"call": id})
+		}()
+		return mcp.NewToolResultText("done"), nil
+	})
+	testServer := NewTestServer(mcpServer)
+	defer testServer.Close()
+	defer stragglers.Wait()
+
+	sseResp, err := http.Get(fmt.Sprintf("%s/sse", testServer.URL))
+	require.NoError(t, err)
+	defer sseResp.Body.Close()
+	reader := bufio.NewReader(sseResp.Body)
+	var messageURL string
+	for messageURL == "" {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			messageURL = strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+
+	// post sends a message, leaving the reply to the stream
+	post := func(message any) {
+		body, _ := json.Marshal(message)
+		resp, err := http.Post(messageURL, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+	// next returns the next message on the stream
+	next := func() map[string]any {
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var message map[string]any
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &message), "unparsable event %q", line)
+			return message
+		}
+	}
+
+	post(initRequest)
+	require.Equal(t, float64(1), next()["id"])
+
+	const calls = 20
+	for id := 2; id < calls+2; id++ {
+		go post(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"method":  "tools/call",
+			"params":  map[string]any{"name": "racy", "arguments": map[string]any{"call": id}},
+		})
+	}
+
+	// Notifications that overflow the event queue are dropped, but none may
+	// be written after the response of its call
+	responded := map[float64]bool{}
+	for len(responded) < calls {
+		message := next()
+		switch {
+		case message["method"] == "test/before":
+			id := message["params"].(map[string]any)["call"].(float64)
+			require.False(t, responded[id], "notification of call %v came after its response", id)
+		case message["id"] != nil:
+			responded[message["id"].(float64)] = true
+		}
+	}
+}
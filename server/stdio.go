@@ -12,6 +12,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -29,20 +30,46 @@ type StdioServer struct {
 	server      *MCPServer
 	errLogger   *log.Logger
 	contextFunc StdioContextFunc
+	session     *stdioSession
 
 	// Thread-safe tool call processing
 	toolCallQueue  chan *toolCallWork
 	workerWg       sync.WaitGroup
 	workerPoolSize int
 	queueSize      int
+	overflowPolicy QueueOverflowPolicy
 	writeMu        sync.Mutex // Protects concurrent writes
+
+	stats         workStats
+	statsInterval time.Duration
+	statsReporter func(Stats)
 }
 
+// QueueOverflowPolicy controls what the stdio server does with a tool call
+// that arrives while the tool call queue is full.
+type QueueOverflowPolicy int
+
+const (
+	// QueueOverflowBlock handles the tool call on the goroutine reading
+	// stdin, so no further input is read until it completes. This is the
+	// default.
+	QueueOverflowBlock QueueOverflowPolicy = iota
+	// QueueOverflowReject answers the tool call right away with a
+	// [mcp.SERVER_BUSY] JSON-RPC error.
+	QueueOverflowReject
+)
+
+// stdioShutdownHookTimeout bounds how long shutdown hooks may run after a
+// stdio server stops listening.
+const stdioShutdownHookTimeout = 10 * time.Second
+
 // toolCallWork represents a queued tool call request
 type toolCallWork struct {
 	ctx     context.Context
 	message json.RawMessage
 	writer  io.Writer
+	// dequeued updates the queue depth once a worker takes the call
+	dequeued func()
 }
 
 // StdioOption defines a function type for configuring StdioServer
@@ -90,13 +117,47 @@ func WithQueueSize(size int) StdioOption {
 	}
 }
 
+// WithQueueOverflowPolicy sets what happens to tool calls that arrive while
+// the tool call queue is full. See QueueOverflowBlock and QueueOverflowReject.
+func WithQueueOverflowPolicy(policy QueueOverflowPolicy) StdioOption {
+	return func(s *StdioServer) {
+		s.overflowPolicy = policy
+	}
+}
+
+// WithStdioSessionID sets the ID of the server's client session, which is
+// "stdio" by default. Session IDs must be unique within an MCPServer, so
+// every stdio server listening at the same time for one MCPServer needs its
+// own ID. It has no effect on the servers of a StdioMultiplexer, whose
+// session IDs are derived from their keys.
+func WithStdioSessionID(id string) StdioOption {
+	return func(s *StdioServer) {
+		s.session = newStdioSession(id)
+	}
+}
+
+// WithStatsReporter calls report with the server's Stats every interval while
+// the server is listening.
+func WithStatsReporter(interval time.Duration, report func(Stats)) StdioOption {
+	return func(s *StdioServer) {
+		if interval <= 0 || report == nil {
+			return
+		}
+		s.statsInterval = interval
+		s.statsReporter = report
+	}
+}
+
 // stdioSession is a static client session, since stdio has only one client.
+// Multiplexed stdio servers use one session per logical session key.
 type stdioSession struct {
+	id                 string
 	notifications      chan mcp.JSONRPCNotification
 	initialized        atomic.Bool
 	loggingLevel       atomic.Value
 	clientInfo         atomic.Value                     // stores session-specific client info
 	clientCapabilities atomic.Value                     // stores session-specific client capabilities
+	protocolVersion    atomic.Value                     // stores the negotiated protocol version
 	writer             io.Writer                        // for sending requests to client
 	requestID          atomic.Int64                     // for generating unique request IDs
 	mu                 sync.RWMutex                     // protects writer
@@ -111,6 +172,9 @@ type samplingResponse struct {
 }
 
 func (s *stdioSession) SessionID() string {
+	if s.id != "" {
+		return s.id
+	}
 	return "stdio"
 }
 
@@ -154,6 +218,17 @@ func (s *stdioSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabi
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *stdioSession) GetProtocolVersion() string {
+	if version, ok := s.protocolVersion.Load().(string); ok {
+		return version
+	}
+	return ""
+}
+
+func (s *stdioSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -220,6 +295,12 @@ func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMe
 	// Wait for the response or context cancellation
 	select {
 	case <-ctx.Done():
+		// Let the client stop its sampling handler. A response it sends anyway
+		// is discarded by handleSamplingResponse.
+		notification, err := json.Marshal(newCancelledNotification(mcp.NewRequestId(id), context.Cause(ctx).Error()))
+		if err == nil {
+			_, _ = writer.Write(append(notification, '\n'))
+		}
 		return nil, ctx.Err()
 	case response := <-responseChan:
 		if response.err != nil {
@@ -237,15 +318,21 @@ func (s *stdioSession) SetWriter(writer io.Writer) {
 }
 
 var (
-	_ ClientSession         = (*stdioSession)(nil)
-	_ SessionWithLogging    = (*stdioSession)(nil)
-	_ SessionWithClientInfo = (*stdioSession)(nil)
-	_ SessionWithSampling   = (*stdioSession)(nil)
+	_ ClientSession              = (*stdioSession)(nil)
+	_ SessionWithLogging         = (*stdioSession)(nil)
+	_ SessionWithClientInfo      = (*stdioSession)(nil)
+	_ SessionWithProtocolVersion = (*stdioSession)(nil)
+	_ SessionWithSampling        = (*stdioSession)(nil)
 )
 
-var stdioSessionInstance = stdioSession{
-	notifications:   make(chan mcp.JSONRPCNotification, 100),
-	pendingRequests: make(map[int64]chan *samplingResponse),
+// newStdioSession creates a session with the given ID. An empty ID stands
+// for the default "stdio".
+func newStdioSession(id string) *stdioSession {
+	return &stdioSession{
+		id:              id,
+		notifications:   make(chan mcp.JSONRPCNotification, 100),
+		pendingRequests: make(map[int64]chan *samplingResponse),
+	}
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
@@ -258,6 +345,7 @@ func NewStdioServer(server *MCPServer) *StdioServer {
 			"",
 			log.LstdFlags,
 		), // Default to discarding logs
+		session:        newStdioSession(""),
 		workerPoolSize: 5,   // Default worker pool size
 		queueSize:      100, // Default queue size
 	}
@@ -276,13 +364,26 @@ func (s *StdioServer) SetContextFunc(fn StdioContextFunc) {
 	s.contextFunc = fn
 }
 
+// SessionID returns the ID under which the server's client session is
+// registered with the MCPServer while listening, for use with
+// SendNotificationToSpecificClient and the other session APIs outside of a
+// request. It is "stdio" unless set with WithStdioSessionID.
+func (s *StdioServer) SessionID() string {
+	return s.session.SessionID()
+}
+
+// Stats returns a snapshot of the tool call queue and worker counters.
+func (s *StdioServer) Stats() Stats {
+	return s.stats.snapshot()
+}
+
 // handleNotifications continuously processes notifications from the session's notification channel
 // and writes them to the provided output. It runs until the context is cancelled.
 // Any errors encountered while writing notifications are logged but do not stop the handler.
 func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer) {
 	for {
 		select {
-		case notification := <-stdioSessionInstance.notifications:
+		case notification := <-s.session.notifications:
 			if err := s.writeResponse(notification, stdout); err != nil {
 				s.errLogger.Printf("Error writing notification: %v", err)
 			}
@@ -334,12 +435,10 @@ func (s *StdioServer) toolCallWorker(ctx context.Context) {
 				// Channel closed, exit worker
 				return
 			}
+			work.dequeued()
 			// Process the tool call
-			response := s.server.HandleMessage(work.ctx, work.message)
-			if response != nil {
-				if err := s.writeResponse(response, work.writer); err != nil {
-					s.errLogger.Printf("Error writing tool response: %v", err)
-				}
+			if err := s.handleToolCall(work.ctx, work.message, work.writer); err != nil {
+				s.errLogger.Printf("Error writing tool response: %v", err)
 			}
 		case <-ctx.Done():
 			return
@@ -347,6 +446,18 @@ func (s *StdioServer) toolCallWorker(ctx context.Context) {
 	}
 }
 
+// handleToolCall handles a tool call and writes its response, recording it
+// in the server's stats.
+func (s *StdioServer) handleToolCall(ctx context.Context, message json.RawMessage, writer io.Writer) error {
+	done := s.stats.begin()
+	defer done()
+	response := s.server.HandleMessage(ctx, message)
+	if response != nil {
+		return s.writeResponse(response, writer)
+	}
+	return nil
+}
+
 // readNextLine reads a single line from the input reader in a context-aware manner.
 // It uses channels to make the read operation cancellable via context.
 // Returns the read line and any error encountered. If the context is cancelled,
@@ -385,14 +496,15 @@ func (s *StdioServer) Listen(
 	s.toolCallQueue = make(chan *toolCallWork, s.queueSize)
 
 	// Set a static client context since stdio only has one client
-	if err := s.server.RegisterSession(ctx, &stdioSessionInstance); err != nil {
+	if err := s.server.RegisterSession(ctx, s.session); err != nil {
 		return fmt.Errorf("register session: %w", err)
 	}
-	defer s.server.UnregisterSession(ctx, stdioSessionInstance.SessionID())
-	ctx = s.server.WithContext(ctx, &stdioSessionInstance)
+	defer s.server.UnregisterSession(ctx, s.session.SessionID())
+	ctx = s.server.WithContext(ctx, s.session)
+	ctx = WithTransportType(ctx, TransportStdio)
 
 	// Set the writer for sending requests to the client
-	stdioSessionInstance.SetWriter(stdout)
+	s.session.SetWriter(stdout)
 
 	// Add in any custom context.
 	if s.contextFunc != nil {
@@ -410,6 +522,10 @@ func (s *StdioServer) Listen(
 	// Start notification handler
 	go s.handleNotifications(ctx, stdout)
 
+	if s.statsReporter != nil {
+		go reportStats(ctx, &s.stats, s.statsInterval, s.statsReporter)
+	}
+
 	// Process input stream
 	err := s.processInputStream(ctx, reader, stdout)
 
@@ -417,6 +533,11 @@ func (s *StdioServer) Listen(
 	close(s.toolCallQueue)
 	s.workerWg.Wait()
 
+	// ctx is usually cancelled by now, so give the hooks a fresh deadline
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stdioShutdownHookTimeout)
+	defer cancel()
+	s.server.runShutdownHooks(shutdownCtx)
+
 	return err
 }
 
@@ -447,28 +568,38 @@ func (s *StdioServer) processMessage(
 
 	// Check if this is a tool call that might need sampling (and thus should be processed concurrently)
 	var baseMessage struct {
-		Method string `json:"method"`
+		ID     *mcp.RequestId `json:"id"`
+		Method string         `json:"method"`
 	}
 	if json.Unmarshal(rawMessage, &baseMessage) == nil && baseMessage.Method == "tools/call" {
 		// Queue tool calls for processing by workers
+		dequeued := s.stats.enqueue()
 		select {
 		case s.toolCallQueue <- &toolCallWork{
-			ctx:     ctx,
-			message: rawMessage,
-			writer:  writer,
+			ctx:      ctx,
+			message:  rawMessage,
+			writer:   writer,
+			dequeued: dequeued,
 		}:
 			return nil
 		case <-ctx.Done():
+			dequeued()
 			return ctx.Err()
 		default:
-			// Queue is full, process synchronously as fallback
-			s.errLogger.Printf("Tool call queue full, processing synchronously")
-			response := s.server.HandleMessage(ctx, rawMessage)
-			if response != nil {
-				return s.writeResponse(response, writer)
+			dequeued()
+		}
+
+		if s.overflowPolicy == QueueOverflowReject {
+			s.stats.reject()
+			var id any
+			if baseMessage.ID != nil {
+				id = baseMessage.ID.Value()
 			}
-			return nil
+			return s.writeResponse(createErrorResponse(id, mcp.SERVER_BUSY, ErrToolCallQueueFull.Error()), writer)
 		}
+		// Queue is full, process synchronously as fallback
+		s.errLogger.Printf("Tool call queue full, processing synchronously")
+		return s.handleToolCall(ctx, rawMessage, writer)
 	}
 
 	// Handle other messages synchronously
@@ -487,7 +618,7 @@ func (s *StdioServer) processMessage(
 // handleSamplingResponse checks if the message is a response to a sampling request
 // and routes it to the appropriate pending request channel.
 func (s *StdioServer) handleSamplingResponse(rawMessage json.RawMessage) bool {
-	return stdioSessionInstance.handleSamplingResponse(rawMessage)
+	return s.session.handleSamplingResponse(rawMessage)
 }
 
 // handleSamplingResponse handles incoming sampling responses for this session
@@ -518,8 +649,11 @@ func (s *stdioSession) handleSamplingResponse(rawMessage json.RawMessage) bool {
 	s.pendingMu.RUnlock()
 
 	if !exists {
-		return false
-	} // Parse and send the response
+		// A late response to a sampling request we abandoned is dropped
+		return idInt64 > 0 && idInt64 <= s.requestID.Load()
+	}
+
+	// Parse and send the response
 	samplingResp := &samplingResponse{}
 
 	if response.Error != nil {
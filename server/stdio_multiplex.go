@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// stdioSessionKeyContextKey is the context key for the logical session key of
+// a multiplexed stdio message.
+type stdioSessionKeyContextKey struct{}
+
+// StdioSessionKeyFromContext returns the logical session key of the
+// multiplexed stdio session handling the current request. It returns false if
+// the request was not received by a StdioMultiplexer.
+func StdioSessionKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(stdioSessionKeyContextKey{}).(string)
+	return key, ok
+}
+
+// stdioMultiplexBuffer is the number of messages buffered per logical session
+// before the multiplexer stops reading stdin.
+const stdioMultiplexBuffer = 100
+
+// StdioMultiplexer serves several logical MCP sessions over one pair of stdio
+// streams. Every message carries the key of its session in the top-level
+// mcp.StdioSessionField; messages are routed to the server registered under
+// that key, and the server's responses, notifications and requests are tagged
+// with the same key. Messages without the field go to the server registered
+// under the empty key, so clients unaware of the extension keep working.
+type StdioMultiplexer struct {
+	servers   map[string]*StdioServer
+	errLogger *log.Logger
+}
+
+// NewStdioMultiplexer creates a multiplexer serving each server under its key.
+// The options are applied to the stdio server of every key.
+func NewStdioMultiplexer(servers map[string]*MCPServer, opts ...StdioOption) *StdioMultiplexer {
+	m := &StdioMultiplexer{
+		servers:   make(map[string]*StdioServer, len(servers)),
+		errLogger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	for key, server := range servers {
+		s := NewStdioServer(server)
+		for _, opt := range opts {
+			opt(s)
+		}
+		m.errLogger = s.errLogger
+
+		sessionID := "stdio"
+		if key != "" {
+			sessionID = "stdio:" + key
+		}
+		s.session = newStdioSession(sessionID)
+
+		contextFunc := s.contextFunc
+		s.contextFunc = func(ctx context.Context) context.Context {
+			ctx = context.WithValue(ctx, stdioSessionKeyContextKey{}, key)
+			if contextFunc != nil {
+				ctx = contextFunc(ctx)
+			}
+			return ctx
+		}
+		m.servers[key] = s
+	}
+	return m
+}
+
+// Listen routes the messages read from stdin to the servers and writes their
+// output to stdout until stdin is closed or ctx is cancelled.
+func (m *StdioMultiplexer) Listen(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := &stdioSharedWriter{w: stdout}
+	inputs := make(map[string]chan []byte, len(m.servers))
+	errs := make(chan error, len(m.servers))
+	var wg sync.WaitGroup
+	for key, s := range m.servers {
+		input := make(chan []byte, stdioMultiplexBuffer)
+		inputs[key] = input
+
+		reader, writer := io.Pipe()
+		go feedStdioSession(ctx, input, writer)
+
+		wg.Add(1)
+		go func(key string, s *StdioServer) {
+			defer wg.Done()
+			err := s.Listen(ctx, reader, &stdioTaggingWriter{key: key, out: out})
+			_ = reader.Close()
+			if err != nil && !errors.Is(err, context.Canceled) {
+				errs <- fmt.Errorf("session %q: %w", key, err)
+			}
+		}(key, s)
+	}
+
+	err := m.route(ctx, bufio.NewReader(stdin), inputs, out)
+	for _, input := range inputs {
+		close(input)
+	}
+	wg.Wait()
+	close(errs)
+
+	if err != nil {
+		return err
+	}
+	return <-errs
+}
+
+// route reads messages from stdin and hands them to the session they are
+// tagged with.
+func (m *StdioMultiplexer) route(
+	ctx context.Context,
+	reader *bufio.Reader,
+	inputs map[string]chan []byte,
+	out *stdioSharedWriter,
+) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					readErr <- err
+				}
+				return
+			}
+		}
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return nil
+		case l, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-readErr:
+					m.errLogger.Printf("Error reading input: %v", err)
+					return err
+				default:
+					return nil
+				}
+			}
+			line = l
+		}
+
+		key, message, err := mcp.UntagStdioSession(line)
+		if err != nil {
+			m.writeError(out, "", nil, mcp.PARSE_ERROR, "Parse error")
+			continue
+		}
+		input, ok := inputs[key]
+		if !ok {
+			var request struct {
+				ID *mcp.RequestId `json:"id"`
+			}
+			if json.Unmarshal(message, &request) == nil && request.ID != nil && !request.ID.IsNil() {
+				m.writeError(out, key, request.ID.Value(), mcp.INVALID_REQUEST, fmt.Sprintf("unknown stdio session %q", key))
+			}
+			continue
+		}
+
+		select {
+		case input <- message:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *StdioMultiplexer) writeError(out *stdioSharedWriter, key string, id any, code int, message string) {
+	response, err := json.Marshal(createErrorResponse(id, code, message))
+	if err != nil {
+		return
+	}
+	if _, err := out.Write(append(mcp.TagStdioSession(response, key), '\n')); err != nil {
+		m.errLogger.Printf("Error writing response: %v", err)
+	}
+}
+
+// feedStdioSession writes the messages of one logical session to the pipe its
+// server reads from, so that a busy session does not hold up the others.
+func feedStdioSession(ctx context.Context, input <-chan []byte, pipe *io.PipeWriter) {
+	defer pipe.Close()
+	for message := range input {
+		if ctx.Err() != nil {
+			continue
+		}
+		if _, err := pipe.Write(message); err != nil {
+			// The server stopped reading; drain the remaining input
+			continue
+		}
+	}
+}
+
+// stdioSharedWriter serializes the writes of all sessions to stdout.
+type stdioSharedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *stdioSharedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// stdioTaggingWriter tags every message written by a session's server with
+// the session key. Each Write must hold exactly one message, which is how the
+// stdio server writes.
+type stdioTaggingWriter struct {
+	key string
+	out io.Writer
+}
+
+func (w *stdioTaggingWriter) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(mcp.TagStdioSession(p, w.key)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ServeStdioMultiplexed is like ServeStdio, but serves each server under its
+// logical session key on os.Stdin and os.Stdout. See StdioMultiplexer.
+func ServeStdioMultiplexed(servers map[string]*MCPServer, opts ...StdioOption) error {
+	m := NewStdioMultiplexer(servers, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Set up signal handling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	return m.Listen(ctx, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStdioMultiplexer(t *testing.T) {
+	newServer := func(name string) *MCPServer {
+		s := NewMCPServer(name, "1.0.0")
+		s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			key, _ := StdioSessionKeyFromContext(ctx)
+			return mcp.NewToolResultText(name + "/" + key), nil
+		})
+		return s
+	}
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+
+	mux := NewStdioMultiplexer(map[string]*MCPServer{
+		"":  newServer("default"),
+		"a": newServer("server-a"),
+		"b": newServer("server-b"),
+	}, WithErrorLogger(log.New(io.Discard, "", 0)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- mux.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+
+	type taggedResponse struct {
+		Session string          `json:"mcpSession"`
+		ID      mcp.RequestId   `json:"id"`
+		Result  json.RawMessage `json:"result"`
+		Error   *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	responses := make(chan taggedResponse, 20)
+	go func() {
+		scanner := bufio.NewScanner(stdoutReader)
+		for scanner.Scan() {
+			var response taggedResponse
+			if err := json.Unmarshal(scanner.Bytes(), &response); err == nil {
+				responses <- response
+			}
+		}
+	}()
+
+	send := func(key, message string) {
+		line := mcp.TagStdioSession([]byte(message), key)
+		_, err := stdinWriter.Write(append(line, '\n'))
+		require.NoError(t, err)
+	}
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"}}}`
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`
+
+	// The same request IDs are used in every session
+	for _, key := range []string{"a", "b", ""} {
+		send(key, initialize)
+		send(key, call)
+	}
+	send("unknown", `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+
+	results := make(map[string]string)
+	var rejected *taggedResponse
+	for i := 0; i < 7; i++ {
+		select {
+		case response := <-responses:
+			if response.Error != nil {
+				rejected = &response
+				continue
+			}
+			if response.ID.String() != mcp.NewRequestId(int64(2)).String() {
+				continue
+			}
+			var result mcp.CallToolResult
+			require.NoError(t, json.Unmarshal(response.Result, &result))
+			require.Len(t, result.Content, 1)
+			results[response.Session] = result.Content[0].(mcp.TextContent).Text
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for responses, got %v", results)
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"":  "default/",
+		"a": "server-a/a",
+		"b": "server-b/b",
+	}, results)
+	require.NotNil(t, rejected, "Expected the unknown session to be rejected")
+	assert.Equal(t, "unknown", rejected.Session)
+	assert.Equal(t, mcp.INVALID_REQUEST, rejected.Error.Code)
+
+	// Closing stdin stops every session
+	stdinWriter.Close()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Listen to return after stdin is closed")
+	}
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -483,4 +485,405 @@ func TestStdioServer(t *testing.T) {
 			t.Errorf("Expected default queue size 100 for negative input, got %d", stdioServer.queueSize)
 		}
 	})
+
+	t.Run("Exposes transport and raw message to handlers", func(t *testing.T) {
+		stdinReader, stdinWriter := io.Pipe()
+		stdoutReader, stdoutWriter := io.Pipe()
+
+		type seen struct {
+			transport TransportType
+			raw       []byte
+		}
+		seenCh := make(chan seen, 1)
+
+		mcpServer := NewMCPServer("test", "1.0.0", WithRawMessageCapture(0))
+		mcpServer.AddTool(mcp.NewTool("inspect"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			seenCh <- seen{
+				transport: TransportFromContext(ctx),
+				raw:       RawMessageFromContext(ctx),
+			}
+			return mcp.NewToolResultText("ok"), nil
+		})
+		stdioServer := NewStdioServer(mcpServer)
+		stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+			stdoutWriter.Close()
+		}()
+
+		request := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"inspect"}}`
+		if _, err := stdinWriter.Write([]byte(request + "\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		scanner := bufio.NewScanner(stdoutReader)
+		if !scanner.Scan() {
+			t.Fatal("failed to read response")
+		}
+
+		select {
+		case got := <-seenCh:
+			if got.transport != TransportStdio {
+				t.Errorf("expected transport %q, got %q", TransportStdio, got.transport)
+			}
+			if string(got.raw) != request {
+				t.Errorf("expected raw message %s, got %s", request, got.raw)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("tool handler was not called")
+		}
+
+		cancel()
+		stdinWriter.Close()
+	})
+}
+
+func TestStdioServer_QueueOverflow(t *testing.T) {
+	type stdioTestResponse struct {
+		ID    mcp.RequestId `json:"id"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	// startSaturated runs a server with one worker and a one-slot queue, and
+	// occupies both with slow tool calls. It returns the server, a channel of
+	// responses, a writer for further requests and a function releasing the
+	// slow tools.
+	startSaturated := func(t *testing.T, opts ...StdioOption) (*StdioServer, <-chan stdioTestResponse, *io.PipeWriter, func()) {
+		t.Helper()
+		stdinReader, stdinWriter := io.Pipe()
+		stdoutReader, stdoutWriter := io.Pipe()
+
+		started := make(chan struct{}, 10)
+		release := make(chan struct{})
+		mcpServer := NewMCPServer("test", "1.0.0")
+		mcpServer.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			started <- struct{}{}
+			<-release
+			return mcp.NewToolResultText("done"), nil
+		})
+
+		stdioServer := NewStdioServer(mcpServer)
+		stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+		WithWorkerPoolSize(1)(stdioServer)
+		WithQueueSize(1)(stdioServer)
+		for _, opt := range opts {
+			opt(stdioServer)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+			stdoutWriter.Close()
+		}()
+
+		responses := make(chan stdioTestResponse, 10)
+		go func() {
+			scanner := bufio.NewScanner(stdoutReader)
+			for scanner.Scan() {
+				var response stdioTestResponse
+				if err := json.Unmarshal(scanner.Bytes(), &response); err == nil {
+					responses <- response
+				}
+			}
+		}()
+
+		send := func(line string) {
+			if _, err := stdinWriter.Write([]byte(line + "\n")); err != nil {
+				t.Fatalf("Failed to write request: %v", err)
+			}
+		}
+		send(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+		select {
+		case <-responses:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for initialize response")
+		}
+
+		// The first call occupies the worker, the second one the queue
+		send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`)
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for the first tool call to start")
+		}
+		send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow"}}`)
+
+		var once sync.Once
+		releaseAll := func() { once.Do(func() { close(release) }) }
+		t.Cleanup(func() {
+			releaseAll()
+			cancel()
+			stdinWriter.Close()
+		})
+		return stdioServer, responses, stdinWriter, releaseAll
+	}
+
+	waitForStats := func(t *testing.T, s *StdioServer, want func(Stats) bool) Stats {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			stats := s.Stats()
+			if want(stats) {
+				return stats
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Unexpected stats: %+v", stats)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	t.Run("reject answers with server busy", func(t *testing.T) {
+		stdioServer, responses, stdin, release := startSaturated(t, WithQueueOverflowPolicy(QueueOverflowReject))
+		waitForStats(t, stdioServer, func(s Stats) bool { return s.ActiveWorkers == 1 && s.QueueDepth == 1 })
+
+		_, err := stdin.Write([]byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"slow"}}` + "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case response := <-responses:
+			if response.ID.String() != mcp.NewRequestId(int64(3)).String() {
+				t.Fatalf("Expected the rejected call to be answered first, got response to %v", response.ID)
+			}
+			if response.Error == nil || response.Error.Code != mcp.SERVER_BUSY {
+				t.Errorf("Expected a server busy error, got %+v", response.Error)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for the rejection")
+		}
+
+		stats := stdioServer.Stats()
+		if stats.Rejected != 1 {
+			t.Errorf("Expected 1 rejected call, got %d", stats.Rejected)
+		}
+
+		release()
+		waitForStats(t, stdioServer, func(s Stats) bool {
+			return s.Processed == 2 && s.ActiveWorkers == 0 && s.QueueDepth == 0
+		})
+	})
+
+	t.Run("block handles the call on the reader", func(t *testing.T) {
+		stdioServer, responses, stdin, release := startSaturated(t)
+		waitForStats(t, stdioServer, func(s Stats) bool { return s.ActiveWorkers == 1 && s.QueueDepth == 1 })
+
+		pingWritten := make(chan struct{})
+		go func() {
+			_, _ = stdin.Write([]byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"slow"}}` + "\n"))
+			_, _ = stdin.Write([]byte(`{"jsonrpc":"2.0","id":4,"method":"ping"}` + "\n"))
+			close(pingWritten)
+		}()
+
+		// The overflowing call runs on the reader, so the ping is not read
+		waitForStats(t, stdioServer, func(s Stats) bool { return s.ActiveWorkers == 2 })
+		select {
+		case <-pingWritten:
+			t.Fatal("Expected the reader to be blocked by the overflowing call")
+		case response := <-responses:
+			t.Fatalf("Unexpected response while saturated: %+v", response)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release()
+		seen := make(map[string]bool)
+		for len(seen) < 4 {
+			select {
+			case response := <-responses:
+				if response.Error != nil {
+					t.Errorf("Unexpected error response: %+v", response.Error)
+				}
+				seen[response.ID.String()] = true
+			case <-time.After(time.Second):
+				t.Fatalf("Timeout waiting for responses, got %v", seen)
+			}
+		}
+
+		stats := waitForStats(t, stdioServer, func(s Stats) bool { return s.Processed == 3 && s.ActiveWorkers == 0 })
+		if stats.Rejected != 0 {
+			t.Errorf("Expected no rejected calls, got %d", stats.Rejected)
+		}
+	})
+}
+
+func TestStdioServer_StatsReporter(t *testing.T) {
+	reports := make(chan Stats, 10)
+	mcpServer := NewMCPServer("test", "1.0.0")
+	stdioServer := NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+	WithStatsReporter(10*time.Millisecond, func(stats Stats) {
+		select {
+		case reports <- stats:
+		default:
+		}
+	})(stdioServer)
+
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinWriter.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(ctx, stdinReader, io.Discard)
+	}()
+
+	select {
+	case <-reports:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a periodic stats report")
+	}
+}
+
+func TestStdioSession_AbandonedSampling(t *testing.T) {
+	session := newStdioSession("test")
+	var output bytes.Buffer
+	session.SetWriter(&output)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := session.RequestSampling(ctx, mcp.CreateMessageRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	scanner := bufio.NewScanner(&output)
+	var methods []string
+	var cancelled map[string]any
+	for scanner.Scan() {
+		var message struct {
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatalf("Failed to decode %q: %v", scanner.Text(), err)
+		}
+		methods = append(methods, message.Method)
+		if message.Method == mcp.MethodNotificationCancelled {
+			cancelled = message.Params
+		}
+	}
+	if len(methods) != 2 || methods[0] != string(mcp.MethodSamplingCreateMessage) || methods[1] != mcp.MethodNotificationCancelled {
+		t.Fatalf("Expected a sampling request followed by a cancellation, got %v", methods)
+	}
+	if cancelled["requestId"] != float64(1) {
+		t.Errorf("Expected the cancellation of request 1, got %v", cancelled["requestId"])
+	}
+
+	// The late response is swallowed rather than handled as a client message
+	if !session.handleSamplingResponse(json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":{}}`)) {
+		t.Error("Expected the late sampling response to be discarded")
+	}
+	if session.handleSamplingResponse(json.RawMessage(`{"jsonrpc":"2.0","id":2,"result":{}}`)) {
+		t.Error("Expected a response to an unknown request not to be claimed")
+	}
+}
+
+func TestStdioServer_Reinitialize(t *testing.T) {
+	// exchange starts a stdio server and returns it with a function sending
+	// a message and returning the response
+	exchange := func(t *testing.T, mcpServer *MCPServer) (*StdioServer, func(message string) map[string]any) {
+		stdioServer := NewStdioServer(mcpServer)
+		stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+		stdinReader, stdinWriter := io.Pipe()
+		stdoutReader, stdoutWriter := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(func() {
+			cancel()
+			_ = stdinWriter.Close()
+			_ = stdoutReader.Close()
+		})
+		go func() {
+			_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		}()
+
+		scanner := bufio.NewScanner(stdoutReader)
+		return stdioServer, func(message string) map[string]any {
+			t.Helper()
+			if _, err := stdinWriter.Write([]byte(message + "\n")); err != nil {
+				t.Fatal(err)
+			}
+			if !scanner.Scan() {
+				t.Fatal("failed to read response")
+			}
+			var response map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			return response
+		}
+	}
+	initialize := func(id int, version string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"initialize","params":{"protocolVersion":%q,"clientInfo":{"name":"client-%d","version":"1.0.0"}}}`, id, version, id)
+	}
+
+	t.Run("returns the original result", func(t *testing.T) {
+		var reinitialized []string
+		hooks := &Hooks{}
+		hooks.AddOnReinitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest, original *mcp.InitializeResult) {
+			reinitialized = append(reinitialized, request.Params.ProtocolVersion+"->"+original.ProtocolVersion)
+		})
+		mcpServer := NewMCPServer("test", "1.0.0", WithHooks(hooks))
+		stdioServer, send := exchange(t, mcpServer)
+
+		first := send(initialize(1, "2025-03-26"))
+		if first["error"] != nil {
+			t.Fatalf("unexpected error: %v", first["error"])
+		}
+		for id, version := range map[int]string{2: "2025-03-26", 3: "2024-11-05"} {
+			response := send(initialize(id, version))
+			if response["error"] != nil {
+				t.Fatalf("unexpected error: %v", response["error"])
+			}
+			if fmt.Sprint(response["result"]) != fmt.Sprint(first["result"]) {
+				t.Errorf("expected the original result %v, got %v", first["result"], response["result"])
+			}
+		}
+
+		// The session keeps what was negotiated first
+		if version := stdioServer.session.GetProtocolVersion(); version != "2025-03-26" {
+			t.Errorf("expected protocol version 2025-03-26, got %q", version)
+		}
+		if name := stdioServer.session.GetClientInfo().Name; name != "client-1" {
+			t.Errorf("expected client info of the first initialize, got %q", name)
+		}
+		if len(reinitialized) != 2 {
+			t.Errorf("expected the hook to see 2 re-initializations, got %v", reinitialized)
+		}
+	})
+
+	t.Run("strict rejects a different request", func(t *testing.T) {
+		_, send := exchange(t, NewMCPServer("test", "1.0.0", WithStrictReinitialization()))
+
+		if response := send(initialize(1, "2025-03-26")); response["error"] != nil {
+			t.Fatalf("unexpected error: %v", response["error"])
+		}
+		if response := send(initialize(2, "2025-03-26")); response["error"] != nil {
+			t.Errorf("expected an identical initialize to succeed, got %v", response["error"])
+		}
+		// An empty experimental object declares no more than a missing one
+		response := send(`{"jsonrpc":"2.0","id":5,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"experimental":{}},"clientInfo":{"name":"c","version":"1"}}}`)
+		if response["error"] != nil {
+			t.Errorf("expected empty experimental capabilities to match missing ones, got %v", response["error"])
+		}
+
+		response = send(initialize(3, "2024-11-05"))
+		rpcErr, _ := response["error"].(map[string]any)
+		if rpcErr == nil {
+			t.Fatalf("expected an error for a protocol downgrade, got %v", response)
+		}
+		if code := rpcErr["code"].(float64); code != mcp.INVALID_REQUEST {
+			t.Errorf("expected code %d, got %v", mcp.INVALID_REQUEST, code)
+		}
+
+		response = send(`{"jsonrpc":"2.0","id":4,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{"listChanged":true}},"clientInfo":{"name":"c","version":"1"}}}`)
+		if response["error"] == nil {
+			t.Errorf("expected an error for different capabilities, got %v", response)
+		}
+	})
 }
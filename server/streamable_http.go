@@ -1,16 +1,21 @@
 package server
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,9 +31,7 @@ type StreamableHTTPOption func(*StreamableHTTPServer)
 // It's only works for `Start` method. When used as a http.Handler, it has no effect.
 func WithEndpointPath(endpointPath string) StreamableHTTPOption {
 	return func(s *StreamableHTTPServer) {
-		// Normalize the endpoint path to ensure it starts with a slash and doesn't end with one
-		normalizedPath := "/" + strings.Trim(endpointPath, "/")
-		s.endpointPath = normalizedPath
+		s.endpointPath = normalizeEndpointPath(endpointPath)
 	}
 }
 
@@ -77,6 +80,16 @@ func WithHTTPContextFunc(fn HTTPContextFunc) StreamableHTTPOption {
 	}
 }
 
+// WithCancelOnDisconnect cancels the handling of a POST request as soon as
+// its client goes away, even when the function set with WithHTTPContextFunc
+// returns a context detached from the request. Without it, a context func may
+// detach the context to let handlers finish after a disconnect.
+func WithCancelOnDisconnect() StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.cancelOnDisconnect = true
+	}
+}
+
 // WithStreamableHTTPServer sets the HTTP server instance for StreamableHTTPServer.
 // NOTE: When providing a custom HTTP server, you must handle routing yourself
 // If routing is not set up, the server will start but won't handle any MCP requests.
@@ -93,6 +106,87 @@ func WithLogger(logger util.Logger) StreamableHTTPOption {
 	}
 }
 
+// WithMaxRequestBodySize limits the size of POST bodies. Larger requests are
+// rejected with 413 Request Entity Too Large. With WithCompression the limit
+// applies to the decompressed body. The default is no limit.
+func WithMaxRequestBodySize(size int64) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.maxRequestBodySize = size
+	}
+}
+
+// WithCompression enables gzip compression. POST bodies sent with
+// "Content-Encoding: gzip" are decompressed, and JSON responses are compressed
+// for clients that send "Accept-Encoding: gzip". SSE streams are never
+// compressed. Combine with WithMaxRequestBodySize to protect against
+// decompression bombs. The default is no compression.
+func WithCompression() StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.compression = true
+	}
+}
+
+// JSONNotificationPolicy controls what happens to notifications emitted while
+// handling a request whose response was negotiated down to a single JSON body.
+type JSONNotificationPolicy int
+
+const (
+	// JSONNotificationsDrop discards the notifications. This is the default.
+	JSONNotificationsDrop JSONNotificationPolicy = iota
+	// JSONNotificationsInline returns the notifications in a JSON array
+	// (a JSON-RPC batch), in the order they were sent, followed by the
+	// response.
+	JSONNotificationsInline
+)
+
+// WithJSONResponseNegotiation makes the server honor a POST request whose
+// Accept header allows application/json but not text/event-stream: the
+// response is never upgraded to an SSE stream. Notifications sent while
+// handling the request are buffered and handled according to the policy set
+// with WithJSONNotificationPolicy, and server-to-client requests such as
+// sampling fail with ErrSamplingUnavailableInline. Without this option, such
+// requests are answered like any other.
+func WithJSONResponseNegotiation() StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.jsonNegotiation = true
+	}
+}
+
+// WithSessionIdleTimeout sets how long the server keeps what a client
+// reported in initialize, see ClientInfoFromContext, and the result of the
+// first initialize, after the last request of its session. Clients often go
+// away without deleting their session, so this bounds the state kept for
+// them. A request of an expired session is handled without the client info,
+// and an initialize request creates a new session. Zero keeps the state until
+// the session is deleted. The default is one hour.
+func WithSessionIdleTimeout(timeout time.Duration) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.sessionClientInfo.idleTimeout = timeout
+	}
+}
+
+// WithJSONNotificationPolicy sets what happens to notifications emitted while
+// answering a JSON-only request. It only has an effect together with
+// WithJSONResponseNegotiation. The default is JSONNotificationsDrop.
+func WithJSONNotificationPolicy(policy JSONNotificationPolicy) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.jsonNotificationPolicy = policy
+	}
+}
+
+// WithHTTPStatsReporter calls report with the server's Stats every interval,
+// from the time the server is created until it is shut down. It is the
+// counterpart of WithStatsReporter for stdio servers.
+func WithHTTPStatsReporter(interval time.Duration, report func(Stats)) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		if interval <= 0 || report == nil {
+			return
+		}
+		s.statsInterval = interval
+		s.statsReporter = report
+	}
+}
+
 // StreamableHTTPServer implements a Streamable-http based MCP server.
 // It communicates with clients over HTTP protocol, supporting both direct HTTP responses, and SSE streams.
 // https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#streamable-http
@@ -119,6 +213,8 @@ func WithLogger(logger util.Logger) StreamableHTTPOption {
 type StreamableHTTPServer struct {
 	server            *MCPServer
 	sessionTools      *sessionToolsStore
+	sessionPrompts    *sessionPromptsStore
+	sessionClientInfo *sessionClientInfoStore
 	sessionRequestIDs sync.Map // sessionId --> last requestID(*atomic.Int64)
 	activeSessions    sync.Map // sessionId --> *streamableHttpSession (for sampling responses)
 
@@ -127,30 +223,54 @@ type StreamableHTTPServer struct {
 
 	endpointPath            string
 	contextFunc             HTTPContextFunc
+	cancelOnDisconnect      bool
 	sessionIdManager        SessionIdManager
 	listenHeartbeatInterval time.Duration
 	logger                  util.Logger
 	sessionLogLevels        *sessionLogLevelsStore
+	maxRequestBodySize      int64
+	compression             bool
+	jsonNegotiation         bool
+	jsonNotificationPolicy  JSONNotificationPolicy
+	stats                   workStats
+	statsInterval           time.Duration
+	statsReporter           func(Stats)
+	stopStatsReporter       context.CancelFunc
 }
 
 // NewStreamableHTTPServer creates a new streamable-http server instance
 func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *StreamableHTTPServer {
 	s := &StreamableHTTPServer{
-		server:           server,
-		sessionTools:     newSessionToolsStore(),
-		sessionLogLevels: newSessionLogLevelsStore(),
-		endpointPath:     "/mcp",
-		sessionIdManager: &InsecureStatefulSessionIdManager{},
-		logger:           util.DefaultLogger(),
+		server:            server,
+		sessionTools:      newSessionToolsStore(),
+		sessionPrompts:    newSessionPromptsStore(),
+		sessionClientInfo: newSessionClientInfoStore(defaultSessionIdleTimeout, server.forgetInitialization),
+		sessionLogLevels:  newSessionLogLevelsStore(),
+		endpointPath:      "/mcp",
+		sessionIdManager:  &InsecureStatefulSessionIdManager{},
+		logger:            util.DefaultLogger(),
 	}
 
 	// Apply all options
 	for _, opt := range opts {
 		opt(s)
 	}
+
+	if s.statsReporter != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopStatsReporter = cancel
+		go reportStats(ctx, &s.stats, s.statsInterval, s.statsReporter)
+	}
 	return s
 }
 
+// Stats returns a snapshot of the server's POST request counters. Requests
+// are handled on their own goroutines, so QueueDepth is always zero and
+// ActiveWorkers is the number of requests in flight.
+func (s *StreamableHTTPServer) Stats() Stats {
+	return s.stats.snapshot()
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -160,6 +280,8 @@ func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		s.handleGet(w, r)
 	case http.MethodDelete:
 		s.handleDelete(w, r)
+	case http.MethodHead:
+		s.handleHead(w, r)
 	default:
 		http.NotFound(w, r)
 	}
@@ -170,7 +292,19 @@ func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request)
 //
 //	s.Start(":8080")
 func (s *StreamableHTTPServer) Start(addr string) error {
+	srv, err := s.serverFor(addr)
+	if err != nil {
+		return err
+	}
+	return srv.ListenAndServe()
+}
+
+// serverFor returns the HTTP server listening on addr, creating it unless one
+// was set with WithStreamableHTTPServer. Once it returns, Shutdown stops the
+// server, even if it has not started listening yet.
+func (s *StreamableHTTPServer) serverFor(addr string) (*http.Server, error) {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.httpServer == nil {
 		mux := http.NewServeMux()
 		mux.Handle(s.endpointPath, s)
@@ -182,13 +316,10 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 		if s.httpServer.Addr == "" {
 			s.httpServer.Addr = addr
 		} else if s.httpServer.Addr != addr {
-			return fmt.Errorf("conflicting listen address: WithStreamableHTTPServer(%q) vs Start(%q)", s.httpServer.Addr, addr)
+			return nil, fmt.Errorf("conflicting listen address: WithStreamableHTTPServer(%q) vs Start(%q)", s.httpServer.Addr, addr)
 		}
 	}
-	srv := s.httpServer
-	s.mu.Unlock()
-
-	return srv.ListenAndServe()
+	return s.httpServer, nil
 }
 
 // Shutdown gracefully stops the server, closing all active sessions
@@ -199,12 +330,75 @@ func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
 	s.mu.RLock()
 	srv := s.httpServer
 	s.mu.RUnlock()
+	if s.stopStatsReporter != nil {
+		s.stopStatsReporter()
+	}
+	defer s.server.runShutdownHooks(ctx)
 	if srv != nil {
 		return srv.Shutdown(ctx)
 	}
 	return nil
 }
 
+// streamableHTTPShutdownTimeout bounds how long ServeStreamableHTTP waits for
+// in-flight requests and shutdown hooks after a termination signal.
+const streamableHTTPShutdownTimeout = 10 * time.Second
+
+// ServeStreamableHTTP is a convenience function that creates a StreamableHTTPServer
+// with the given options and serves it on addr, like ServeStdio does for stdio.
+// It sets up signal handling for graceful shutdown on SIGTERM and SIGINT, and
+// returns nil once the server has been shut down that way.
+// Returns an error if the server fails to listen or stops unexpectedly.
+func ServeStreamableHTTP(server *MCPServer, addr string, opts ...StreamableHTTPOption) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Set up signal handling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigChan)
+
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return serveStreamableHTTP(ctx, NewStreamableHTTPServer(server, opts...), addr)
+}
+
+// serveStreamableHTTP runs s on addr until it fails or ctx is done, in which
+// case it shuts s down gracefully.
+func serveStreamableHTTP(ctx context.Context, s *StreamableHTTPServer, addr string) error {
+	// Create the server before serving, so that a cancellation coming before
+	// it listens still shuts it down
+	srv, err := s.serverFor(addr)
+	if err != nil {
+		return err
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), streamableHTTPShutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errChan; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
 // --- internal methods ---
 
 func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
@@ -219,8 +413,15 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check the request body is valid json, meanwhile, get the request Method
-	rawData, err := io.ReadAll(r.Body)
-	if err != nil {
+	rawData, err := s.readBody(r)
+	switch {
+	case errors.Is(err, errRequestBodyTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, errUnsupportedContentEncoding):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	case err != nil:
 		s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, fmt.Sprintf("read request body error: %v", err))
 		return
 	}
@@ -256,8 +457,13 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	// for interaction with the mcp server.
 	var sessionID string
 	if isInitializeRequest {
-		// generate a new one for initialize request
-		sessionID = s.sessionIdManager.Generate()
+		// A client initializing its established session again keeps it, so
+		// that it gets the result of the first initialize; otherwise a new
+		// session is created
+		sessionID = r.Header.Get(HeaderKeySessionID)
+		if sessionID == "" || !s.server.sessionInitialized(sessionID) {
+			sessionID = s.sessionIdManager.Generate()
+		}
 	} else {
 		// Get session ID from header.
 		// Stateful servers need the client to carry the session ID.
@@ -271,82 +477,125 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 			http.Error(w, "Session terminated", http.StatusNotFound)
 			return
 		}
+		s.sessionClientInfo.touch(sessionID)
 	}
 
+	release, errResponse := s.server.acquireRequestSlot(sessionID, rawData)
+	if errResponse != nil {
+		s.stats.reject()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(errResponse); err != nil {
+			s.logger.Errorf("Failed to write response: %v", err)
+		}
+		return
+	}
+	defer release()
+	finished := s.stats.begin()
+	defer finished()
+
 	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionLogLevels)
+	session.prompts = s.sessionPrompts
+	session.clientInfo = s.sessionClientInfo
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
+	ctx = WithTransportType(ctx, TransportStreamableHTTP)
 	if s.contextFunc != nil {
 		ctx = s.contextFunc(ctx, r)
 	}
+	if s.cancelOnDisconnect {
+		// Stop handling the request as soon as the client goes away, e.g.
+		// when an HTTP/2 stream is reset, even if the context func detached
+		// the context
+		var stop context.CancelFunc
+		ctx, stop = cancelWhenDone(ctx, r.Context())
+		defer stop()
+	}
 
-	// handle potential notifications
-	mu := sync.Mutex{}
-	upgradedHeader := false
-	done := make(chan struct{})
+	// Clients that can't take an SSE stream get a single JSON body, so
+	// notifications are buffered and server-to-client requests refused
+	jsonOnly := s.jsonNegotiation && acceptsOnlyJSON(r)
+	if jsonOnly {
+		ctx = context.WithValue(ctx, inlineResponse, true)
+	}
 
 	ctx = context.WithValue(ctx, requestHeader, r.Header)
-	go func() {
-		for {
-			select {
-			case nt := <-session.notificationChannel:
-				func() {
-					mu.Lock()
-					defer mu.Unlock()
-					// if the done chan is closed, as the request is terminated, just return
-					select {
-					case <-done:
-						return
-					default:
-					}
-					defer func() {
-						flusher, ok := w.(http.Flusher)
-						if ok {
-							flusher.Flush()
-						}
-					}()
-
-					// if there's notifications, upgradedHeader to SSE response
-					if !upgradedHeader {
-						w.Header().Set("Content-Type", "text/event-stream")
-						w.Header().Set("Connection", "keep-alive")
-						w.Header().Set("Cache-Control", "no-cache")
-						w.WriteHeader(http.StatusOK)
-						upgradedHeader = true
-					}
-					err := writeSSEEvent(w, nt)
-					if err != nil {
-						s.logger.Errorf("Failed to write SSE event: %v", err)
-						return
-					}
-				}()
-			case <-done:
-				return
-			case <-ctx.Done():
-				return
+
+	// All writes to w happen in this goroutine, so notifications and the
+	// response never interleave. Notifications the handler sends before it
+	// returns are written first, and the response is always the last event;
+	// notifications sent after that go to the session's standalone GET
+	// stream, or are dropped and reported to the OnError hooks with
+	// ErrNotificationAfterResponse.
+	upgradedHeader := false
+	var buffered []any
+	writeNotification := func(nt mcp.JSONRPCNotification) {
+		if ctx.Err() != nil {
+			return
+		}
+		if jsonOnly {
+			if s.jsonNotificationPolicy == JSONNotificationsInline {
+				buffered = append(buffered, nt)
 			}
+			return
 		}
-	}()
+		// if there's notifications, upgradedHeader to SSE response
+		if !upgradedHeader {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			upgradedHeader = true
+		}
+		if err := writeSSEEvent(w, nt); err != nil {
+			s.logger.Errorf("Failed to write SSE event: %v", err)
+			return
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
 
 	// Process message through MCPServer
-	response := s.server.HandleMessage(ctx, rawData)
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- s.server.HandleMessage(ctx, rawData)
+	}()
+	var response mcp.JSONRPCMessage
+	for waiting := true; waiting; {
+		select {
+		case nt := <-session.notificationChannel:
+			writeNotification(nt)
+		case response = <-responses:
+			waiting = false
+		}
+	}
+	for _, nt := range session.finishResponse(s.lateNotifications(sessionID)) {
+		writeNotification(nt)
+	}
+
 	if response == nil {
 		// For notifications, just send 202 Accepted with no body
-		w.WriteHeader(http.StatusAccepted)
+		if !upgradedHeader {
+			w.WriteHeader(http.StatusAccepted)
+		}
 		return
 	}
 
 	// Write response
-	mu.Lock()
-	defer mu.Unlock()
-	// close the done chan before unlock
-	defer close(done)
 	if ctx.Err() != nil {
 		return
 	}
+	if jsonOnly {
+		if isInitializeRequest && sessionID != "" {
+			w.Header().Set(HeaderKeySessionID, sessionID)
+		}
+		s.writeNegotiatedJSONResponse(w, r, session, buffered, response)
+		return
+	}
 	// If client-server communication already upgraded to SSE stream
-	if session.upgradeToSSE.Load() {
+	if upgradedHeader || session.upgradeToSSE.Load() {
 		if !upgradedHeader {
 			w.Header().Set("Content-Type", "text/event-stream")
 			w.Header().Set("Connection", "keep-alive")
@@ -363,14 +612,137 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 			// send the session ID back to the client
 			w.Header().Set(HeaderKeySessionID, sessionID)
 		}
-		w.WriteHeader(http.StatusOK)
-		err := json.NewEncoder(w).Encode(response)
-		if err != nil {
+		if err := s.writeJSONResponse(w, r, response); err != nil {
 			s.logger.Errorf("Failed to write response: %v", err)
 		}
 	}
 }
 
+var (
+	errRequestBodyTooLarge        = errors.New("request body too large")
+	errUnsupportedContentEncoding = errors.New("unsupported content encoding")
+)
+
+// minCompressedResponseSize is the smallest JSON response that is gzipped
+// when compression is enabled; smaller ones gain nothing from it.
+const minCompressedResponseSize = 1024
+
+// readBody reads the body of a POST request, decompressing it if
+// compression is enabled. The size limit applies to the decompressed data.
+func (s *StreamableHTTPServer) readBody(r *http.Request) ([]byte, error) {
+	var body io.Reader = r.Body
+	if s.compression {
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gzip body: %w", err)
+			}
+			defer zr.Close()
+			body = zr
+		default:
+			return nil, fmt.Errorf("%w: %s", errUnsupportedContentEncoding, encoding)
+		}
+	}
+
+	if s.maxRequestBodySize <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.maxRequestBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxRequestBodySize {
+		return nil, errRequestBodyTooLarge
+	}
+	return data, nil
+}
+
+// writeJSONResponse writes response with status 200, gzipping it if
+// compression is enabled and the client accepts it.
+// The response is written from json.Encoder's pooled buffer, which saves
+// the copies that marshaling it into a byte slice first would take.
+func (s *StreamableHTTPServer) writeJSONResponse(w http.ResponseWriter, r *http.Request, response any) error {
+	body := &jsonResponseWriter{w: w, compress: s.compression && acceptsGzip(r)}
+	if err := json.NewEncoder(body).Encode(response); err != nil {
+		// json.Encoder writes nothing when marshaling fails, while a
+		// failed write has already sent the status
+		if !body.started {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return err
+	}
+	return body.Close()
+}
+
+// writeNegotiatedJSONResponse answers a JSON-only request. Notifications
+// still queued when the handler returned are collected first, so none that
+// were sent before the response are lost.
+func (s *StreamableHTTPServer) writeNegotiatedJSONResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	session *streamableHttpSession,
+	buffered []any,
+	response mcp.JSONRPCMessage,
+) {
+	for drained := false; !drained; {
+		select {
+		case nt := <-session.notificationChannel:
+			if s.jsonNotificationPolicy == JSONNotificationsInline {
+				buffered = append(buffered, nt)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	var body any = response
+	if len(buffered) > 0 {
+		body = append(buffered, response)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := s.writeJSONResponse(w, r, body); err != nil {
+		s.logger.Errorf("Failed to write response: %v", err)
+	}
+}
+
+// acceptsOnlyJSON reports whether the Accept header of r allows
+// application/json but no event stream.
+func acceptsOnlyJSON(r *http.Request) bool {
+	if !acceptsJSON(r) {
+		return false
+	}
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			switch mediaType {
+			case "text/event-stream", "text/*", "*/*":
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+				continue
+			}
+			q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+			return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+		}
+	}
+	return false
+}
+
 func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request) {
 	// get request is for listening to notifications
 	// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#listening-for-messages-from-the-server
@@ -382,14 +754,22 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 		// It's a stateless server,
 		// but the MCP server requires a unique ID for registering, so we use a random one
 		sessionID = uuid.New().String()
+		defer s.sessionRequestIDs.Delete(sessionID)
+	} else {
+		s.sessionClientInfo.touch(sessionID)
 	}
 
 	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionLogLevels)
+	session.prompts = s.sessionPrompts
+	session.clientInfo = s.sessionClientInfo
 	if err := s.server.RegisterSession(r.Context(), session); err != nil {
 		http.Error(w, fmt.Sprintf("Session registration failed: %v", err), http.StatusBadRequest)
 		return
 	}
-	defer s.server.UnregisterSession(r.Context(), sessionID)
+	// The request context is usually canceled by the time the stream ends,
+	// which is how a closed connection or HTTP/2 stream is noticed; keep its
+	// values but not its cancellation for the unregister hooks
+	defer s.server.UnregisterSession(context.WithoutCancel(r.Context()), sessionID)
 	
 	// Register session for sampling response delivery
 	s.activeSessions.Store(sessionID, session)
@@ -485,6 +865,8 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				return
 			}
 			flusher.Flush()
+		case <-session.closed:
+			return
 		case <-r.Context().Done():
 			return
 		}
@@ -506,6 +888,9 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 
 	// remove the session relateddata from the sessionToolsStore
 	s.sessionTools.delete(sessionID)
+	s.sessionPrompts.delete(sessionID)
+	s.sessionClientInfo.delete(sessionID)
+	s.server.forgetInitialization(sessionID)
 	s.sessionLogLevels.delete(sessionID)
 	// remove current session's requstID information
 	s.sessionRequestIDs.Delete(sessionID)
@@ -513,13 +898,25 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 }
 
-func writeSSEEvent(w io.Writer, data any) error {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+// handleHead lets clients cheaply check that the endpoint is reachable and,
+// if they send a session ID, that their session is still valid.
+func (s *StreamableHTTPServer) handleHead(w http.ResponseWriter, r *http.Request) {
+	if sessionID := r.Header.Get(HeaderKeySessionID); sessionID != "" {
+		isTerminated, err := s.sessionIdManager.Validate(sessionID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if isTerminated {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 	}
-	_, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", jsonData)
-	if err != nil {
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeSSEEvent(w io.Writer, data any) error {
+	if err := encodeSSEEvent(w, data); err != nil {
 		return fmt.Errorf("failed to write SSE event: %w", err)
 	}
 	return nil
@@ -614,6 +1011,12 @@ func (s *StreamableHTTPServer) deliverSamplingResponse(sessionID string, respons
 
 	// Look up the dedicated response channel for this specific request
 	responseChannelInterface, exists := session.samplingRequests.Load(response.requestID)
+	if !exists && response.requestID > 0 && response.requestID <= session.requestIDCounter.Load() {
+		// The request was abandoned, typically because the tool call that
+		// made it was cancelled
+		s.logger.Infof("Discarding late sampling response for session %s, request %d", sessionID, response.requestID)
+		return nil
+	}
 	if !exists {
 		return fmt.Errorf("no pending request found for session %s, request %d", sessionID, response.requestID)
 	}
@@ -656,6 +1059,19 @@ func (s *StreamableHTTPServer) nextRequestID(sessionID string) int64 {
 	return counter.Add(1)
 }
 
+// cancelWhenDone returns a copy of ctx that is also canceled when done is,
+// and a function releasing the resources associated with it.
+func cancelWhenDone(ctx, done context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(done, func() {
+		cancel(context.Cause(done))
+	})
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
 // --- session ---
 type sessionLogLevelsStore struct {
 	mu   sync.RWMutex
@@ -719,6 +1135,132 @@ func (s *sessionToolsStore) delete(sessionID string) {
 	delete(s.tools, sessionID)
 }
 
+type sessionPromptsStore struct {
+	mu      sync.RWMutex
+	prompts map[string]map[string]ServerPrompt // sessionID -> promptName -> prompt
+}
+
+func newSessionPromptsStore() *sessionPromptsStore {
+	return &sessionPromptsStore{
+		prompts: make(map[string]map[string]ServerPrompt),
+	}
+}
+
+func (s *sessionPromptsStore) get(sessionID string) map[string]ServerPrompt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.prompts[sessionID]
+}
+
+func (s *sessionPromptsStore) set(sessionID string, prompts map[string]ServerPrompt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts[sessionID] = prompts
+}
+
+func (s *sessionPromptsStore) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.prompts, sessionID)
+}
+
+// defaultSessionIdleTimeout is how long client info is kept after the last
+// request of its session, unless set with WithSessionIdleTimeout.
+const defaultSessionIdleTimeout = time.Hour
+
+// sessionClientInfo is what a client reported about itself in initialize,
+// and the protocol version negotiated with it.
+type sessionClientInfo struct {
+	info            mcp.Implementation
+	capabilities    mcp.ClientCapabilities
+	protocolVersion string
+	lastSeen        time.Time
+}
+
+type sessionClientInfoStore struct {
+	mu      sync.RWMutex
+	clients map[string]sessionClientInfo // sessionID -> client info
+	// idleTimeout is how long an entry is kept after its session was last
+	// seen; zero keeps it until it is deleted. Idle entries are dropped by
+	// update, at most once per idleTimeout, so an entry may outlive it by
+	// up to twice.
+	idleTimeout time.Duration
+	lastExpiry  time.Time
+	// onExpire is called with the ID of every session whose entry expired,
+	// so other state of the session can be dropped with it.
+	onExpire func(sessionID string)
+}
+
+func newSessionClientInfoStore(idleTimeout time.Duration, onExpire func(sessionID string)) *sessionClientInfoStore {
+	return &sessionClientInfoStore{
+		clients:     make(map[string]sessionClientInfo),
+		idleTimeout: idleTimeout,
+		lastExpiry:  time.Now(),
+		onExpire:    onExpire,
+	}
+}
+
+func (s *sessionClientInfoStore) get(sessionID string) sessionClientInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clients[sessionID]
+}
+
+func (s *sessionClientInfoStore) update(sessionID string, update func(*sessionClientInfo)) {
+	// Stateless sessions share the empty ID, so nothing is kept for them
+	if sessionID == "" {
+		return
+	}
+	s.mu.Lock()
+	now := time.Now()
+	expired := s.expire(now)
+	client := s.clients[sessionID]
+	update(&client)
+	client.lastSeen = now
+	s.clients[sessionID] = client
+	s.mu.Unlock()
+
+	if s.onExpire != nil {
+		for _, expiredID := range expired {
+			s.onExpire(expiredID)
+		}
+	}
+}
+
+// touch marks the session as seen, if the store has an entry for it.
+func (s *sessionClientInfoStore) touch(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if client, ok := s.clients[sessionID]; ok {
+		client.lastSeen = time.Now()
+		s.clients[sessionID] = client
+	}
+}
+
+// expire drops the entries of sessions not seen for idleTimeout, if it has
+// not done so within the last idleTimeout, and returns their session IDs.
+// The caller must hold mu.
+func (s *sessionClientInfoStore) expire(now time.Time) []string {
+	if s.idleTimeout <= 0 || now.Sub(s.lastExpiry) < s.idleTimeout {
+		return nil
+	}
+	s.lastExpiry = now
+	var expired []string
+	for sessionID, client := range s.clients {
+		if now.Sub(client.lastSeen) >= s.idleTimeout {
+			delete(s.clients, sessionID)
+			expired = append(expired, sessionID)
+		}
+	}
+	return expired
+}
+
+func (s *sessionClientInfoStore) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, sessionID)
+}
+
 // Sampling support types for HTTP transport
 type samplingRequestItem struct {
 	requestID int64
@@ -739,9 +1281,22 @@ type streamableHttpSession struct {
 	sessionID           string
 	notificationChannel chan mcp.JSONRPCNotification // server -> client notifications
 	tools               *sessionToolsStore
+	prompts             *sessionPromptsStore
+	clientInfo          *sessionClientInfoStore
 	upgradeToSSE        atomic.Bool
 	logLevels           *sessionLogLevelsStore
 
+	// closed ends the standalone GET stream when the server cancels the session
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// Once the response to the request of a POST session has been written,
+	// responded is set and notifications are passed to late instead. mu
+	// keeps a notification from being queued while the response is written.
+	mu        sync.RWMutex
+	responded bool
+	late      func(mcp.JSONRPCNotification) error
+
 	// Sampling support for bidirectional communication
 	samplingRequestChan  chan samplingRequestItem      // server -> client sampling requests
 	samplingRequests     sync.Map                      // requestID -> pending sampling request context
@@ -755,10 +1310,74 @@ func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore, le
 		tools:                toolStore,
 		logLevels:            levels,
 		samplingRequestChan:  make(chan samplingRequestItem, 10),
+		closed:               make(chan struct{}),
 	}
 	return s
 }
 
+// closeSession ends the standalone GET stream of the session, if any.
+func (s *streamableHttpSession) closeSession() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+// writeNotification queues the notification for the stream of the session.
+// Notifications sent after the response to a POST request are handed to the
+// late function set by finishResponse.
+func (s *streamableHttpSession) writeNotification(notification mcp.JSONRPCNotification) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.responded {
+		return s.late(notification)
+	}
+	select {
+	case s.notificationChannel <- notification:
+		return nil
+	default:
+		return ErrNotificationChannelBlocked
+	}
+}
+
+var _ notificationWriter = (*streamableHttpSession)(nil)
+
+// finishResponse marks the response of a POST session as about to be
+// written. It returns the notifications still queued, which must be written
+// before the response, and passes later notifications to late.
+func (s *streamableHttpSession) finishResponse(late func(mcp.JSONRPCNotification) error) []mcp.JSONRPCNotification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responded = true
+	s.late = late
+	var pending []mcp.JSONRPCNotification
+	for {
+		select {
+		case notification := <-s.notificationChannel:
+			pending = append(pending, notification)
+		default:
+			return pending
+		}
+	}
+}
+
+// lateNotifications returns the function that delivers the notifications a
+// POST request's handler sends after its response: they go to the standalone
+// GET stream of the session if it has one, and are dropped otherwise.
+func (s *StreamableHTTPServer) lateNotifications(sessionID string) func(mcp.JSONRPCNotification) error {
+	return func(notification mcp.JSONRPCNotification) error {
+		value, ok := s.activeSessions.Load(sessionID)
+		if !ok || sessionID == "" {
+			return ErrNotificationAfterResponse
+		}
+		select {
+		case value.(*streamableHttpSession).notificationChannel <- notification:
+			return nil
+		default:
+			return ErrNotificationChannelBlocked
+		}
+	}
+}
+
 func (s *streamableHttpSession) SessionID() string {
 	return s.sessionID
 }
@@ -795,9 +1414,65 @@ func (s *streamableHttpSession) SetSessionTools(tools map[string]ServerTool) {
 	s.tools.set(s.sessionID, tools)
 }
 
+func (s *streamableHttpSession) GetSessionPrompts() map[string]ServerPrompt {
+	if s.prompts == nil {
+		return nil
+	}
+	return s.prompts.get(s.sessionID)
+}
+
+func (s *streamableHttpSession) SetSessionPrompts(prompts map[string]ServerPrompt) {
+	if s.prompts == nil {
+		return
+	}
+	s.prompts.set(s.sessionID, prompts)
+}
+
+func (s *streamableHttpSession) GetClientInfo() mcp.Implementation {
+	if s.clientInfo == nil {
+		return mcp.Implementation{}
+	}
+	return s.clientInfo.get(s.sessionID).info
+}
+
+func (s *streamableHttpSession) SetClientInfo(clientInfo mcp.Implementation) {
+	if s.clientInfo != nil {
+		s.clientInfo.update(s.sessionID, func(client *sessionClientInfo) { client.info = clientInfo })
+	}
+}
+
+func (s *streamableHttpSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if s.clientInfo == nil {
+		return mcp.ClientCapabilities{}
+	}
+	return s.clientInfo.get(s.sessionID).capabilities
+}
+
+func (s *streamableHttpSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabilities) {
+	if s.clientInfo != nil {
+		s.clientInfo.update(s.sessionID, func(client *sessionClientInfo) { client.capabilities = clientCapabilities })
+	}
+}
+
+func (s *streamableHttpSession) GetProtocolVersion() string {
+	if s.clientInfo == nil {
+		return ""
+	}
+	return s.clientInfo.get(s.sessionID).protocolVersion
+}
+
+func (s *streamableHttpSession) SetProtocolVersion(version string) {
+	if s.clientInfo != nil {
+		s.clientInfo.update(s.sessionID, func(client *sessionClientInfo) { client.protocolVersion = version })
+	}
+}
+
 var (
-	_ SessionWithTools   = (*streamableHttpSession)(nil)
-	_ SessionWithLogging = (*streamableHttpSession)(nil)
+	_ SessionWithTools           = (*streamableHttpSession)(nil)
+	_ SessionWithPrompts         = (*streamableHttpSession)(nil)
+	_ SessionWithLogging         = (*streamableHttpSession)(nil)
+	_ SessionWithClientInfo      = (*streamableHttpSession)(nil)
+	_ SessionWithProtocolVersion = (*streamableHttpSession)(nil)
 )
 
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
@@ -843,6 +1518,12 @@ func (s *streamableHttpSession) RequestSampling(ctx context.Context, request mcp
 		}
 		return response.result, nil
 	case <-ctx.Done():
+		// Let the client stop its sampling handler. A response it sends anyway
+		// is discarded by deliverSamplingResponse.
+		select {
+		case s.notificationChannel <- newCancelledNotification(mcp.NewRequestId(requestID), context.Cause(ctx).Error()):
+		default:
+		}
 		return nil, ctx.Err()
 	}
 }
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MultiplexHandler serves several MCP servers over streamable HTTP from one
+// http.Handler, each under its own endpoint path. Every server keeps its own
+// sessions: the session IDs it issues carry the path they belong to, so a
+// session created under one path is rejected under another.
+type MultiplexHandler struct {
+	servers map[string]*StreamableHTTPServer
+}
+
+// NewMultiplexHandler creates a handler serving each server at the endpoint
+// path it is keyed by, such as "/github" or "/jira/mcp". Paths are matched
+// exactly against the request path, ignoring leading and trailing slashes;
+// other paths are answered with 404 Not Found. The options are applied to the
+// streamable HTTP server of every path. WithEndpointPath is ignored.
+func NewMultiplexHandler(servers map[string]*MCPServer, opts ...StreamableHTTPOption) *MultiplexHandler {
+	h := &MultiplexHandler{servers: make(map[string]*StreamableHTTPServer, len(servers))}
+	for path, server := range servers {
+		path = normalizeEndpointPath(path)
+		s := NewStreamableHTTPServer(server, opts...)
+		s.endpointPath = path
+		if _, stateless := s.sessionIdManager.(*StatelessSessionIdManager); !stateless {
+			s.sessionIdManager = &namespacedSessionIdManager{
+				namespace: strings.Trim(path, "/") + ":",
+				manager:   s.sessionIdManager,
+			}
+		}
+		h.servers[path] = s
+	}
+	return h
+}
+
+// ServeHTTP routes the request to the server mounted at its path.
+func (h *MultiplexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.servers[normalizeEndpointPath(r.URL.Path)]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.ServeHTTP(w, r)
+}
+
+// Server returns the streamable HTTP server mounted at path, for example to
+// read its Stats. It returns nil if no server is mounted there.
+func (h *MultiplexHandler) Server(path string) *StreamableHTTPServer {
+	return h.servers[normalizeEndpointPath(path)]
+}
+
+// Shutdown shuts down every mounted server, running their shutdown hooks.
+// It does not stop the http.Server serving the handler.
+func (h *MultiplexHandler) Shutdown(ctx context.Context) error {
+	var errs []error
+	for path, s := range h.servers {
+		if err := s.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server %q: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeEndpointPath makes path start with a slash and not end with one.
+func normalizeEndpointPath(path string) string {
+	return "/" + strings.Trim(path, "/")
+}
+
+// namespacedSessionIdManager prefixes the session IDs of a SessionIdManager
+// with a namespace, and rejects the IDs of other namespaces.
+type namespacedSessionIdManager struct {
+	namespace string
+	manager   SessionIdManager
+}
+
+func (m *namespacedSessionIdManager) Generate() string {
+	return m.namespace + m.manager.Generate()
+}
+
+func (m *namespacedSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
+	id, ok := strings.CutPrefix(sessionID, m.namespace)
+	if !ok {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	return m.manager.Validate(id)
+}
+
+func (m *namespacedSessionIdManager) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	id, ok := strings.CutPrefix(sessionID, m.namespace)
+	if !ok {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	return m.manager.Terminate(id)
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMultiplexHandler(t *testing.T) {
+	var shutdowns []string
+	newServer := func(name string) *MCPServer {
+		s := NewMCPServer(name, "1.0.0", WithShutdownHook(func(ctx context.Context) {
+			shutdowns = append(shutdowns, name)
+		}))
+		s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name), nil
+		})
+		return s
+	}
+	handler := NewMultiplexHandler(map[string]*MCPServer{
+		"github":     newServer("github-server"),
+		"/jira/mcp/": newServer("jira-server"),
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	post := func(path, sessionID string, message any) *http.Response {
+		t.Helper()
+		body, err := json.Marshal(message)
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(HeaderKeySessionID, sessionID)
+		}
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+	whoami := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      2,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": "whoami"},
+	}
+
+	sessions := map[string]string{}
+	for path, name := range map[string]string{"/github": "github-server", "/jira/mcp": "jira-server"} {
+		resp := post(path, "", initRequest)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var initialized jsonRPCResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&initialized))
+		assert.Equal(t, name, initialized.Result["serverInfo"].(map[string]any)["name"])
+
+		sessionID := resp.Header.Get(HeaderKeySessionID)
+		require.NotEmpty(t, sessionID)
+		sessions[path] = sessionID
+
+		// Trailing slashes reach the same server
+		resp = post(path+"/", sessionID, whoami)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Len(t, result.Result.Content, 1)
+		assert.Equal(t, name, result.Result.Content[0].(mcp.TextContent).Text)
+	}
+	assert.True(t, strings.HasPrefix(sessions["/github"], "github:"), sessions["/github"])
+	assert.True(t, strings.HasPrefix(sessions["/jira/mcp"], "jira/mcp:"), sessions["/jira/mcp"])
+
+	// A session belongs to the server that created it
+	assert.Equal(t, http.StatusBadRequest, post("/jira/mcp", sessions["/github"], whoami).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, post("/github", sessions["/jira/mcp"], whoami).StatusCode)
+
+	assert.Equal(t, http.StatusNotFound, post("/jira", "", initRequest).StatusCode)
+	assert.Equal(t, http.StatusNotFound, post("/", "", initRequest).StatusCode)
+
+	require.NotNil(t, handler.Server("/github/"))
+	assert.Nil(t, handler.Server("/missing"))
+
+	require.NoError(t, handler.Shutdown(context.Background()))
+	assert.ElementsMatch(t, []string{"github-server", "jira-server"}, shutdowns)
+}
+
+func TestMultiplexHandler_Stateless(t *testing.T) {
+	handler := NewMultiplexHandler(map[string]*MCPServer{
+		"a": NewMCPServer("a", "1.0.0"),
+	}, WithStateLess(true))
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	resp, err := postJSON(ts.URL+"/a", initRequest)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(HeaderKeySessionID))
+}
@@ -3,12 +3,16 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -894,9 +898,1227 @@ func TestStreamableHTTP_HeaderPassthrough(t *testing.T) {
 	}
 }
 
+func TestStreamableHTTP_MaxConcurrentRequestsPerSession(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0",
+		WithMaxConcurrentRequestsPerSession(1),
+	)
+
+	started := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	mcpServer.AddTool(
+		mcp.NewTool("slow"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			started <- struct{}{}
+			<-unblock
+			return mcp.NewToolResultText("done"), nil
+		},
+	)
+
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	resp.Body.Close()
+
+	callTool := func(id int) (*http.Response, error) {
+		body, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"method":  "tools/call",
+			"params": map[string]any{
+				"name": "slow",
+			},
+		})
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		return server.Client().Do(req)
+	}
+
+	firstDone := make(chan error, 1)
+	go func() {
+		resp, err := callTool(2)
+		if err == nil {
+			resp.Body.Close()
+		}
+		firstDone <- err
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for first tool call to start")
+	}
+
+	// The second request exceeds the limit and must be rejected
+	resp, err = callTool(3)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	var rejected struct {
+		ID    int `json:"id"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rejected); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if rejected.ID != 3 {
+		t.Errorf("Expected id 3, got %d", rejected.ID)
+	}
+	if rejected.Error == nil {
+		t.Fatalf("Expected error response, got %+v", rejected)
+	}
+	if rejected.Error.Code != mcp.SERVER_BUSY {
+		t.Errorf("Expected error code %d, got %d", mcp.SERVER_BUSY, rejected.Error.Code)
+	}
+
+	close(unblock)
+	if err := <-firstDone; err != nil {
+		t.Fatalf("First tool call failed: %v", err)
+	}
+
+	// Once the first request has finished, new requests are accepted again
+	resp, err = callTool(4)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	var accepted jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&accepted); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if accepted.Error != nil {
+		t.Errorf("Expected successful response, got error %+v", accepted.Error)
+	}
+}
+
+func TestStreamableHTTP_TransportAndRawMessage(t *testing.T) {
+	var hookTransport TransportType
+	var hookRaw, handlerRaw []byte
+	var handlerTransport TransportType
+
+	hooks := &Hooks{}
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		hookTransport = TransportFromContext(ctx)
+		hookRaw = RawMessageFromContext(ctx)
+	})
+
+	mcpServer := NewMCPServer("test-mcp-server", "1.0",
+		WithHooks(hooks),
+		WithRawMessageCapture(40),
+	)
+	mcpServer.AddTool(mcp.NewTool("inspect"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		handlerTransport = TransportFromContext(ctx)
+		handlerRaw = RawMessageFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	resp.Body.Close()
+
+	body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"inspect"}}`
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderKeySessionID, sessionID)
+	resp, err = server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+
+	if handlerTransport != TransportStreamableHTTP {
+		t.Errorf("Expected handler transport %q, got %q", TransportStreamableHTTP, handlerTransport)
+	}
+	if hookTransport != TransportStreamableHTTP {
+		t.Errorf("Expected hook transport %q, got %q", TransportStreamableHTTP, hookTransport)
+	}
+	// The capture is bounded to the configured limit
+	if string(handlerRaw) != body[:40] {
+		t.Errorf("Expected raw message %q, got %q", body[:40], handlerRaw)
+	}
+	if string(hookRaw) != body[:40] {
+		t.Errorf("Expected hook raw message %q, got %q", body[:40], hookRaw)
+	}
+}
+
+func TestStreamableHTTP_ClientInfo(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("client"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info := ClientInfoFromContext(ctx)
+		return mcp.NewToolResultText(info.Name + "/" + info.Version), nil
+	})
+
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	callClientTool := func(sessionID string) string {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"client"}}`
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response jsonRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		content, _ := response.Result["content"].([]any)
+		if len(content) != 1 {
+			t.Fatalf("Expected one content item, got %v", response.Result)
+		}
+		return content[0].(map[string]any)["text"].(string)
+	}
+
+	// Each POST gets a fresh session object, so the info must outlive the
+	// initialize request
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	resp.Body.Close()
+
+	if got := callClientTool(sessionID); got != "test-client/1.0.0" {
+		t.Errorf("Expected client info %q, got %q", "test-client/1.0.0", got)
+	}
+}
+
+func TestStreamableHTTP_ProtocolVersion(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("version"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(ProtocolVersionFromContext(ctx)), nil
+	})
+
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	initialize := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params": map[string]any{
+			"protocolVersion": "2025-03-26",
+			"clientInfo":      map[string]any{"name": "test-client", "version": "1.0.0"},
+		},
+	}
+	resp, err := postJSON(server.URL, initialize)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	resp.Body.Close()
+
+	// Clients of this version send no Mcp-Protocol-Version header, so the
+	// version must be kept with the session
+	body := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"version"}}`
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderKeySessionID, sessionID)
+	resp, err = server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var response jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	content, _ := response.Result["content"].([]any)
+	if len(content) != 1 {
+		t.Fatalf("Expected one content item, got %v", response.Result)
+	}
+	if got := content[0].(map[string]any)["text"]; got != "2025-03-26" {
+		t.Errorf("Expected protocol version %q, got %q", "2025-03-26", got)
+	}
+}
+
+func TestStreamableHTTP_TitlesForProtocolVersion(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("greet", mcp.WithTitle("Greeter")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("hello"), nil
+	})
+
+	server := NewTestStreamableHTTPServer(mcpServer, WithStateLess(true))
+	defer server.Close()
+
+	// listToolTitle lists the tools with the given Mcp-Protocol-Version
+	// header, if any, and returns the title of the only tool
+	listToolTitle := func(t *testing.T, version string) any {
+		t.Helper()
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if version != "" {
+			req.Header.Set(HeaderKeyProtocolVersion, version)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response jsonRPCResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		tools, _ := response.Result["tools"].([]any)
+		if len(tools) != 1 {
+			t.Fatalf("Expected one tool, got %v", response.Result)
+		}
+		return tools[0].(map[string]any)["title"]
+	}
+
+	t.Run("header with a version that knows titles", func(t *testing.T) {
+		if got := listToolTitle(t, mcp.LATEST_PROTOCOL_VERSION); got != "Greeter" {
+			t.Errorf("Expected title %q, got %v", "Greeter", got)
+		}
+	})
+
+	t.Run("header with an older version", func(t *testing.T) {
+		if got := listToolTitle(t, "2025-03-26"); got != nil {
+			t.Errorf("Expected no title, got %v", got)
+		}
+	})
+
+	t.Run("no header is treated as 2025-03-26", func(t *testing.T) {
+		if got := listToolTitle(t, ""); got != nil {
+			t.Errorf("Expected no title, got %v", got)
+		}
+	})
+}
+
+func TestSessionClientInfoStore_Expiry(t *testing.T) {
+	var expired []string
+	store := newSessionClientInfoStore(time.Minute, func(sessionID string) {
+		expired = append(expired, sessionID)
+	})
+	store.update("idle", func(client *sessionClientInfo) { client.info.Name = "idle-client" })
+	store.update("active", func(client *sessionClientInfo) { client.info.Name = "active-client" })
+
+	// Pretend both sessions were last seen, and entries last expired, two
+	// minutes ago, then let the active session send a request
+	past := time.Now().Add(-2 * time.Minute)
+	for sessionID, client := range store.clients {
+		client.lastSeen = past
+		store.clients[sessionID] = client
+	}
+	store.lastExpiry = past
+	store.touch("active")
+
+	store.update("new", func(client *sessionClientInfo) { client.info.Name = "new-client" })
+
+	if got := store.get("idle").info.Name; got != "" {
+		t.Errorf("Expected the idle session to expire, got client %q", got)
+	}
+	if got := store.get("active").info.Name; got != "active-client" {
+		t.Errorf("Expected the active session to be kept, got client %q", got)
+	}
+	if got := store.get("new").info.Name; got != "new-client" {
+		t.Errorf("Expected the new session to be stored, got client %q", got)
+	}
+	if len(expired) != 1 || expired[0] != "idle" {
+		t.Errorf("Expected only the idle session to be reported as expired, got %v", expired)
+	}
+}
+
+func TestStreamableHTTP_Reinitialize(t *testing.T) {
+	type initializeResponse struct {
+		Result map[string]any `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	// initialize sends an initialize request for the given protocol version,
+	// on the session with the given ID if any
+	initialize := func(t *testing.T, url, sessionID, version string) (string, initializeResponse) {
+		t.Helper()
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q,"clientInfo":{"name":"test-client","version":"1.0.0"}}}`, version)
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(HeaderKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response initializeResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.Header.Get(HeaderKeySessionID), response
+	}
+
+	t.Run("returns the original result", func(t *testing.T) {
+		reinitialized := make(chan string, 2)
+		hooks := &Hooks{}
+		hooks.AddOnReinitialize(func(ctx context.Context, id any, request *mcp.InitializeRequest, original *mcp.InitializeResult) {
+			reinitialized <- ClientSessionFromContext(ctx).SessionID()
+		})
+		server := NewTestStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0", WithHooks(hooks)))
+		defer server.Close()
+
+		sessionID, first := initialize(t, server.URL, "", "2025-03-26")
+		if first.Error != nil || sessionID == "" {
+			t.Fatalf("Initialize failed: %v", first.Error)
+		}
+
+		for _, version := range []string{"2025-03-26", "2024-11-05"} {
+			gotID, response := initialize(t, server.URL, sessionID, version)
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %v", response.Error)
+			}
+			if gotID != sessionID {
+				t.Errorf("Expected the session %q to be kept, got %q", sessionID, gotID)
+			}
+			if !reflect.DeepEqual(response.Result, first.Result) {
+				t.Errorf("Expected the original result %v, got %v", first.Result, response.Result)
+			}
+			select {
+			case id := <-reinitialized:
+				if id != sessionID {
+					t.Errorf("Expected the hook to see session %q, got %q", sessionID, id)
+				}
+			default:
+				t.Error("Expected the reinitialize hook to be called")
+			}
+		}
+
+		// An unknown session ID does not adopt the client's choice
+		gotID, response := initialize(t, server.URL, "mcp-session-unknown", "2024-11-05")
+		if response.Error != nil || gotID == "mcp-session-unknown" || gotID == sessionID {
+			t.Errorf("Expected a new session, got %q (error %v)", gotID, response.Error)
+		}
+		if version := response.Result["protocolVersion"]; version != "2024-11-05" {
+			t.Errorf("Expected a new negotiation, got version %v", version)
+		}
+	})
+
+	t.Run("strict rejects a downgrade", func(t *testing.T) {
+		server := NewTestStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0", WithStrictReinitialization()))
+		defer server.Close()
+
+		sessionID, response := initialize(t, server.URL, "", "2025-03-26")
+		if response.Error != nil {
+			t.Fatalf("Initialize failed: %v", response.Error)
+		}
+		if _, response := initialize(t, server.URL, sessionID, "2025-03-26"); response.Error != nil {
+			t.Errorf("Expected an identical initialize to succeed, got %v", response.Error)
+		}
+
+		_, response = initialize(t, server.URL, sessionID, "2024-11-05")
+		if response.Error == nil || response.Error.Code != mcp.INVALID_REQUEST {
+			t.Fatalf("Expected an INVALID_REQUEST error, got %+v", response)
+		}
+		if !strings.Contains(response.Error.Message, ErrSessionAlreadyInitialized.Error()) {
+			t.Errorf("Expected %q in the error, got %q", ErrSessionAlreadyInitialized, response.Error.Message)
+		}
+
+		// Once terminated, the session can't be initialized again
+		req, _ := http.NewRequest(http.MethodDelete, server.URL, nil)
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+		resp.Body.Close()
+		if gotID, response := initialize(t, server.URL, sessionID, "2024-11-05"); response.Error != nil || gotID == sessionID {
+			t.Errorf("Expected a new session, got %q (error %v)", gotID, response.Error)
+		}
+	})
+
+	t.Run("stateless servers negotiate every time", func(t *testing.T) {
+		server := NewTestStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0", WithStrictReinitialization()), WithStateLess(true))
+		defer server.Close()
+
+		for _, version := range []string{"2025-03-26", "2024-11-05"} {
+			_, response := initialize(t, server.URL, "", version)
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %v", response.Error)
+			}
+			if got := response.Result["protocolVersion"]; got != version {
+				t.Errorf("Expected version %q, got %v", version, got)
+			}
+		}
+	})
+}
+
+func TestStreamableHTTP_RawMessageCaptureDisabledByDefault(t *testing.T) {
+	var raw []byte
+	called := false
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("inspect"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		raw = RawMessageFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	server := NewTestStreamableHTTPServer(mcpServer, WithStateLess(true))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": "inspect"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+
+	if !called {
+		t.Fatal("Expected tool handler to be called")
+	}
+	if raw != nil {
+		t.Errorf("Expected no raw message without WithRawMessageCapture, got %q", raw)
+	}
+}
+
+func TestStreamableHTTP_Compression(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("text", "")), nil
+	})
+	server := NewTestStreamableHTTPServer(mcpServer,
+		WithStateLess(true),
+		WithCompression(),
+		WithMaxRequestBodySize(8<<20),
+	)
+	defer server.Close()
+
+	gzipBody := func(t *testing.T, data []byte) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatalf("Failed to compress body: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("Failed to compress body: %v", err)
+		}
+		return &buf
+	}
+
+	t.Run("gzipped request and response round trip", func(t *testing.T) {
+		text := strings.Repeat("a", 5<<20)
+		body, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  "tools/call",
+			"params":  map[string]any{"name": "echo", "arguments": map[string]any{"text": text}},
+		})
+		compressed := gzipBody(t, body)
+		if compressed.Len() >= len(body)/100 {
+			t.Fatalf("Expected a highly compressed body, got %d bytes", compressed.Len())
+		}
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL, compressed)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzipped response, got Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read gzipped response: %v", err)
+		}
+		var response struct {
+			Result mcp.CallToolResult `json:"result"`
+		}
+		if err := json.NewDecoder(zr).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if got := response.Result.Content[0].(mcp.TextContent).Text; got != text {
+			t.Errorf("Expected echoed text of %d bytes, got %d bytes", len(text), len(got))
+		}
+	})
+
+	t.Run("decompression bomb is rejected", func(t *testing.T) {
+		bomb := gzipBody(t, bytes.Repeat([]byte(" "), 64<<20))
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bomb)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("unsupported encoding is rejected", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "br")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status 415, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("small responses are not compressed", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("Expected uncompressed response, got Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+		}
+	})
+}
+
+func TestStreamableHTTP_MaxRequestBodySize(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	server := NewTestStreamableHTTPServer(mcpServer, WithStateLess(true), WithMaxRequestBodySize(64))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "ping",
+		"params":  map[string]any{"padding": strings.Repeat("x", 100)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", resp.StatusCode)
+	}
+
+	resp, err = postJSON(server.URL, map[string]any{"jsonrpc": "2.0", "id": 1, "method": "ping"})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
 func postJSON(url string, bodyObject any) (*http.Response, error) {
 	jsonBody, _ := json.Marshal(bodyObject)
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	return http.DefaultClient.Do(req)
 }
+
+func newHTTP2TestServer(handler http.Handler) *httptest.Server {
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	return server
+}
+
+func TestStreamableHTTP_HTTP2StreamClosure(t *testing.T) {
+	t.Run("GET stream closure unregisters the session", func(t *testing.T) {
+		registered := make(chan string, 1)
+		unregistered := make(chan error, 1)
+		hooks := &Hooks{}
+		hooks.AddOnRegisterSession(func(ctx context.Context, session ClientSession) {
+			registered <- session.SessionID()
+		})
+		hooks.AddOnUnregisterSession(func(ctx context.Context, session ClientSession) {
+			unregistered <- ctx.Err()
+		})
+		mcpServer := NewMCPServer("test-mcp-server", "1.0", WithHooks(hooks))
+		server := newHTTP2TestServer(NewStreamableHTTPServer(mcpServer))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("Expected an HTTP/2 stream, got %s", resp.Proto)
+		}
+
+		select {
+		case <-registered:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Session was not registered")
+		}
+
+		// Closing the body resets the HTTP/2 stream while the connection stays open
+		resp.Body.Close()
+		select {
+		case err := <-unregistered:
+			if err != nil {
+				t.Errorf("Expected the unregister hook to get a live context, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Session was not unregistered after the stream closed")
+		}
+	})
+
+	t.Run("POST stream closure cancels the handler", func(t *testing.T) {
+		started := make(chan struct{})
+		canceled := make(chan struct{})
+		mcpServer := NewMCPServer("test-mcp-server", "1.0")
+		mcpServer.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		})
+		server := newHTTP2TestServer(NewStreamableHTTPServer(mcpServer,
+			WithStateLess(true),
+			// With WithCancelOnDisconnect, a context func detaching the
+			// context must not keep the handler running
+			WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+				return context.WithoutCancel(ctx)
+			}),
+			WithCancelOnDisconnect(),
+		))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}}`
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		go func() {
+			resp, err := server.Client().Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Tool handler did not start")
+		}
+		cancel()
+		select {
+		case <-canceled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Tool handler was not canceled after the stream closed")
+		}
+	})
+
+	t.Run("POST handler keeps a detached context by default", func(t *testing.T) {
+		started := make(chan struct{})
+		finished := make(chan error, 1)
+		mcpServer := NewMCPServer("test-mcp-server", "1.0")
+		mcpServer.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			select {
+			case <-ctx.Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			finished <- ctx.Err()
+			return mcp.NewToolResultText("done"), nil
+		})
+		server := newHTTP2TestServer(NewStreamableHTTPServer(mcpServer,
+			WithStateLess(true),
+			WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+				return context.WithoutCancel(ctx)
+			}),
+		))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait"}}`
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		go func() {
+			resp, err := server.Client().Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Tool handler did not start")
+		}
+		cancel()
+		select {
+		case err := <-finished:
+			if err != nil {
+				t.Errorf("Expected the detached context to outlive the stream, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Tool handler did not finish")
+		}
+	})
+}
+
+func TestServeStreamableHTTP(t *testing.T) {
+	t.Run("Serves until the context is done", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to find a free port: %v", err)
+		}
+		addr := listener.Addr().String()
+		listener.Close()
+
+		hookCalled := make(chan struct{})
+		mcpServer := NewMCPServer("test", "1.0.0", WithShutdownHook(func(ctx context.Context) {
+			close(hookCalled)
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- serveStreamableHTTP(ctx, NewStreamableHTTPServer(mcpServer), addr)
+		}()
+
+		body, _ := json.Marshal(initRequest)
+		var resp *http.Response
+		for range 50 {
+			resp, err = http.Post("http://"+addr+"/mcp", "application/json", bytes.NewReader(body))
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("Failed to send initialize request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+
+		cancel()
+		select {
+		case err := <-errChan:
+			if err != nil {
+				t.Errorf("Expected nil error after shutdown, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Server did not shut down")
+		}
+		select {
+		case <-hookCalled:
+		default:
+			t.Error("Shutdown hooks were not run")
+		}
+	})
+
+	t.Run("Stops when canceled before listening", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- serveStreamableHTTP(ctx, NewStreamableHTTPServer(NewMCPServer("test", "1.0.0")), "127.0.0.1:0")
+		}()
+		select {
+		case err := <-errChan:
+			if err != nil {
+				t.Errorf("Expected nil error after shutdown, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Server did not shut down")
+		}
+	})
+
+	t.Run("Returns listen errors", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		defer listener.Close()
+
+		err = ServeStreamableHTTP(NewMCPServer("test", "1.0.0"), listener.Addr().String())
+		if err == nil {
+			t.Error("Expected an error for an address in use")
+		}
+	})
+}
+
+func TestStreamableHTTP_JSONResponseNegotiation(t *testing.T) {
+	callTool := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      2,
+		"method":  "tools/call",
+		"params":  map[string]any{"name": "sseTool"},
+	}
+
+	postJSONOnly := func(t *testing.T, url, accept string) (*http.Response, []byte) {
+		t.Helper()
+		initResp, err := postJSON(url, initRequest)
+		if err != nil {
+			t.Fatalf("Failed to send initialize: %v", err)
+		}
+		initResp.Body.Close()
+
+		body, _ := json.Marshal(callTool)
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		req.Header.Set(HeaderKeySessionID, initResp.Header.Get(HeaderKeySessionID))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to call tool: %v", err)
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+		return resp, respBody
+	}
+
+	t.Run("drops notifications by default", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0")
+		addSSETool(mcpServer)
+		server := NewTestStreamableHTTPServer(mcpServer, WithJSONResponseNegotiation())
+		defer server.Close()
+
+		resp, body := postJSONOnly(t, server.URL, "application/json")
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("Expected content-type application/json, got %s", ct)
+		}
+		var response jsonRPCResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Expected a single response, got %s: %v", body, err)
+		}
+		if response.ID != 2 || response.Result == nil {
+			t.Errorf("Unexpected response: %s", body)
+		}
+	})
+
+	t.Run("inlines notifications", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0")
+		addSSETool(mcpServer)
+		server := NewTestStreamableHTTPServer(mcpServer,
+			WithJSONResponseNegotiation(),
+			WithJSONNotificationPolicy(JSONNotificationsInline),
+		)
+		defer server.Close()
+
+		resp, body := postJSONOnly(t, server.URL, "application/json")
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("Expected content-type application/json, got %s", ct)
+		}
+		var batch []map[string]any
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Fatalf("Expected a batch, got %s: %v", body, err)
+		}
+		if len(batch) != 11 {
+			t.Fatalf("Expected 10 notifications and the response, got %d messages", len(batch))
+		}
+		for i, message := range batch[:10] {
+			if message["method"] != "test/notification" {
+				t.Fatalf("Expected notification at %d, got %v", i, message)
+			}
+			params := message["params"].(map[string]any)
+			if params["value"] != float64(i) {
+				t.Errorf("Expected notification %d in order, got %v", i, params["value"])
+			}
+		}
+		if batch[10]["id"] != float64(2) || batch[10]["result"] == nil {
+			t.Errorf("Expected the response last, got %v", batch[10])
+		}
+	})
+
+	t.Run("streams when the client accepts SSE", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0")
+		addSSETool(mcpServer)
+		server := NewTestStreamableHTTPServer(mcpServer, WithJSONResponseNegotiation())
+		defer server.Close()
+
+		resp, _ := postJSONOnly(t, server.URL, "application/json, text/event-stream")
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Expected content-type text/event-stream, got %s", ct)
+		}
+	})
+
+	t.Run("streams without negotiation", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0")
+		addSSETool(mcpServer)
+		server := NewTestStreamableHTTPServer(mcpServer)
+		defer server.Close()
+
+		resp, _ := postJSONOnly(t, server.URL, "application/json")
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Expected content-type text/event-stream, got %s", ct)
+		}
+	})
+}
+
+// readSSEMessages parses every event of an SSE body as a JSON-RPC message,
+// failing the test if any event is malformed.
+func readSSEMessages(t *testing.T, body []byte) []map[string]any {
+	t.Helper()
+	var messages []map[string]any
+	for _, event := range strings.Split(strings.TrimSpace(string(body)), "\n\n") {
+		lines := strings.Split(event, "\n")
+		if len(lines) != 2 || lines[0] != "event: message" || !strings.HasPrefix(lines[1], "data: ") {
+			t.Fatalf("Malformed SSE event %q", event)
+		}
+		var message map[string]any
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &message); err != nil {
+			t.Fatalf("Unparsable SSE data %q: %v", lines[1], err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func TestStreamableHTTP_NotificationsAfterReturn(t *testing.T) {
+	var late sync.WaitGroup
+	var dropped sync.Map
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		if errors.Is(err, ErrNotificationAfterResponse) {
+			dropped.Store(message.(map[string]any)["method"], true)
+		}
+	})
+	mcpServer := NewMCPServer("test", "1.0", WithHooks(hooks))
+
+	// The tool notifies from goroutines that race with its return
+	var stragglers sync.WaitGroup
+	mcpServer.AddTool(mcp.NewTool("racy"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		server := ServerFromContext(ctx)
+		_ = server.SendNotificationToClient(ctx, "test/before", nil)
+		for i := 0; i < 5; i++ {
+			stragglers.Add(1)
+			go func() {
+				defer stragglers.Done()
+				_ = server.SendNotificationToClient(ctx, "test/racing", map[string]any{"value": i})
+			}()
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	// The tool notifies once the test has read its response
+	releases := make(chan chan struct{}, 1)
+	mcpServer.AddTool(mcp.NewTool("late"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		server := ServerFromContext(ctx)
+		_ = server.SendNotificationToClient(ctx, "test/before", nil)
+		release := <-releases
+		late.Add(1)
+		go func() {
+			defer late.Done()
+			<-release
+			_ = server.SendNotificationToClient(ctx, "test/late", nil)
+		}()
+		return mcp.NewToolResultText("done"), nil
+	})
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+
+	call := func(t *testing.T, id int, tool string) []map[string]any {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"method":  "tools/call",
+			"params":  map[string]any{"name": tool},
+		})
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Errorf("Failed to call %s: %v", tool, err)
+			return nil
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Errorf("Failed to read response: %v", err)
+			return nil
+		}
+		return readSSEMessages(t, data)
+	}
+
+	t.Run("response is the last event", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for id := 2; id < 52; id++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				messages := call(t, id, "racy")
+				if len(messages) < 2 {
+					t.Errorf("Expected a notification and the response, got %v", messages)
+					return
+				}
+				if messages[0]["method"] != "test/before" {
+					t.Errorf("Expected the notification sent before returning first, got %v", messages[0])
+				}
+				last := messages[len(messages)-1]
+				if last["id"] != float64(id) || last["result"] == nil {
+					t.Errorf("Expected the response to %d last, got %v", id, last)
+				}
+				for _, message := range messages[:len(messages)-1] {
+					if message["id"] != nil {
+						t.Errorf("Expected only notifications before the response, got %v", message)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		stragglers.Wait()
+	})
+
+	t.Run("late notifications are dropped without a stream", func(t *testing.T) {
+		release := make(chan struct{})
+		releases <- release
+		messages := call(t, 100, "late")
+		close(release)
+		late.Wait()
+		if len(messages) != 2 || messages[1]["id"] != float64(100) {
+			t.Fatalf("Expected a notification and the response, got %v", messages)
+		}
+		deadline := time.Now().Add(time.Second)
+		for {
+			if _, ok := dropped.Load("test/late"); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the OnError hook to report the dropped notification")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	t.Run("late notifications go to the GET stream", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		stream, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to open GET stream: %v", err)
+		}
+		defer stream.Body.Close()
+
+		// The stream is registered by the time its headers are received
+		release := make(chan struct{})
+		releases <- release
+		call(t, 101, "late")
+		close(release)
+
+		reader := bufio.NewReader(stream.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Failed to read GET stream: %v", err)
+			}
+			if strings.Contains(line, "test/late") {
+				break
+			}
+		}
+		late.Wait()
+	})
+}
+
+func TestStreamableHTTP_StatsReporter(t *testing.T) {
+	reports := make(chan Stats, 100)
+	httpServer := NewStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"),
+		WithHTTPStatsReporter(5*time.Millisecond, func(stats Stats) {
+			select {
+			case reports <- stats:
+			default:
+			}
+		}),
+	)
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+	resp.Body.Close()
+
+	timeout := time.After(time.Second)
+	for processed := uint64(0); processed == 0; {
+		select {
+		case stats := <-reports:
+			processed = stats.Processed
+		case <-timeout:
+			t.Fatal("Timeout waiting for a report of the processed request")
+		}
+		if processed > 1 {
+			t.Errorf("Expected 1 processed request, got %d", processed)
+		}
+	}
+
+	// No reports are made once the server is shut down
+	if err := httpServer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	for len(reports) > 0 {
+		<-reports
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(reports) != 0 {
+		t.Errorf("Expected no reports after shutdown, got %d", len(reports))
+	}
+}
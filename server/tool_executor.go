@@ -0,0 +1,388 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrExecutorClosed is returned for tool calls made after a
+// SubprocessToolExecutor was closed.
+var ErrExecutorClosed = errors.New("tool executor closed")
+
+// ToolExecutor runs the calls of a tool registered with AddToolWithExecutor.
+// Implementations must be safe for concurrent use.
+type ToolExecutor interface {
+	// Execute runs the tool call described by request. handler is the
+	// handler the tool was registered with.
+	Execute(ctx context.Context, request mcp.CallToolRequest, handler ToolHandlerFunc) (*mcp.CallToolResult, error)
+}
+
+type inProcessExecutor struct{}
+
+func (inProcessExecutor) Execute(ctx context.Context, request mcp.CallToolRequest, handler ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	return handler(ctx, request)
+}
+
+// InProcessExecutor returns the default executor, which calls the tool
+// handler directly in the server process.
+func InProcessExecutor() ToolExecutor {
+	return inProcessExecutor{}
+}
+
+// AddToolWithExecutor registers a tool whose calls are run by executor. The
+// tool handler middlewares still run in the server process, around the
+// executor. A nil executor is the same as InProcessExecutor.
+func (s *MCPServer) AddToolWithExecutor(tool mcp.Tool, handler ToolHandlerFunc, executor ToolExecutor) {
+	if executor == nil {
+		executor = InProcessExecutor()
+	}
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return executor.Execute(ctx, request, handler)
+	})
+}
+
+// SubprocessExecutorOption configures a SubprocessToolExecutor.
+type SubprocessExecutorOption func(*SubprocessToolExecutor)
+
+// WithSubprocessPoolSize sets the number of worker processes, which is also
+// the number of tool calls run concurrently. It defaults to 1.
+func WithSubprocessPoolSize(size int) SubprocessExecutorOption {
+	return func(e *SubprocessToolExecutor) {
+		if size > 0 {
+			e.poolSize = size
+		}
+	}
+}
+
+// WithSubprocessCallTimeout limits the duration of each tool call. A worker
+// that does not answer in time is killed and replaced, and the call returns
+// a result with IsError set. By default calls are only bounded by their
+// context.
+func WithSubprocessCallTimeout(timeout time.Duration) SubprocessExecutorOption {
+	return func(e *SubprocessToolExecutor) {
+		e.callTimeout = timeout
+	}
+}
+
+// SubprocessToolExecutor runs tool calls in a pool of worker processes,
+// isolating the server from the tool code. Create it with
+// SubprocessExecutor.
+type SubprocessToolExecutor struct {
+	template    *exec.Cmd
+	poolSize    int
+	callTimeout time.Duration
+
+	// pool holds one entry per slot: an idle worker, or nil for a slot
+	// whose worker has to be started.
+	pool    chan *toolWorker
+	mu      sync.Mutex
+	workers map[*toolWorker]struct{}
+	closed  bool
+}
+
+// SubprocessExecutor returns an executor that runs tool calls in worker
+// processes started from cmdTemplate. Its Path, Args, Env, Dir, Stderr and
+// SysProcAttr are copied for each worker, so SysProcAttr can be used to
+// drop privileges or otherwise sandbox the workers.
+//
+// Workers must serve the tools with RunToolWorker. Requests are sent to them
+// over stdin and stdout using the stdio transport framing, one call at a
+// time per worker. The handler passed to AddToolWithExecutor is not called;
+// the worker runs the handler it registered under the tool's name.
+//
+// Workers are started right away and kept warm between calls. A worker that
+// crashes, or is killed because its call timed out or was cancelled, is
+// replaced; the call returns a result with IsError set rather than an error.
+// Call Close to stop the workers, e.g. from WithShutdownHook.
+func SubprocessExecutor(cmdTemplate *exec.Cmd, opts ...SubprocessExecutorOption) *SubprocessToolExecutor {
+	e := &SubprocessToolExecutor{
+		template: cmdTemplate,
+		poolSize: 1,
+		workers:  make(map[*toolWorker]struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	e.pool = make(chan *toolWorker, e.poolSize)
+	for range e.poolSize {
+		worker, _ := e.spawn()
+		e.pool <- worker
+	}
+	return e
+}
+
+// Execute runs the call in an idle worker, waiting for one if all are busy.
+func (e *SubprocessToolExecutor) Execute(ctx context.Context, request mcp.CallToolRequest, _ ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	worker, err := e.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	callCtx := ctx
+	if e.callTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, e.callTimeout)
+		defer cancel()
+	}
+
+	result, err := worker.call(callCtx, request)
+	var exitErr *workerExitError
+	switch {
+	case errors.As(err, &exitErr):
+		e.replace(worker)
+		return mcp.NewToolResultErrorf("tool %q failed: %v", request.Params.Name, exitErr), nil
+	case callCtx.Err() != nil && result == nil:
+		e.replace(worker)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return mcp.NewToolResultErrorf("tool %q timed out after %s", request.Params.Name, e.callTimeout), nil
+	default:
+		e.pool <- worker
+		return result, err
+	}
+}
+
+// Close kills the workers. Calls made afterwards fail with
+// ErrExecutorClosed.
+func (e *SubprocessToolExecutor) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	for worker := range e.workers {
+		worker.kill()
+	}
+	clear(e.workers)
+	return nil
+}
+
+func (e *SubprocessToolExecutor) isClosed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closed
+}
+
+// acquire takes a slot from the pool, starting a worker for it if needed.
+func (e *SubprocessToolExecutor) acquire(ctx context.Context) (*toolWorker, error) {
+	select {
+	case worker := <-e.pool:
+		if e.isClosed() {
+			e.pool <- nil
+			return nil, ErrExecutorClosed
+		}
+		if worker != nil && worker.alive() {
+			return worker, nil
+		}
+		if worker != nil {
+			e.discard(worker)
+		}
+		worker, err := e.spawn()
+		if err != nil {
+			e.pool <- nil
+			return nil, err
+		}
+		return worker, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// replace kills worker and starts a new one for its slot in the background.
+func (e *SubprocessToolExecutor) replace(worker *toolWorker) {
+	e.discard(worker)
+	go func() {
+		worker, _ := e.spawn()
+		e.pool <- worker
+	}()
+}
+
+func (e *SubprocessToolExecutor) discard(worker *toolWorker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.workers, worker)
+	worker.kill()
+}
+
+func (e *SubprocessToolExecutor) spawn() (*toolWorker, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil, ErrExecutorClosed
+	}
+	if e.template.Err != nil {
+		return nil, e.template.Err
+	}
+
+	cmd := &exec.Cmd{
+		Path:        e.template.Path,
+		Args:        slices.Clone(e.template.Args),
+		Env:         slices.Clone(e.template.Env),
+		Dir:         e.template.Dir,
+		Stderr:      e.template.Stderr,
+		SysProcAttr: e.template.SysProcAttr,
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start tool worker: %w", err)
+	}
+
+	worker := &toolWorker{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+		exited: make(chan struct{}),
+	}
+	go func() {
+		worker.waitErr = cmd.Wait()
+		close(worker.exited)
+	}()
+	e.workers[worker] = struct{}{}
+	return worker, nil
+}
+
+// workerExitError reports a worker that exited during a call.
+type workerExitError struct {
+	err error
+}
+
+func (e *workerExitError) Error() string {
+	if e.err == nil {
+		return "tool worker exited"
+	}
+	return fmt.Sprintf("tool worker exited: %v", e.err)
+}
+
+func (e *workerExitError) Unwrap() error {
+	return e.err
+}
+
+// toolWorker is a worker process. It handles one call at a time.
+type toolWorker struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+	nextID  int64
+	exited  chan struct{}
+	waitErr error
+}
+
+type toolWorkerResponse struct {
+	ID     mcp.RequestId   `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (w *toolWorker) alive() bool {
+	select {
+	case <-w.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+func (w *toolWorker) kill() {
+	_ = w.stdin.Close()
+	_ = w.cmd.Process.Kill()
+}
+
+// call sends request to the worker and waits for its response.
+func (w *toolWorker) call(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	w.nextID++
+	id := mcp.NewRequestId(w.nextID)
+	data, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Params:  request.Params,
+		Request: mcp.Request{Method: string(mcp.MethodToolsCall)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tool call: %w", err)
+	}
+
+	type outcome struct {
+		response *toolWorkerResponse
+		err      error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		response, err := w.readResponse(id)
+		done <- outcome{response, err}
+	}()
+	if _, err := w.stdin.Write(append(data, '\n')); err != nil {
+		w.kill()
+	}
+
+	select {
+	case out := <-done:
+		if out.err != nil {
+			return nil, out.err
+		}
+		if out.response.Error != nil {
+			return nil, fmt.Errorf("tool worker: %s (code %d)", out.response.Error.Message, out.response.Error.Code)
+		}
+		return mcp.ParseCallToolResult(&out.response.Result)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readResponse reads messages until the response to id, skipping the
+// notifications and requests the worker sends.
+func (w *toolWorker) readResponse(id mcp.RequestId) (*toolWorkerResponse, error) {
+	for {
+		line, err := w.stdout.ReadBytes('\n')
+		if err != nil {
+			<-w.exited
+			return nil, &workerExitError{err: w.waitErr}
+		}
+		var response toolWorkerResponse
+		if err := json.Unmarshal(line, &response); err != nil || response.Method != "" {
+			continue
+		}
+		if response.ID.String() == id.String() {
+			return &response, nil
+		}
+	}
+}
+
+// RunToolWorker serves handlers, keyed by tool name, to a
+// SubprocessToolExecutor over stdin and stdout. It returns when stdin is
+// closed, which happens when the executor kills the worker or the server
+// process exits.
+//
+// A program usually acts as both the server and its workers, selecting the
+// worker mode with an argument passed in the executor's command:
+//
+//	if len(os.Args) > 1 && os.Args[1] == "tool-worker" {
+//		log.Fatal(server.RunToolWorker(handlers))
+//	}
+func RunToolWorker(handlers map[string]ToolHandlerFunc) error {
+	s := NewMCPServer("tool-worker", "1.0.0", WithToolCapabilities(false))
+	for name, handler := range handlers {
+		s.AddTool(mcp.NewTool(name), handler)
+	}
+	return ServeStdio(s)
+}
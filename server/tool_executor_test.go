@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mockToolWorker is the mock tool worker binary, built once for all tests that
+// need it and removed by TestMain.
+var mockToolWorker struct {
+	once sync.Once
+	dir  string
+	path string
+	err  error
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if mockToolWorker.dir != "" {
+		_ = os.RemoveAll(mockToolWorker.dir)
+	}
+	os.Exit(code)
+}
+
+func compileToolWorker(t *testing.T) string {
+	t.Helper()
+	mockToolWorker.once.Do(func() {
+		mockToolWorker.dir, mockToolWorker.err = os.MkdirTemp("", "mocktoolworker")
+		if mockToolWorker.err != nil {
+			return
+		}
+		mockToolWorker.path = filepath.Join(mockToolWorker.dir, "mocktoolworker")
+		if runtime.GOOS == "windows" {
+			mockToolWorker.path += ".exe"
+		}
+		cmd := exec.Command("go", "build", "-o", mockToolWorker.path, "../testdata/mocktoolworker")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			mockToolWorker.err = fmt.Errorf("compilation failed: %w: %s", err, output)
+		}
+	})
+	require.NoError(t, mockToolWorker.err)
+	return mockToolWorker.path
+}
+
+func callExecutorTool(t *testing.T, srv *MCPServer, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.NewCallToolRequest(name, args)
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, mustMarshal(t, request.Params))
+	response := srv.HandleMessage(context.Background(), []byte(message))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected a response, got %#v", response)
+	result := resp.Result.(mcp.CallToolResult)
+	return &result
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func newSubprocessServer(t *testing.T, opts ...SubprocessExecutorOption) (*MCPServer, *SubprocessToolExecutor) {
+	t.Helper()
+	executor := SubprocessExecutor(exec.Command(compileToolWorker(t)), opts...)
+	t.Cleanup(func() { _ = executor.Close() })
+
+	srv := NewMCPServer("test", "1.0.0", WithToolCapabilities(false))
+	for _, name := range []string{"echo", "crash", "sleep"} {
+		srv.AddToolWithExecutor(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			t.Error("the handler must not run in the server process")
+			return nil, nil
+		}, executor)
+	}
+	return srv, executor
+}
+
+func TestSubprocessExecutor_Success(t *testing.T) {
+	srv, _ := newSubprocessServer(t, WithSubprocessPoolSize(2))
+
+	result := callExecutorTool(t, srv, "echo", map[string]any{"message": "hello"})
+	require.False(t, result.IsError, resultText(result))
+	assert.True(t, strings.HasPrefix(resultText(result), "hello from "))
+	assert.NotEqual(t, fmt.Sprintf("hello from %d", os.Getpid()), resultText(result), "the tool runs in another process")
+
+	// Workers are reused between calls
+	pids := map[string]bool{}
+	for range 6 {
+		pids[resultText(callExecutorTool(t, srv, "echo", map[string]any{"message": "hello"}))] = true
+	}
+	assert.LessOrEqual(t, len(pids), 2)
+}
+
+func TestSubprocessExecutor_Crash(t *testing.T) {
+	srv, _ := newSubprocessServer(t)
+
+	result := callExecutorTool(t, srv, "crash", nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(result), "tool worker exited")
+
+	// The crashed worker is replaced
+	result = callExecutorTool(t, srv, "echo", map[string]any{"message": "again"})
+	require.False(t, result.IsError, resultText(result))
+	assert.True(t, strings.HasPrefix(resultText(result), "again from "))
+}
+
+func TestSubprocessExecutor_Timeout(t *testing.T) {
+	srv, _ := newSubprocessServer(t, WithSubprocessCallTimeout(500*time.Millisecond))
+
+	start := time.Now()
+	result := callExecutorTool(t, srv, "sleep", nil)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(result), "timed out")
+
+	result = callExecutorTool(t, srv, "echo", map[string]any{"message": "awake"})
+	require.False(t, result.IsError, resultText(result))
+	assert.True(t, strings.HasPrefix(resultText(result), "awake from "))
+}
+
+func TestSubprocessExecutor_Close(t *testing.T) {
+	srv, executor := newSubprocessServer(t)
+	require.NoError(t, executor.Close())
+
+	_, err := executor.Execute(context.Background(), mcp.NewCallToolRequest("echo", nil), nil)
+	assert.ErrorIs(t, err, ErrExecutorClosed)
+
+	response := srv.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`))
+	_, ok := response.(mcp.JSONRPCError)
+	assert.True(t, ok, "expected a JSON-RPC error, got %T", response)
+}
+
+func TestAddToolWithExecutor_InProcess(t *testing.T) {
+	srv := NewMCPServer("test", "1.0.0", WithToolCapabilities(false))
+	srv.AddToolWithExecutor(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("message", "")), nil
+	}, nil)
+
+	result := callExecutorTool(t, srv, "echo", map[string]any{"message": "local"})
+	assert.Equal(t, "local", resultText(result))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TrafficDirection tells whether a recorded message was received or sent by
+// the server.
+type TrafficDirection string
+
+const (
+	// TrafficInbound marks a message received from a client.
+	TrafficInbound TrafficDirection = "inbound"
+	// TrafficOutbound marks a response or notification sent to a client.
+	TrafficOutbound TrafficDirection = "outbound"
+)
+
+// TrafficRecord is one line of the stream written by WithTrafficRecorder.
+type TrafficRecord struct {
+	Time      time.Time        `json:"time"`
+	Direction TrafficDirection `json:"direction"`
+	// SessionID is the ID of the client session the message belongs to, if
+	// the message was exchanged within a session.
+	SessionID string `json:"sessionId,omitempty"`
+	// Message is the JSON-RPC message. Inbound messages that are not valid
+	// JSON are recorded as a JSON string holding the raw bytes.
+	Message json.RawMessage `json:"message"`
+}
+
+// TrafficRedactor rewrites a message before it is recorded, for example to
+// mask secrets in tool arguments. Returning nil drops the message from the
+// recording. It runs after the server's Redactor, if any.
+type TrafficRedactor func(direction TrafficDirection, message json.RawMessage) json.RawMessage
+
+// TrafficRecorderOption configures WithTrafficRecorder.
+type TrafficRecorderOption func(*trafficRecorder)
+
+// WithTrafficRedactor sets the function applied to every message before it
+// is recorded.
+func WithTrafficRedactor(redact TrafficRedactor) TrafficRecorderOption {
+	return func(r *trafficRecorder) {
+		r.redact = redact
+	}
+}
+
+// WithTrafficRecorder appends every message handled by the server to w as a
+// JSONL stream of TrafficRecord values: the requests and notifications it
+// receives, and the responses and notifications it sends. The stream can be
+// fed back to a server with mcptest.Replay. Writes are serialized; write
+// errors are ignored so that recording never affects the traffic itself.
+// Recording is disabled unless this option is set.
+func WithTrafficRecorder(w io.Writer, opts ...TrafficRecorderOption) ServerOption {
+	return func(s *MCPServer) {
+		r := &trafficRecorder{w: w}
+		for _, opt := range opts {
+			opt(r)
+		}
+		s.trafficRecorder = r
+	}
+}
+
+type trafficRecorder struct {
+	mu       sync.Mutex
+	w        io.Writer
+	redactor Redactor
+	redact   TrafficRedactor
+}
+
+// recordInbound records a message received by HandleMessage.
+func (r *trafficRecorder) recordInbound(ctx context.Context, message []byte) {
+	var raw json.RawMessage
+	if json.Valid(message) {
+		raw = append(json.RawMessage(nil), message...)
+	} else {
+		encoded, err := json.Marshal(string(message))
+		if err != nil {
+			return
+		}
+		raw = encoded
+	}
+	r.record(sessionIDFromContext(ctx), TrafficInbound, raw)
+}
+
+// recordOutbound records a response or notification sent by the server.
+func (r *trafficRecorder) recordOutbound(sessionID string, message mcp.JSONRPCMessage) {
+	if message == nil {
+		return
+	}
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	r.record(sessionID, TrafficOutbound, raw)
+}
+
+func (r *trafficRecorder) record(sessionID string, direction TrafficDirection, message json.RawMessage) {
+	if r.redactor != nil {
+		if message = r.redactor.RedactJSON(message); message == nil {
+			return
+		}
+	}
+	if r.redact != nil {
+		if message = r.redact(direction, message); message == nil {
+			return
+		}
+	}
+	line, err := json.Marshal(TrafficRecord{
+		Time:      time.Now(),
+		Direction: direction,
+		SessionID: sessionID,
+		Message:   message,
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.w.Write(line)
+}
+
+func sessionIDFromContext(ctx context.Context) string {
+	if session := ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
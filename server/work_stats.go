@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the request processing counters of a transport
+// server.
+type Stats struct {
+	// QueueDepth is the number of requests waiting for a worker.
+	QueueDepth int
+	// ActiveWorkers is the number of requests currently being handled.
+	ActiveWorkers int
+	// Processed is the number of requests handled to completion.
+	Processed uint64
+	// Rejected is the number of requests refused because the server was
+	// busy.
+	Rejected uint64
+}
+
+// workStats collects the counters reported as Stats. It is safe for
+// concurrent use and shared by the transports that process requests in the
+// background.
+type workStats struct {
+	queued    atomic.Int64
+	active    atomic.Int64
+	processed atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+// enqueue records a request waiting for a worker. The returned function must
+// be called once a worker picks the request up.
+func (w *workStats) enqueue() func() {
+	w.queued.Add(1)
+	return func() { w.queued.Add(-1) }
+}
+
+// begin records a request being handled. The returned function must be
+// called once the request is done.
+func (w *workStats) begin() func() {
+	w.active.Add(1)
+	return func() {
+		w.active.Add(-1)
+		w.processed.Add(1)
+	}
+}
+
+func (w *workStats) reject() {
+	w.rejected.Add(1)
+}
+
+func (w *workStats) snapshot() Stats {
+	return Stats{
+		QueueDepth:    int(w.queued.Load()),
+		ActiveWorkers: int(w.active.Load()),
+		Processed:     w.processed.Load(),
+		Rejected:      w.rejected.Load(),
+	}
+}
+
+// reportStats calls report with a snapshot of stats every interval until ctx
+// is done.
+func reportStats(ctx context.Context, stats *workStats, interval time.Duration, report func(Stats)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			report(stats.snapshot())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -68,6 +68,7 @@ func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 				"tools": map[string]any{
 					"listChanged": true,
 				},
+				"logging": map[string]any{},
 			},
 		}
 	case "ping":